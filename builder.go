@@ -0,0 +1,148 @@
+package sitemap
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"time"
+)
+
+const (
+	// maxLocLength is the maximum length of a <loc> value according to the sitemaps.org protocol.
+	maxLocLength = 2048
+)
+
+type (
+	// Builder is a structure to construct sitemaps programmatically.
+	// The urls field is a slice of URL structs that stores the valid URLs added.
+	// The errs field is a slice of errors that holds the validation errors of the invalid URLs added.
+	Builder struct {
+		urls []URL
+		errs []error
+	}
+
+	// URLOption is a function that sets an optional field of a URL added to a Builder.
+	URLOption func(*URL)
+)
+
+// NewBuilder creates a new instance of the Builder structure and returns a pointer to it.
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// WithLastMod sets the <lastmod> value of the URL.
+func WithLastMod(lastMod time.Time) URLOption {
+	return func(u *URL) {
+		u.LastMod = NewLastMod(lastMod)
+	}
+}
+
+// WithChangeFreq sets the <changefreq> value of the URL.
+// Possible values are: "always", "hourly", "daily", "weekly", "monthly", "yearly", and "never".
+func WithChangeFreq(changeFreq string) URLOption {
+	return func(u *URL) {
+		cf := urlChangeFreq(changeFreq)
+		u.ChangeFreq = &cf
+	}
+}
+
+// WithPriority sets the <priority> value of the URL. It should be between 0.0 and 1.0, NaN is invalid.
+func WithPriority(priority float32) URLOption {
+	return func(u *URL) {
+		u.Priority = &priority
+	}
+}
+
+// WithImage adds an <image:image> entry to the URL.
+func WithImage(image Image) URLOption {
+	return func(u *URL) {
+		u.Images = append(u.Images, image)
+	}
+}
+
+// WithVideo adds a <video:video> entry to the URL.
+func WithVideo(video Video) URLOption {
+	return func(u *URL) {
+		u.Videos = append(u.Videos, video)
+	}
+}
+
+// WithNews sets the <news:news> entry of the URL.
+func WithNews(news News) URLOption {
+	return func(u *URL) {
+		u.News = &news
+	}
+}
+
+// WithAlternate adds an <xhtml:link rel="alternate"> entry with the given language and location to the URL.
+func WithAlternate(hreflang string, href string) URLOption {
+	return func(u *URL) {
+		u.Alternates = append(u.Alternates, Alternate{Rel: "alternate", Hreflang: hreflang, Href: href})
+	}
+}
+
+// AddURL adds a URL with the given location and options to the Builder.
+// The URL is validated: the location must be an absolute http or https URL of at most 2048 characters,
+// the change frequency must be a valid value and the priority must be between 0.0 and 1.0.
+// Invalid URLs are not added, their validation errors are collected and can be retrieved using Errors().
+// If the Builder is nil, nil is returned.
+// The function returns a pointer to the Builder structure to allow method chaining.
+func (b *Builder) AddURL(loc string, opts ...URLOption) *Builder {
+	if b == nil {
+		return nil
+	}
+	u := URL{Loc: loc}
+	for _, opt := range opts {
+		opt(&u)
+	}
+
+	if err := validateURL(u); err != nil {
+		b.errs = append(b.errs, err)
+		return b
+	}
+	b.urls = append(b.urls, u)
+
+	return b
+}
+
+// Errors returns a copy of the validation errors of the invalid URLs added to the Builder.
+// If the Builder is nil or every URL is valid, nil is returned.
+func (b *Builder) Errors() []error {
+	if b == nil {
+		return nil
+	}
+	return append([]error(nil), b.errs...)
+}
+
+// Build returns a URLSet containing the valid URLs added to the Builder, ready to be written with WriteXML.
+// If the Builder is nil, an empty URLSet is returned.
+func (b *Builder) Build() URLSet {
+	if b == nil {
+		return URLSet{}
+	}
+	urls := make([]URL, len(b.urls))
+	copy(urls, b.urls)
+	return URLSet{URL: urls}
+}
+
+// validateURL checks whether the URL complies with the sitemaps.org protocol.
+// It returns an error describing the first violation found, or nil if the URL is valid.
+func validateURL(u URL) error {
+	if len(u.Loc) > maxLocLength {
+		return fmt.Errorf("invalid URL %q: loc exceeds %d characters", u.Loc, maxLocLength)
+	}
+	parsedURL, err := url.Parse(u.Loc)
+	if err != nil {
+		return fmt.Errorf("invalid URL %q: %w", u.Loc, err)
+	}
+	if (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+		return fmt.Errorf("invalid URL %q: loc must be an absolute http or https URL", u.Loc)
+	}
+	if u.ChangeFreq != nil && !u.ChangeFreq.valid() {
+		return fmt.Errorf("invalid URL %q: invalid changefreq %q", u.Loc, *u.ChangeFreq)
+	}
+	if u.Priority != nil && (math.IsNaN(float64(*u.Priority)) || *u.Priority < 0 || *u.Priority > 1) {
+		return fmt.Errorf("invalid URL %q: priority %v is out of range 0.0-1.0", u.Loc, *u.Priority)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package sitemap
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuilder_AddURL(t *testing.T) {
+	lastMod := time.Date(2024, time.February, 12, 12, 34, 56, 0, time.FixedZone("", 3600))
+
+	tests := []struct {
+		name     string
+		loc      string
+		opts     []URLOption
+		wantURLs []URL
+		wantErrs []error
+	}{
+		{
+			name:     "loc only",
+			loc:      "https://www.sitemaps.org/",
+			wantURLs: []URL{{Loc: "https://www.sitemaps.org/"}},
+		},
+		{
+			name: "all options",
+			loc:  "https://www.sitemaps.org/",
+			opts: []URLOption{
+				WithLastMod(lastMod),
+				WithChangeFreq("daily"),
+				WithPriority(0.8),
+				WithImage(Image{Loc: "https://www.sitemaps.org/1.jpg"}),
+				WithVideo(Video{Title: "Video"}),
+				WithNews(News{Title: "News"}),
+				WithAlternate("de", "https://www.sitemaps.org/de/"),
+			},
+			wantURLs: []URL{
+				{
+					Loc:        "https://www.sitemaps.org/",
+					LastMod:    pointerOfLastModTime(LastMod{lastMod}),
+					ChangeFreq: pointerOfURLChangeFreq(changeFreqDaily),
+					Priority:   pointerOfFloat32(0.8),
+					Images:     []Image{{Loc: "https://www.sitemaps.org/1.jpg"}},
+					Videos:     []Video{{Title: "Video"}},
+					News:       &News{Title: "News"},
+					Alternates: []Alternate{{Rel: "alternate", Hreflang: "de", Href: "https://www.sitemaps.org/de/"}},
+				},
+			},
+		},
+		{
+			name:     "relative loc",
+			loc:      "/page",
+			wantErrs: []error{errors.New("invalid URL \"/page\": loc must be an absolute http or https URL")},
+		},
+		{
+			name:     "unsupported scheme",
+			loc:      "ftp://www.sitemaps.org/",
+			wantErrs: []error{errors.New("invalid URL \"ftp://www.sitemaps.org/\": loc must be an absolute http or https URL")},
+		},
+		{
+			name:     "unparseable loc",
+			loc:      "https://www.sitemaps.org/%zz",
+			wantErrs: []error{errors.New("invalid URL \"https://www.sitemaps.org/%zz\": parse \"https://www.sitemaps.org/%zz\": invalid URL escape \"%zz\"")},
+		},
+		{
+			name:     "too long loc",
+			loc:      "https://www.sitemaps.org/" + strings.Repeat("a", 2048),
+			wantErrs: []error{errors.New("invalid URL \"https://www.sitemaps.org/" + strings.Repeat("a", 2048) + "\": loc exceeds 2048 characters")},
+		},
+		{
+			name:     "invalid changefreq",
+			loc:      "https://www.sitemaps.org/",
+			opts:     []URLOption{WithChangeFreq("sometimes")},
+			wantErrs: []error{errors.New("invalid URL \"https://www.sitemaps.org/\": invalid changefreq \"sometimes\"")},
+		},
+		{
+			name:     "invalid priority",
+			loc:      "https://www.sitemaps.org/",
+			opts:     []URLOption{WithPriority(1.5)},
+			wantErrs: []error{errors.New("invalid URL \"https://www.sitemaps.org/\": priority 1.5 is out of range 0.0-1.0")},
+		},
+		{
+			name:     "NaN priority",
+			loc:      "https://www.sitemaps.org/",
+			opts:     []URLOption{WithPriority(float32(math.NaN()))},
+			wantErrs: []error{errors.New("invalid URL \"https://www.sitemaps.org/\": priority NaN is out of range 0.0-1.0")},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b := NewBuilder().AddURL(test.loc, test.opts...)
+
+			urlSet := b.Build()
+			if len(urlSet.URL) != len(test.wantURLs) || (len(test.wantURLs) > 0 && !reflect.DeepEqual(urlSet.URL, test.wantURLs)) {
+				t.Errorf("expected %+v, got %+v", test.wantURLs, urlSet.URL)
+			}
+
+			errs := b.Errors()
+			if len(errs) != len(test.wantErrs) {
+				t.Fatalf("expected %v, got %v", test.wantErrs, errs)
+			}
+			for i, err := range errs {
+				if err.Error() != test.wantErrs[i].Error() {
+					t.Errorf("expected %v, got %v", test.wantErrs[i], err)
+				}
+			}
+		})
+	}
+}
+
+func TestBuilder_Build(t *testing.T) {
+	b := NewBuilder().
+		AddURL("https://www.sitemaps.org/1", WithPriority(1)).
+		AddURL("invalid").
+		AddURL("https://www.sitemaps.org/2", WithChangeFreq("never"))
+
+	if len(b.Errors()) != 1 {
+		t.Errorf("expected 1 error, got %d", len(b.Errors()))
+	}
+
+	var buf bytes.Buffer
+	if err := WriteXML(&buf, b.Build().URL); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	s := New()
+	s.parse("https://www.sitemaps.org/sitemap.xml", buf.String())
+	if s.GetErrorsCount() > 0 {
+		t.Fatalf("unexpected errors: %v", s.GetErrors())
+	}
+	if !compareURLsLocs(s.GetURLs(), b.Build().URL) {
+		t.Errorf("expected %v, got %v", b.Build().URL, s.GetURLs())
+	}
+
+	// the returned errors must not share the Builder's slice
+	b.Errors()[0] = nil
+	if b.Errors()[0] == nil {
+		t.Error("Errors() returned the internal slice")
+	}
+
+	// the returned URLSet must not share the Builder's slice
+	urlSet := b.Build()
+	urlSet.URL[0].Loc = "modified"
+	if b.Build().URL[0].Loc != "https://www.sitemaps.org/1" {
+		t.Error("Build() returned the internal slice")
+	}
+
+	var nilBuilder *Builder
+	if len(nilBuilder.Build().URL) != 0 || nilBuilder.Errors() != nil {
+		t.Error("expected empty results on nil receiver")
+	}
+}
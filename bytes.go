@@ -0,0 +1,119 @@
+package sitemap
+
+import (
+	"bytes"
+	"errors"
+	neturl "net/url"
+	"strings"
+	"time"
+)
+
+// DecodeOptions holds the options of decoding a document with its ParseURLSetBytes and ParseSitemapIndexBytes methods,
+// the same as the corresponding settings of the Sitemap Parser. The zero value means the defaults.
+// The LastModFormats field is the list of layouts of the <lastmod> values of the URLs, nil means the defaults, see SetLastModFormats.
+// The LastModLocation field is the location of the <lastmod> values without zone information, nil means UTC, see SetDefaultLastModLocation.
+// The Fields field is the list of the child elements of <url> decoded, empty means all of them, see SetFields.
+// The Limits field holds the limits of the decoding, see SetDecodeLimits.
+// The MaxURLs field is the maximum number of <url> entries decoded, the rest of the document is not read; 0 means no limit.
+// The TrackPositions field determines whether the position of each <url> element is recorded, see SetTrackPositions.
+type DecodeOptions struct {
+	LastModFormats  []string
+	LastModLocation *time.Location
+	Fields          []Field
+	Limits          DecodeLimits
+	MaxURLs         int
+	TrackPositions  bool
+}
+
+// errEmptyURLSet and errEmptySitemapIndex are the errors of decoding an empty document.
+var (
+	errEmptyURLSet       = errors.New("sitemap is empty")
+	errEmptySitemapIndex = errors.New("sitemapindex is empty")
+)
+
+// ParseURLSetBytes decodes a <urlset> document, e.g. a message of a stream processing job, with the default options.
+// It is the same as DecodeOptions{}.ParseURLSetBytes(data).
+func ParseURLSetBytes(data []byte) (URLSet, error) {
+	return DecodeOptions{}.ParseURLSetBytes(data)
+}
+
+// ParseSitemapIndexBytes decodes a <sitemapindex> document with the default options.
+// It is the same as DecodeOptions{}.ParseSitemapIndexBytes(data).
+func ParseSitemapIndexBytes(data []byte) (SitemapIndex, error) {
+	return DecodeOptions{}.ParseSitemapIndexBytes(data)
+}
+
+// ParseURLSetBytes decodes a <urlset> document with the options, the same way Parse decodes the sitemaps it fetches.
+// The document must not be compressed. It returns an error if the document is empty, cannot be decoded or its root element is not <urlset>,
+// ErrDoctypeNotAllowed if it has a DOCTYPE declaration, and a DecodeLimitError if it exceeds the limits.
+func (o DecodeOptions) ParseURLSetBytes(data []byte) (URLSet, error) {
+	if len(data) == 0 {
+		return URLSet{}, errEmptyURLSet
+	}
+	return decodeURLSet(bytes.NewReader(data), o.decodeOptions())
+}
+
+// ParseSitemapIndexBytes decodes a <sitemapindex> document with the options, the same way Parse decodes the sitemap indexes it fetches.
+// The document must not be compressed. It returns an error if the document is empty, cannot be decoded or its root element is not <sitemapindex>,
+// ErrDoctypeNotAllowed if it has a DOCTYPE declaration, and a DecodeLimitError if it exceeds the limits.
+func (o DecodeOptions) ParseSitemapIndexBytes(data []byte) (SitemapIndex, error) {
+	if len(data) == 0 {
+		return SitemapIndex{}, errEmptySitemapIndex
+	}
+	return decodeSitemapIndex(bytes.NewReader(data), o.decodeOptions())
+}
+
+// decodeOptions returns the options of the decoding.
+func (o DecodeOptions) decodeOptions() decodeOptions {
+	return decodeOptions{
+		lastModFormats:  o.LastModFormats,
+		lastModLocation: o.LastModLocation,
+		maxURLs:         o.MaxURLs,
+		limits:          o.Limits,
+		fields:          fieldSet(o.Fields),
+		trackPositions:  o.TrackPositions,
+	}
+}
+
+// DetectKind returns the kind of the given uncompressed document, without decoding it in full:
+// SitemapKindURLSet or SitemapKindIndex for an XML document with a <urlset> or a <sitemapindex> root element,
+// SitemapKindRobotsTXT for a text document with at least one robots.txt directive (e.g. "User-agent:" or "Sitemap:"),
+// SitemapKindText for a text document listing only absolute http or https URLs, one per line,
+// and SitemapKindUnknown for any other document, including other XML documents and empty ones.
+func DetectKind(data []byte) SitemapKind {
+	content := strings.TrimLeft(strings.TrimPrefix(string(data), "\ufeff"), " \t\r\n")
+	if strings.HasPrefix(content, "<") {
+		return detectKind(content)
+	}
+
+	urls := 0
+	for _, line := range strings.FieldsFunc(content, func(r rune) bool { return r == '\n' || r == '\r' }) {
+		if robotsDirective(line) {
+			return SitemapKindRobotsTXT
+		}
+		if line = strings.TrimSpace(line); line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if u, err := neturl.Parse(line); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return SitemapKindUnknown
+		}
+		urls++
+	}
+	if urls == 0 {
+		return SitemapKindUnknown
+	}
+	return SitemapKindText
+}
+
+// robotsDirective reports whether the given line is a robots.txt directive of a common name, see extractRobotsSitemaps.
+func robotsDirective(line string) bool {
+	name, _, found := strings.Cut(line, ":")
+	if !found {
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "user-agent", "allow", "disallow", "sitemap", "crawl-delay", "host":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,194 @@
+package sitemap
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseURLSetBytes(t *testing.T) {
+	data, err := os.ReadFile("./test/sitemap-02.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	urlSet, err := ParseURLSetBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := locsOf(urlSet.URL); !reflect.DeepEqual(got, []string{"http://HOST/page-02", "http://HOST/page-03"}) {
+		t.Errorf("unexpected locations %v", got)
+	}
+	u := urlSet.URL[0]
+	if u.LastMod == nil || !u.LastMod.Equal(time.Date(2024, 2, 12, 11, 34, 56, 0, time.UTC)) || u.ChangeFreq == nil || u.Priority == nil {
+		t.Errorf("expected all the fields to be decoded, got %+v", u)
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{name: "empty", data: nil, wantErr: errEmptyURLSet},
+		{name: "sitemap index", data: []byte(`<sitemapindex><sitemap><loc>https://www.example.com/sitemap.xml</loc></sitemap></sitemapindex>`)},
+		{name: "DOCTYPE", data: []byte(`<!DOCTYPE urlset [<!ENTITY a "a">]><urlset></urlset>`), wantErr: ErrDoctypeNotAllowed},
+		{name: "invalid", data: []byte(`<urlset><url>`)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseURLSetBytes(test.data)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if test.wantErr != nil && !errors.Is(err, test.wantErr) {
+				t.Errorf("expected %v, got %v", test.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestDecodeOptions_ParseURLSetBytes(t *testing.T) {
+	data := []byte(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+<url><loc>https://www.example.com/a</loc><lastmod>2024-02-12</lastmod><priority>0.5</priority></url>
+<url><loc>https://www.example.com/b</loc><lastmod>2024-02-13</lastmod><priority>0.8</priority></url>
+<url><loc>https://www.example.com/c</loc></url>
+</urlset>`)
+
+	tests := []struct {
+		name  string
+		opts  DecodeOptions
+		data  []byte
+		check func(t *testing.T, urlSet URLSet)
+	}{
+		{
+			name: "defaults",
+			check: func(t *testing.T, urlSet URLSet) {
+				if len(urlSet.URL) != 3 || urlSet.URL[0].LastMod == nil || urlSet.URL[0].Priority == nil || urlSet.URL[0].Position != nil {
+					t.Errorf("unexpected URLs %+v", urlSet.URL)
+				}
+			},
+		},
+		{
+			name: "lastmod formats",
+			opts: DecodeOptions{LastModFormats: []string{"02/01/2006"}},
+			data: []byte(`<urlset><url><loc>https://www.example.com/a</loc></url><url><loc>https://www.example.com/b</loc><lastmod>13/02/2024</lastmod></url></urlset>`),
+			check: func(t *testing.T, urlSet URLSet) {
+				if lastMod := urlSet.URL[1].LastMod; lastMod == nil || !lastMod.Equal(time.Date(2024, 2, 13, 0, 0, 0, 0, time.UTC)) {
+					t.Errorf("expected the lastmod to be parsed, got %v", lastMod)
+				}
+			},
+		},
+		{
+			name: "lastmod location",
+			opts: DecodeOptions{LastModLocation: time.FixedZone("UTC+2", 2*60*60)},
+			check: func(t *testing.T, urlSet URLSet) {
+				if lastMod := urlSet.URL[0].LastMod; lastMod == nil || lastMod.Format(time.RFC3339) != "2024-02-12T00:00:00+02:00" {
+					t.Errorf("expected the lastmod in the location, got %v", lastMod)
+				}
+			},
+		},
+		{
+			name: "fields",
+			opts: DecodeOptions{Fields: []Field{FieldLoc}},
+			check: func(t *testing.T, urlSet URLSet) {
+				if len(urlSet.URL) != 3 || urlSet.URL[0].Loc != "https://www.example.com/a" || urlSet.URL[0].Priority != nil {
+					t.Errorf("expected only the locations to be decoded, got %+v", urlSet.URL[0])
+				}
+			},
+		},
+		{
+			name: "max URLs",
+			opts: DecodeOptions{MaxURLs: 2},
+			check: func(t *testing.T, urlSet URLSet) {
+				if got := locsOf(urlSet.URL); !reflect.DeepEqual(got, []string{"https://www.example.com/a", "https://www.example.com/b"}) {
+					t.Errorf("expected the first 2 URLs, got %v", got)
+				}
+			},
+		},
+		{
+			name: "positions",
+			opts: DecodeOptions{TrackPositions: true},
+			check: func(t *testing.T, urlSet URLSet) {
+				if position := urlSet.URL[1].Position; position == nil || position.Line != 3 || position.Column != 1 {
+					t.Errorf("expected the position 3:1, got %v", position)
+				}
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.data == nil {
+				test.data = data
+			}
+			urlSet, err := test.opts.ParseURLSetBytes(test.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			test.check(t, urlSet)
+		})
+	}
+
+	t.Run("limits", func(t *testing.T) {
+		_, err := DecodeOptions{Limits: DecodeLimits{MaxTokens: 5}}.ParseURLSetBytes(data)
+		var limitErr *DecodeLimitError
+		if !errors.As(err, &limitErr) || limitErr.Limit != DecodeLimitTokens {
+			t.Errorf("expected a DecodeLimitError of the tokens, got %v", err)
+		}
+	})
+}
+
+func TestParseSitemapIndexBytes(t *testing.T) {
+	data, err := os.ReadFile("./test/sitemapindex-1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	smIndex, err := ParseSitemapIndexBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(smIndex.Sitemap) != 3 || smIndex.Sitemap[0].Loc != "http://HOST/sitemap-01.xml" || smIndex.Sitemap[0].LastMod == nil {
+		t.Errorf("unexpected sitemaps %+v", smIndex.Sitemap)
+	}
+
+	if _, err := ParseSitemapIndexBytes(nil); !errors.Is(err, errEmptySitemapIndex) {
+		t.Errorf("expected %v, got %v", errEmptySitemapIndex, err)
+	}
+	if _, err := ParseSitemapIndexBytes([]byte(`<urlset></urlset>`)); err == nil {
+		t.Error("expected an error for a <urlset>")
+	}
+	if _, err := (DecodeOptions{Limits: DecodeLimits{MaxDepth: 1}}).ParseSitemapIndexBytes(data); err == nil {
+		t.Error("expected a DecodeLimitError")
+	}
+}
+
+func TestDetectKind_Bytes(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want SitemapKind
+	}{
+		{name: "urlset", data: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"></urlset>`, want: SitemapKindURLSet},
+		{name: "sitemap index", data: `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"></sitemapindex>`, want: SitemapKindIndex},
+		{name: "urlset with a byte order mark and whitespace", data: "\ufeff\n  <urlset></urlset>", want: SitemapKindURLSet},
+		{name: "other XML", data: `<rss version="2.0"></rss>`, want: SitemapKindUnknown},
+		{name: "HTML", data: "<!doctype html><html></html>", want: SitemapKindUnknown},
+		{name: "robots.txt", data: "User-agent: *\nDisallow: /private/\n\nSitemap: https://www.example.com/sitemap.xml\n", want: SitemapKindRobotsTXT},
+		{name: "robots.txt with comments and CRLF", data: "# robots\r\nuser-agent: *\r\n", want: SitemapKindRobotsTXT},
+		{name: "text", data: "https://www.example.com/a\nhttp://www.example.com/b\r\n\nhttps://www.example.com/c", want: SitemapKindText},
+		{name: "text with other lines", data: "https://www.example.com/a\nnot a URL\n", want: SitemapKindUnknown},
+		{name: "text with relative URLs", data: "/a\n/b\n", want: SitemapKindUnknown},
+		{name: "empty", data: "", want: SitemapKindUnknown},
+		{name: "whitespace", data: " \n\t\n", want: SitemapKindUnknown},
+		{name: "binary", data: "\x1f\x8b\x08\x00", want: SitemapKindUnknown},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := DetectKind([]byte(test.data)); got != test.want {
+				t.Errorf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
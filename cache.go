@@ -0,0 +1,139 @@
+package sitemap
+
+import (
+	"container/list"
+	"sync"
+)
+
+// Cache is a cache of fetched bodies keyed by URL, set with SetBodyCache.
+// Get returns the body cached for the URL and true, or false if there is none.
+// Set stores the body fetched from the URL.
+// The bodies are the response bodies as received (after decoding the Content-Encoding, if any), and must not be modified.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	Get(url string) ([]byte, bool)
+	Set(url string, body []byte)
+}
+
+// SetBodyCache sets the cache of the fetched bodies for the Sitemap Parser.
+// The cache is consulted before each fetch; on a hit, no request is sent and the CacheHit field of the fetch metadata is set.
+// Fetched bodies are stored after the fetch, unless their compressed content is truncated or corrupted.
+// Re-fetches of corrupted content (see SetRetries) bypass the cache. A nil cache (the default) disables caching.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetBodyCache(c Cache) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.bodyCache = c
+
+	return s
+}
+
+// fetchBody fetches the given URL with fetchWithRetries, unless its body is in the body cache.
+// The fs: locations of a parse of a file system are read from it instead, bypassing the cache, see ParseFS.
+// A cache hit is returned with metadata holding only the size of the body and the CacheHit flag.
+// A fetched body is stored in the cache, unless its compressed content is truncated or corrupted.
+func (s *S) fetchBody(url string) ([]byte, FetchMeta, error) {
+	if s.isFSLocation(url) {
+		return s.readFS(url)
+	}
+	if s.cfg.bodyCache == nil {
+		return s.fetchWithRetries(url)
+	}
+	if content, ok := s.cfg.bodyCache.Get(url); ok {
+		return content, FetchMeta{
+			CompressedBytes:   int64(len(content)),
+			DecompressedBytes: int64(len(content)),
+			CacheHit:          true,
+		}, nil
+	}
+
+	content, meta, err := s.fetchWithRetries(url)
+	if err == nil && !s.corrupted(url, content) {
+		s.cfg.bodyCache.Set(url, content)
+	}
+	return content, meta, err
+}
+
+// MemoryCache is a thread-safe in-memory Cache bounded by the total size of the cached bodies, created with NewMemoryCache.
+// When adding a body would exceed the maximum size, the least recently used bodies are evicted.
+// The maxBytes field is the maximum total size of the cached bodies, the size field is their current total size.
+// The entries field is the list of the cached entries from the most to the least recently used, the elements field indexes it by URL.
+type MemoryCache struct {
+	mu       sync.Mutex
+	maxBytes int64
+	size     int64
+	entries  *list.List
+	elements map[string]*list.Element
+}
+
+// memoryCacheEntry is an entry of a MemoryCache.
+type memoryCacheEntry struct {
+	url  string
+	body []byte
+}
+
+// NewMemoryCache creates a new in-memory LRU cache holding at most maxBytes bytes of bodies, e.g. NewMemoryCache(64<<20).
+// A body larger than maxBytes is not cached.
+func NewMemoryCache(maxBytes int64) *MemoryCache {
+	return &MemoryCache{
+		maxBytes: maxBytes,
+		entries:  list.New(),
+		elements: map[string]*list.Element{},
+	}
+}
+
+// Get returns the body cached for the given URL and true, marking it as the most recently used, or false if there is none.
+func (c *MemoryCache) Get(url string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.elements[url]
+	if !ok {
+		return nil, false
+	}
+	c.entries.MoveToFront(element)
+	return element.Value.(*memoryCacheEntry).body, true
+}
+
+// Set stores the body of the given URL as the most recently used, replacing the body cached for it, if any.
+// The least recently used bodies are evicted until the total size fits in the maximum size.
+func (c *MemoryCache) Set(url string, body []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.elements[url]; ok {
+		c.remove(element)
+	}
+	if int64(len(body)) > c.maxBytes {
+		return
+	}
+	for c.size+int64(len(body)) > c.maxBytes {
+		c.remove(c.entries.Back())
+	}
+	c.elements[url] = c.entries.PushFront(&memoryCacheEntry{url: url, body: body})
+	c.size += int64(len(body))
+}
+
+// Len returns the number of cached bodies.
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.entries.Len()
+}
+
+// Size returns the total size of the cached bodies in bytes.
+func (c *MemoryCache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.size
+}
+
+// remove removes the given entry from the cache. It must be called with c.mu held.
+func (c *MemoryCache) remove(element *list.Element) {
+	entry := c.entries.Remove(element).(*memoryCacheEntry)
+	delete(c.elements, entry.url)
+	c.size -= int64(len(entry.body))
+}
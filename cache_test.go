@@ -0,0 +1,330 @@
+package sitemap
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// mapCache is a Cache backed by a map, counting its hits.
+type mapCache struct {
+	mu     sync.Mutex
+	bodies map[string][]byte
+	hits   int
+}
+
+func (c *mapCache) Get(url string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	body, ok := c.bodies[url]
+	if ok {
+		c.hits++
+	}
+	return body, ok
+}
+
+func (c *mapCache) Set(url string, body []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.bodies == nil {
+		c.bodies = map[string][]byte{}
+	}
+	c.bodies[url] = body
+}
+
+func TestS_SetBodyCache(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	var requests int64
+	handler := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		handler.ServeHTTP(w, r)
+	})
+
+	tests := []struct {
+		name         string
+		url          string
+		wantRequests int64
+		wantURLs     int64
+	}{
+		{
+			name:         "robots.txt",
+			url:          server.URL + "/robots-with-sitemapindex/robots.txt",
+			wantRequests: 5,
+			wantURLs:     6,
+		},
+		{
+			name:         "gzip sitemap index",
+			url:          server.URL + "/sitemapindex-1.xml.gz",
+			wantRequests: 4,
+			wantURLs:     6,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cache := &mapCache{}
+			atomic.StoreInt64(&requests, 0)
+
+			first, err := New().SetBodyCache(cache).Parse(test.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := atomic.LoadInt64(&requests); got != test.wantRequests {
+				t.Errorf("expected %d requests, got %d", test.wantRequests, got)
+			}
+
+			second, err := New().SetBodyCache(cache).Parse(test.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := atomic.LoadInt64(&requests); got != test.wantRequests {
+				t.Errorf("expected no requests on the second parse, got %d", got-test.wantRequests)
+			}
+			if cache.hits != int(test.wantRequests) {
+				t.Errorf("expected %d cache hits, got %d", test.wantRequests, cache.hits)
+			}
+			if second.GetURLCount() != test.wantURLs || second.GetErrorsCount() != 0 {
+				t.Errorf("expected %d URLs and no errors, got %d URLs and %v", test.wantURLs, second.GetURLCount(), second.GetErrors())
+			}
+			if got, want := sortedLocs(second.GetURLs()), sortedLocs(first.GetURLs()); !reflect.DeepEqual(got, want) {
+				t.Errorf("expected URLs %v, got %v", want, got)
+			}
+			for loc, meta := range second.GetFetchMetadata() {
+				if !meta.CacheHit || meta.StatusCode != 0 || meta.DecompressedBytes == 0 {
+					t.Errorf("%s: expected cache hit, got %+v", loc, meta)
+				}
+			}
+			for loc, meta := range first.GetFetchMetadata() {
+				if meta.CacheHit {
+					t.Errorf("%s: expected no cache hit, got %+v", loc, meta)
+				}
+			}
+		})
+	}
+}
+
+func TestS_SetBodyCache_Corrupted(t *testing.T) {
+	content, err := os.ReadFile("./test/sitemap-02.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	good := gzipByte(string(content))
+	corrupted := append([]byte(nil), good...)
+	corrupted[len(corrupted)-8] ^= 0xff
+
+	var requests int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&requests, 1) == 1 {
+			_, _ = w.Write(corrupted)
+			return
+		}
+		_, _ = w.Write(good)
+	}))
+	defer server.Close()
+
+	url := server.URL + "/sitemap.xml.gz"
+	cache := &mapCache{}
+
+	s, err := New().SetBodyCache(cache).Parse(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.GetErrorsCount() == 0 {
+		t.Error("expected errors")
+	}
+	if _, ok := cache.bodies[url]; ok {
+		t.Error("expected the corrupted body not to be cached")
+	}
+
+	s, err = New().SetBodyCache(cache).Parse(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.GetURLCount() != 2 || s.GetFetchMetadata()[url].CacheHit {
+		t.Errorf("expected 2 URLs fetched without cache hit, got %d URLs, %+v", s.GetURLCount(), s.GetFetchMetadata()[url])
+	}
+	if _, ok := cache.bodies[url]; !ok {
+		t.Error("expected the body to be cached")
+	}
+
+	s, err = New().SetBodyCache(cache).Parse(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.GetURLCount() != 2 || !s.GetFetchMetadata()[url].CacheHit || atomic.LoadInt64(&requests) != 2 {
+		t.Errorf("expected 2 URLs from the cache after 2 requests, got %d URLs, %d requests", s.GetURLCount(), atomic.LoadInt64(&requests))
+	}
+}
+
+func TestMemoryCache_Set(t *testing.T) {
+	type op struct {
+		set  bool
+		url  string
+		size int
+	}
+	tests := []struct {
+		name     string
+		maxBytes int64
+		ops      []op
+		want     []string
+		wantSize int64
+	}{
+		{
+			name:     "fits",
+			maxBytes: 10,
+			ops:      []op{{true, "a", 3}, {true, "b", 3}, {true, "c", 4}},
+			want:     []string{"a", "b", "c"},
+			wantSize: 10,
+		},
+		{
+			name:     "evicts the least recently set",
+			maxBytes: 10,
+			ops:      []op{{true, "a", 3}, {true, "b", 3}, {true, "c", 4}, {true, "d", 2}},
+			want:     []string{"b", "c", "d"},
+			wantSize: 9,
+		},
+		{
+			name:     "evicts the least recently used",
+			maxBytes: 10,
+			ops:      []op{{true, "a", 3}, {true, "b", 3}, {true, "c", 4}, {false, "a", 0}, {true, "d", 2}},
+			want:     []string{"a", "c", "d"},
+			wantSize: 9,
+		},
+		{
+			name:     "evicts several entries by size",
+			maxBytes: 10,
+			ops:      []op{{true, "a", 3}, {true, "b", 3}, {true, "c", 4}, {true, "d", 8}},
+			want:     []string{"d"},
+			wantSize: 8,
+		},
+		{
+			name:     "replaces an entry",
+			maxBytes: 10,
+			ops:      []op{{true, "a", 3}, {true, "b", 3}, {true, "a", 7}},
+			want:     []string{"a", "b"},
+			wantSize: 10,
+		},
+		{
+			name:     "body larger than the maximum size",
+			maxBytes: 10,
+			ops:      []op{{true, "a", 3}, {true, "b", 11}},
+			want:     []string{"a"},
+			wantSize: 3,
+		},
+		{
+			name:     "replaced by a body larger than the maximum size",
+			maxBytes: 10,
+			ops:      []op{{true, "a", 3}, {true, "a", 11}},
+			want:     []string{},
+			wantSize: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := NewMemoryCache(test.maxBytes)
+			for _, o := range test.ops {
+				if o.set {
+					c.Set(o.url, make([]byte, o.size))
+				} else if _, ok := c.Get(o.url); !ok {
+					t.Fatalf("expected %s to be cached", o.url)
+				}
+			}
+
+			got := []string{}
+			for _, url := range []string{"a", "b", "c", "d"} {
+				if _, ok := c.Get(url); ok {
+					got = append(got, url)
+				}
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected cached %v, got %v", test.want, got)
+			}
+			if c.Len() != len(test.want) || c.Size() != test.wantSize {
+				t.Errorf("expected %d entries of %d bytes, got %d entries of %d bytes", len(test.want), test.wantSize, c.Len(), c.Size())
+			}
+		})
+	}
+}
+
+func TestMemoryCache_Concurrent(t *testing.T) {
+	c := NewMemoryCache(1000)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				url := fmt.Sprintf("https://www.sitemaps.org/sitemap-%d.xml", (i*j)%50)
+				if body, ok := c.Get(url); ok && len(body) != 10+len(url) {
+					t.Errorf("unexpected body of %s: %d bytes", url, len(body))
+				}
+				c.Set(url, make([]byte, 10+len(url)))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var size int64
+	for i := 0; i < 50; i++ {
+		if body, ok := c.Get(fmt.Sprintf("https://www.sitemaps.org/sitemap-%d.xml", i)); ok {
+			size += int64(len(body))
+		}
+	}
+	if size != c.Size() || size > 1000 {
+		t.Errorf("expected a size of at most 1000 bytes matching the entries, got %d and %d", c.Size(), size)
+	}
+}
+
+func TestS_SetBodyCache_MemoryCache(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	var requests int64
+	handler := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		handler.ServeHTTP(w, r)
+	})
+
+	cache := NewMemoryCache(64 << 20)
+	urls := []string{
+		server.URL + "/robots-with-sitemapindex/robots.txt",
+		server.URL + "/sitemapindex-2.xml.gz",
+		server.URL + "/sitemap-02.xml.zlib",
+	}
+
+	for _, url := range urls {
+		if _, err := New().SetBodyCache(cache).Parse(url, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fetched := atomic.LoadInt64(&requests)
+	if fetched == 0 || int64(cache.Len()) != fetched {
+		t.Fatalf("expected every fetched body to be cached, got %d requests and %d entries", fetched, cache.Len())
+	}
+
+	for _, url := range urls {
+		s, err := New().SetBodyCache(cache).Parse(url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.GetErrorsCount() != 0 || s.GetURLCount() == 0 {
+			t.Errorf("%s: expected URLs without errors, got %d URLs and %v", url, s.GetURLCount(), s.GetErrors())
+		}
+	}
+	if got := atomic.LoadInt64(&requests) - fetched; got != 0 {
+		t.Errorf("expected no requests on the second parse, got %d", got)
+	}
+}
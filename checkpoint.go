@@ -0,0 +1,240 @@
+package sitemap
+
+import (
+	"context"
+	"errors"
+)
+
+// Checkpoint is a JSON-serializable snapshot of a parse, which can be resumed with ParseFromCheckpoint.
+// The MainURL field is the URL passed to Parse.
+// The Tree field is a copy of the sitemap tree, see GetSitemapTree. The errors of its nodes are not included.
+// The Fetched field lists the locations that have been processed, successfully or not, in tree order.
+// The Pending field lists the locations that have been discovered, but not processed yet, in tree order.
+// The URLs field holds the URLs collected so far, with the location of the sitemap each was found in.
+// In loc-only mode, see SetLocOnly, the URLs hold only their locations, without the location of their sitemap.
+// The URLCount field is the number of URLs collected so far, including the URLs only counted when collecting URLs is turned off.
+// The Errors field holds the messages of the errors encountered so far.
+// The FailedSitemaps field is the number of sitemaps that could not be fetched or parsed.
+type Checkpoint struct {
+	MainURL        string          `json:"main_url"`
+	Tree           *SitemapNode    `json:"tree"`
+	Fetched        []string        `json:"fetched"`
+	Pending        []string        `json:"pending"`
+	URLs           []CheckpointURL `json:"urls"`
+	URLCount       int64           `json:"url_count"`
+	Errors         []string        `json:"errors"`
+	FailedSitemaps int             `json:"failed_sitemaps"`
+}
+
+// CheckpointURL is a URL collected before a checkpoint.
+// The SourceSitemap field is the location of the sitemap the URL was found in.
+type CheckpointURL struct {
+	URL
+	SourceSitemap string `json:"source_sitemap"`
+}
+
+// GetCheckpoint returns a snapshot of the parse, which can be saved and resumed with ParseFromCheckpoint,
+// e.g. after the parse has been cancelled with the context passed to ParseContext.
+// Sitemaps skipped because the parse was cut short, or whose fetch failed because of its cancellation, are pending.
+// If the S object is nil or Parse has not been called, a zero Checkpoint is returned.
+func (s *S) GetCheckpoint() Checkpoint {
+	if s == nil {
+		return Checkpoint{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tree == nil {
+		return Checkpoint{}
+	}
+
+	cp := Checkpoint{
+		MainURL:        s.mainURL,
+		Tree:           copyNode(s.tree, nil, map[*SitemapNode]bool{}),
+		Fetched:        []string{},
+		Pending:        []string{},
+		URLs:           make([]CheckpointURL, 0, len(s.urls)+len(s.locs)),
+		Errors:         make([]string, 0, len(s.errs)),
+		FailedSitemaps: s.failedSitemaps,
+	}
+	walkNodes(s.tree, func(n *SitemapNode) {
+		if n.fetched {
+			cp.Fetched = append(cp.Fetched, n.Loc)
+		} else {
+			cp.Pending = append(cp.Pending, n.Loc)
+		}
+		cp.URLCount += n.URLCount
+	})
+	for _, u := range s.urls {
+		cp.URLs = append(cp.URLs, CheckpointURL{URL: u, SourceSitemap: u.source})
+	}
+	for _, loc := range s.locs {
+		cp.URLs = append(cp.URLs, CheckpointURL{URL: URL{Loc: loc}})
+	}
+	for _, err := range s.errs {
+		cp.Errors = append(cp.Errors, err.Error())
+	}
+
+	return cp
+}
+
+// ParseFromCheckpoint resumes the parse saved by GetCheckpoint.
+// It is equivalent to ParseFromCheckpointContext with context.Background().
+func (s *S) ParseFromCheckpoint(cp Checkpoint) (*S, error) {
+	return s.ParseFromCheckpointContext(context.Background(), cp)
+}
+
+// ParseFromCheckpointContext resumes the parse saved by GetCheckpoint with the given context.
+// The S object is expected to be new, configured as the S object the checkpoint was taken from.
+// The results of the checkpoint are restored, then the pending locations are fetched and parsed as with ParseContext;
+// the locations already processed are not fetched again. The restored errors do not wrap their original errors,
+// and they count against the maximum number of errors, see SetMaxErrors.
+// If the main URL has not been processed, the parse starts over with ParseContext.
+// If the S object is nil, it returns ErrNilReceiver.
+// If the S object has any errors, it returns an error with the message "errors occurred before parsing, see GetErrors() for details".
+func (s *S) ParseFromCheckpointContext(ctx context.Context, cp Checkpoint) (*S, error) {
+	if s == nil {
+		return nil, ErrNilReceiver
+	}
+
+	if len(s.errs) > 0 {
+		return s, errors.New("errors occurred before parsing, see GetErrors() for details")
+	}
+
+	fetched := make(map[string]bool, len(cp.Fetched))
+	for _, loc := range cp.Fetched {
+		fetched[loc] = true
+	}
+	if cp.Tree == nil || !fetched[cp.MainURL] {
+		return s.ParseContext(ctx, cp.MainURL, nil)
+	}
+
+	s.startParse(ctx)
+	defer s.endParse(ctx)
+	s.restoreCheckpoint(cp, fetched)
+
+	if !s.cfg.followIndexes {
+		return s, nil
+	}
+
+	var pending []string
+	for _, loc := range cp.Pending {
+		if !fetched[loc] {
+			pending = append(pending, loc)
+		}
+	}
+	if s.cfg.multiThread {
+		s.parseAndFetchUrlsMultiThread(pending)
+	} else {
+		s.parseAndFetchUrlsSequential(pending)
+	}
+	s.publishCircuitErrors()
+
+	s.mu.Lock()
+	s.truncateByContext()
+	abortErr := s.abortErr()
+	s.mu.Unlock()
+
+	return s, abortErr
+}
+
+// restoreCheckpoint replaces the results of the S object with the results of the checkpoint.
+// The given set holds the locations already processed.
+func (s *S) restoreCheckpoint(cp Checkpoint, fetched map[string]bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.mainURL = cp.MainURL
+	s.nodes = map[string]*SitemapNode{}
+	s.tree = copyNode(cp.Tree, nil, map[*SitemapNode]bool{})
+	s.sitemapLocations = nil
+	s.robotsTxtSitemapURLs = nil
+	s.sitemapsStarted = 0
+	walkNodes(s.tree, func(n *SitemapNode) {
+		s.nodes[n.Loc] = n
+		n.fetched = fetched[n.Loc]
+		if n.fetched && n != s.tree {
+			s.sitemapsStarted++
+		}
+		// rebuild the sitemap locations the way parse and parseRobotsTXT record them
+		switch n.Kind {
+		case SitemapKindIndex:
+			s.sitemapLocations = append(s.sitemapLocations, n.Loc)
+			for _, child := range n.Children {
+				s.sitemapLocations = append(s.sitemapLocations, child.Loc)
+			}
+		case SitemapKindRobotsTXT:
+			for _, child := range n.Children {
+				s.robotsTxtSitemapURLs = append(s.robotsTxtSitemapURLs, child.Loc)
+			}
+		}
+	})
+
+	s.restoreURLs(cp.URLs, s.cfg.locOnly)
+
+	s.errs = make([]error, 0, len(cp.Errors))
+	for _, msg := range cp.Errors {
+		s.errs = append(s.errs, errors.New(msg))
+	}
+	s.failedSitemaps = cp.FailedSitemaps
+}
+
+// restoreURLs replaces the URLs of the S object with the given URLs, or with their locations if locOnly is true, see SetLocOnly.
+// It must be called with s.mu held.
+func (s *S) restoreURLs(urls []CheckpointURL, locOnly bool) {
+	s.urls = nil
+	s.locs = nil
+	s.memoryUsed = 0
+	for _, u := range urls {
+		if locOnly {
+			s.locs = append(s.locs, u.Loc)
+		} else {
+			u.URL.source = u.SourceSitemap
+			s.urls = append(s.urls, u.URL)
+		}
+		s.memoryUsed += s.storedMemorySize(u.URL)
+	}
+	s.report = nil
+	s.uniqueURLCount = nil
+}
+
+// copyNode returns a deep copy of the given node with the given parent, without its error.
+// A node referenced more than once is copied with its children only at its first occurrence,
+// the given set holds the nodes copied so far.
+func copyNode(n *SitemapNode, parent *SitemapNode, copied map[*SitemapNode]bool) *SitemapNode {
+	c := &SitemapNode{
+		Loc:              n.Loc,
+		Kind:             n.Kind,
+		Parent:           parent,
+		URLCount:         n.URLCount,
+		Truncated:        n.Truncated,
+		LastMod:          n.LastMod,
+		NewestURLLastMod: n.NewestURLLastMod,
+		fetched:          n.fetched,
+	}
+	if copied[n] {
+		return c
+	}
+	copied[n] = true
+	for _, child := range n.Children {
+		c.Children = append(c.Children, copyNode(child, c, copied))
+	}
+	return c
+}
+
+// walkNodes calls fn for the given node and its descendants in depth-first order, once per location.
+func walkNodes(n *SitemapNode, fn func(*SitemapNode)) {
+	walkNodesOnce(n, fn, map[string]bool{})
+}
+
+// walkNodesOnce calls fn for the given node and its descendants whose locations are not in the given set yet.
+func walkNodesOnce(n *SitemapNode, fn func(*SitemapNode), visited map[string]bool) {
+	if visited[n.Loc] {
+		return
+	}
+	visited[n.Loc] = true
+	fn(n)
+	for _, child := range n.Children {
+		walkNodesOnce(child, fn, visited)
+	}
+}
@@ -0,0 +1,230 @@
+package sitemap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestS_ParseFromCheckpoint(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	var mu sync.Mutex
+	var requests []string
+	handler := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.RequestURI)
+		mu.Unlock()
+		handler.ServeHTTP(w, r)
+	})
+
+	indexContent := strings.ReplaceAll(`<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+    <sitemap><loc>http://HOST/sitemap-01.xml</loc></sitemap>
+    <sitemap><loc>http://HOST/sitemap-02.xml</loc></sitemap>
+    <sitemap><loc>http://HOST/sitemap-03.xml</loc></sitemap>
+</sitemapindex>`, "http://HOST", server.URL)
+
+	expiredCtx, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+
+	tests := []struct {
+		name           string
+		s              *S
+		ctx            context.Context
+		url            string
+		content        *string
+		wantFetched    []string
+		wantPending    []string
+		wantURLCount   int64
+		wantRequests   []string
+		wantResumedURL int64
+	}{
+		{
+			name:         "max sitemaps",
+			s:            New().SetMultiThread(false).SetMaxSitemaps(2),
+			url:          server.URL + "/robots-with-sitemapindex/robots.txt",
+			wantFetched:  []string{"/robots-with-sitemapindex/robots.txt", "/sitemapindex-1.xml", "/sitemap-01.xml"},
+			wantPending:  []string{"/sitemap-02.xml", "/sitemap-03.xml"},
+			wantURLCount: 1,
+			wantRequests: []string{"/sitemap-02.xml", "/sitemap-03.xml"},
+		},
+		{
+			name:         "deadline",
+			s:            New(),
+			ctx:          expiredCtx,
+			url:          server.URL + "/sitemapindex.xml",
+			content:      &indexContent,
+			wantFetched:  []string{"/sitemapindex.xml"},
+			wantPending:  []string{"/sitemap-01.xml", "/sitemap-02.xml", "/sitemap-03.xml"},
+			wantURLCount: 0,
+			wantRequests: []string{"/sitemap-01.xml", "/sitemap-02.xml", "/sitemap-03.xml"},
+		},
+		{
+			name:         "complete",
+			s:            New(),
+			url:          server.URL + "/robots-with-sitemapindex/robots.txt",
+			wantFetched:  []string{"/robots-with-sitemapindex/robots.txt", "/sitemapindex-1.xml", "/sitemap-01.xml", "/sitemap-02.xml", "/sitemap-03.xml"},
+			wantPending:  []string{},
+			wantURLCount: 6,
+			wantRequests: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := test.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			if _, err := test.s.ParseContext(ctx, test.url, test.content); err != nil {
+				t.Fatal(err)
+			}
+
+			cp := test.s.GetCheckpoint()
+			if got := trimPrefixes(cp.Fetched, server.URL); !reflect.DeepEqual(got, test.wantFetched) {
+				t.Errorf("expected fetched %v, got %v", test.wantFetched, got)
+			}
+			if got := trimPrefixes(cp.Pending, server.URL); !reflect.DeepEqual(got, test.wantPending) {
+				t.Errorf("expected pending %v, got %v", test.wantPending, got)
+			}
+			if cp.URLCount != test.wantURLCount || int64(len(cp.URLs)) != test.wantURLCount {
+				t.Errorf("expected %d URLs, got %d (%d in the checkpoint)", test.wantURLCount, cp.URLCount, len(cp.URLs))
+			}
+
+			data, err := json.Marshal(cp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var restored Checkpoint
+			if err := json.Unmarshal(data, &restored); err != nil {
+				t.Fatal(err)
+			}
+
+			mu.Lock()
+			requests = nil
+			mu.Unlock()
+
+			resumed, err := New().ParseFromCheckpoint(restored)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mu.Lock()
+			gotRequests := append([]string(nil), requests...)
+			mu.Unlock()
+			sort.Strings(gotRequests)
+			if !reflect.DeepEqual(gotRequests, test.wantRequests) {
+				t.Errorf("expected requests %v, got %v", test.wantRequests, gotRequests)
+			}
+
+			full, err := New().SetMultiThread(false).Parse(test.url, test.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := sortedLocs(resumed.GetURLs()), sortedLocs(full.GetURLs()); !reflect.DeepEqual(got, want) {
+				t.Errorf("expected URLs %v, got %v", want, got)
+			}
+			if got, want := resumed.GetSitemapLocations(), full.GetSitemapLocations(); !reflect.DeepEqual(got, want) {
+				t.Errorf("expected sitemap locations %v, got %v", want, got)
+			}
+			if got := resumed.GetCompleteness(); !got.Complete {
+				t.Errorf("expected complete parse, got %v", got)
+			}
+			if pending := resumed.GetCheckpoint().Pending; len(pending) != 0 {
+				t.Errorf("expected no pending locations, got %v", pending)
+			}
+			for _, u := range resumed.GetURLs() {
+				if u.source == "" {
+					t.Errorf("expected source sitemap of %s", u.Loc)
+				}
+			}
+		})
+	}
+}
+
+func TestS_ParseFromCheckpoint_Restart(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		cp   Checkpoint
+	}{
+		{
+			name: "zero checkpoint",
+			cp:   Checkpoint{MainURL: server.URL + "/sitemap-01.xml"},
+		},
+		{
+			name: "main URL pending",
+			cp: Checkpoint{
+				MainURL: server.URL + "/sitemap-01.xml",
+				Tree:    &SitemapNode{Loc: server.URL + "/sitemap-01.xml", Kind: SitemapKindUnknown},
+				Pending: []string{server.URL + "/sitemap-01.xml"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := New().ParseFromCheckpoint(test.cp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.GetURLCount() != 1 {
+				t.Errorf("expected 1 URL, got %d", s.GetURLCount())
+			}
+		})
+	}
+}
+
+func TestS_GetCheckpoint_Errors(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	s, err := New().Parse(fmt.Sprintf("%s/sitemapindex-with-invalid-sitemap.xml", server.URL), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cp := s.GetCheckpoint()
+	if len(cp.Errors) != 1 || cp.FailedSitemaps != 1 || len(cp.Pending) != 0 {
+		t.Fatalf("unexpected checkpoint: errors %v, failed %d, pending %v", cp.Errors, cp.FailedSitemaps, cp.Pending)
+	}
+
+	resumed, err := New().ParseFromCheckpoint(cp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resumed.GetErrorsCount() != 1 || resumed.GetErrors()[0].Error() != cp.Errors[0] {
+		t.Errorf("expected errors %v, got %v", cp.Errors, resumed.GetErrors())
+	}
+	if got := resumed.GetCompleteness().FailedSitemaps; got != 1 {
+		t.Errorf("expected 1 failed sitemap, got %d", got)
+	}
+}
+
+// trimPrefixes returns the given strings without the given prefix.
+func trimPrefixes(values []string, prefix string) []string {
+	trimmed := make([]string, 0, len(values))
+	for _, v := range values {
+		trimmed = append(trimmed, strings.TrimPrefix(v, prefix))
+	}
+	return trimmed
+}
+
+// sortedLocs returns the sorted locations of the given URLs.
+func sortedLocs(urls []URL) []string {
+	locs := locsOf(urls)
+	sort.Strings(locs)
+	return locs
+}
@@ -0,0 +1,119 @@
+package sitemap
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrCircuitOpen is matched by errors.Is for every CircuitOpenError.
+var ErrCircuitOpen = errors.New("circuit open")
+
+type (
+	// CircuitOpenError is the error recorded once for a host whose circuit breaker opened, when all fetches of the parse are done.
+	// The Host field is the host the circuit was opened for.
+	// The Failures field is the number of consecutive failures that opened the circuit.
+	// The Skipped field holds the locations on the host that were not fetched because the circuit was open.
+	CircuitOpenError struct {
+		Host     string
+		Failures int
+		Skipped  []string
+	}
+
+	// hostCircuits tracks the consecutive fetch failures per host, created on demand.
+	// The threshold field is the number of consecutive failures that opens the circuit of a host.
+	hostCircuits struct {
+		mu        sync.Mutex
+		threshold int
+		hosts     map[string]*hostCircuit
+	}
+
+	// hostCircuit is the state of a single host in hostCircuits.
+	// The failures field is the number of consecutive failures of the host.
+	// The open field is the error recorded when the circuit was opened, nil while the circuit is closed.
+	hostCircuit struct {
+		failures int
+		open     *CircuitOpenError
+	}
+)
+
+// Error returns the message of the error.
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit open for host %s after %d failures", e.Host, e.Failures)
+}
+
+// Is reports whether target is ErrCircuitOpen.
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// publishCircuitErrors records the errors of the circuits opened during the parse, in the order they were opened.
+// It is called once all fetches are done, so that the Skipped field of the errors is complete and no longer modified.
+func (s *S) publishCircuitErrors() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, err := range s.circuitErrs {
+		s.appendError(err)
+	}
+	s.circuitErrs = nil
+}
+
+// newHostCircuits creates a hostCircuits opening the circuit of a host after threshold consecutive failures.
+// It returns nil if threshold is not positive, which means the circuit breaker is disabled.
+func newHostCircuits(threshold int) *hostCircuits {
+	if threshold <= 0 {
+		return nil
+	}
+	return &hostCircuits{
+		threshold: threshold,
+		hosts:     map[string]*hostCircuit{},
+	}
+}
+
+// allow returns the CircuitOpenError of the given host if its circuit is open, adding loc to the skipped locations.
+// It returns nil if the fetch is allowed. A nil hostCircuits allows every fetch.
+func (h *hostCircuits) allow(host string, loc string) error {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	hc, ok := h.hosts[host]
+	if !ok || hc.open == nil {
+		return nil
+	}
+	hc.open.Skipped = append(hc.open.Skipped, loc)
+	return hc.open
+}
+
+// record records the result of a fetch from the given host.
+// A success resets the consecutive failures of the host, a failure increments them.
+// It returns the CircuitOpenError if this failure opened the circuit, nil otherwise.
+func (h *hostCircuits) record(host string, failed bool) *CircuitOpenError {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	hc, ok := h.hosts[host]
+	if !ok {
+		hc = &hostCircuit{}
+		h.hosts[host] = hc
+	}
+	if hc.open != nil {
+		return nil
+	}
+	if !failed {
+		hc.failures = 0
+		return nil
+	}
+	hc.failures++
+	if hc.failures < h.threshold {
+		return nil
+	}
+	hc.open = &CircuitOpenError{Host: host, Failures: hc.failures}
+	return hc.open
+}
@@ -0,0 +1,189 @@
+package sitemap
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHostCircuits(t *testing.T) {
+	t.Run("opens after consecutive failures", func(t *testing.T) {
+		circuits := newHostCircuits(2)
+
+		if circuitErr := circuits.record("a.sitemaps.org", true); circuitErr != nil {
+			t.Fatalf("expected closed circuit after 1 failure, got %v", circuitErr)
+		}
+		circuitErr := circuits.record("a.sitemaps.org", true)
+		if circuitErr == nil {
+			t.Fatal("expected circuit to open after 2 failures")
+		}
+		if circuitErr.Error() != "circuit open for host a.sitemaps.org after 2 failures" {
+			t.Errorf("unexpected error message: %v", circuitErr)
+		}
+		if again := circuits.record("a.sitemaps.org", true); again != nil {
+			t.Errorf("expected the circuit to be opened only once, got %v", again)
+		}
+
+		err := circuits.allow("a.sitemaps.org", "https://a.sitemaps.org/sitemap.xml")
+		if !errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("expected %v, got %v", ErrCircuitOpen, err)
+		}
+		if !reflect.DeepEqual(circuitErr.Skipped, []string{"https://a.sitemaps.org/sitemap.xml"}) {
+			t.Errorf("unexpected skipped locations: %v", circuitErr.Skipped)
+		}
+		if err = circuits.allow("b.sitemaps.org", "https://b.sitemaps.org/sitemap.xml"); err != nil {
+			t.Errorf("expected other host to be allowed, got %v", err)
+		}
+	})
+
+	t.Run("success resets failures", func(t *testing.T) {
+		circuits := newHostCircuits(2)
+
+		circuits.record("a.sitemaps.org", true)
+		circuits.record("a.sitemaps.org", false)
+		if circuitErr := circuits.record("a.sitemaps.org", true); circuitErr != nil {
+			t.Errorf("expected closed circuit, got %v", circuitErr)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		circuits := newHostCircuits(0)
+		if circuits != nil {
+			t.Fatalf("expected nil, got %v", circuits)
+		}
+		if circuitErr := circuits.record("a.sitemaps.org", true); circuitErr != nil {
+			t.Errorf("expected nil, got %v", circuitErr)
+		}
+		if err := circuits.allow("a.sitemaps.org", "https://a.sitemaps.org/sitemap.xml"); err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+	})
+}
+
+func TestS_Parse_CircuitBreaker(t *testing.T) {
+	var blockedRequests int64
+	blocked := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&blockedRequests, 1)
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer blocked.Close()
+
+	server := testServer()
+	defer server.Close()
+
+	var content strings.Builder
+	content.WriteString(`<?xml version="1.0" encoding="UTF-8"?><sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`)
+	for i := 1; i <= 5; i++ {
+		_, _ = fmt.Fprintf(&content, "<sitemap><loc>%s/sitemap-%02d.xml</loc></sitemap>", blocked.URL, i)
+	}
+	for i := 1; i <= 3; i++ {
+		_, _ = fmt.Fprintf(&content, "<sitemap><loc>%s/sitemap-%02d.xml</loc></sitemap>", server.URL, i)
+	}
+	content.WriteString(`</sitemapindex>`)
+	indexContent := content.String()
+
+	blockedHost, _ := hostOf(blocked.URL)
+
+	t.Run("sequential", func(t *testing.T) {
+		atomic.StoreInt64(&blockedRequests, 0)
+		s, err := New().SetMultiThread(false).SetCircuitBreaker(2).Parse(fmt.Sprintf("%s/sitemapindex.xml", server.URL), &indexContent)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := atomic.LoadInt64(&blockedRequests); got != 2 {
+			t.Errorf("expected 2 requests to the blocked host, got %d", got)
+		}
+		if s.GetURLCount() != 6 {
+			t.Errorf("expected 6 URLs from the other host, got %d", s.GetURLCount())
+		}
+
+		// 2 HTTP errors and a single circuit error
+		if s.GetErrorsCount() != 3 {
+			t.Fatalf("expected 3 errors, got %d: %v", s.GetErrorsCount(), s.GetErrors())
+		}
+		var circuitErr *CircuitOpenError
+		if !errors.As(s.GetErrors()[2], &circuitErr) {
+			t.Fatalf("expected CircuitOpenError, got %v", s.GetErrors()[2])
+		}
+		if circuitErr.Host != blockedHost || circuitErr.Failures != 2 {
+			t.Errorf("unexpected circuit error: %v", circuitErr)
+		}
+		wantSkipped := []string{
+			fmt.Sprintf("%s/sitemap-03.xml", blocked.URL),
+			fmt.Sprintf("%s/sitemap-04.xml", blocked.URL),
+			fmt.Sprintf("%s/sitemap-05.xml", blocked.URL),
+		}
+		if !reflect.DeepEqual(circuitErr.Skipped, wantSkipped) {
+			t.Errorf("expected skipped %v, got %v", wantSkipped, circuitErr.Skipped)
+		}
+		wantCompleteness := Completeness{Complete: false, TruncatedBy: TruncatedByNone, FailedSitemaps: 2, SkippedSitemaps: 3}
+		if got := s.GetCompleteness(); got != wantCompleteness {
+			t.Errorf("expected %+v, got %+v", wantCompleteness, got)
+		}
+	})
+
+	t.Run("multi-thread", func(t *testing.T) {
+		s, err := New().SetCircuitBreaker(2).Parse(fmt.Sprintf("%s/sitemapindex.xml", server.URL), &indexContent)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if s.GetURLCount() != 6 {
+			t.Errorf("expected 6 URLs from the other host, got %d", s.GetURLCount())
+		}
+		circuitErrs := 0
+		for _, err := range s.GetErrors() {
+			if errors.Is(err, ErrCircuitOpen) {
+				circuitErrs++
+			}
+		}
+		if circuitErrs != 1 {
+			t.Errorf("expected a single circuit error, got %d: %v", circuitErrs, s.GetErrors())
+		}
+	})
+
+	t.Run("recorded complete", func(t *testing.T) {
+		// the skipped locations alternate with the delayed fetches from the other host
+		var content strings.Builder
+		content.WriteString(`<?xml version="1.0" encoding="UTF-8"?><sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`)
+		_, _ = fmt.Fprintf(&content, "<sitemap><loc>%s/sitemap-01.xml</loc></sitemap>", blocked.URL)
+		_, _ = fmt.Fprintf(&content, "<sitemap><loc>%s/sitemap-02.xml</loc></sitemap>", blocked.URL)
+		for i := 1; i <= 3; i++ {
+			_, _ = fmt.Fprintf(&content, "<sitemap><loc>%s/sitemap-%02d.xml</loc></sitemap>", server.URL, i)
+			_, _ = fmt.Fprintf(&content, "<sitemap><loc>%s/sitemap-%02d.xml</loc></sitemap>", blocked.URL, i+2)
+		}
+		content.WriteString(`</sitemapindex>`)
+		indexContent := content.String()
+
+		s := New().SetMultiThread(false).SetCircuitBreaker(2).SetRequestDelay(20*time.Millisecond, 0)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, _ = s.Parse(fmt.Sprintf("%s/sitemapindex.xml", server.URL), &indexContent)
+		}()
+
+		for running := true; running; {
+			select {
+			case <-done:
+				running = false
+			default:
+			}
+			for _, err := range s.GetErrors() {
+				var circuitErr *CircuitOpenError
+				if errors.As(err, &circuitErr) && len(circuitErr.Skipped) != 3 {
+					t.Fatalf("expected the circuit error to be recorded with its 3 skipped locations, got %v", circuitErr.Skipped)
+				}
+			}
+		}
+		if !errors.Is(s.GetErrors()[2], ErrCircuitOpen) {
+			t.Errorf("expected a circuit error, got %v", s.GetErrors())
+		}
+	})
+}
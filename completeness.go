@@ -0,0 +1,133 @@
+package sitemap
+
+import (
+	"context"
+	"errors"
+)
+
+// TruncationCause represents the reason a parse was cut short.
+type TruncationCause string
+
+const (
+	// TruncatedByNone means the parse was not cut short.
+	TruncatedByNone TruncationCause = "none"
+
+	// TruncatedByMaxURLs means the parse stopped because the maximum number of URLs was reached.
+	TruncatedByMaxURLs TruncationCause = "max_urls"
+
+	// TruncatedByMaxSitemaps means the parse stopped because the maximum number of sitemaps was reached.
+	TruncatedByMaxSitemaps TruncationCause = "max_sitemaps"
+
+	// TruncatedByMemoryBudget means the parse stopped because the memory budget was exceeded.
+	TruncatedByMemoryBudget TruncationCause = "memory_budget"
+
+	// TruncatedByDeadline means the parse stopped because the deadline of its context was exceeded.
+	TruncatedByDeadline TruncationCause = "deadline"
+
+	// TruncatedByCancelled means the parse stopped because its context was cancelled.
+	TruncatedByCancelled TruncationCause = "cancelled"
+
+	// TruncatedByFailureRate means the parse was aborted because the rate of the sitemaps failed exceeded the maximum, see SetMaxFailureRate.
+	TruncatedByFailureRate TruncationCause = "failure_rate"
+
+	// TruncatedByMaxErrors means the parse was aborted because the maximum number of errors was reached, see SetMaxErrors.
+	TruncatedByMaxErrors TruncationCause = "max_errors"
+)
+
+// Completeness reports whether the result of a parse represents the whole site.
+// The Complete field is true if the parse was not cut short and no sitemap failed or was skipped.
+// The TruncatedBy field is the reason the parse was cut short, the first one if there were several.
+// The FailedSitemaps field is the number of sitemaps that could not be fetched or parsed.
+// The SkippedSitemaps field is the number of sitemaps that were not fetched because of a limit,
+// the cancellation of the parse, an open circuit breaker or an advertised size over the hint, see SetMaxFetchSizeHint.
+type Completeness struct {
+	Complete        bool            `json:"complete"`
+	TruncatedBy     TruncationCause `json:"truncated_by"`
+	FailedSitemaps  int             `json:"failed_sitemaps"`
+	SkippedSitemaps int             `json:"skipped_sitemaps"`
+}
+
+// errTruncated is returned by startSitemap when a sitemap is skipped because the parse has been cut short.
+var errTruncated = errors.New("parse truncated")
+
+// GetCompleteness returns whether the result of the parse represents the whole site.
+// If the S object is nil, a zero Completeness is returned.
+func (s *S) GetCompleteness() Completeness {
+	if s == nil {
+		return Completeness{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	truncatedBy := s.truncatedBy
+	if truncatedBy == "" {
+		truncatedBy = TruncatedByNone
+	}
+	return Completeness{
+		Complete:        truncatedBy == TruncatedByNone && s.failedSitemaps == 0 && s.skippedSitemaps == 0,
+		TruncatedBy:     truncatedBy,
+		FailedSitemaps:  s.failedSitemaps,
+		SkippedSitemaps: s.skippedSitemaps,
+	}
+}
+
+// truncate records the cause the parse was cut short by, unless one has been recorded already.
+// It must be called with s.mu held.
+func (s *S) truncate(cause TruncationCause) {
+	if s.truncatedBy == "" {
+		s.truncatedBy = cause
+	}
+}
+
+// truncateByContext records the cancellation of the context of the parse as the cause it was cut short by, if it is done.
+// It must be called with s.mu held.
+func (s *S) truncateByContext() {
+	switch {
+	case errors.Is(s.context().Err(), context.DeadlineExceeded):
+		s.truncate(TruncatedByDeadline)
+	case s.context().Err() != nil:
+		s.truncate(TruncatedByCancelled)
+	}
+}
+
+// startSitemap is called before fetching a sitemap, it counts the sitemap against the maximum number of sitemaps.
+// It returns errTruncated, counting the sitemap as skipped, if the parse has been cut short or the maximum has been reached.
+func (s *S) startSitemap() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.truncateByContext()
+	if s.truncatedBy == "" && s.cfg.maxSitemaps > 0 && s.sitemapsStarted >= s.cfg.maxSitemaps {
+		s.truncate(TruncatedByMaxSitemaps)
+	}
+	if s.truncatedBy != "" {
+		s.skippedSitemaps++
+		return errTruncated
+	}
+	s.sitemapsStarted++
+	return nil
+}
+
+// failSitemap counts the sitemap of the given location that could not be fetched or parsed, and marks it as processed.
+// A fetch failing because of the cancellation of the parse counts as skipped instead, and the sitemap remains pending.
+func (s *S) failSitemap(location string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.context().Err() != nil {
+		s.truncateByContext()
+		s.skippedSitemaps++
+		return
+	}
+	s.failedSitemaps++
+	s.node(location).fetched = true
+	s.recordOutcome(true)
+}
+
+// skipSitemap counts a sitemap that was not fetched.
+func (s *S) skipSitemap() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.skippedSitemaps++
+}
@@ -0,0 +1,109 @@
+package sitemap
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestS_GetCompleteness(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	indexContent := strings.ReplaceAll(`<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+    <sitemap><loc>http://HOST/sitemap-01.xml</loc></sitemap>
+    <sitemap><loc>http://HOST/sitemap-02.xml</loc></sitemap>
+    <sitemap><loc>http://HOST/sitemap-03.xml</loc></sitemap>
+</sitemapindex>`, "http://HOST", server.URL)
+
+	expiredCtx, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name      string
+		s         *S
+		ctx       context.Context
+		url       string
+		content   *string
+		want      Completeness
+		urlsCount int64
+	}{
+		{
+			name:      "complete",
+			s:         New(),
+			url:       fmt.Sprintf("%s/robots-with-sitemapindex/robots.txt", server.URL),
+			want:      Completeness{Complete: true, TruncatedBy: TruncatedByNone},
+			urlsCount: 6,
+		},
+		{
+			name:      "failed sitemap",
+			s:         New(),
+			url:       fmt.Sprintf("%s/sitemapindex-with-invalid-sitemap.xml", server.URL),
+			want:      Completeness{Complete: false, TruncatedBy: TruncatedByNone, FailedSitemaps: 1},
+			urlsCount: 0,
+		},
+		{
+			name:      "max URLs",
+			s:         New().SetMultiThread(false).SetMaxURLs(2),
+			url:       fmt.Sprintf("%s/robots-with-sitemapindex/robots.txt", server.URL),
+			want:      Completeness{Complete: false, TruncatedBy: TruncatedByMaxURLs, SkippedSitemaps: 1},
+			urlsCount: 2,
+		},
+		{
+			name:      "max sitemaps",
+			s:         New().SetMultiThread(false).SetMaxSitemaps(2),
+			url:       fmt.Sprintf("%s/robots-with-sitemapindex/robots.txt", server.URL),
+			want:      Completeness{Complete: false, TruncatedBy: TruncatedByMaxSitemaps, SkippedSitemaps: 2},
+			urlsCount: 1,
+		},
+		{
+			name:      "deadline",
+			s:         New(),
+			ctx:       expiredCtx,
+			url:       fmt.Sprintf("%s/sitemapindex.xml", server.URL),
+			content:   &indexContent,
+			want:      Completeness{Complete: false, TruncatedBy: TruncatedByDeadline, SkippedSitemaps: 3},
+			urlsCount: 0,
+		},
+		{
+			name:      "cancelled",
+			s:         New().SetMultiThread(false),
+			ctx:       cancelledCtx,
+			url:       fmt.Sprintf("%s/sitemapindex.xml", server.URL),
+			content:   &indexContent,
+			want:      Completeness{Complete: false, TruncatedBy: TruncatedByCancelled, SkippedSitemaps: 3},
+			urlsCount: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := test.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			s, err := test.s.ParseContext(ctx, test.url, test.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.GetCompleteness(); got != test.want {
+				t.Errorf("expected %+v, got %+v", test.want, got)
+			}
+			if s.GetURLCount() != test.urlsCount {
+				t.Errorf("expected %d URLs, got %d", test.urlsCount, s.GetURLCount())
+			}
+		})
+	}
+
+	t.Run("nil receiver", func(t *testing.T) {
+		var s *S
+		if got := s.GetCompleteness(); got != (Completeness{}) {
+			t.Errorf("expected zero Completeness, got %+v", got)
+		}
+	})
+}
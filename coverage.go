@@ -0,0 +1,119 @@
+package sitemap
+
+import (
+	"strings"
+)
+
+// Coverage is the result of the comparison of the parsed URLs with an external list of URLs, e.g. the URLs discovered by a crawler.
+// The OnlyInSitemap field holds the parsed URLs missing from the list (orphans, if the list is a crawl), in their order.
+// The OnlyInCrawl field holds the URLs of the list missing from the sitemaps, in their order.
+// The InBoth field is the number of distinct URLs found in both.
+// The URLs are compared by their normalized form, see Coverage, and each of them is listed once, by its first occurrence.
+type Coverage struct {
+	OnlyInSitemap []URL    `json:"only_in_sitemap"`
+	OnlyInCrawl   []string `json:"only_in_crawl"`
+	InBoth        int      `json:"in_both"`
+}
+
+// Coverage compares the parsed URLs, including the locations stored in loc-only mode (see SetLocOnly), with the given list of URLs.
+// The URLs are compared by a normalized form, so that insignificant differences do not produce false gaps:
+// the scheme and the host are compared case-insensitively and in the ASCII (punycode) form of the host,
+// the default port of the scheme, a trailing slash of the path, an empty query and the fragment are ignored.
+// The path and the query are compared exactly. The lists are processed with hash sets, in time linear in their length.
+// If the S object is nil, every URL of the list is only in the crawl.
+func (s *S) Coverage(crawled []string) Coverage {
+	coverage := Coverage{OnlyInSitemap: []URL{}, OnlyInCrawl: []string{}}
+
+	keys := make([]string, len(crawled))
+	crawledKeys := make(map[string]struct{}, len(crawled))
+	for i, loc := range crawled {
+		keys[i] = coverageKey(loc)
+		crawledKeys[keys[i]] = struct{}{}
+	}
+
+	var sitemapKeys map[string]struct{}
+	if s != nil {
+		s.mu.Lock()
+		sitemapKeys = make(map[string]struct{}, len(s.locs)+len(s.urls))
+		add := func(u URL) {
+			key := coverageKey(u.Loc)
+			if _, ok := sitemapKeys[key]; ok {
+				return
+			}
+			sitemapKeys[key] = struct{}{}
+			if _, ok := crawledKeys[key]; ok {
+				coverage.InBoth++
+			} else {
+				coverage.OnlyInSitemap = append(coverage.OnlyInSitemap, u)
+			}
+		}
+		for _, loc := range s.locs {
+			add(URL{Loc: loc})
+		}
+		for _, u := range s.urls {
+			add(u)
+		}
+		s.mu.Unlock()
+	} else {
+		sitemapKeys = map[string]struct{}{}
+	}
+
+	for i, loc := range crawled {
+		key := keys[i]
+		if _, ok := sitemapKeys[key]; ok {
+			continue
+		}
+		// mark the URL as listed, so that its duplicates are skipped
+		sitemapKeys[key] = struct{}{}
+		coverage.OnlyInCrawl = append(coverage.OnlyInCrawl, loc)
+	}
+
+	return coverage
+}
+
+// coverageKey returns the normalized form of the given location the URLs are compared by in Coverage.
+// The location is split by scanning it rather than with net/url, which would dominate the cost of the comparison of millions of URLs.
+// A location without a scheme and a host is compared as it is.
+func coverageKey(loc string) string {
+	loc = strings.TrimSpace(loc)
+	i := strings.Index(loc, "://")
+	if i <= 0 {
+		return loc
+	}
+	scheme, rest := strings.ToLower(loc[:i]), loc[i+len("://"):]
+	end := strings.IndexAny(rest, "/?#")
+	if end < 0 {
+		end = len(rest)
+	}
+	host, rest := rest[:end], rest[end:]
+	if host == "" {
+		return loc
+	}
+	host = asciiHost(host)
+	if (scheme == "http" && strings.HasSuffix(host, ":80")) || (scheme == "https" && strings.HasSuffix(host, ":443")) {
+		host = host[:strings.LastIndexByte(host, ':')]
+	}
+
+	if fragment := strings.IndexByte(rest, '#'); fragment >= 0 {
+		rest = rest[:fragment]
+	}
+	path, query := rest, ""
+	if q := strings.IndexByte(rest, '?'); q >= 0 {
+		path, query = rest[:q], rest[q+1:]
+	}
+	path = strings.TrimRight(path, "/")
+
+	// most locations are in the normalized form already, they are returned without allocating a new string:
+	// the path and the query are taken from loc unchanged, so only the scheme and the host may differ if the length matches
+	n := len(scheme) + len("://") + len(host) + len(path)
+	if query != "" {
+		n += len("?") + len(query)
+	}
+	if n == len(loc) && loc[:i] == scheme && loc[i+len("://"):i+len("://")+len(host)] == host {
+		return loc
+	}
+	if query != "" {
+		return scheme + "://" + host + path + "?" + query
+	}
+	return scheme + "://" + host + path
+}
@@ -0,0 +1,123 @@
+package sitemap
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestS_Coverage(t *testing.T) {
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+    <url><loc>https://www.example.com/</loc></url>
+    <url><loc>https://www.example.com/about/</loc></url>
+    <url><loc>https://WWW.Example.com:443/contact</loc></url>
+    <url><loc>https://münchen.example/page</loc></url>
+    <url><loc>https://www.example.com/orphan</loc></url>
+    <url><loc>https://www.example.com/orphan/</loc></url>
+    <url><loc>https://www.example.com/search?q=a</loc></url>
+</urlset>`
+
+	crawled := []string{
+		"https://www.example.com",
+		"https://www.example.com/about",
+		"https://www.example.com/contact#form",
+		"https://xn--mnchen-3ya.example/page/",
+		"https://www.example.com/search?q=b",
+		"https://www.example.com/Contact",
+		"https://www.example.com/Contact/",
+		"http://www.example.com/about",
+	}
+
+	tests := []struct {
+		name string
+		s    *S
+		want Coverage
+	}{
+		{
+			name: "URLs",
+			s:    New(),
+			want: Coverage{
+				OnlyInSitemap: []URL{{Loc: "https://www.example.com/orphan"}, {Loc: "https://www.example.com/search?q=a"}},
+				OnlyInCrawl:   []string{"https://www.example.com/search?q=b", "https://www.example.com/Contact", "http://www.example.com/about"},
+				InBoth:        4,
+			},
+		},
+		{
+			name: "loc only",
+			s:    New().SetLocOnly(true),
+			want: Coverage{
+				OnlyInSitemap: []URL{{Loc: "https://www.example.com/orphan"}, {Loc: "https://www.example.com/search?q=a"}},
+				OnlyInCrawl:   []string{"https://www.example.com/search?q=b", "https://www.example.com/Contact", "http://www.example.com/about"},
+				InBoth:        4,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := test.s.Parse("https://www.example.com/sitemap.xml", &content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := s.Coverage(crawled)
+			for i := range got.OnlyInSitemap {
+				got.OnlyInSitemap[i].source = ""
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %+v, got %+v", test.want, got)
+			}
+		})
+	}
+
+	var nilS *S
+	if got, want := nilS.Coverage([]string{"https://www.example.com/", "https://www.example.com"}), (Coverage{OnlyInSitemap: []URL{}, OnlyInCrawl: []string{"https://www.example.com/"}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestCoverageKey(t *testing.T) {
+	tests := []struct {
+		name string
+		loc  string
+		want string
+	}{
+		{name: "root", loc: "https://www.example.com/", want: "https://www.example.com"},
+		{name: "host case", loc: "HTTPS://WWW.Example.COM/Path", want: "https://www.example.com/Path"},
+		{name: "trailing slash", loc: "https://www.example.com/a/b/", want: "https://www.example.com/a/b"},
+		{name: "default port", loc: "http://www.example.com:80/a", want: "http://www.example.com/a"},
+		{name: "other port", loc: "http://www.example.com:443/a", want: "http://www.example.com:443/a"},
+		{name: "fragment", loc: "https://www.example.com/a#top", want: "https://www.example.com/a"},
+		{name: "query", loc: "https://www.example.com/a/?b=c", want: "https://www.example.com/a?b=c"},
+		{name: "empty query", loc: "https://www.example.com/a?", want: "https://www.example.com/a"},
+		{name: "IDN", loc: "https://München.example/", want: "https://xn--mnchen-3ya.example"},
+		{name: "relative", loc: "/a/", want: "/a/"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := coverageKey(test.loc); got != test.want {
+				t.Errorf("expected %s, got %s", test.want, got)
+			}
+		})
+	}
+}
+
+func Benchmark_Coverage(b *testing.B) {
+	const n = 1000000
+	s := New()
+	s.urls = make([]URL, 0, n)
+	for i := 0; i < n; i++ {
+		s.urls = append(s.urls, URL{Loc: fmt.Sprintf("https://www.example.com/page-%d", i)})
+	}
+	crawled := make([]string, 0, n)
+	for i := n / 2; i < n+n/2; i++ {
+		crawled = append(crawled, fmt.Sprintf("https://www.example.com/page-%d", i))
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = s.Coverage(crawled)
+	}
+}
@@ -0,0 +1,123 @@
+package sitemap
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// CSVColumn represents a column of the CSV output produced by WriteCSV.
+type CSVColumn string
+
+const (
+	// CSVColumnLoc is the column holding the <loc> value of the URL.
+	CSVColumnLoc CSVColumn = "loc"
+
+	// CSVColumnLastMod is the column holding the <lastmod> value of the URL in RFC3339 format.
+	CSVColumnLastMod CSVColumn = "lastmod"
+
+	// CSVColumnChangeFreq is the column holding the <changefreq> value of the URL.
+	CSVColumnChangeFreq CSVColumn = "changefreq"
+
+	// CSVColumnPriority is the column holding the <priority> value of the URL.
+	CSVColumnPriority CSVColumn = "priority"
+
+	// CSVColumnSourceSitemap is the column holding the location of the sitemap the URL was found in.
+	CSVColumnSourceSitemap CSVColumn = "source_sitemap"
+)
+
+// csvColumnsDefault is the list of columns written by WriteCSV when no columns are specified.
+var csvColumnsDefault = []CSVColumn{
+	CSVColumnLoc,
+	CSVColumnLastMod,
+	CSVColumnChangeFreq,
+	CSVColumnPriority,
+	CSVColumnSourceSitemap,
+}
+
+// WriteCSV writes the parsed URLs to w in CSV format.
+// The first row is a header row containing the column names, followed by one row per URL.
+// If no columns are specified, all columns are written in the order: loc, lastmod, changefreq, priority, source_sitemap.
+// The lastmod value is formatted as RFC3339; nil fields are written as empty cells.
+// In loc-only mode (see SetLocOnly), the locations are written with only the loc cell filled in.
+// Quoting and escaping are handled by encoding/csv, the rows are written one by one from a copy of the URLs,
+// so the S object is not locked while writing to w.
+// It returns an error if an unknown column is requested or writing to w fails.
+func (s *S) WriteCSV(w io.Writer, columns ...CSVColumn) error {
+	if len(columns) == 0 {
+		columns = csvColumnsDefault
+	}
+	for _, column := range columns {
+		if !column.valid() {
+			return fmt.Errorf("unknown CSV column %q", column)
+		}
+	}
+
+	csvWriter := csv.NewWriter(w)
+
+	record := make([]string, len(columns))
+	for i, column := range columns {
+		record[i] = string(column)
+	}
+	if err := csvWriter.Write(record); err != nil {
+		return err
+	}
+
+	var urls []URL
+	if s != nil {
+		s.mu.Lock()
+		urls = make([]URL, 0, len(s.urls)+len(s.locs))
+		urls = append(urls, s.urls...)
+		for _, loc := range s.locs {
+			urls = append(urls, URL{Loc: loc})
+		}
+		s.mu.Unlock()
+	}
+	for _, u := range urls {
+		for i, column := range columns {
+			record[i] = column.value(u)
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+
+	return csvWriter.Error()
+}
+
+// valid reports whether the column is one of the known CSV columns.
+func (c CSVColumn) valid() bool {
+	for _, column := range csvColumnsDefault {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// value returns the value of the column for the given URL, or an empty string if the field is nil.
+func (c CSVColumn) value(u URL) string {
+	switch c {
+	case CSVColumnLoc:
+		return u.Loc
+	case CSVColumnLastMod:
+		if u.LastMod != nil {
+			return u.LastMod.Format(time.RFC3339)
+		}
+	case CSVColumnChangeFreq:
+		if u.ChangeFreq != nil {
+			return string(*u.ChangeFreq)
+		}
+	case CSVColumnPriority:
+		if u.Priority != nil {
+			return strconv.FormatFloat(float64(*u.Priority), 'f', -1, 32)
+		}
+	case CSVColumnSourceSitemap:
+		return u.source
+	}
+	return ""
+}
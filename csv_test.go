@@ -0,0 +1,88 @@
+package sitemap
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestS_WriteCSV(t *testing.T) {
+	urls := []URL{
+		{
+			Loc:        "https://www.sitemaps.org/page?a=1,b=2",
+			LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, time.FixedZone("", 3600))}),
+			ChangeFreq: pointerOfURLChangeFreq(changeFreqDaily),
+			Priority:   pointerOfFloat32(0.8),
+			source:     "https://www.sitemaps.org/sitemap.xml",
+		},
+		{
+			Loc:    `https://www.sitemaps.org/"quoted"`,
+			source: "https://www.sitemaps.org/sitemap.xml",
+		},
+	}
+
+	tests := []struct {
+		name    string
+		s       *S
+		columns []CSVColumn
+		want    string
+		wantErr error
+	}{
+		{
+			name: "nil receiver",
+			s:    nil,
+			want: "loc,lastmod,changefreq,priority,source_sitemap\n",
+		},
+		{
+			name: "no URLs",
+			s:    New(),
+			want: "loc,lastmod,changefreq,priority,source_sitemap\n",
+		},
+		{
+			name: "all columns",
+			s:    &S{urls: urls},
+			want: "loc,lastmod,changefreq,priority,source_sitemap\n" +
+				"\"https://www.sitemaps.org/page?a=1,b=2\",2024-02-12T12:34:56+01:00,daily,0.8,https://www.sitemaps.org/sitemap.xml\n" +
+				"\"https://www.sitemaps.org/\"\"quoted\"\"\",,,,https://www.sitemaps.org/sitemap.xml\n",
+		},
+		{
+			name:    "selected columns",
+			s:       &S{urls: urls},
+			columns: []CSVColumn{CSVColumnPriority, CSVColumnLoc},
+			want: "priority,loc\n" +
+				"0.8,\"https://www.sitemaps.org/page?a=1,b=2\"\n" +
+				",\"https://www.sitemaps.org/\"\"quoted\"\"\"\n",
+		},
+		{
+			name: "loc-only mode",
+			s:    &S{locs: []string{"https://www.sitemaps.org/1", "https://www.sitemaps.org/2"}},
+			want: "loc,lastmod,changefreq,priority,source_sitemap\n" +
+				"https://www.sitemaps.org/1,,,,\n" +
+				"https://www.sitemaps.org/2,,,,\n",
+		},
+		{
+			name:    "unknown column",
+			s:       &S{urls: urls},
+			columns: []CSVColumn{CSVColumnLoc, "unknown"},
+			want:    "",
+			wantErr: errors.New("unknown CSV column \"unknown\""),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := test.s.WriteCSV(&buf, test.columns...)
+			if (err != nil) != (test.wantErr != nil) {
+				t.Fatalf("unexpected err: got %v, want %v", err, test.wantErr)
+			}
+			if err != nil && err.Error() != test.wantErr.Error() {
+				t.Errorf("unexpected err: got %v, want %v", err, test.wantErr)
+			}
+			if buf.String() != test.want {
+				t.Errorf("expected %q, got %q", test.want, buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,316 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// decodeOptions holds the options of the parser the decoding is performed for.
+// The lastModFormats field is the list of layouts of the <lastmod> values, nil means the defaults, see SetLastModFormats.
+// The lastModLocation field is the location of the <lastmod> values without zone information, nil means UTC, see SetDefaultLastModLocation.
+// The maxURLs field is the maximum number of <url> entries decoded from a <urlset>, 0 means no limit.
+// The limits field holds the limits of the decoding, see SetDecodeLimits.
+// The fields field is the set of the child elements of <url> to decode, nil means all of them, see SetFields.
+// The trackPositions field determines whether the position of each <url> element is recorded, see SetTrackPositions.
+// The strictElements field determines whether the unexpected elements of a <urlset> are reported, see SetStrictElements.
+type decodeOptions struct {
+	lastModFormats  []string
+	lastModLocation *time.Location
+	maxURLs         int
+	limits          DecodeLimits
+	fields          map[string]bool
+	trackPositions  bool
+	strictElements  bool
+}
+
+// DecodeLimits holds the limits enforced while decoding a document, protecting against documents crafted to exhaust memory or stack.
+// The MaxDepth field is the maximum nesting depth of the elements.
+// The MaxCharDataLength field is the maximum length in bytes of the character data directly inside an element, e.g. of a <loc> value.
+// The MaxTokens field is the maximum number of tokens (elements, character data, comments, ...) of the document.
+// A zero field means the default of DefaultDecodeLimits, a negative field means no limit.
+type DecodeLimits struct {
+	MaxDepth          int
+	MaxCharDataLength int
+	MaxTokens         int
+}
+
+// DefaultDecodeLimits are the default limits of the decoding, generous enough for any valid sitemap:
+// sitemap elements nest a few levels deep, and the protocol caps a sitemap at 50,000 URLs and 50 MB.
+var DefaultDecodeLimits = DecodeLimits{
+	MaxDepth:          64,
+	MaxCharDataLength: 64 << 10,
+	MaxTokens:         10_000_000,
+}
+
+// SetDecodeLimits sets the limits enforced by the Sitemap Parser while decoding the documents, see DecodeLimits.
+// A document exceeding a limit is not parsed further, and a DecodeLimitError is recorded with its location.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetDecodeLimits(limits DecodeLimits) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.decodeLimits = limits
+
+	return s
+}
+
+// withDefaults returns the limits with the zero fields set to their defaults, see DefaultDecodeLimits.
+func (l DecodeLimits) withDefaults() DecodeLimits {
+	if l.MaxDepth == 0 {
+		l.MaxDepth = DefaultDecodeLimits.MaxDepth
+	}
+	if l.MaxCharDataLength == 0 {
+		l.MaxCharDataLength = DefaultDecodeLimits.MaxCharDataLength
+	}
+	if l.MaxTokens == 0 {
+		l.MaxTokens = DefaultDecodeLimits.MaxTokens
+	}
+	return l
+}
+
+// decoderOptions holds the options of the decoders created by newDecoder, keyed by decoder,
+// so that the UnmarshalXML methods, which have no access to the parser, can read them.
+var decoderOptions sync.Map
+
+// newDecoder creates a decoder reading from r with the given options, see optionsOf.
+// The decoder rejects DOCTYPE declarations with ErrDoctypeNotAllowed, and enforces the limits of the options, see guardedTokenReader.
+// If the options restrict the fields, the other child elements of <url> are dropped, see fieldFilterTokenReader.
+// The returned function must be called once the decoding is done.
+func newDecoder(r io.Reader, opts decodeOptions) (*xml.Decoder, func()) {
+	d, _, release := newGuardedDecoder(r, opts)
+	return d, release
+}
+
+// newGuardedDecoder is newDecoder, also returning the guardedTokenReader of the decoder,
+// which holds the position of the last <url> element started if the options track the positions,
+// and the unexpected elements of a <urlset> if the options are strict about the elements.
+func newGuardedDecoder(r io.Reader, opts decodeOptions) (*xml.Decoder, *guardedTokenReader, func()) {
+	guarded := &guardedTokenReader{d: xml.NewDecoder(r), limits: opts.limits.withDefaults(), trackPositions: opts.trackPositions}
+	if opts.strictElements {
+		guarded.strict = &strictElements{}
+	}
+	var tokens xml.TokenReader = guarded
+	if opts.fields != nil {
+		tokens = &fieldFilterTokenReader{r: tokens, fields: opts.fields}
+	}
+	d := xml.NewTokenDecoder(tokens)
+	if opts.lastModFormats == nil && opts.lastModLocation == nil {
+		return d, guarded, func() {}
+	}
+	decoderOptions.Store(d, opts)
+	return d, guarded, func() {
+		decoderOptions.Delete(d)
+	}
+}
+
+// optionsOf returns the options of the given decoder, the defaults if it has not been created by newDecoder with options.
+func optionsOf(d *xml.Decoder) decodeOptions {
+	if opts, ok := decoderOptions.Load(d); ok {
+		return opts.(decodeOptions)
+	}
+	return decodeOptions{}
+}
+
+// guardedTokenReader reads the tokens of the underlying decoder, failing with ErrDoctypeNotAllowed at the first directive,
+// and with a DecodeLimitError at the first token exceeding one of the limits.
+// encoding/xml does not expand the entities declared in a DTD, and in strict mode it fails on the undeclared ones,
+// but rejecting the declarations outright stops such documents before any of their content is decoded.
+// The limits stop the decoding at the offending token, which the underlying decoder has already read in full.
+// The depth field is the current nesting depth, the charData field is the length of the character data of the current element so far,
+// and the tokens field is the number of tokens read.
+// If the trackPositions field is true, the urlStart field is the position of the last element started at depth 2, i.e. the last <url> of a <urlset>;
+// the child elements of <url> are at depth 3 and below, so the position is not overwritten while a <url> is being decoded.
+// The strict field checks the elements of a <urlset>, nil unless the unexpected elements are reported, see SetStrictElements.
+// It sees every element, before the fields not decoded are dropped, see fieldFilterTokenReader.
+type guardedTokenReader struct {
+	d              *xml.Decoder
+	limits         DecodeLimits
+	depth          int
+	charData       int
+	tokens         int
+	trackPositions bool
+	urlStart       Position
+	strict         *strictElements
+}
+
+// Token returns the next token of the underlying decoder, or an error if it is a directive or exceeds a limit.
+func (g *guardedTokenReader) Token() (xml.Token, error) {
+	var start Position
+	if g.trackPositions || g.strict != nil {
+		// the decoder is positioned at the start of the next token, the character data before an element is a token of its own
+		start.Offset = g.d.InputOffset()
+		start.Line, start.Column = g.d.InputPos()
+	}
+	token, err := g.d.Token()
+	if token == nil {
+		return token, err
+	}
+
+	g.tokens++
+	if g.limits.MaxTokens > 0 && g.tokens > g.limits.MaxTokens {
+		return nil, &DecodeLimitError{Limit: DecodeLimitTokens, Max: g.limits.MaxTokens}
+	}
+	switch t := token.(type) {
+	case xml.Directive:
+		return nil, ErrDoctypeNotAllowed
+	case xml.StartElement:
+		g.depth++
+		g.charData = 0
+		if g.limits.MaxDepth > 0 && g.depth > g.limits.MaxDepth {
+			return nil, &DecodeLimitError{Limit: DecodeLimitDepth, Max: g.limits.MaxDepth}
+		}
+		if g.trackPositions && g.depth == 2 {
+			g.urlStart = start
+		}
+		if g.strict != nil {
+			g.strict.check(token, g.depth, start)
+		}
+	case xml.EndElement:
+		if g.strict != nil {
+			g.strict.check(token, g.depth, start)
+		}
+		g.depth--
+		g.charData = 0
+	case xml.CharData:
+		g.charData += len(t)
+		if g.limits.MaxCharDataLength > 0 && g.charData > g.limits.MaxCharDataLength {
+			return nil, &DecodeLimitError{Limit: DecodeLimitCharDataLength, Max: g.limits.MaxCharDataLength}
+		}
+		if g.strict != nil {
+			g.strict.check(token, g.depth, start)
+		}
+	}
+	return token, err
+}
+
+// unexpectedElements returns the unexpected elements of the <urlset> read, nil unless the elements are checked, see SetStrictElements.
+func (g *guardedTokenReader) unexpectedElements() []*UnexpectedElementError {
+	if g.strict == nil {
+		return nil
+	}
+	return g.strict.violations
+}
+
+// DecodeURLSet decodes a <urlset> document read from r, without fetching anything.
+// The document must not be compressed. It returns an error if the document cannot be decoded or its root element is not <urlset>,
+// ErrDoctypeNotAllowed if it has a DOCTYPE declaration, and a DecodeLimitError if it exceeds DefaultDecodeLimits.
+func DecodeURLSet(r io.Reader) (URLSet, error) {
+	return decodeURLSet(r, decodeOptions{})
+}
+
+// decodeURLSet is DecodeURLSet with the given options.
+func decodeURLSet(r io.Reader, opts decodeOptions) (URLSet, error) {
+	var urlSet URLSet
+	d, guarded, release := newGuardedDecoder(r, opts)
+	defer release()
+	if opts.maxURLs > 0 || opts.trackPositions {
+		name, err := decodeURLElements(d, opts.maxURLs, func(start *xml.StartElement) error {
+			var u URL
+			position := guarded.urlStart
+			if err := d.DecodeElement(&u, start); err != nil {
+				return err
+			}
+			if opts.trackPositions {
+				u.Position = &position
+			}
+			urlSet.URL = append(urlSet.URL, u)
+			return nil
+		})
+		if err != nil {
+			return URLSet{}, err
+		}
+		urlSet.XMLName = name
+		urlSet.unexpected = guarded.unexpectedElements()
+		return urlSet, nil
+	}
+	if err := d.Decode(&urlSet); err != nil {
+		return URLSet{}, err
+	}
+	urlSet.unexpected = guarded.unexpectedElements()
+	return urlSet, nil
+}
+
+// decodeURLElements reads the <urlset> document of d, calling decodeURL for each of its <url> elements, until maxURLs elements have been decoded.
+// The rest of the document is not read once the limit is reached, a maxURLs of 0 means no limit. Other elements of the <urlset> are skipped.
+// It returns the name of the root element, and an error if the document cannot be decoded or its root element is not <urlset>.
+func decodeURLElements(d *xml.Decoder, maxURLs int, decodeURL func(start *xml.StartElement) error) (xml.Name, error) {
+	var root xml.StartElement
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return xml.Name{}, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			root = start
+			break
+		}
+	}
+	if root.Name.Local != "urlset" {
+		return xml.Name{}, fmt.Errorf("expected element type <urlset> but have <%s>", root.Name.Local)
+	}
+
+	for decoded := 0; maxURLs <= 0 || decoded < maxURLs; {
+		token, err := d.Token()
+		if err != nil {
+			return xml.Name{}, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Local != "url" {
+				if err := d.Skip(); err != nil {
+					return xml.Name{}, err
+				}
+				continue
+			}
+			if err := decodeURL(&t); err != nil {
+				return xml.Name{}, err
+			}
+			decoded++
+		case xml.EndElement:
+			return root.Name, nil
+		}
+	}
+	return root.Name, nil
+}
+
+// DecodeSitemapIndex decodes a <sitemapindex> document read from r, without fetching anything.
+// The document must not be compressed. It returns an error if the document cannot be decoded or its root element is not <sitemapindex>,
+// ErrDoctypeNotAllowed if it has a DOCTYPE declaration, and a DecodeLimitError if it exceeds DefaultDecodeLimits.
+// The <lastmod> values are parsed leniently: a missing or invalid value leaves the LastMod field of the entry nil.
+func DecodeSitemapIndex(r io.Reader) (SitemapIndex, error) {
+	return decodeSitemapIndex(r, decodeOptions{})
+}
+
+// decodeSitemapIndex is DecodeSitemapIndex with the given options.
+func decodeSitemapIndex(r io.Reader, opts decodeOptions) (SitemapIndex, error) {
+	var smIndex SitemapIndex
+	d, release := newDecoder(r, opts)
+	defer release()
+	if err := d.Decode(&smIndex); err != nil {
+		return SitemapIndex{}, err
+	}
+	return smIndex, nil
+}
+
+// UnmarshalXML decodes a <sitemap> entry of a <sitemapindex>, parsing its <lastmod> value leniently, see DecodeSitemapIndex.
+func (sm *IndexSitemap) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var entry struct {
+		Loc     string  `xml:"loc"`
+		LastMod *string `xml:"lastmod"`
+	}
+	if err := d.DecodeElement(&entry, &start); err != nil {
+		return err
+	}
+
+	*sm = IndexSitemap{Loc: entry.Loc}
+	if entry.LastMod != nil {
+		opts := optionsOf(d)
+		if t, err := parseLastModFormats(*entry.LastMod, opts.lastModFormats, opts.lastModLocation); err == nil {
+			sm.LastMod = &t
+		}
+	}
+	return nil
+}
@@ -0,0 +1,341 @@
+package sitemap
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeURLSet(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		wantLocs []string
+		wantErr  string
+	}{
+		{
+			name:     "urlset",
+			data:     `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><url><loc>https://www.example.com/a</loc><lastmod>2024-02-12</lastmod></url><url><loc>https://www.example.com/b</loc></url></urlset>`,
+			wantLocs: []string{"https://www.example.com/a", "https://www.example.com/b"},
+		},
+		{
+			name:    "sitemapindex",
+			data:    `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"></sitemapindex>`,
+			wantErr: "expected element type <urlset> but have <sitemapindex>",
+		},
+		{
+			name:    "empty",
+			data:    "",
+			wantErr: "EOF",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			urlSet, err := DecodeURLSet(strings.NewReader(test.data))
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Errorf("expected error %q, got %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := locsOf(urlSet.URL); strings.Join(got, " ") != strings.Join(test.wantLocs, " ") {
+				t.Errorf("expected %v, got %v", test.wantLocs, got)
+			}
+		})
+	}
+}
+
+func TestDecodeURLSet_MaxURLs(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		maxURLs  int
+		wantLocs []string
+		wantErr  bool
+	}{
+		{
+			name:     "fewer entries",
+			data:     `<urlset><url><loc>a</loc></url><url><loc>b</loc></url></urlset>`,
+			maxURLs:  3,
+			wantLocs: []string{"a", "b"},
+		},
+		{
+			name:     "more entries",
+			data:     `<urlset><url><loc>a</loc></url><!-- b --><foo><url><loc>x</loc></url></foo><url><loc>b</loc></url><url><loc>c</loc></url></urlset>`,
+			maxURLs:  2,
+			wantLocs: []string{"a", "b"},
+		},
+		{
+			name:     "syntax error after the limit",
+			data:     `<urlset><url><loc>a</loc></url><url><loc>b</loc></url><url><loc>c</urlset>`,
+			maxURLs:  2,
+			wantLocs: []string{"a", "b"},
+		},
+		{
+			name:    "syntax error before the limit",
+			data:    `<urlset><url><loc>a</loc></url><url><loc>b</urlset>`,
+			maxURLs: 2,
+			wantErr: true,
+		},
+		{
+			name:    "unclosed urlset",
+			data:    `<urlset><url><loc>a</loc></url>`,
+			maxURLs: 2,
+			wantErr: true,
+		},
+		{
+			name:    "sitemapindex",
+			data:    `<sitemapindex></sitemapindex>`,
+			maxURLs: 2,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			urlSet, err := decodeURLSet(strings.NewReader(test.data), decodeOptions{maxURLs: test.maxURLs})
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %v", urlSet)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if urlSet.XMLName.Local != "urlset" {
+				t.Errorf("expected urlset, got %v", urlSet.XMLName)
+			}
+			if got := locsOf(urlSet.URL); strings.Join(got, " ") != strings.Join(test.wantLocs, " ") {
+				t.Errorf("expected %v, got %v", test.wantLocs, got)
+			}
+		})
+	}
+}
+
+func TestDecodeURLSet_Doctype(t *testing.T) {
+	billionLaughs, err := os.ReadFile("./test/sitemap-billion-laughs.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr error
+	}{
+		{
+			name:    "billion laughs",
+			data:    string(billionLaughs),
+			wantErr: ErrDoctypeNotAllowed,
+		},
+		{
+			name:    "external entity",
+			data:    `<!DOCTYPE urlset [<!ENTITY xxe SYSTEM "file:///etc/passwd">]><urlset><url><loc>&xxe;</loc></url></urlset>`,
+			wantErr: ErrDoctypeNotAllowed,
+		},
+		{
+			name:    "doctype after a comment",
+			data:    `<?xml version="1.0"?><!-- sitemap --><!DOCTYPE urlset><urlset></urlset>`,
+			wantErr: ErrDoctypeNotAllowed,
+		},
+		{
+			name: "undeclared entity",
+			data: `<urlset><url><loc>&lol;</loc></url></urlset>`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, maxURLs := range []int{0, 1} {
+				var before, after runtime.MemStats
+				runtime.ReadMemStats(&before)
+				_, err := decodeURLSet(strings.NewReader(test.data), decodeOptions{maxURLs: maxURLs})
+				runtime.ReadMemStats(&after)
+
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				if test.wantErr != nil && !errors.Is(err, test.wantErr) {
+					t.Errorf("expected %v, got %v", test.wantErr, err)
+				}
+				if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+					t.Errorf("expected bounded memory, allocated %d bytes", allocated)
+				}
+			}
+		})
+	}
+
+	if _, err := DecodeSitemapIndex(strings.NewReader(`<!DOCTYPE sitemapindex><sitemapindex></sitemapindex>`)); !errors.Is(err, ErrDoctypeNotAllowed) {
+		t.Errorf("expected %v, got %v", ErrDoctypeNotAllowed, err)
+	}
+}
+
+func TestDecodeURLSet_Limits(t *testing.T) {
+	deep := "<urlset><url>" + strings.Repeat("<a>", 100) + strings.Repeat("</a>", 100) + "</url></urlset>"
+	huge := "<urlset><url><loc>https://www.example.com/" + strings.Repeat("a", 1<<20) + "</loc></url></urlset>"
+	split := "<urlset><url><loc>" + strings.Repeat("a", 40) + "<!-- -->" + strings.Repeat("a", 40) + "</loc></url></urlset>"
+	many := "<urlset>" + strings.Repeat("<url><loc>a</loc></url>", 1000) + "</urlset>"
+
+	tests := []struct {
+		name      string
+		data      string
+		limits    DecodeLimits
+		wantLimit DecodeLimit
+		wantMax   int
+	}{
+		{
+			name:      "deep nesting",
+			data:      deep,
+			wantLimit: DecodeLimitDepth,
+			wantMax:   DefaultDecodeLimits.MaxDepth,
+		},
+		{
+			name:   "deep nesting, no limit",
+			data:   deep,
+			limits: DecodeLimits{MaxDepth: -1},
+		},
+		{
+			name:      "huge loc",
+			data:      huge,
+			wantLimit: DecodeLimitCharDataLength,
+			wantMax:   DefaultDecodeLimits.MaxCharDataLength,
+		},
+		{
+			name:   "huge loc, higher limit",
+			data:   huge,
+			limits: DecodeLimits{MaxCharDataLength: 2 << 20},
+		},
+		{
+			name:      "character data split by a comment",
+			data:      split,
+			limits:    DecodeLimits{MaxCharDataLength: 64},
+			wantLimit: DecodeLimitCharDataLength,
+			wantMax:   64,
+		},
+		{
+			name:      "many tokens",
+			data:      many,
+			limits:    DecodeLimits{MaxTokens: 2000},
+			wantLimit: DecodeLimitTokens,
+			wantMax:   2000,
+		},
+		{
+			name:   "within the limits",
+			data:   many,
+			limits: DecodeLimits{MaxDepth: 3, MaxCharDataLength: 1, MaxTokens: 5002},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, maxURLs := range []int{0, 2000} {
+				_, err := decodeURLSet(strings.NewReader(test.data), decodeOptions{maxURLs: maxURLs, limits: test.limits})
+				if test.wantLimit == "" {
+					if err != nil {
+						t.Errorf("unexpected error: %v", err)
+					}
+					continue
+				}
+				var limitErr *DecodeLimitError
+				if !errors.As(err, &limitErr) {
+					t.Fatalf("expected a decode limit error, got %v", err)
+				}
+				if limitErr.Limit != test.wantLimit || limitErr.Max != test.wantMax {
+					t.Errorf("expected %s over %d, got %v", test.wantLimit, test.wantMax, limitErr)
+				}
+			}
+		})
+	}
+
+	if _, err := DecodeSitemapIndex(strings.NewReader(strings.Replace(deep, "urlset", "sitemapindex", 2))); err == nil {
+		t.Error("expected a decode limit error")
+	}
+}
+
+func TestS_SetDecodeLimits(t *testing.T) {
+	limits := DecodeLimits{MaxDepth: 10, MaxCharDataLength: -1}
+
+	s := New().SetDecodeLimits(limits)
+	if s.cfg.decodeLimits != limits {
+		t.Errorf("expected %v, got %v", limits, s.cfg.decodeLimits)
+	}
+	want := DecodeLimits{MaxDepth: 10, MaxCharDataLength: -1, MaxTokens: DefaultDecodeLimits.MaxTokens}
+	if got := s.decodeOptions().limits.withDefaults(); got != want {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestDecodeSitemapIndex(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		wantLocs    []string
+		wantLastMod []string
+		wantErr     string
+	}{
+		{
+			name: "sitemapindex",
+			data: `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+<sitemap><loc>https://www.example.com/sitemap-01.xml</loc><lastmod>2024-02-12T12:34:56+01:00</lastmod></sitemap>
+<sitemap><loc>https://www.example.com/sitemap-02.xml</loc><lastmod>2024-02-13</lastmod></sitemap>
+<sitemap><loc>https://www.example.com/sitemap-03.xml</loc><lastmod>yesterday</lastmod></sitemap>
+<sitemap><loc>https://www.example.com/sitemap-04.xml</loc></sitemap>
+</sitemapindex>`,
+			wantLocs: []string{
+				"https://www.example.com/sitemap-01.xml",
+				"https://www.example.com/sitemap-02.xml",
+				"https://www.example.com/sitemap-03.xml",
+				"https://www.example.com/sitemap-04.xml",
+			},
+			wantLastMod: []string{"2024-02-12T12:34:56+01:00", "2024-02-13T00:00:00Z", "", ""},
+		},
+		{
+			name:    "urlset",
+			data:    `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"></urlset>`,
+			wantErr: "expected element type <sitemapindex> but have <urlset>",
+		},
+		{
+			name:    "empty",
+			data:    "",
+			wantErr: "EOF",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			smIndex, err := DecodeSitemapIndex(strings.NewReader(test.data))
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Errorf("expected error %q, got %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(smIndex.Sitemap) != len(test.wantLocs) {
+				t.Fatalf("expected %d sitemaps, got %d", len(test.wantLocs), len(smIndex.Sitemap))
+			}
+			for i, sm := range smIndex.Sitemap {
+				if sm.Loc != test.wantLocs[i] {
+					t.Errorf("expected loc %s, got %s", test.wantLocs[i], sm.Loc)
+				}
+				lastMod := ""
+				if sm.LastMod != nil {
+					lastMod = sm.LastMod.Format(time.RFC3339)
+				}
+				if lastMod != test.wantLastMod[i] {
+					t.Errorf("%s: expected lastmod %q, got %q", sm.Loc, test.wantLastMod[i], lastMod)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package sitemap
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	neturl "net/url"
+	"strings"
+)
+
+// ErrDecompressedTooLarge is matched by errors.Is for the errors of the content decompressed over the maximum size, see SetMaxDecompressedSize.
+var ErrDecompressedTooLarge = errors.New("decompressed content too large")
+
+// Decompressor is a codec the Sitemap Parser uses to decompress fetched content, registered with SetDecompressors.
+// It allows compression formats such as Zstandard or Brotli without the package depending on their implementations.
+// The Encoding field is the content coding of the codec (e.g. "zstd" or "br"), matched against the Content-Encoding response header.
+// The Magic field is the prefix identifying the compressed content (e.g. "\x28\xb5\x2f\xfd" for Zstandard), empty if there is none.
+// The Extensions field is the list of file extensions identifying the compressed content by the path of its location (e.g. ".zst").
+// The NewReader field creates a reader of the decompressed content, the reader is closed after use if it is an io.Closer.
+type Decompressor struct {
+	Encoding   string
+	Magic      []byte
+	Extensions []string
+	NewReader  func(r io.Reader) (io.Reader, error)
+}
+
+// SetDecompressors sets the codecs used by the Sitemap Parser besides the built-in gzip, zlib and deflate support.
+// Fetched content is decompressed by the first codec whose Magic prefix or one of whose Extensions matches,
+// and response bodies sent with a Content-Encoding header are decoded by the codec of that Encoding.
+// A codec without NewReader, or without any of Encoding, Magic or Extensions, is recorded as an error, see GetErrors.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetDecompressors(decompressors ...Decompressor) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.decompressors = nil
+	for _, d := range decompressors {
+		if d.NewReader == nil || (d.Encoding == "" && len(d.Magic) == 0 && len(d.Extensions) == 0) {
+			s.errs = append(s.errs, fmt.Errorf("invalid decompressor %q: NewReader and one of Encoding, Magic or Extensions are required", d.Encoding))
+			continue
+		}
+		s.cfg.decompressors = append(s.cfg.decompressors, d)
+	}
+
+	return s
+}
+
+// SetMaxDecompressedSize sets the maximum size of a decompressed document, in bytes, protecting against decompression bombs:
+// small compressed files expanding to a huge content. It applies to every decompression, whether the content is detected by its
+// magic bytes, by the extension of its location or by the Content-Encoding header of its response, and whatever the codec.
+// The decompression stops as soon as the size is exceeded, and the location is skipped with an error matching ErrDecompressedTooLarge recorded.
+// A value of 0 (the default) means no limit.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetMaxDecompressedSize(bytes int64) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.maxDecompressedSize = bytes
+
+	return s
+}
+
+// limitDecompressed returns a reader of the decompressed content of reader, stopping one byte over the maximum size
+// (see SetMaxDecompressedSize) given the number of bytes already decompressed, so that checkDecompressedSize detects the excess.
+// It returns reader unchanged if there is no maximum size.
+func (s *S) limitDecompressed(reader io.Reader, decompressed int) io.Reader {
+	if s.cfg.maxDecompressedSize <= 0 {
+		return reader
+	}
+	return io.LimitReader(reader, max(s.cfg.maxDecompressedSize-int64(decompressed)+1, 0))
+}
+
+// checkDecompressedSize returns an error matching ErrDecompressedTooLarge if the given size of decompressed content
+// is over the maximum size, see SetMaxDecompressedSize.
+func (s *S) checkDecompressedSize(decompressed int) error {
+	if s.cfg.maxDecompressedSize <= 0 || int64(decompressed) <= s.cfg.maxDecompressedSize {
+		return nil
+	}
+	return fmt.Errorf("%w: over %d bytes", ErrDecompressedTooLarge, s.cfg.maxDecompressedSize)
+}
+
+// readDecompressed reads the decompressed content of reader up to the maximum size, see SetMaxDecompressedSize.
+// Every decompression goes through it, or through limitDecompressed and checkDecompressedSize for multiple streams.
+// It returns the content read so far along with a read error, and an error matching ErrDecompressedTooLarge if the maximum size is exceeded.
+func (s *S) readDecompressed(reader io.Reader) ([]byte, error) {
+	decompressed, err := io.ReadAll(s.limitDecompressed(reader, 0))
+	if err != nil {
+		return decompressed, err
+	}
+	if err = s.checkDecompressedSize(len(decompressed)); err != nil {
+		return nil, err
+	}
+	return decompressed, nil
+}
+
+// decompressorFor returns the codec matching the content or the path of its location, nil if there is none.
+func (s *S) decompressorFor(location string, content []byte) *Decompressor {
+	path := location
+	if u, err := neturl.Parse(location); err == nil {
+		path = u.Path
+	}
+	for i, d := range s.cfg.decompressors {
+		if len(d.Magic) > 0 && bytes.HasPrefix(content, d.Magic) {
+			return &s.cfg.decompressors[i]
+		}
+		for _, extension := range d.Extensions {
+			if extension != "" && strings.HasSuffix(strings.ToLower(path), strings.ToLower(extension)) {
+				return &s.cfg.decompressors[i]
+			}
+		}
+	}
+	return nil
+}
+
+// decompressorForEncoding returns the codec of the given content coding, nil if there is none.
+func (s *S) decompressorForEncoding(encoding string) *Decompressor {
+	for i, d := range s.cfg.decompressors {
+		if d.Encoding != "" && strings.EqualFold(d.Encoding, encoding) {
+			return &s.cfg.decompressors[i]
+		}
+	}
+	return nil
+}
+
+// decompress decompresses the content with the given codec, up to the maximum size, see readDecompressed.
+func (s *S) decompress(d *Decompressor, content []byte) ([]byte, error) {
+	reader, err := d.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", d.name(), err)
+	}
+	if closer, ok := reader.(io.Closer); ok {
+		defer func() {
+			_ = closer.Close()
+		}()
+	}
+
+	uncompressed, err := s.readDecompressed(reader)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", d.name(), err)
+	}
+	return uncompressed, nil
+}
+
+// name returns the name of the codec used in error messages.
+func (d *Decompressor) name() string {
+	if d.Encoding != "" {
+		return d.Encoding
+	}
+	return "decompressor"
+}
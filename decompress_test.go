@@ -0,0 +1,214 @@
+package sitemap
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// fakeZstd is a test codec identified by the Zstandard magic bytes, its "compressed" content is the magic followed by the plain content,
+// as in the test/sitemap-02.xml.fake-zst fixture. It is not Zstandard: only the registry is tested, not a real codec.
+var fakeZstd = Decompressor{
+	Encoding: "zstd",
+	Magic:    []byte("\x28\xb5\x2f\xfd"),
+	NewReader: func(r io.Reader) (io.Reader, error) {
+		magic := make([]byte, 4)
+		if _, err := io.ReadFull(r, magic); err != nil {
+			return nil, err
+		}
+		return r, nil
+	},
+}
+
+// fakeBrotli is a test codec without magic bytes, its "compressed" content is base64 encoded, as in the test/sitemap-03.xml.fake-br fixture.
+// It is not Brotli: only the registry is tested, not a real codec.
+var fakeBrotli = Decompressor{
+	Encoding:   "br",
+	Extensions: []string{".fake-br"},
+	NewReader: func(r io.Reader) (io.Reader, error) {
+		return base64.NewDecoder(base64.StdEncoding, r), nil
+	},
+}
+
+func TestS_SetDecompressors(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		s := New().SetDecompressors(fakeZstd, fakeBrotli)
+		if len(s.cfg.decompressors) != 2 || s.GetErrorsCount() != 0 {
+			t.Errorf("expected 2 decompressors and no errors, got %d and %v", len(s.cfg.decompressors), s.GetErrors())
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		s := New().SetDecompressors(
+			Decompressor{Encoding: "zstd"},
+			Decompressor{NewReader: fakeZstd.NewReader},
+			fakeBrotli,
+		)
+		if len(s.cfg.decompressors) != 1 || s.GetErrorsCount() != 2 {
+			t.Errorf("expected 1 decompressor and 2 errors, got %d and %v", len(s.cfg.decompressors), s.GetErrors())
+		}
+	})
+}
+
+func TestS_Parse_Decompressors(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		s         *S
+		url       string
+		urlsCount int64
+		errsCount int64
+	}{
+		{
+			name:      "magic bytes",
+			s:         New().SetDecompressors(fakeZstd, fakeBrotli),
+			url:       fmt.Sprintf("%s/sitemap-02.xml.fake-zst", server.URL),
+			urlsCount: 2,
+		},
+		{
+			name:      "extension",
+			s:         New().SetDecompressors(fakeZstd, fakeBrotli),
+			url:       fmt.Sprintf("%s/sitemap-03.xml.fake-br", server.URL),
+			urlsCount: 3,
+		},
+		{
+			name:      "not registered",
+			s:         New(),
+			url:       fmt.Sprintf("%s/sitemap-02.xml.fake-zst", server.URL),
+			urlsCount: 0,
+			errsCount: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, _ := test.s.Parse(test.url, nil)
+			if s.GetURLCount() != test.urlsCount {
+				t.Errorf("expected %d URLs, got %d", test.urlsCount, s.GetURLCount())
+			}
+			if s.GetErrorsCount() != test.errsCount {
+				t.Errorf("expected %d errors, got %v", test.errsCount, s.GetErrors())
+			}
+		})
+	}
+
+	t.Run("Content-Encoding", func(t *testing.T) {
+		content, err := os.ReadFile("./test/sitemap-01.xml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		brServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Encoding", "BR")
+			_, _ = w.Write([]byte(base64.StdEncoding.EncodeToString(content)))
+		}))
+		defer brServer.Close()
+
+		s, err := New().SetDecompressors(fakeBrotli).Parse(fmt.Sprintf("%s/sitemap.xml", brServer.URL), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.GetURLCount() != 1 {
+			t.Errorf("expected 1 URL, got %d: %v", s.GetURLCount(), s.GetErrors())
+		}
+	})
+
+	t.Run("decompression error", func(t *testing.T) {
+		failing := Decompressor{
+			Encoding: "zstd",
+			Magic:    fakeZstd.Magic,
+			NewReader: func(r io.Reader) (io.Reader, error) {
+				return nil, errors.New("unsupported frame")
+			},
+		}
+		url := fmt.Sprintf("%s/sitemap-02.xml.fake-zst", server.URL)
+		s, _ := New().SetDecompressors(failing).Parse(url, nil)
+		if s.GetErrorsCount() == 0 || !strings.HasPrefix(s.GetErrors()[0].Error(), url+": zstd: unsupported frame") {
+			t.Errorf("expected decompression error, got %v", s.GetErrors())
+		}
+	})
+}
+
+// flateCodec is a test codec of raw deflate streams, identified by its magic prefix, its extension or its content coding,
+// so that small compressed bodies expand past the maximum decompressed size by any of the three.
+var flateCodec = Decompressor{
+	Encoding:   "x-flate",
+	Magic:      []byte("FLT!"),
+	Extensions: []string{".flate"},
+	NewReader: func(r io.Reader) (io.Reader, error) {
+		var magic [4]byte
+		if _, err := io.ReadFull(r, magic[:]); err != nil {
+			return nil, err
+		}
+		return flate.NewReader(r), nil
+	},
+}
+
+// bombContent returns a valid urlset repeating the same URL the given number of times.
+func bombContent(count int) []byte {
+	content := []byte(`<?xml version="1.0" encoding="UTF-8"?><urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`)
+	content = append(content, bytes.Repeat([]byte("<url><loc>https://www.example.com/</loc></url>"), count)...)
+	return append(content, "</urlset>"...)
+}
+
+func TestS_SetMaxDecompressedSize(t *testing.T) {
+	content := bombContent(20000)
+	var gzipped, deflated bytes.Buffer
+	gzipWriter := gzip.NewWriter(&gzipped)
+	_, _ = gzipWriter.Write(content)
+	_ = gzipWriter.Close()
+	flateWriter, _ := flate.NewWriter(&deflated, flate.BestCompression)
+	_, _ = flateWriter.Write(content)
+	_ = flateWriter.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sitemap.xml.gz":
+			_, _ = w.Write(gzipped.Bytes())
+		case "/sitemap-magic.xml":
+			_, _ = w.Write(append([]byte("FLT!"), deflated.Bytes()...))
+		case "/sitemap.xml.flate":
+			_, _ = w.Write(append([]byte("0000"), deflated.Bytes()...))
+		case "/sitemap-encoded.xml":
+			w.Header().Set("Content-Encoding", "x-flate")
+			_, _ = w.Write(append([]byte("FLT!"), deflated.Bytes()...))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	for _, path := range []string{"/sitemap.xml.gz", "/sitemap-magic.xml", "/sitemap.xml.flate", "/sitemap-encoded.xml"} {
+		t.Run(path, func(t *testing.T) {
+			if deflated.Len() > 64<<10 || gzipped.Len() > 64<<10 {
+				t.Fatalf("expected a small compressed body, got %d and %d bytes", deflated.Len(), gzipped.Len())
+			}
+
+			s, err := New().SetDecompressors(flateCodec).Parse(server.URL+path, nil)
+			if err != nil || s.GetURLCount() != 20000 {
+				t.Fatalf("expected 20000 URLs without a maximum size, got %d: %v", s.GetURLCount(), s.GetErrors())
+			}
+
+			s, err = New().SetDecompressors(flateCodec).SetMaxDecompressedSize(64<<10).Parse(server.URL+path, nil)
+			if !errors.Is(err, ErrDecompressedTooLarge) {
+				t.Errorf("expected %v, got %v", ErrDecompressedTooLarge, err)
+			}
+			if s.GetURLCount() != 0 {
+				t.Errorf("expected no URLs, got %d", s.GetURLCount())
+			}
+			if errs := s.GetErrors(); len(errs) != 1 || !errors.Is(errs[0], ErrDecompressedTooLarge) {
+				t.Errorf("expected a single error matching %v, got %v", ErrDecompressedTooLarge, errs)
+			}
+		})
+	}
+}
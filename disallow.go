@@ -0,0 +1,275 @@
+package sitemap
+
+import (
+	"math"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// robotsGroup is a group of a robots.txt file: the lowercased product tokens of its User-agent lines and its Allow and Disallow rules, in order,
+// and its Crawl-delay, 0 if it has none.
+type robotsGroup struct {
+	agents     []string
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+// robotsRule is an Allow or Disallow rule of a robots.txt group, the pattern is percent-encoded (see encodeLoc).
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+// SetRobotsTxt sets the content of the robots.txt file the parsed URLs are checked against by GetDisallowedURLs,
+// e.g. when the parse starts from a sitemap rather than from the robots.txt file of the site.
+// The rules apply to the URLs on the host of the main URL of the parse. The Sitemap directives of the content are not followed.
+// An empty content (the default) means the robots.txt file the parse started from, if any.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetRobotsTxt(content string) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.robotsGroups = nil
+	if content != "" {
+		s.cfg.robotsGroups = parseRobotsGroups(content)
+	}
+
+	return s
+}
+
+// SetRespectCrawlDelay sets whether the Crawl-delay of the robots.txt rules is respected between the requests to the host of the main URL:
+// the rules set with SetRobotsTxt, or else the ones of the robots.txt file the parse started from, applying to the requests made after it is parsed.
+// The Crawl-delay is taken from the groups the rules are (see GetDisallowedURLs), the longest one if there are several,
+// and the stricter of it and the delay set with SetRequestDelay applies. By default, it is off.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetRespectCrawlDelay(respect bool) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.respectCrawlDelay = respect
+
+	return s
+}
+
+// applyCrawlDelay sets the Crawl-delay of the robots.txt rules on the host of the main URL, if it is respected, see SetRespectCrawlDelay.
+func (s *S) applyCrawlDelay() {
+	if !s.cfg.respectCrawlDelay {
+		return
+	}
+	crawlDelay := selectCrawlDelay(s.robotsTxtGroups(), s.cfg.userAgent)
+	host, ok := hostOf(s.mainURL)
+	if crawlDelay <= 0 || !ok {
+		return
+	}
+	s.hostDelays = s.hostDelays.withCrawlDelay(host, crawlDelay)
+}
+
+// robotsTxtGroups returns the groups of the robots.txt content set with SetRobotsTxt, or else of the robots.txt file the parse started from.
+func (s *S) robotsTxtGroups() []robotsGroup {
+	if s.cfg.robotsGroups != nil {
+		return s.cfg.robotsGroups
+	}
+	return s.robotsGroups
+}
+
+// GetDisallowedURLs returns the parsed URLs on the host of the main URL whose path is disallowed by the robots.txt rules,
+// in the order they were parsed: the rules set with SetRobotsTxt, or else the ones of the robots.txt file the parse started from.
+// The rules are the ones of the groups naming the product token of the configured user agent (its part before the first "/"
+// or whitespace, matched case-insensitively), or, if there is none, of the groups of "*". A rule matches the path and query
+// of a location by prefix, where "*" matches any sequence of characters and a trailing "$" anchors the pattern to the end.
+// The longest matching pattern decides, an Allow rule wins over a Disallow rule of the same length, and a location
+// no rule matches is allowed. In loc-only mode, the returned URLs only have their location set, see SetLocOnly.
+// If the S object is nil or no URL is disallowed, an empty slice is returned.
+func (s *S) GetDisallowedURLs() []URL {
+	if s == nil {
+		return []URL{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rules := selectRobotsRules(s.robotsTxtGroups(), s.cfg.userAgent)
+	disallowed := []URL{}
+	if len(rules) == 0 {
+		return disallowed
+	}
+	host, ok := hostOf(s.mainURL)
+	if !ok {
+		return disallowed
+	}
+	if s.cfg.locOnly {
+		for _, loc := range s.locs {
+			if robotsDisallowed(rules, host, loc) {
+				disallowed = append(disallowed, URL{Loc: loc})
+			}
+		}
+		return disallowed
+	}
+	for _, url := range s.urls {
+		if robotsDisallowed(rules, host, url.Loc) {
+			disallowed = append(disallowed, url)
+		}
+	}
+	return disallowed
+}
+
+// parseRobotsGroups returns the groups of the given robots.txt content. The consecutive User-agent lines start a group,
+// and the rules before the first User-agent line, the empty Allow and Disallow rules, the Crawl-delay values that are not
+// a non-negative number of seconds and the other lines are ignored.
+// The parsing is as tolerant as the one of the Sitemap directives, see ExtractSitemapURLs.
+func parseRobotsGroups(robotsTxt string) []robotsGroup {
+	var groups []robotsGroup
+	inRules := false
+	robotsTxt = strings.TrimPrefix(robotsTxt, "\ufeff")
+	lines := strings.FieldsFunc(robotsTxt, func(r rune) bool { return r == '\n' || r == '\r' })
+	for _, line := range lines {
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		name, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		name, value = strings.ToLower(strings.TrimSpace(name)), strings.TrimSpace(value)
+		switch name {
+		case "user-agent":
+			if len(groups) == 0 || inRules {
+				groups = append(groups, robotsGroup{})
+				inRules = false
+			}
+			group := &groups[len(groups)-1]
+			group.agents = append(group.agents, productToken(value))
+		case "allow", "disallow":
+			if len(groups) == 0 {
+				continue
+			}
+			inRules = true
+			if value == "" {
+				continue
+			}
+			pattern, _ := encodeLoc(value)
+			group := &groups[len(groups)-1]
+			group.rules = append(group.rules, robotsRule{allow: name == "allow", pattern: pattern})
+		case "crawl-delay":
+			if len(groups) == 0 {
+				continue
+			}
+			inRules = true
+			seconds, err := strconv.ParseFloat(value, 64)
+			if err != nil || seconds < 0 || math.IsInf(seconds, 0) {
+				continue
+			}
+			groups[len(groups)-1].crawlDelay = time.Duration(seconds * float64(time.Second))
+		}
+	}
+	return groups
+}
+
+// selectRobotsGroups returns the groups naming the product token of the given user agent,
+// or, if there is none, the groups of "*", see GetDisallowedURLs.
+func selectRobotsGroups(groups []robotsGroup, userAgent string) []robotsGroup {
+	token := productToken(userAgent)
+	var selected, fallback []robotsGroup
+	for _, group := range groups {
+		for _, agent := range group.agents {
+			if agent == token && token != "" {
+				selected = append(selected, group)
+				break
+			}
+			if agent == "*" {
+				fallback = append(fallback, group)
+				break
+			}
+		}
+	}
+	if selected != nil {
+		return selected
+	}
+	return fallback
+}
+
+// selectRobotsRules returns the rules of the groups selected for the given user agent, see selectRobotsGroups.
+func selectRobotsRules(groups []robotsGroup, userAgent string) []robotsRule {
+	var rules []robotsRule
+	for _, group := range selectRobotsGroups(groups, userAgent) {
+		rules = append(rules, group.rules...)
+	}
+	return rules
+}
+
+// selectCrawlDelay returns the longest Crawl-delay of the groups selected for the given user agent, see selectRobotsGroups.
+func selectCrawlDelay(groups []robotsGroup, userAgent string) time.Duration {
+	var crawlDelay time.Duration
+	for _, group := range selectRobotsGroups(groups, userAgent) {
+		crawlDelay = max(crawlDelay, group.crawlDelay)
+	}
+	return crawlDelay
+}
+
+// productToken returns the lowercased part of the given user agent before the first "/" or whitespace.
+func productToken(userAgent string) string {
+	userAgent = strings.TrimSpace(userAgent)
+	if i := strings.IndexFunc(userAgent, func(r rune) bool { return r == '/' || r == ' ' || r == '\t' }); i >= 0 {
+		userAgent = userAgent[:i]
+	}
+	return strings.ToLower(userAgent)
+}
+
+// robotsDisallowed reports whether the given location is on the given host and its path is disallowed by the rules.
+func robotsDisallowed(rules []robotsRule, host string, loc string) bool {
+	u, err := neturl.Parse(loc)
+	if err != nil || !strings.EqualFold(u.Host, host) {
+		return false
+	}
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" || u.ForceQuery {
+		path += "?" + u.RawQuery
+	}
+	return !robotsAllowed(rules, path)
+}
+
+// robotsAllowed reports whether the given path (including the query) is allowed by the rules:
+// the longest matching pattern decides, Allow winning the ties, and a path no rule matches is allowed.
+func robotsAllowed(rules []robotsRule, path string) bool {
+	allowed, longest := true, -1
+	for _, rule := range rules {
+		if !robotsMatch(rule.pattern, path) {
+			continue
+		}
+		if n := len(rule.pattern); n > longest || n == longest && rule.allow {
+			allowed, longest = rule.allow, n
+		}
+	}
+	return allowed
+}
+
+// robotsMatch reports whether the given pattern of a rule matches the path: by prefix,
+// with "*" matching any sequence of characters and a trailing "$" matching the end of the path.
+func robotsMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	pos := len(parts[0])
+	if len(parts) == 1 {
+		return !anchored || pos == len(path)
+	}
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(path[pos:], part)
+		if i < 0 {
+			return false
+		}
+		pos += i + len(part)
+	}
+	last := parts[len(parts)-1]
+	if anchored {
+		return len(path)-pos >= len(last) && strings.HasSuffix(path, last)
+	}
+	return strings.Contains(path[pos:], last)
+}
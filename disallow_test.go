@@ -0,0 +1,359 @@
+package sitemap
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRobotsAllowed(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []robotsRule
+		path  string
+		want  bool
+	}{
+		{
+			name: "no rules",
+			path: "/page",
+			want: true,
+		},
+		{
+			name:  "no matching rule",
+			rules: []robotsRule{{pattern: "/private"}},
+			path:  "/page",
+			want:  true,
+		},
+		{
+			name:  "disallow by prefix",
+			rules: []robotsRule{{pattern: "/private"}},
+			path:  "/private/page",
+			want:  false,
+		},
+		{
+			name:  "longer allow wins",
+			rules: []robotsRule{{pattern: "/private"}, {allow: true, pattern: "/private/public"}},
+			path:  "/private/public/page",
+			want:  true,
+		},
+		{
+			name:  "longer disallow wins",
+			rules: []robotsRule{{allow: true, pattern: "/shop"}, {pattern: "/shop/cart"}},
+			path:  "/shop/cart?id=1",
+			want:  false,
+		},
+		{
+			name:  "allow wins a tie",
+			rules: []robotsRule{{pattern: "/page"}, {allow: true, pattern: "/page"}},
+			path:  "/page",
+			want:  true,
+		},
+		{
+			name:  "allow wins a tie in any order",
+			rules: []robotsRule{{allow: true, pattern: "/pag*"}, {pattern: "/page"}},
+			path:  "/page",
+			want:  true,
+		},
+		{
+			name:  "wildcard disallow",
+			rules: []robotsRule{{pattern: "/*.pdf$"}, {allow: true, pattern: "/"}},
+			path:  "/docs/guide.pdf",
+			want:  false,
+		},
+		{
+			name:  "anchored pattern does not match longer path",
+			rules: []robotsRule{{pattern: "/*.pdf$"}},
+			path:  "/docs/guide.pdf?download=1",
+			want:  true,
+		},
+		{
+			name:  "query disallowed",
+			rules: []robotsRule{{pattern: "/*?sort="}},
+			path:  "/shoes?sort=price",
+			want:  false,
+		},
+		{
+			name:  "root disallows everything",
+			rules: []robotsRule{{pattern: "/"}},
+			path:  "/",
+			want:  false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := robotsAllowed(test.rules, test.path); got != test.want {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestRobotsMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{pattern: "/fish", path: "/fish", want: true},
+		{pattern: "/fish", path: "/fish.html", want: true},
+		{pattern: "/fish", path: "/Fish.asp", want: false},
+		{pattern: "/fish*", path: "/fishheads/yummy.html", want: true},
+		{pattern: "/fish/", path: "/fish", want: false},
+		{pattern: "/*.php", path: "/folder/filename.php?parameters", want: true},
+		{pattern: "/*.php", path: "/windows.PHP", want: false},
+		{pattern: "/*.php$", path: "/filename.php", want: true},
+		{pattern: "/*.php$", path: "/filename.php/", want: false},
+		{pattern: "/fish*.php", path: "/fishheads/catfish.php?parameters", want: true},
+		{pattern: "/fish*.php", path: "/Fish.PHP", want: false},
+		{pattern: "/a*b*c$", path: "/abxbc", want: true},
+		{pattern: "/a*b*c$", path: "/abxbcd", want: false},
+		{pattern: "/page$", path: "/page", want: true},
+		{pattern: "/*$", path: "/anything", want: true},
+		{pattern: "/a$b", path: "/a$b/c", want: true},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s %s", test.pattern, test.path), func(t *testing.T) {
+			if got := robotsMatch(test.pattern, test.path); got != test.want {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestSelectRobotsRules(t *testing.T) {
+	robotsTxt := "\ufeffDisallow: /ignored\r\n" +
+		"User-agent: *\r\n" +
+		"Disallow: /private # comment\r\n" +
+		"\r\n" +
+		"user-agent: Go-Sitemap-Parser/2.0\n" +
+		"User-agent: other-bot\n" +
+		"Disallow: /parser-only\n" +
+		"Allow:\n" +
+		"Sitemap: https://www.example.com/sitemap.xml\n" +
+		"ALLOW: /parser-only/public\n" +
+		"User-agent: go-sitemap-parser\n" +
+		"Disallow: /more\n"
+
+	tests := []struct {
+		name      string
+		userAgent string
+		want      []robotsRule
+	}{
+		{
+			name:      "groups of the product token combined",
+			userAgent: "go-sitemap-parser (+https://github.com/aafeher/go-sitemap-parser/blob/main/README.md)",
+			want: []robotsRule{
+				{pattern: "/parser-only"},
+				{allow: true, pattern: "/parser-only/public"},
+				{pattern: "/more"},
+			},
+		},
+		{
+			name:      "second agent of a group",
+			userAgent: "Other-Bot/1.0",
+			want: []robotsRule{
+				{pattern: "/parser-only"},
+				{allow: true, pattern: "/parser-only/public"},
+			},
+		},
+		{
+			name:      "fallback to *",
+			userAgent: "unknown-bot",
+			want:      []robotsRule{{pattern: "/private"}},
+		},
+	}
+	groups := parseRobotsGroups(robotsTxt)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := selectRobotsRules(groups, test.userAgent); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestSelectCrawlDelay(t *testing.T) {
+	tests := []struct {
+		name      string
+		robotsTxt string
+		userAgent string
+		want      time.Duration
+	}{
+		{
+			name:      "seconds",
+			robotsTxt: "User-agent: *\nCrawl-delay: 2\n",
+			userAgent: "bot",
+			want:      2 * time.Second,
+		},
+		{
+			name:      "fraction of a second",
+			robotsTxt: "User-agent: *\ncrawl-delay: 0.5 # comment\n",
+			userAgent: "bot",
+			want:      500 * time.Millisecond,
+		},
+		{
+			name:      "group of the product token",
+			robotsTxt: "User-agent: *\nCrawl-delay: 10\n\nUser-agent: bot\nCrawl-delay: 1\nDisallow: /private\n",
+			userAgent: "bot/1.0",
+			want:      time.Second,
+		},
+		{
+			name:      "longest of the groups",
+			robotsTxt: "User-agent: bot\nCrawl-delay: 1\nUser-agent: bot\nCrawl-delay: 3\n",
+			userAgent: "bot",
+			want:      3 * time.Second,
+		},
+		{
+			name:      "crawl delay ends the user agents of a group",
+			robotsTxt: "User-agent: *\nCrawl-delay: 1\nUser-agent: bot\nDisallow: /\n",
+			userAgent: "bot",
+			want:      0,
+		},
+		{
+			name:      "invalid values",
+			robotsTxt: "Crawl-delay: 5\nUser-agent: *\nCrawl-delay: soon\nCrawl-delay: -1\nCrawl-delay: +Inf\n",
+			userAgent: "bot",
+			want:      0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := selectCrawlDelay(parseRobotsGroups(test.robotsTxt), test.userAgent); got != test.want {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestS_Parse_RespectCrawlDelay(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		s           *S
+		wantAtLeast time.Duration
+		wantAtMost  time.Duration
+	}{
+		{
+			name:        "robots.txt content set",
+			s:           New().SetRobotsTxt("User-agent: *\nCrawl-delay: 0.1\n").SetRespectCrawlDelay(true),
+			wantAtLeast: 300 * time.Millisecond,
+		},
+		{
+			name:        "stricter request delay",
+			s:           New().SetRobotsTxt("User-agent: *\nCrawl-delay: 0.01\n").SetRespectCrawlDelay(true).SetRequestDelay(100*time.Millisecond, 0),
+			wantAtLeast: 400 * time.Millisecond,
+		},
+		{
+			name:       "not respected",
+			s:          New().SetRobotsTxt("User-agent: *\nCrawl-delay: 1\n"),
+			wantAtMost: 400 * time.Millisecond,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			start := time.Now()
+			if _, err := test.s.Parse(fmt.Sprintf("%s/robots-with-sitemapindex/robots.txt", server.URL), nil); err != nil {
+				t.Fatal(err)
+			}
+			elapsed := time.Since(start)
+			if elapsed < test.wantAtLeast {
+				t.Errorf("expected at least %v, got %v", test.wantAtLeast, elapsed)
+			}
+			if test.wantAtMost > 0 && elapsed > test.wantAtMost {
+				t.Errorf("expected at most %v, got %v", test.wantAtMost, elapsed)
+			}
+		})
+	}
+}
+
+func TestS_GetDisallowedURLs(t *testing.T) {
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+    <url><loc>https://www.example.com/</loc></url>
+    <url><loc>https://www.example.com/private/page</loc></url>
+    <url><loc>https://www.example.com/private/public/page</loc></url>
+    <url><loc>https://www.example.com/search?q=shoes</loc></url>
+    <url><loc>https://WWW.EXAMPLE.COM/private/other</loc></url>
+    <url><loc>https://other.example.com/private/page</loc></url>
+</urlset>`
+	robotsTxt := "User-agent: *\nDisallow: /private\nAllow: /private/public\nDisallow: /search?\n"
+
+	tests := []struct {
+		name string
+		s    *S
+		want []string
+	}{
+		{
+			name: "rules set",
+			s:    New().SetRobotsTxt(robotsTxt),
+			want: []string{
+				"https://www.example.com/private/page",
+				"https://www.example.com/search?q=shoes",
+				"https://WWW.EXAMPLE.COM/private/other",
+			},
+		},
+		{
+			name: "loc-only mode",
+			s:    New().SetRobotsTxt(robotsTxt).SetLocOnly(true),
+			want: []string{
+				"https://www.example.com/private/page",
+				"https://www.example.com/search?q=shoes",
+				"https://WWW.EXAMPLE.COM/private/other",
+			},
+		},
+		{
+			name: "rules of another user agent",
+			s:    New().SetRobotsTxt("User-agent: other-bot\nDisallow: /\n"),
+			want: []string{},
+		},
+		{
+			name: "no rules",
+			s:    New(),
+			want: []string{},
+		},
+		{
+			name: "rules reset",
+			s:    New().SetRobotsTxt(robotsTxt).SetRobotsTxt(""),
+			want: []string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := test.s.Parse("https://www.example.com/sitemap.xml", &content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := locsOf(s.GetDisallowedURLs()); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestS_GetDisallowedURLs_RobotsTxt(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	s, err := New().Parse(fmt.Sprintf("%s/robots-with-sitemapindex/robots.txt", server.URL), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	urls := s.GetURLs()
+	if len(urls) == 0 {
+		t.Fatal("expected URLs to be parsed")
+	}
+	if got := s.GetDisallowedURLs(); !reflect.DeepEqual(sortedLocs(got), sortedLocs(urls)) {
+		t.Errorf("expected every URL to be disallowed, got %v", locsOf(got))
+	}
+
+	s, err = New().SetRobotsTxt("User-agent: *\nDisallow: /sitemap-99\n").Parse(fmt.Sprintf("%s/robots-with-sitemapindex/robots.txt", server.URL), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.GetDisallowedURLs(); len(got) != 0 {
+		t.Errorf("expected the rules set to override the robots.txt file, got %v", locsOf(got))
+	}
+}
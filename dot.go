@@ -0,0 +1,73 @@
+package sitemap
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteDOT writes the sitemap tree built during parsing (see GetSitemapTree) to w as a Graphviz digraph,
+// e.g. to render it with "dot -Tsvg". Each document is a node labelled with its location, its kind,
+// the number of URLs collected from it and its error, if any; each reference from a robots.txt file
+// or a sitemap index to a document is an edge. The documents that failed are drawn in red,
+// the ones that have not been fetched (e.g. skipped because of a limit) are drawn dashed.
+// The locations are used as the IDs of the nodes, so the output of two parses of a site can be diffed.
+// If the S object is nil or Parse has not been called, an empty digraph is written.
+// It returns an error if writing to w fails.
+func (s *S) WriteDOT(w io.Writer) error {
+	var dot strings.Builder
+	dot.WriteString("digraph sitemap {\n\tnode [shape=box];\n")
+
+	if s != nil {
+		s.mu.Lock()
+		if s.tree != nil {
+			var edges strings.Builder
+			walkNodes(s.tree, func(n *SitemapNode) {
+				fmt.Fprintf(&dot, "\t%s [%s];\n", dotQuote(n.Loc), dotAttributes(n))
+				for _, child := range n.Children {
+					fmt.Fprintf(&edges, "\t%s -> %s;\n", dotQuote(n.Loc), dotQuote(child.Loc))
+				}
+			})
+			dot.WriteString(edges.String())
+		}
+		s.mu.Unlock()
+	}
+
+	dot.WriteString("}\n")
+	_, err := io.WriteString(w, dot.String())
+	return err
+}
+
+// dotAttributes returns the attributes of the DOT node of the given sitemap node: its label and its style.
+func dotAttributes(n *SitemapNode) string {
+	label := []string{n.Loc}
+	switch {
+	case n.Kind == SitemapKindURLSet:
+		details := fmt.Sprintf("%s, %s", n.Kind, plural(n.URLCount, "URL", "URLs"))
+		if n.Truncated {
+			details += ", truncated"
+		}
+		label = append(label, details)
+	case n.Kind != SitemapKindUnknown:
+		label = append(label, string(n.Kind))
+	case !n.fetched && n.Err == nil:
+		label = append(label, "not fetched")
+	}
+	if n.Err != nil {
+		label = append(label, "error: "+n.Err.Error())
+	}
+
+	attributes := "label=" + dotQuote(strings.Join(label, "\n"))
+	switch {
+	case n.Err != nil:
+		attributes += ", color=red, fontcolor=red"
+	case !n.fetched:
+		attributes += ", style=dashed"
+	}
+	return attributes
+}
+
+// dotQuote returns the given string as a quoted DOT string, with the backslashes, the double quotes and the line breaks escaped.
+func dotQuote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r", "", "\n", `\n`).Replace(value) + `"`
+}
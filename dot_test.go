@@ -0,0 +1,91 @@
+package sitemap
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestS_WriteDOT(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	want, err := os.ReadFile("./test/tree-robots-with-sitemapindex-2.dot")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, multiThread := range []bool{true, false} {
+		t.Run(fmt.Sprintf("multiThread=%v", multiThread), func(t *testing.T) {
+			s, err := New().SetMultiThread(multiThread).Parse(server.URL+"/robots-with-sitemapindex-2/robots.txt", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := s.WriteDOT(&buf); err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.ReplaceAll(buf.String(), server.URL, "http://HOST"); got != string(want) {
+				t.Errorf("expected\n%s\ngot\n%s", want, got)
+			}
+		})
+	}
+}
+
+func TestS_WriteDOT_Nodes(t *testing.T) {
+	root := &SitemapNode{Loc: "https://www.example.com/sitemapindex.xml", Kind: SitemapKindIndex, fetched: true}
+	children := []*SitemapNode{
+		{Loc: "https://www.example.com/sitemap-01.xml", Kind: SitemapKindURLSet, URLCount: 1, fetched: true},
+		{Loc: "https://www.example.com/sitemap-02.xml", Kind: SitemapKindURLSet, URLCount: 12345, Truncated: true, fetched: true},
+		{Loc: "https://www.example.com/sitemap-03.xml", Kind: SitemapKindUnknown, Err: errors.New(`received "HTTP" status 404`), fetched: true},
+		{Loc: "https://www.example.com/sitemap-04.xml", Kind: SitemapKindUnknown},
+		{Loc: `https://www.example.com/a"b\c` + "\nd", Kind: SitemapKindUnknown, fetched: true},
+	}
+	for _, child := range children {
+		child.Parent = root
+		root.Children = append(root.Children, child)
+	}
+	// a sitemap referenced twice has a single node
+	root.Children = append(root.Children, children[0])
+
+	want := `digraph sitemap {
+	node [shape=box];
+	"https://www.example.com/sitemapindex.xml" [label="https://www.example.com/sitemapindex.xml\nsitemapindex"];
+	"https://www.example.com/sitemap-01.xml" [label="https://www.example.com/sitemap-01.xml\nurlset, 1 URL"];
+	"https://www.example.com/sitemap-02.xml" [label="https://www.example.com/sitemap-02.xml\nurlset, 12,345 URLs, truncated"];
+	"https://www.example.com/sitemap-03.xml" [label="https://www.example.com/sitemap-03.xml\nerror: received \"HTTP\" status 404", color=red, fontcolor=red];
+	"https://www.example.com/sitemap-04.xml" [label="https://www.example.com/sitemap-04.xml\nnot fetched", style=dashed];
+	"https://www.example.com/a\"b\\c\nd" [label="https://www.example.com/a\"b\\c\nd"];
+	"https://www.example.com/sitemapindex.xml" -> "https://www.example.com/sitemap-01.xml";
+	"https://www.example.com/sitemapindex.xml" -> "https://www.example.com/sitemap-02.xml";
+	"https://www.example.com/sitemapindex.xml" -> "https://www.example.com/sitemap-03.xml";
+	"https://www.example.com/sitemapindex.xml" -> "https://www.example.com/sitemap-04.xml";
+	"https://www.example.com/sitemapindex.xml" -> "https://www.example.com/a\"b\\c\nd";
+	"https://www.example.com/sitemapindex.xml" -> "https://www.example.com/sitemap-01.xml";
+}
+`
+
+	tests := []struct {
+		name string
+		s    *S
+		want string
+	}{
+		{name: "tree", s: &S{tree: root}, want: want},
+		{name: "not parsed", s: New(), want: "digraph sitemap {\n\tnode [shape=box];\n}\n"},
+		{name: "nil receiver", s: nil, want: "digraph sitemap {\n\tnode [shape=box];\n}\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := test.s.WriteDOT(&buf); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != test.want {
+				t.Errorf("expected\n%s\ngot\n%s", test.want, got)
+			}
+		})
+	}
+}
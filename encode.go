@@ -0,0 +1,112 @@
+package sitemap
+
+import (
+	"strings"
+)
+
+// SetEncodeLocs sets whether the locations of the URLs decoded from a sitemap are repaired like the locations of the sitemaps:
+// the surrounding whitespace is trimmed, and the characters not allowed in a URL (e.g. spaces, control characters or non-ASCII characters)
+// after the host are percent-encoded. A *LocEncodedError warning is recorded for each URL encoded, see GetWarnings.
+// The repair is applied before the other rewrites, see SetPunycodeHosts, SetHostRewrite and SetURLRewriter.
+// The locations of the sitemaps referenced by a sitemap index or a robots.txt file are always repaired, so that they can be fetched.
+// By default, the locations of the URLs are stored as they are.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetEncodeLocs(encodeLocs bool) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.encodeLocs = encodeLocs
+
+	return s
+}
+
+// encodeSitemapLocs repairs the locations of the given sitemaps of the index at location in place, see encodeLoc,
+// and returns a *LocEncodedError warning for each location encoded.
+func encodeSitemapLocs(location string, sitemaps []IndexSitemap) []error {
+	var warnings []error
+	for i := range sitemaps {
+		encoded, changed := encodeLoc(sitemaps[i].Loc)
+		if changed {
+			warnings = append(warnings, locationError(location, &LocEncodedError{Location: location, Loc: sitemaps[i].Loc, Encoded: encoded}))
+		}
+		sitemaps[i].Loc = encoded
+	}
+	return warnings
+}
+
+// encodeURLLocs repairs the locations of the given URLs of the sitemap at location in place if it is turned on, see SetEncodeLocs,
+// and returns a *LocEncodedError warning for each location encoded.
+func (s *S) encodeURLLocs(location string, urls []URL) []error {
+	if !s.cfg.encodeLocs {
+		return nil
+	}
+	var warnings []error
+	for i := range urls {
+		encoded, changed := encodeLoc(urls[i].Loc)
+		if changed {
+			warnings = append(warnings, locationError(location, &LocEncodedError{Location: location, Loc: urls[i].Loc, Encoded: encoded, Position: urls[i].Position}))
+		}
+		urls[i].Loc = encoded
+	}
+	return warnings
+}
+
+// encodeLoc returns the given location with the surrounding whitespace trimmed, and the bytes not allowed in a URL percent-encoded
+// after the scheme and the host: the control characters, the space, the non-ASCII bytes, the characters `"<>\^`{|}` and
+// the '%' characters not starting an escape sequence. The reserved characters and the valid escape sequences are kept,
+// so an encoded location is not changed again. The second return value reports whether the trimmed location has been encoded.
+// The location is scanned rather than parsed with net/url, which rejects the locations needing the repair the most.
+func encodeLoc(loc string) (string, bool) {
+	loc = strings.TrimSpace(loc)
+	start := 0
+	if i := strings.Index(loc, "://"); i > 0 {
+		start = i + len("://")
+		if end := strings.IndexAny(loc[start:], "/?#"); end >= 0 {
+			start += end
+		} else {
+			start = len(loc)
+		}
+	}
+
+	var b strings.Builder
+	for i := start; i < len(loc); i++ {
+		c := loc[i]
+		if !locByteAllowed(loc, i) {
+			if b.Len() == 0 {
+				b.Grow(len(loc) + 16)
+				b.WriteString(loc[:i])
+			}
+			b.WriteByte('%')
+			b.WriteByte(upperHex[c>>4])
+			b.WriteByte(upperHex[c&0x0f])
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte(c)
+		}
+	}
+	if b.Len() == 0 {
+		return loc, false
+	}
+	return b.String(), true
+}
+
+// upperHex is the alphabet of the percent-encoding.
+const upperHex = "0123456789ABCDEF"
+
+// locByteAllowed reports whether the byte of the location at index i is allowed in a URL as it is, see encodeLoc.
+func locByteAllowed(loc string, i int) bool {
+	c := loc[i]
+	switch {
+	case c <= ' ' || c >= 0x7f:
+		return false
+	case c == '%':
+		return i+2 < len(loc) && isHex(loc[i+1]) && isHex(loc[i+2])
+	}
+	return !strings.ContainsRune("\"<>\\^`{|}", rune(c))
+}
+
+// isHex reports whether the byte is a hexadecimal digit.
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
@@ -0,0 +1,152 @@
+package sitemap
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func Test_encodeLoc(t *testing.T) {
+	tests := []struct {
+		name        string
+		loc         string
+		want        string
+		wantChanged bool
+	}{
+		{name: "valid", loc: "https://example.com/a/b?c=d&e=f#g", want: "https://example.com/a/b?c=d&e=f#g"},
+		{name: "escaped", loc: "https://example.com/a%20b", want: "https://example.com/a%20b"},
+		{name: "surrounding whitespace", loc: "\n  https://example.com/a  \n", want: "https://example.com/a"},
+		{name: "space in the path", loc: "https://example.com/a b.xml", want: "https://example.com/a%20b.xml", wantChanged: true},
+		{name: "space in the query", loc: "https://example.com/a?b=c d", want: "https://example.com/a?b=c%20d", wantChanged: true},
+		{name: "control character", loc: "https://example.com/a\tb", want: "https://example.com/a%09b", wantChanged: true},
+		{name: "non-ASCII", loc: "https://example.com/página", want: "https://example.com/p%C3%A1gina", wantChanged: true},
+		{name: "non-ASCII host", loc: "https://bücher.example/página", want: "https://bücher.example/p%C3%A1gina", wantChanged: true},
+		{name: "stray percent", loc: "https://example.com/100%", want: "https://example.com/100%25", wantChanged: true},
+		{name: "invalid escape", loc: "https://example.com/a%zzb", want: "https://example.com/a%25zzb", wantChanged: true},
+		{name: "unsafe characters", loc: `https://example.com/a|b{c}"d"`, want: "https://example.com/a%7Cb%7Bc%7D%22d%22", wantChanged: true},
+		{name: "host only", loc: "https://example.com", want: "https://example.com"},
+		{name: "relative", loc: "/a b", want: "/a%20b", wantChanged: true},
+		{name: "empty", loc: "", want: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, changed := encodeLoc(test.loc)
+			if got != test.want || changed != test.wantChanged {
+				t.Errorf("expected %q and %v, got %q and %v", test.want, test.wantChanged, got, changed)
+			}
+			// encoding is idempotent
+			if again, changed := encodeLoc(got); again != got || changed {
+				t.Errorf("expected %q to be kept, got %q", got, again)
+			}
+		})
+	}
+}
+
+func TestS_SetEncodeLocs(t *testing.T) {
+	tests := []struct {
+		name       string
+		encodeLocs bool
+	}{
+		{name: "enabled", encodeLocs: true},
+		{name: "disabled", encodeLocs: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New().SetEncodeLocs(test.encodeLocs)
+			if s.cfg.encodeLocs != test.encodeLocs {
+				t.Errorf("expected %v, got %v", test.encodeLocs, s.cfg.encodeLocs)
+			}
+		})
+	}
+}
+
+func TestS_Parse_EncodedLocs(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	sitemapWarnings := []LocEncodedError{
+		{Location: "/sitemapindex-unencoded.xml", Loc: " /sitemap with space.xml ", Encoded: "/sitemap%20with%20space.xml"},
+		{Location: "/sitemapindex-unencoded.xml", Loc: "/sitemap-02.xml?page=1 of 2", Encoded: "/sitemap-02.xml?page=1%20of%202"},
+	}
+
+	tests := []struct {
+		name         string
+		s            *S
+		wantLocs     []string
+		wantWarnings []LocEncodedError
+	}{
+		{
+			name:         "sitemap locations",
+			s:            New(),
+			wantLocs:     []string{"/encoded%20page", "/page with space", "/page-02", "/page-03", "/página"},
+			wantWarnings: sitemapWarnings,
+		},
+		{
+			name:     "page locations",
+			s:        New().SetEncodeLocs(true),
+			wantLocs: []string{"/encoded%20page", "/p%C3%A1gina", "/page%20with%20space", "/page-02", "/page-03"},
+			wantWarnings: append(sitemapWarnings,
+				LocEncodedError{Location: "/sitemap%20with%20space.xml", Loc: "/page with space", Encoded: "/page%20with%20space"},
+				LocEncodedError{Location: "/sitemap%20with%20space.xml", Loc: "/página", Encoded: "/p%C3%A1gina"},
+			),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := test.s.SetMultiThread(false).Parse(server.URL+"/sitemapindex-unencoded.xml", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if errs := s.GetErrors(); len(errs) != 0 {
+				t.Fatalf("expected the sitemaps to be fetched, got %v", errs)
+			}
+
+			locs := trimPrefixes(s.GetLocs(), server.URL)
+			sort.Strings(locs)
+			if !reflect.DeepEqual(locs, test.wantLocs) {
+				t.Errorf("expected %v, got %v", test.wantLocs, locs)
+			}
+
+			var warnings []LocEncodedError
+			for _, warning := range s.GetWarnings() {
+				var encodedErr *LocEncodedError
+				if errors.As(warning, &encodedErr) {
+					warnings = append(warnings, LocEncodedError{
+						Location: strings.ReplaceAll(encodedErr.Location, server.URL, ""),
+						Loc:      strings.ReplaceAll(encodedErr.Loc, server.URL, ""),
+						Encoded:  strings.ReplaceAll(encodedErr.Encoded, server.URL, ""),
+					})
+				}
+			}
+			if !reflect.DeepEqual(warnings, test.wantWarnings) {
+				t.Errorf("expected %+v, got %+v", test.wantWarnings, warnings)
+			}
+		})
+	}
+}
+
+func TestS_Parse_EncodedRobotsTXTLocs(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	content := fmt.Sprintf("User-agent: *\nSitemap: %s/sitemap with space.xml\r\nSitemap: %s/sitemap-01.xml\r\n", server.URL, server.URL)
+	s, err := New().Parse(server.URL+"/robots.txt", &content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := s.GetErrors(); len(errs) != 0 {
+		t.Fatalf("expected the sitemaps to be fetched, got %v", errs)
+	}
+	if got := len(s.GetURLs()); got != 4 {
+		t.Errorf("expected 4 URLs, got %d", got)
+	}
+	// the CRLF line endings are not part of the locations, the warning holds the location as found otherwise
+	want := fmt.Sprintf("%s/robots.txt: %s", server.URL, &LocEncodedError{Loc: server.URL + "/sitemap with space.xml", Encoded: server.URL + "/sitemap%20with%20space.xml"})
+	if warnings := s.GetWarnings(); len(warnings) != 1 || warnings[0].Error() != want {
+		t.Errorf("expected [%s], got %v", want, warnings)
+	}
+}
@@ -0,0 +1,266 @@
+package sitemap
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+)
+
+// ErrNilReceiver is returned by Parse and ParseContext when called on a nil *S.
+var ErrNilReceiver = errors.New("method called on a nil *S")
+
+// ErrDoctypeNotAllowed is the error of decoding a document with a DOCTYPE declaration, or any other <!...> directive.
+// Such documents are rejected outright, as sitemaps have no use for a DTD, which would only serve entity expansion attacks.
+var ErrDoctypeNotAllowed = errors.New("DOCTYPE declarations are not allowed")
+
+// UnsupportedSchemeError is the error recorded for a location that is not fetched because its URL scheme is not allowed.
+// The Location field is the location, the Scheme field is its URL scheme.
+type UnsupportedSchemeError struct {
+	Location string
+	Scheme   string
+}
+
+// Error returns the message of the error.
+func (e *UnsupportedSchemeError) Error() string {
+	return fmt.Sprintf("unsupported scheme %q", e.Scheme)
+}
+
+// PanicError is the error recorded when processing a location panics.
+// The Location field is the location being processed, the Value field is the value passed to panic,
+// and the Stack field is the stack trace of the goroutine at the time of the panic.
+type PanicError struct {
+	Location string
+	Value    interface{}
+	Stack    []byte
+}
+
+// Error returns the message of the error.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// DecodeLimit represents a limit enforced while decoding a document, see DecodeLimits.
+type DecodeLimit string
+
+const (
+	// DecodeLimitDepth is the limit of the nesting depth of the elements.
+	DecodeLimitDepth DecodeLimit = "depth"
+
+	// DecodeLimitCharDataLength is the limit of the length of the character data of an element.
+	DecodeLimitCharDataLength DecodeLimit = "char_data_length"
+
+	// DecodeLimitTokens is the limit of the number of tokens of a document.
+	DecodeLimitTokens DecodeLimit = "tokens"
+)
+
+// DecodeLimitError is the error recorded for a document exceeding a limit of the decoding, see SetDecodeLimits.
+// The Location field is the location of the document, empty if it has been decoded with DecodeURLSet or DecodeSitemapIndex.
+// The Limit field is the limit exceeded, the Max field is its value.
+type DecodeLimitError struct {
+	Location string
+	Limit    DecodeLimit
+	Max      int
+}
+
+// Error returns the message of the error.
+func (e *DecodeLimitError) Error() string {
+	return fmt.Sprintf("decode limit exceeded: %s over %d", e.Limit, e.Max)
+}
+
+// LocTooLongError is the warning recorded for a URL whose location exceeds the 2048 characters allowed by the sitemaps.org protocol.
+// The Location field is the location of the sitemap, the Loc field is the location of the URL and the Length field is its length in bytes.
+// The Position field is the position of the URL in the sitemap, nil unless the positions are tracked, see SetTrackPositions.
+type LocTooLongError struct {
+	Location string
+	Loc      string
+	Length   int
+	Position *Position
+}
+
+// Error returns the message of the error.
+func (e *LocTooLongError) Error() string {
+	return fmt.Sprintf("loc exceeds %d characters: %d%s", maxLocLength, e.Length, positionSuffix(e.Position))
+}
+
+// Severity returns SeverityWarning, as the URL is still stored unless the long locations are dropped, see SetDropLongLocs.
+func (e *LocTooLongError) Severity() Severity {
+	return SeverityWarning
+}
+
+// LocEncodedError is the warning recorded for a location repaired before it is fetched or stored, see SetEncodeLocs.
+// The Location field is the location of the sitemap the location was found in, the Loc field is the location as found,
+// and the Encoded field is the location with the surrounding whitespace trimmed and the characters not allowed in a URL percent-encoded.
+// The Position field is the position of the URL in the sitemap, nil for the location of a sitemap or unless the positions are tracked, see SetTrackPositions.
+type LocEncodedError struct {
+	Location string
+	Loc      string
+	Encoded  string
+	Position *Position
+}
+
+// Error returns the message of the error.
+func (e *LocEncodedError) Error() string {
+	return fmt.Sprintf("loc %q contains characters not allowed in a URL, encoded as %q%s", e.Loc, e.Encoded, positionSuffix(e.Position))
+}
+
+// Severity returns SeverityWarning, as the repaired location is used.
+func (e *LocEncodedError) Severity() Severity {
+	return SeverityWarning
+}
+
+// UnexpectedElementError is the warning recorded for an element of a <urlset> that is not expected, see SetStrictElements.
+// The Location field is the location of the sitemap, the Element field is the name of the element, and the Parent field is
+// the name of its parent element, "urlset" or "url". The Loc field is the location of the <url> the element is in, empty for a child of <urlset>.
+// The Position field is the position of the element in the sitemap.
+type UnexpectedElementError struct {
+	Location string
+	Element  xml.Name
+	Parent   string
+	Loc      string
+	Position Position
+}
+
+// Error returns the message of the error.
+func (e *UnexpectedElementError) Error() string {
+	name := e.Element.Local
+	if e.Element.Space != "" {
+		name = e.Element.Space + " " + name
+	}
+	if e.Loc != "" {
+		return fmt.Sprintf("unexpected element <%s> in <%s> of %q at %s", name, e.Parent, e.Loc, e.Position)
+	}
+	return fmt.Sprintf("unexpected element <%s> in <%s> at %s", name, e.Parent, e.Position)
+}
+
+// Severity returns SeverityWarning, as the element is skipped.
+func (e *UnexpectedElementError) Severity() Severity {
+	return SeverityWarning
+}
+
+// DecompressionError is the error recorded for compressed content that is truncated or corrupted.
+// The Location field is the location of the content, the Truncated field is true if the content ended unexpectedly,
+// and the Err field is the underlying error (io.ErrUnexpectedEOF for truncated content), reachable with errors.Is and errors.As.
+// The Discarded field is true if the content was discarded instead of being parsed as far as it goes, see SetStrictDecompression.
+type DecompressionError struct {
+	Location  string
+	Truncated bool
+	Discarded bool
+	Err       error
+}
+
+// Error returns the message of the error.
+func (e *DecompressionError) Error() string {
+	if e.Truncated {
+		return "truncated compressed content"
+	}
+	return fmt.Sprintf("corrupted compressed content: %v", e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *DecompressionError) Unwrap() error {
+	return e.Err
+}
+
+// Severity returns SeverityWarning for truncated content parsed as far as it goes, and SeverityError otherwise.
+func (e *DecompressionError) Severity() Severity {
+	if e.Truncated && !e.Discarded {
+		return SeverityWarning
+	}
+	return SeverityError
+}
+
+// ErrorCategory represents the category of a fetch error.
+type ErrorCategory string
+
+const (
+	// ErrorCategoryDNS is the category of errors resolving the host name.
+	ErrorCategoryDNS ErrorCategory = "dns"
+
+	// ErrorCategoryConnectionRefused is the category of errors caused by the host refusing the connection.
+	ErrorCategoryConnectionRefused ErrorCategory = "connection_refused"
+
+	// ErrorCategoryTimeout is the category of errors caused by a timeout or an exceeded deadline.
+	ErrorCategoryTimeout ErrorCategory = "timeout"
+
+	// ErrorCategoryTLS is the category of errors of the TLS handshake, including certificate verification errors.
+	ErrorCategoryTLS ErrorCategory = "tls"
+
+	// ErrorCategoryHTTPStatus is the category of errors caused by a response with a status other than 200 (OK).
+	ErrorCategoryHTTPStatus ErrorCategory = "http_status"
+
+	// ErrorCategoryCanceled is the category of errors caused by the cancellation of the context of the parse.
+	ErrorCategoryCanceled ErrorCategory = "canceled"
+
+	// ErrorCategoryOther is the category of all other errors.
+	ErrorCategoryOther ErrorCategory = "other"
+)
+
+// FetchError is the error returned when fetching a location fails.
+// The URL field is the location being fetched.
+// The StatusCode field is the HTTP status of the response, 0 if no response was received.
+// The Err field is the underlying error, it is reachable with errors.Is and errors.As.
+type FetchError struct {
+	URL        string
+	StatusCode int
+	Err        error
+}
+
+// Error returns the message of the underlying error.
+func (e *FetchError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *FetchError) Unwrap() error {
+	return e.Err
+}
+
+// Category returns the category of the error, derived from the status code and the underlying error.
+func (e *FetchError) Category() ErrorCategory {
+	if e.StatusCode != 0 {
+		return ErrorCategoryHTTPStatus
+	}
+	return errorCategory(e.Err)
+}
+
+// errorCategory returns the category of the given network error.
+func errorCategory(err error) ErrorCategory {
+	var dnsErr *net.DNSError
+	var recordHeaderErr tls.RecordHeaderError
+	var alertErr tls.AlertError
+	var certVerificationErr *tls.CertificateVerificationError
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var certInvalidErr x509.CertificateInvalidError
+	var netErr net.Error
+	var opErr *net.OpError
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return ErrorCategoryCanceled
+	case errors.As(err, &dnsErr):
+		return ErrorCategoryDNS
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ErrorCategoryConnectionRefused
+	case errors.As(err, &recordHeaderErr),
+		errors.As(err, &alertErr),
+		errors.As(err, &certVerificationErr),
+		errors.As(err, &unknownAuthorityErr),
+		errors.As(err, &hostnameErr),
+		errors.As(err, &certInvalidErr),
+		// alerts sent by the server, e.g. when it rejects or requires a client certificate
+		errors.As(err, &opErr) && opErr.Op == "remote error":
+		return ErrorCategoryTLS
+	case errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, os.ErrDeadlineExceeded),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorCategoryTimeout
+	}
+	return ErrorCategoryOther
+}
@@ -0,0 +1,215 @@
+package sitemap
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetchError_Category(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	tlsServer := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	// the handshake errors are expected, do not log them
+	tlsServer.Config.ErrorLog = log.New(io.Discard, "", 0)
+	tlsServer.StartTLS()
+	defer tlsServer.Close()
+
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(3 * time.Second):
+		}
+	}))
+	defer slowServer.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	refusedAddr := listener.Addr().String()
+	_ = listener.Close()
+
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name   string
+		url    string
+		ctx    context.Context
+		want   ErrorCategory
+		wantAs func(err error) bool
+	}{
+		{
+			name: "dns",
+			url:  "http://nonexistent.invalid/sitemap.xml",
+			want: ErrorCategoryDNS,
+			wantAs: func(err error) bool {
+				var dnsErr *net.DNSError
+				return errors.As(err, &dnsErr)
+			},
+		},
+		{
+			name: "connection refused",
+			url:  fmt.Sprintf("http://%s/sitemap.xml", refusedAddr),
+			want: ErrorCategoryConnectionRefused,
+			wantAs: func(err error) bool {
+				var opErr *net.OpError
+				return errors.As(err, &opErr)
+			},
+		},
+		{
+			name: "timeout",
+			url:  fmt.Sprintf("%s/sitemap.xml", slowServer.URL),
+			want: ErrorCategoryTimeout,
+			wantAs: func(err error) bool {
+				var netErr net.Error
+				return errors.As(err, &netErr) && netErr.Timeout()
+			},
+		},
+		{
+			name: "tls",
+			url:  fmt.Sprintf("%s/sitemap.xml", tlsServer.URL),
+			want: ErrorCategoryTLS,
+			wantAs: func(err error) bool {
+				var unknownAuthorityErr x509.UnknownAuthorityError
+				return errors.As(err, &unknownAuthorityErr)
+			},
+		},
+		{
+			name: "http status",
+			url:  fmt.Sprintf("%s/404", server.URL),
+			want: ErrorCategoryHTTPStatus,
+		},
+		{
+			name: "canceled",
+			url:  fmt.Sprintf("%s/sitemap-01.xml", server.URL),
+			ctx:  canceledCtx,
+			want: ErrorCategoryCanceled,
+			wantAs: func(err error) bool {
+				return errors.Is(err, context.Canceled)
+			},
+		},
+		{
+			name: "other",
+			url:  "invalid_url",
+			want: ErrorCategoryOther,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New().SetFetchTimeout(1)
+			s.ctx = test.ctx
+
+			_, _, err := s.fetch(test.url)
+			var fetchErr *FetchError
+			if !errors.As(err, &fetchErr) {
+				t.Fatalf("expected *FetchError, got %T: %v", err, err)
+			}
+			if fetchErr.URL != test.url {
+				t.Errorf("expected URL %s, got %s", test.url, fetchErr.URL)
+			}
+			if fetchErr.Category() != test.want {
+				t.Errorf("expected category %s, got %s: %v", test.want, fetchErr.Category(), err)
+			}
+			if test.wantAs != nil && !test.wantAs(err) {
+				t.Errorf("expected the underlying error to be reachable, got %v", err)
+			}
+		})
+	}
+}
+
+func TestS_Parse_ErrorLocation(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	s, err := New().Parse(fmt.Sprintf("%s/sitemapindex-with-invalid-sitemap.xml", server.URL), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.GetErrorsCount() != 1 {
+		t.Fatalf("expected 1 error, got %v", s.GetErrors())
+	}
+
+	err = s.GetErrors()[0]
+	location := fmt.Sprintf("%s/invalid.xml", server.URL)
+	if err.Error() != location+": received HTTP status 404" {
+		t.Errorf("unexpected error message: %v", err)
+	}
+	var fetchErr *FetchError
+	if !errors.As(err, &fetchErr) || fetchErr.URL != location {
+		t.Errorf("expected *FetchError of %s, got %v", location, errors.Unwrap(err))
+	}
+}
+
+// panicTransport is an http.RoundTripper panicking on every request.
+type panicTransport struct{}
+
+func (panicTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	panic("poisonous document")
+}
+
+func TestS_recoverPanic(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	for _, multiThread := range []bool{true, false} {
+		t.Run(fmt.Sprintf("parse panics, multiThread=%v", multiThread), func(t *testing.T) {
+			s := New().SetMultiThread(multiThread)
+			// a nil regular expression panics when matching the URLs of the sitemaps
+			s.cfg.rulesRegexes = []*regexp.Regexp{nil}
+
+			s, err := s.Parse(fmt.Sprintf("%s/robots-with-sitemapindex/robots.txt", server.URL), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.GetErrorsCount() != 3 {
+				t.Fatalf("expected 3 errors, got %v", s.GetErrors())
+			}
+			for _, err := range s.GetErrors() {
+				var panicErr *PanicError
+				if !errors.As(err, &panicErr) {
+					t.Fatalf("expected *PanicError, got %v", err)
+				}
+				if !strings.HasPrefix(err.Error(), panicErr.Location+": panic: ") {
+					t.Errorf("expected the error to carry the location, got %v", err)
+				}
+				if len(panicErr.Stack) == 0 {
+					t.Error("expected the stack trace to be recorded")
+				}
+			}
+			if got := s.GetCompleteness().FailedSitemaps; got != 3 {
+				t.Errorf("expected 3 failed sitemaps, got %d", got)
+			}
+		})
+	}
+
+	t.Run("fetch panics", func(t *testing.T) {
+		s := New()
+		s.transport = panicTransport{}
+
+		s.parseAndFetchUrlsMultiThread([]string{
+			fmt.Sprintf("%s/sitemap-01.xml", server.URL),
+			fmt.Sprintf("%s/sitemap-02.xml", server.URL),
+		})
+		if s.GetErrorsCount() != 2 {
+			t.Fatalf("expected 2 errors, got %v", s.GetErrors())
+		}
+		var panicErr *PanicError
+		if !errors.As(s.GetErrors()[0], &panicErr) || panicErr.Value != "poisonous document" {
+			t.Errorf("expected *PanicError, got %v", s.GetErrors()[0])
+		}
+	})
+}
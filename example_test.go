@@ -0,0 +1,73 @@
+package sitemap_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/aafeher/go-sitemap-parser"
+)
+
+func ExampleParseURL() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+    <url><loc>https://www.example.com/</loc></url>
+    <url><loc>https://www.example.com/about</loc></url>
+</urlset>`)
+	}))
+	defer server.Close()
+
+	urls, err := sitemap.ParseURL(context.Background(), server.URL+"/sitemap.xml",
+		sitemap.WithUserAgent("my-crawler/1.0"),
+		sitemap.WithMaxURLs(1000),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, u := range urls {
+		fmt.Println(u.Loc)
+	}
+	// Output:
+	// https://www.example.com/
+	// https://www.example.com/about
+}
+
+func ExampleDecodeSitemapIndex() {
+	smIndex, err := sitemap.DecodeSitemapIndex(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+    <sitemap><loc>https://www.example.com/sitemap-01.xml</loc><lastmod>2024-02-12</lastmod></sitemap>
+    <sitemap><loc>https://www.example.com/sitemap-02.xml</loc></sitemap>
+</sitemapindex>`))
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, sm := range smIndex.Sitemap {
+		fmt.Println(sm.Loc, sm.LastMod != nil)
+	}
+	// Output:
+	// https://www.example.com/sitemap-01.xml true
+	// https://www.example.com/sitemap-02.xml false
+}
+
+func ExampleNewLastMod() {
+	u := sitemap.URL{
+		Loc:     "https://www.example.com/",
+		LastMod: sitemap.NewLastMod(time.Date(2024, 2, 12, 12, 34, 56, 0, time.UTC)),
+	}
+
+	data, err := json.Marshal(u.LastMod)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(string(data))
+	fmt.Println(u.LastMod.Time.Year())
+	// Output:
+	// "2024-02-12T12:34:56Z"
+	// 2024
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/aafeher/go-sitemap-parser"
 	"log"
+	"time"
 )
 
 func main() {
@@ -41,7 +42,7 @@ func main() {
 			fmt.Printf(", Priority: %.1f", *u.Priority)
 		}
 		if u.LastMod != nil {
-			fmt.Printf(", LastMod: %s", u.LastMod.String())
+			fmt.Printf(", LastMod: %s", u.LastMod.Format(time.RFC3339))
 		}
 		fmt.Println()
 	}
@@ -59,7 +60,7 @@ func main() {
 			fmt.Printf(", Priority: %.1f", *u.Priority)
 		}
 		if u.LastMod != nil {
-			fmt.Printf(", LastMod: %s", u.LastMod.String())
+			fmt.Printf(", LastMod: %s", u.LastMod.Format(time.RFC3339))
 		}
 		fmt.Println()
 	}
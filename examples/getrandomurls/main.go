@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/aafeher/go-sitemap-parser"
 	"log"
+	"time"
 )
 
 // main is the entry point of the program.
@@ -28,7 +29,7 @@ func main() {
 			fmt.Printf(", Priority: %.1f", *u.Priority)
 		}
 		if u.LastMod != nil {
-			fmt.Printf(", LastMod: %s", u.LastMod.String())
+			fmt.Printf(", LastMod: %s", u.LastMod.Format(time.RFC3339))
 		}
 		fmt.Println()
 	}
@@ -0,0 +1,72 @@
+package sitemap
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrFailureRateExceeded is matched by errors.Is for every FailureRateError.
+var ErrFailureRateExceeded = errors.New("failure rate exceeded")
+
+// FailureRateError is the error returned by Parse when the parse is aborted because too many sitemaps failed, see SetMaxFailureRate.
+// The Failed field is the number of sitemaps failed, the Completed field is the number of sitemaps processed (failed or not)
+// when the parse was aborted, and the MaxRate field is the maximum failure rate set.
+type FailureRateError struct {
+	Failed    int
+	Completed int
+	MaxRate   float64
+}
+
+// Error returns the message of the error.
+func (e *FailureRateError) Error() string {
+	return fmt.Sprintf("failure rate exceeded: %d of %d sitemaps failed, over the maximum rate of %g", e.Failed, e.Completed, e.MaxRate)
+}
+
+// Is reports whether target is ErrFailureRateExceeded.
+func (e *FailureRateError) Is(target error) bool {
+	return target == ErrFailureRateExceeded
+}
+
+// SetMaxFailureRate sets the maximum rate of the sitemaps failing to be fetched or parsed, e.g. after a site migration
+// left most of the sitemaps of an index returning 404. Once at least minSamples sitemaps (including the main URL) have been processed
+// and the rate of the failed ones exceeds ratio, the parse is aborted: no further sitemaps are fetched, the fetches in progress
+// are cancelled, and Parse returns a *FailureRateError (also recorded in the errors) along with the partial results.
+// The parse is reported as truncated by TruncatedByFailureRate, see GetCompleteness.
+// A ratio of 0 (the default) or of at least 1 disables the limit, a minSamples below 1 is handled as 1.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetMaxFailureRate(ratio float64, minSamples int) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.maxFailureRate = ratio
+	s.cfg.failureRateMinSamples = max(minSamples, 1)
+
+	return s
+}
+
+// failureRateEnabled reports whether the maximum failure rate is set.
+func (s *S) failureRateEnabled() bool {
+	return s.cfg.maxFailureRate > 0 && s.cfg.maxFailureRate < 1
+}
+
+// recordOutcome counts a sitemap processed, failed or not, and aborts the parse if the failure rate exceeds the maximum.
+// It must be called with s.mu held.
+func (s *S) recordOutcome(failed bool) {
+	if !s.failureRateEnabled() || s.failureRateErr != nil || s.errorLimitErr != nil {
+		return
+	}
+	s.outcomesCompleted++
+	if failed {
+		s.outcomesFailed++
+	}
+	if s.outcomesCompleted < s.cfg.failureRateMinSamples || float64(s.outcomesFailed)/float64(s.outcomesCompleted) <= s.cfg.maxFailureRate {
+		return
+	}
+
+	s.failureRateErr = &FailureRateError{Failed: s.outcomesFailed, Completed: s.outcomesCompleted, MaxRate: s.cfg.maxFailureRate}
+	s.appendError(s.failureRateErr)
+	s.truncate(TruncatedByFailureRate)
+	if s.cancelParse != nil {
+		s.cancelParse()
+	}
+}
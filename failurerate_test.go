@@ -0,0 +1,195 @@
+package sitemap
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aafeher/go-sitemap-parser/sitemaptest"
+)
+
+// failingIndexServer serves a sitemap index at /index.xml referencing the given number of sitemaps, /sitemap-1.xml to /sitemap-n.xml.
+// The sitemaps for which failing returns true respond with 404 (Not Found), those for which hanging returns true
+// respond only when the request is cancelled, the others contain a single URL.
+// The number of sitemap requests received is counted in requests.
+func failingIndexServer(n int, failing func(i int) bool, hanging func(i int) bool, requests *int64) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/index.xml" {
+			locs := make([]string, 0, n)
+			for i := 1; i <= n; i++ {
+				locs = append(locs, fmt.Sprintf("%s/sitemap-%d.xml", server.URL, i))
+			}
+			_, _ = w.Write(sitemaptest.SitemapIndex(locs...))
+			return
+		}
+
+		atomic.AddInt64(requests, 1)
+		var i int
+		if _, err := fmt.Sscanf(r.URL.Path, "/sitemap-%d.xml", &i); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		switch {
+		case failing(i):
+			http.NotFound(w, r)
+		case hanging(i):
+			<-r.Context().Done()
+		default:
+			_, _ = w.Write(sitemaptest.URLSet(fmt.Sprintf("%s/page-%d", server.URL, i)))
+		}
+	}))
+	return server
+}
+
+func TestS_SetMaxFailureRate(t *testing.T) {
+	tests := []struct {
+		name           string
+		ratio          float64
+		minSamples     int
+		wantMinSamples int
+		wantEnabled    bool
+	}{
+		{name: "enabled", ratio: 0.5, minSamples: 10, wantMinSamples: 10, wantEnabled: true},
+		{name: "min samples below 1", ratio: 0.5, minSamples: 0, wantMinSamples: 1, wantEnabled: true},
+		{name: "disabled", ratio: 0, minSamples: 10, wantMinSamples: 10, wantEnabled: false},
+		{name: "ratio of 1", ratio: 1, minSamples: 10, wantMinSamples: 10, wantEnabled: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New().SetMaxFailureRate(test.ratio, test.minSamples)
+			if s.cfg.maxFailureRate != test.ratio || s.cfg.failureRateMinSamples != test.wantMinSamples {
+				t.Errorf("expected %v and %d, got %v and %d", test.ratio, test.wantMinSamples, s.cfg.maxFailureRate, s.cfg.failureRateMinSamples)
+			}
+			if got := s.failureRateEnabled(); got != test.wantEnabled {
+				t.Errorf("expected %v, got %v", test.wantEnabled, got)
+			}
+		})
+	}
+}
+
+func TestS_Parse_MaxFailureRate(t *testing.T) {
+	never := func(int) bool { return false }
+
+	tests := []struct {
+		name         string
+		s            *S
+		failing      func(i int) bool
+		wantErr      *FailureRateError
+		wantRequests int64
+		wantURLs     int
+		wantSkipped  int
+	}{
+		{
+			// the main URL and sitemaps 1-2 succeed, the rate exceeds 0.5 after the 4th failure: 4 of 7
+			name:         "aborted",
+			s:            New().SetMultiThread(false).SetMaxFailureRate(0.5, 4),
+			failing:      func(i int) bool { return i > 2 },
+			wantErr:      &FailureRateError{Failed: 4, Completed: 7, MaxRate: 0.5},
+			wantRequests: 6,
+			wantURLs:     2,
+			wantSkipped:  14,
+		},
+		{
+			// the rate is high from the start, but is only checked after the minimum number of samples
+			name:         "min samples",
+			s:            New().SetMultiThread(false).SetMaxFailureRate(0.1, 10),
+			failing:      func(i int) bool { return true },
+			wantErr:      &FailureRateError{Failed: 9, Completed: 10, MaxRate: 0.1},
+			wantRequests: 9,
+			wantURLs:     0,
+			wantSkipped:  11,
+		},
+		{
+			name:         "under the maximum",
+			s:            New().SetMultiThread(false).SetMaxFailureRate(0.5, 4),
+			failing:      func(i int) bool { return i%3 == 0 },
+			wantRequests: 20,
+			wantURLs:     14,
+		},
+		{
+			name:         "disabled",
+			s:            New().SetMultiThread(false),
+			failing:      func(i int) bool { return true },
+			wantRequests: 20,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var requests int64
+			server := failingIndexServer(20, test.failing, never, &requests)
+			defer server.Close()
+
+			s, err := test.s.Parse(server.URL+"/index.xml", nil)
+
+			var rateErr *FailureRateError
+			if test.wantErr == nil {
+				if err != nil {
+					t.Fatalf("unexpected err: %v", err)
+				}
+			} else {
+				if !errors.As(err, &rateErr) || !errors.Is(err, ErrFailureRateExceeded) {
+					t.Fatalf("expected a *FailureRateError, got %v", err)
+				}
+				if *rateErr != *test.wantErr {
+					t.Errorf("expected %+v, got %+v", test.wantErr, rateErr)
+				}
+				recorded := false
+				for _, e := range s.GetErrors() {
+					recorded = recorded || errors.Is(e, ErrFailureRateExceeded)
+				}
+				if !recorded {
+					t.Errorf("expected the error to be recorded, got %v", s.GetErrors())
+				}
+			}
+
+			if got := atomic.LoadInt64(&requests); got != test.wantRequests {
+				t.Errorf("expected %d requests, got %d", test.wantRequests, got)
+			}
+			if got := len(s.GetURLs()); got != test.wantURLs {
+				t.Errorf("expected %d URLs, got %d", test.wantURLs, got)
+			}
+			completeness := s.GetCompleteness()
+			if wantTruncatedBy := map[bool]TruncationCause{true: TruncatedByFailureRate, false: TruncatedByNone}[test.wantErr != nil]; completeness.TruncatedBy != wantTruncatedBy {
+				t.Errorf("expected %s, got %s", wantTruncatedBy, completeness.TruncatedBy)
+			}
+			if completeness.SkippedSitemaps != test.wantSkipped {
+				t.Errorf("expected %d skipped sitemaps, got %d", test.wantSkipped, completeness.SkippedSitemaps)
+			}
+		})
+	}
+}
+
+func TestS_Parse_MaxFailureRate_CancelsInFlight(t *testing.T) {
+	var requests int64
+	// sitemaps 1-10 fail at once, sitemaps 11-15 hang until their request is cancelled
+	server := failingIndexServer(15, func(i int) bool { return i <= 10 }, func(i int) bool { return i > 10 }, &requests)
+	defer server.Close()
+
+	start := time.Now()
+	s, err := New().SetFetchTimeout(10).SetMaxFailureRate(0.5, 5).Parse(server.URL+"/index.xml", nil)
+	if !errors.Is(err, ErrFailureRateExceeded) {
+		t.Fatalf("expected %v, got %v", ErrFailureRateExceeded, err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the fetches in progress to be cancelled, the parse took %v", elapsed)
+	}
+
+	completeness := s.GetCompleteness()
+	if completeness.TruncatedBy != TruncatedByFailureRate {
+		t.Errorf("expected %s, got %s", TruncatedByFailureRate, completeness.TruncatedBy)
+	}
+	if completeness.FailedSitemaps+completeness.SkippedSitemaps != 15 {
+		t.Errorf("expected 15 failed or skipped sitemaps, got %d and %d", completeness.FailedSitemaps, completeness.SkippedSitemaps)
+	}
+
+	// the context of the parse is restored, the state of the parse is not reported as cancelled
+	if err := s.context().Err(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
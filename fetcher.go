@@ -0,0 +1,48 @@
+package sitemap
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Fetcher retrieves the content of a location in place of the HTTP client of the parser, see SetFetcher.
+// Fetch returns the body of the given URL, or an error if it cannot be retrieved, e.g. because it does not exist.
+// It is called from multiple goroutines when multi-threading is on (see SetMultiThread), so it must be safe for concurrent use,
+// and it should return early with the error of ctx when ctx is done.
+type Fetcher interface {
+	Fetch(ctx context.Context, url string) ([]byte, error)
+}
+
+// SetFetcher sets the fetcher retrieving the content of the locations instead of the HTTP client, e.g. a fake fetcher
+// serving fixtures from memory in tests (see sitemaptest.FakeFetcher), or a client with custom authentication.
+// The fetcher receives the URL after the host rewrite and the HTTPS upgrade (see SetFetchHostRewrite and SetUpgradeToHTTPS),
+// and the rate limits, the concurrency limits, the request delay, the retries and the body cache apply as usual.
+// An error of the fetcher is recorded as a FetchError without a status code, a body is handled like a response of status 200
+// without a Content-Encoding. The timeouts, the user agent, the TLS settings and the size hint (see SetMaxFetchSizeHint) do not apply.
+// A nil fetcher (the default) means the HTTP client.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetFetcher(fetcher Fetcher) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.fetcher = fetcher
+
+	return s
+}
+
+// fetchWithFetcher retrieves the content of the given URL from the target URL with the fetcher set, see SetFetcher and fetchTarget.
+// The metadata is filled in like for a response of status 200, as far as the fetch got.
+func (s *S) fetchWithFetcher(ctx context.Context, url string, target string, start time.Time) ([]byte, FetchMeta, error) {
+	var meta FetchMeta
+	content, err := s.cfg.fetcher.Fetch(ctx, target)
+	meta.Duration = time.Since(start)
+	if err != nil {
+		return nil, meta, &FetchError{URL: url, Err: err}
+	}
+	meta.StatusCode = http.StatusOK
+	meta.CompressedBytes = int64(len(content))
+	meta.DecompressedBytes = meta.CompressedBytes
+
+	return content, meta, nil
+}
@@ -0,0 +1,73 @@
+package sitemap
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// mapFetcher is a Fetcher serving the contents of a map keyed by URL and recording the URLs fetched.
+type mapFetcher struct {
+	mu       sync.Mutex
+	contents map[string]string
+	fetched  []string
+}
+
+func (f *mapFetcher) Fetch(_ context.Context, url string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.fetched = append(f.fetched, url)
+	content, ok := f.contents[url]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return []byte(content), nil
+}
+
+func TestS_SetFetcher(t *testing.T) {
+	fetcher := &mapFetcher{contents: map[string]string{
+		"https://staging.example.com/sitemap-index.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+    <sitemap><loc>https://www.example.com/sitemap-1.xml</loc></sitemap>
+    <sitemap><loc>https://www.example.com/sitemap-2.xml</loc></sitemap>
+</sitemapindex>`,
+		"https://staging.example.com/sitemap-1.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+    <url><loc>https://www.example.com/page-1</loc></url>
+</urlset>`,
+	}}
+
+	s, err := New().
+		SetMultiThread(false).
+		SetFetcher(fetcher).
+		SetFetchHostRewrite(map[string]string{"www.example.com": "staging.example.com"}).
+		Parse("https://www.example.com/sitemap-index.xml", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.GetLocs(), []string{"https://www.example.com/page-1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	wantFetched := []string{
+		"https://staging.example.com/sitemap-index.xml",
+		"https://staging.example.com/sitemap-1.xml",
+		"https://staging.example.com/sitemap-2.xml",
+	}
+	if !reflect.DeepEqual(fetcher.fetched, wantFetched) {
+		t.Errorf("expected fetched %v, got %v", wantFetched, fetcher.fetched)
+	}
+
+	errs := s.GetErrors()
+	var fetchErr *FetchError
+	if len(errs) != 1 || !errors.As(errs[0], &fetchErr) || fetchErr.URL != "https://www.example.com/sitemap-2.xml" {
+		t.Errorf("expected a FetchError of the missing sitemap, got %v", errs)
+	}
+	meta := s.GetFetchMetadata()["https://www.example.com/sitemap-1.xml"]
+	if meta.StatusCode != http.StatusOK || meta.CompressedBytes == 0 || meta.DecompressedBytes != meta.CompressedBytes {
+		t.Errorf("unexpected fetch metadata %+v", meta)
+	}
+}
@@ -0,0 +1,117 @@
+package sitemap
+
+import (
+	"encoding/xml"
+)
+
+// Field represents a child element of <url>, identified by its local name.
+type Field string
+
+const (
+	// FieldLoc is the <loc> element, it is always decoded.
+	FieldLoc Field = "loc"
+
+	// FieldLastMod is the <lastmod> element.
+	FieldLastMod Field = "lastmod"
+
+	// FieldChangeFreq is the <changefreq> element.
+	FieldChangeFreq Field = "changefreq"
+
+	// FieldPriority is the <priority> element.
+	FieldPriority Field = "priority"
+
+	// FieldImages is the <image:image> element.
+	FieldImages Field = "image"
+
+	// FieldVideos is the <video:video> element.
+	FieldVideos Field = "video"
+
+	// FieldNews is the <news:news> element.
+	FieldNews Field = "news"
+
+	// FieldAlternates is the <xhtml:link> element.
+	FieldAlternates Field = "link"
+
+	// FieldMobile is the <mobile:mobile> element.
+	FieldMobile Field = "mobile"
+)
+
+// SetFields sets the child elements of <url> decoded by the Sitemap Parser, the others are skipped at the token level,
+// leaving the corresponding fields of the URLs zero. It saves the decoding and the allocations of the fields not needed.
+// FieldLoc is always decoded, as the rules and the other features rely on it.
+// Calling it without fields (the default) decodes all fields.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetFields(fields ...Field) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.fields = append([]Field(nil), fields...)
+
+	return s
+}
+
+// fieldSet returns the set of the local names of the given child elements of <url> to decode and of <loc>,
+// nil means all of them.
+func fieldSet(fields []Field) map[string]bool {
+	if len(fields) == 0 {
+		return nil
+	}
+	set := map[string]bool{string(FieldLoc): true}
+	for _, field := range fields {
+		set[string(field)] = true
+	}
+	return set
+}
+
+// fieldFilterTokenReader reads the tokens of the underlying token reader, dropping the child elements of the <url> elements of a <urlset>
+// whose local name is not in the fields set, together with all their tokens.
+// The depth field is the current nesting depth, and the urlSet field reports whether the root element is a <urlset>.
+type fieldFilterTokenReader struct {
+	r      xml.TokenReader
+	fields map[string]bool
+	depth  int
+	urlSet bool
+}
+
+// Token returns the next token of the underlying token reader which is not dropped.
+func (f *fieldFilterTokenReader) Token() (xml.Token, error) {
+	for {
+		token, err := f.r.Token()
+		if token == nil {
+			return token, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			f.depth++
+			if f.depth == 1 {
+				f.urlSet = t.Name.Local == "urlset"
+			}
+			if f.urlSet && f.depth == 3 && !f.fields[t.Name.Local] {
+				if err := f.skip(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		case xml.EndElement:
+			f.depth--
+		}
+		return token, err
+	}
+}
+
+// skip drops the tokens of the element just started, up to and including its end element.
+func (f *fieldFilterTokenReader) skip() error {
+	for depth := f.depth; f.depth >= depth; {
+		token, err := f.r.Token()
+		if err != nil {
+			return err
+		}
+		switch token.(type) {
+		case xml.StartElement:
+			f.depth++
+		case xml.EndElement:
+			f.depth--
+		}
+	}
+	return nil
+}
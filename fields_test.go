@@ -0,0 +1,104 @@
+package sitemap
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestS_SetFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []Field
+		want   map[string]bool
+	}{
+		{
+			name:   "all fields",
+			fields: nil,
+			want:   nil,
+		},
+		{
+			name:   "lastmod",
+			fields: []Field{FieldLastMod},
+			want:   map[string]bool{"loc": true, "lastmod": true},
+		},
+		{
+			name:   "loc and images",
+			fields: []Field{FieldLoc, FieldImages},
+			want:   map[string]bool{"loc": true, "image": true},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New().SetFields(test.fields...)
+			if got := fieldSet(s.cfg.fields); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestS_Parse_Fields(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	full, err := New().Parse(server.URL+"/sitemap-extensions.xml", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		fields []Field
+		keep   func(u URL) URL
+	}{
+		{
+			name:   "loc and lastmod",
+			fields: []Field{FieldLoc, FieldLastMod},
+			keep: func(u URL) URL {
+				return URL{Loc: u.Loc, LastMod: u.LastMod, source: u.source}
+			},
+		},
+		{
+			name:   "loc only",
+			fields: []Field{FieldLoc},
+			keep: func(u URL) URL {
+				return URL{Loc: u.Loc, source: u.source}
+			},
+		},
+		{
+			name:   "extensions",
+			fields: []Field{FieldImages, FieldVideos, FieldNews, FieldAlternates},
+			keep: func(u URL) URL {
+				return URL{Loc: u.Loc, Images: u.Images, Videos: u.Videos, News: u.News, Alternates: u.Alternates, source: u.source}
+			},
+		},
+		{
+			name:   "changefreq and priority",
+			fields: []Field{FieldChangeFreq, FieldPriority},
+			keep: func(u URL) URL {
+				return URL{Loc: u.Loc, ChangeFreq: u.ChangeFreq, Priority: u.Priority, source: u.source}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := New().SetFields(test.fields...).Parse(server.URL+"/sitemap-extensions.xml", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.GetErrorsCount() != 0 {
+				t.Fatalf("unexpected errors: %v", s.GetErrors())
+			}
+
+			var want []URL
+			for _, u := range full.GetURLs() {
+				want = append(want, test.keep(u))
+			}
+			if got := s.GetURLs(); !reflect.DeepEqual(got, want) {
+				t.Errorf("expected %+v, got %+v", want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package sitemap
+
+// Filter represents a filter rejecting URLs or sitemap locations during parsing.
+type Filter string
+
+const (
+	// FilterRules is the filter of the URLs not matching any of the regular expressions set by SetRules.
+	FilterRules Filter = "rules"
+
+	// FilterFollow is the filter of the sitemaps of an index not matching any of the regular expressions set by SetFollow.
+	FilterFollow Filter = "follow"
+
+	// FilterSitemapLastMod is the filter of the sitemaps of an index skipped because of their lastmod,
+	// see SetMinSitemapLastMod and SetMaxSitemapsByLastMod.
+	FilterSitemapLastMod Filter = "sitemap_lastmod"
+
+	// FilterURLMatcher is the filter of the URLs rejected by the function set by SetURLMatcher.
+	FilterURLMatcher Filter = "url_matcher"
+
+	// FilterSitemapMatcher is the filter of the sitemaps of an index rejected by the function set by SetSitemapMatcher.
+	FilterSitemapMatcher Filter = "sitemap_matcher"
+
+	// FilterLocLength is the filter of the URLs whose location exceeds 2048 characters, see SetDropLongLocs.
+	FilterLocLength Filter = "loc_length"
+)
+
+// filteredSitemap is a sitemap location of an index rejected by a filter.
+type filteredSitemap struct {
+	loc    string
+	filter Filter
+}
+
+// GetFilteredURLCount returns the number of URLs decoded, but rejected by a filter, such as the rules set by SetRules.
+// If the S object is nil, 0 is returned.
+func (s *S) GetFilteredURLCount() int64 {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var count int64
+	for _, n := range s.filteredURLs {
+		count += n
+	}
+	return count
+}
+
+// GetFilteredURLCounts returns the number of URLs decoded, but rejected, per filter. Filters that rejected no URL are not included.
+// The returned map is a fresh copy. If the S object is nil, an empty map is returned.
+func (s *S) GetFilteredURLCounts() map[Filter]int64 {
+	counts := map[Filter]int64{}
+	if s == nil {
+		return counts
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for filter, n := range s.filteredURLs {
+		counts[filter] = n
+	}
+	return counts
+}
+
+// filterURL records that a URL has been rejected by the given filter.
+// It must be called with s.mu held.
+func (s *S) filterURL(filter Filter) {
+	if s.filteredURLs == nil {
+		s.filteredURLs = map[Filter]int64{}
+	}
+	s.filteredURLs[filter]++
+}
+
+// GetSkippedSitemaps returns the sitemap locations seen in an index, but not fetched because a filter rejected them,
+// such as the follow patterns set by SetFollow, in the order they were rejected.
+// The returned slice is a fresh copy. If the S object is nil, an empty slice is returned.
+func (s *S) GetSkippedSitemaps() []string {
+	if s == nil {
+		return []string{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	locs := make([]string, 0, len(s.filteredSitemaps))
+	for _, sm := range s.filteredSitemaps {
+		locs = append(locs, sm.loc)
+	}
+	return locs
+}
+
+// GetSkippedSitemapsByFilter returns the sitemap locations returned by GetSkippedSitemaps per filter.
+// Filters that rejected no sitemap are not included.
+// The returned map is a fresh copy. If the S object is nil, an empty map is returned.
+func (s *S) GetSkippedSitemapsByFilter() map[Filter][]string {
+	locs := map[Filter][]string{}
+	if s == nil {
+		return locs
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, sm := range s.filteredSitemaps {
+		locs[sm.filter] = append(locs[sm.filter], sm.loc)
+	}
+	return locs
+}
+
+// filterSitemap records that the sitemap location of an index has been rejected by the given filter.
+// It must be called with s.mu held.
+func (s *S) filterSitemap(loc string, filter Filter) {
+	s.filteredSitemaps = append(s.filteredSitemaps, filteredSitemap{loc: loc, filter: filter})
+}
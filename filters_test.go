@@ -0,0 +1,155 @@
+package sitemap
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestS_GetFilteredURLCount(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	tests := []struct {
+		name          string
+		s             *S
+		wantURLs      int64
+		wantFiltered  int64
+		wantBreakdown map[Filter]int64
+	}{
+		{
+			name:          "no rules",
+			s:             New(),
+			wantURLs:      6,
+			wantFiltered:  0,
+			wantBreakdown: map[Filter]int64{},
+		},
+		{
+			name:          "rules matching half",
+			s:             New().SetRules([]string{`page-0[135]$`}),
+			wantURLs:      3,
+			wantFiltered:  3,
+			wantBreakdown: map[Filter]int64{FilterRules: 3},
+		},
+		{
+			name:          "rules matching half, loc only",
+			s:             New().SetLocOnly(true).SetRules([]string{`page-0[135]$`}),
+			wantURLs:      3,
+			wantFiltered:  3,
+			wantBreakdown: map[Filter]int64{FilterRules: 3},
+		},
+		{
+			name:          "rules with a typo",
+			s:             New().SetRules([]string{`pgae-`}),
+			wantURLs:      0,
+			wantFiltered:  6,
+			wantBreakdown: map[Filter]int64{FilterRules: 6},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := test.s.Parse(server.URL+"/sitemapindex-1.xml", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.GetURLCount(); got != test.wantURLs {
+				t.Errorf("expected %d URLs, got %d", test.wantURLs, got)
+			}
+			if got := s.GetFilteredURLCount(); got != test.wantFiltered {
+				t.Errorf("expected %d filtered URLs, got %d", test.wantFiltered, got)
+			}
+			if got := s.GetFilteredURLCounts(); !reflect.DeepEqual(got, test.wantBreakdown) {
+				t.Errorf("expected %v, got %v", test.wantBreakdown, got)
+			}
+		})
+	}
+}
+
+func TestS_GetSkippedSitemaps(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	tests := []struct {
+		name          string
+		s             *S
+		url           string
+		wantSkipped   []string
+		wantBreakdown map[Filter][]string
+		wantFetched   []string
+	}{
+		{
+			name:          "no follow patterns",
+			s:             New(),
+			url:           "/sitemapindex-follow-1.xml",
+			wantSkipped:   []string{},
+			wantBreakdown: map[Filter][]string{},
+			wantFetched:   []string{"/sitemap-follow-alpha-01.xml", "/sitemap-follow-alpha-02.xml", "/sitemap-follow-beta-01.xml"},
+		},
+		{
+			name:          "follow alpha",
+			s:             New().SetFollow([]string{`alpha`}),
+			url:           "/sitemapindex-follow-1.xml",
+			wantSkipped:   []string{"/sitemap-follow-beta-01.xml"},
+			wantBreakdown: map[Filter][]string{FilterFollow: {"/sitemap-follow-beta-01.xml"}},
+			wantFetched:   []string{"/sitemap-follow-alpha-01.xml", "/sitemap-follow-alpha-02.xml"},
+		},
+		{
+			name:          "follow pattern with a typo",
+			s:             New().SetFollow([]string{`aplha`}),
+			url:           "/sitemapindex-follow-1.xml",
+			wantSkipped:   []string{"/sitemap-follow-alpha-01.xml", "/sitemap-follow-alpha-02.xml", "/sitemap-follow-beta-01.xml"},
+			wantBreakdown: map[Filter][]string{FilterFollow: {"/sitemap-follow-alpha-01.xml", "/sitemap-follow-alpha-02.xml", "/sitemap-follow-beta-01.xml"}},
+			wantFetched:   []string{},
+		},
+		{
+			name: "follow and lastmod",
+			s: New().SetFollow([]string{`sitemap-0[1-4]`}).
+				SetMinSitemapLastMod(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)).SetSkipSitemapsWithoutLastMod(true),
+			url:         "/sitemapindex-lastmod.xml",
+			wantSkipped: []string{"/sitemap-01.xml", "/sitemap-03.xml", "/sitemap-04.xml", "/sitemap-05.xml"},
+			wantBreakdown: map[Filter][]string{
+				FilterSitemapLastMod: {"/sitemap-01.xml", "/sitemap-03.xml", "/sitemap-04.xml"},
+				FilterFollow:         {"/sitemap-05.xml"},
+			},
+			wantFetched: []string{"/sitemap-02.xml"},
+		},
+		{
+			name:          "max sitemaps by lastmod",
+			s:             New().SetMaxSitemapsByLastMod(2),
+			url:           "/sitemapindex-lastmod.xml",
+			wantSkipped:   []string{"/sitemap-01.xml", "/sitemap-03.xml", "/sitemap-04.xml"},
+			wantBreakdown: map[Filter][]string{FilterSitemapLastMod: {"/sitemap-01.xml", "/sitemap-03.xml", "/sitemap-04.xml"}},
+			wantFetched:   []string{"/sitemap-02.xml", "/sitemap-05.xml"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := test.s.Parse(server.URL+test.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := trimPrefixes(s.GetSkippedSitemaps(), server.URL); !reflect.DeepEqual(got, test.wantSkipped) {
+				t.Errorf("expected skipped %v, got %v", test.wantSkipped, got)
+			}
+			breakdown := map[Filter][]string{}
+			for filter, locs := range s.GetSkippedSitemapsByFilter() {
+				breakdown[filter] = trimPrefixes(locs, server.URL)
+			}
+			if !reflect.DeepEqual(breakdown, test.wantBreakdown) {
+				t.Errorf("expected %v, got %v", test.wantBreakdown, breakdown)
+			}
+			fetched := []string{}
+			for _, child := range s.GetSitemapTree().Children {
+				fetched = append(fetched, strings.TrimPrefix(child.Loc, server.URL))
+			}
+			sort.Strings(fetched)
+			if !reflect.DeepEqual(fetched, test.wantFetched) {
+				t.Errorf("expected fetched %v, got %v", test.wantFetched, fetched)
+			}
+		})
+	}
+}
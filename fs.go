@@ -0,0 +1,109 @@
+package sitemap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	neturl "net/url"
+	"strings"
+	"time"
+)
+
+// fsScheme is the URL scheme of the locations of the files of a file system parsed with ParseFS.
+const fsScheme = "fs"
+
+// ParseFS parses the file at the given path of the file system, e.g. an embed.FS of fixtures or an fstest.MapFS, as the main document.
+// It is equivalent to ParseFSContext with context.Background().
+func (s *S) ParseFS(fsys fs.FS, path string) (*S, error) {
+	return s.ParseFSContext(context.Background(), fsys, path)
+}
+
+// ParseFSContext parses the file at the given path of the file system as the main document, like ParseContext does with a URL,
+// e.g. to run the whole pipeline on embedded fixtures without a network. The path is in the form of fs.ValidPath, e.g. "sitemaps/index.xml".
+// The files are given "fs:///" locations, e.g. "fs:///sitemaps/index.xml", which appear in the results, the errors and the sitemap tree.
+// The relative locations of a sitemap index or robots.txt file read from the file system, e.g. "sitemap-01.xml" or "/sitemap-01.xml",
+// are resolved against its location and read from the same file system, while the absolute http and https locations
+// are fetched over the network as usual. The compressed files are detected by their content or extension, like the fetched ones.
+// The files are not cached (see SetBodyCache) and not throttled, and their read errors are recorded as *FetchError errors
+// wrapping the error of the file system, e.g. fs.ErrNotExist.
+// An invalid file system or path is recorded as an error and returned, like an invalid URL.
+// If the S object is nil, it returns ErrNilReceiver.
+func (s *S) ParseFSContext(ctx context.Context, fsys fs.FS, path string) (*S, error) {
+	if s == nil {
+		return nil, ErrNilReceiver
+	}
+	if fsys == nil {
+		err := errors.New("invalid file system: nil")
+		s.addError(err)
+		return s, err
+	}
+	if !fs.ValidPath(path) || path == "." {
+		err := fmt.Errorf("invalid path %q: %w", path, fs.ErrInvalid)
+		s.addError(err)
+		return s, err
+	}
+
+	s.fsys = fsys
+	defer func() {
+		s.fsys = nil
+	}()
+
+	return s.ParseContext(ctx, fsLocation(path), nil)
+}
+
+// fsLocation returns the location of the file at the given path of a file system, see ParseFS.
+func fsLocation(path string) string {
+	return (&neturl.URL{Scheme: fsScheme, Path: "/" + path}).String()
+}
+
+// isFSLocation reports whether the given location is the location of a file of the file system being parsed, see ParseFS.
+func (s *S) isFSLocation(location string) bool {
+	if s.fsys == nil || !strings.HasPrefix(strings.ToLower(location), fsScheme+":") {
+		return false
+	}
+	u, err := neturl.Parse(location)
+	return err == nil && u.Host == ""
+}
+
+// readFS reads the file of the given location from the file system being parsed, see ParseFS.
+// The metadata holds the size of the file and the duration of the read. The returned error is a *FetchError.
+func (s *S) readFS(location string) ([]byte, FetchMeta, error) {
+	var meta FetchMeta
+
+	u, err := neturl.Parse(location)
+	if err != nil {
+		return nil, meta, &FetchError{URL: location, Err: err}
+	}
+	path := strings.TrimPrefix(u.Path, "/")
+	if !fs.ValidPath(path) {
+		return nil, meta, &FetchError{URL: location, Err: fmt.Errorf("invalid path %q: %w", path, fs.ErrInvalid)}
+	}
+
+	start := time.Now()
+	content, err := fs.ReadFile(s.fsys, path)
+	meta.Duration = time.Since(start)
+	if err != nil {
+		return nil, meta, &FetchError{URL: location, Err: err}
+	}
+	meta.CompressedBytes = int64(len(content))
+	meta.DecompressedBytes = meta.CompressedBytes
+
+	return content, meta, nil
+}
+
+// resolveFSLocs resolves the relative locations of the given sitemaps of the index at the location of a file of a file system in place,
+// against the location of the index, see ParseFS.
+func resolveFSLocs(location string, sitemaps []IndexSitemap) {
+	base, err := neturl.Parse(location)
+	if err != nil {
+		return
+	}
+	for i := range sitemaps {
+		ref, err := neturl.Parse(sitemaps[i].Loc)
+		if err != nil || ref.IsAbs() || ref.Host != "" || sitemaps[i].Loc == "" {
+			continue
+		}
+		sitemaps[i].Loc = base.ResolveReference(ref).String()
+	}
+}
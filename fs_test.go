@@ -0,0 +1,148 @@
+package sitemap
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+//go:embed test
+var fixtures embed.FS
+
+func TestS_ParseFS(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	mapFS := fstest.MapFS{
+		"index-remote.xml": &fstest.MapFile{Data: []byte(fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+    <sitemap><loc>%s/sitemap-01.xml</loc></sitemap>
+    <sitemap><loc>local.xml</loc></sitemap>
+</sitemapindex>`, server.URL))},
+		"local.xml":         &fstest.MapFile{Data: []byte(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><url><loc>https://www.example.com/local</loc></url></urlset>`)},
+		"index-missing.xml": &fstest.MapFile{Data: []byte(`<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><sitemap><loc>missing.xml</loc></sitemap></sitemapindex>`)},
+	}
+
+	tests := []struct {
+		name          string
+		fsys          fs.FS
+		path          string
+		wantLocs      []string
+		wantSitemaps  []string
+		wantErrs      int
+		wantNotExist  bool
+		wantInvalid   bool
+		wantParseFail bool
+	}{
+		{
+			name:     "sitemap",
+			fsys:     fixtures,
+			path:     "test/sitemap-01.xml",
+			wantLocs: []string{"http://HOST/page-01"},
+		},
+		{
+			name:     "gzip detected by content",
+			fsys:     fixtures,
+			path:     "test/sitemap-02.xml.gz",
+			wantLocs: []string{"http://HOST/page-02", "http://HOST/page-03"},
+		},
+		{
+			name: "index with relative locations",
+			fsys: fixtures,
+			path: "test/sitemapindex-relative.xml",
+			wantLocs: []string{
+				"http://HOST/page-01",
+				"http://HOST/page-02",
+				"http://HOST/page-03",
+				"http://HOST/page-04",
+				"http://HOST/page-05",
+				"http://HOST/page-06",
+			},
+			wantSitemaps: []string{
+				"fs:///test/sitemapindex-relative.xml",
+				"fs:///test/sitemap-01.xml",
+				"fs:///test/sitemap-02.xml.gz",
+				"fs:///test/sitemap-03.xml",
+			},
+		},
+		{
+			name:     "index with an absolute location",
+			fsys:     mapFS,
+			path:     "index-remote.xml",
+			wantLocs: []string{"http://" + server.Listener.Addr().String() + "/page-01", "https://www.example.com/local"},
+		},
+		{
+			name:         "missing sitemap",
+			wantLocs:     []string{},
+			fsys:         mapFS,
+			path:         "index-missing.xml",
+			wantErrs:     1,
+			wantNotExist: true,
+		},
+		{
+			name:          "missing main document",
+			wantLocs:      []string{},
+			fsys:          mapFS,
+			path:          "missing.xml",
+			wantErrs:      1,
+			wantNotExist:  true,
+			wantParseFail: true,
+		},
+		{
+			name:          "invalid path",
+			wantLocs:      []string{},
+			fsys:          mapFS,
+			path:          "/index-missing.xml",
+			wantErrs:      1,
+			wantInvalid:   true,
+			wantParseFail: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := New().ParseFS(test.fsys, test.path)
+			if (err != nil) != test.wantParseFail {
+				t.Fatalf("expected error %v, got %v", test.wantParseFail, err)
+			}
+			if got := sortedLocs(s.GetURLs()); !reflect.DeepEqual(got, test.wantLocs) {
+				t.Errorf("expected %v, got %v", test.wantLocs, got)
+			}
+			if test.wantSitemaps != nil {
+				if got := s.GetSitemapLocations(); !reflect.DeepEqual(got, test.wantSitemaps) {
+					t.Errorf("expected sitemaps %v, got %v", test.wantSitemaps, got)
+				}
+			}
+
+			errs := s.GetErrors()
+			if len(errs) != test.wantErrs {
+				t.Fatalf("expected %d errors, got %v", test.wantErrs, errs)
+			}
+			if test.wantNotExist {
+				var fetchErr *FetchError
+				if !errors.As(errs[0], &fetchErr) || !errors.Is(errs[0], fs.ErrNotExist) {
+					t.Errorf("expected a *FetchError wrapping %v, got %v", fs.ErrNotExist, errs[0])
+				}
+			}
+			if test.wantInvalid && !errors.Is(errs[0], fs.ErrInvalid) {
+				t.Errorf("expected %v, got %v", fs.ErrInvalid, errs[0])
+			}
+		})
+	}
+}
+
+func TestS_ParseFS_NotKept(t *testing.T) {
+	s, err := New().ParseFS(fixtures, "test/sitemap-01.xml")
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if s.fsys != nil {
+		t.Errorf("expected the file system to be released after the parse")
+	}
+	if err := s.validateSitemapURL("fs:///test/sitemap-01.xml"); err == nil {
+		t.Errorf("expected the fs: locations to be invalid outside of ParseFS")
+	}
+}
@@ -0,0 +1,122 @@
+package sitemap
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const (
+	// InvalidHostKey is the key under which URLs with an unparseable location or without a host are grouped.
+	InvalidHostKey = "invalid"
+)
+
+// GetURLCountsByHost returns the number of parsed URLs per host, including the locations stored in loc-only mode, see SetLocOnly.
+// The hosts are extracted from the Loc values using net/url and lowercased,
+// URLs with an unparseable Loc or without a host are counted under the InvalidHostKey key.
+// If the S object is nil, an empty map is returned.
+func (s *S) GetURLCountsByHost() map[string]int64 {
+	counts := map[string]int64{}
+	if s == nil {
+		return counts
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, loc := range s.locs {
+		counts[hostKey(loc)]++
+	}
+	for _, u := range s.urls {
+		counts[hostKey(u.Loc)]++
+	}
+
+	return counts
+}
+
+// GetURLsGroupedByHost returns the parsed URLs grouped by host, in their order within each host.
+// The hosts are extracted from the Loc values using net/url and lowercased,
+// URLs with an unparseable Loc or without a host are grouped under the InvalidHostKey key.
+// The slices are fresh copies. In loc-only mode, see SetLocOnly, an empty map is returned, as there are no URLs.
+// If the S object is nil, an empty map is returned.
+func (s *S) GetURLsGroupedByHost() map[string][]URL {
+	groups := map[string][]URL{}
+	if s == nil {
+		return groups
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.urls {
+		host := hostKey(u.Loc)
+		groups[host] = append(groups[host], u)
+	}
+
+	return groups
+}
+
+// GetHosts returns the sorted list of distinct hosts of the parsed URLs, including the locations stored in loc-only mode, see SetLocOnly.
+// The hosts are lowercased, URLs with an unparseable Loc or without a host are skipped.
+// The returned slice is a fresh copy. If the S object is nil, an empty slice is returned.
+func (s *S) GetHosts() []string {
+	if s == nil {
+		return []string{}
+	}
+	s.mu.Lock()
+	locs := make([]string, 0, len(s.locs)+len(s.urls))
+	locs = append(locs, s.locs...)
+	for _, u := range s.urls {
+		locs = append(locs, u.Loc)
+	}
+	s.mu.Unlock()
+
+	return distinctHosts(locs)
+}
+
+// GetSitemapHosts returns the sorted list of distinct hosts of the sitemap locations.
+// The hosts are lowercased, unparseable locations or locations without a host are skipped.
+// The returned slice is a fresh copy. If the S object is nil, an empty slice is returned.
+func (s *S) GetSitemapHosts() []string {
+	if s == nil {
+		return []string{}
+	}
+	s.mu.Lock()
+	locs := append([]string(nil), s.sitemapLocations...)
+	s.mu.Unlock()
+
+	return distinctHosts(locs)
+}
+
+// distinctHosts returns the sorted list of distinct hosts of the given locations.
+func distinctHosts(locs []string) []string {
+	seen := map[string]bool{}
+	hosts := []string{}
+	for _, loc := range locs {
+		host, ok := hostOf(loc)
+		if !ok || seen[host] {
+			continue
+		}
+		seen[host] = true
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// hostKey returns the host of the given location as returned by hostOf, or InvalidHostKey if it has none.
+func hostKey(loc string) string {
+	host, ok := hostOf(loc)
+	if !ok {
+		return InvalidHostKey
+	}
+	return host
+}
+
+// hostOf returns the lowercased host (including the port, if any) of the given location.
+// The second return value is false if the location can not be parsed or has no host.
+func hostOf(loc string) (string, bool) {
+	parsedURL, err := url.Parse(loc)
+	if err != nil || parsedURL.Host == "" {
+		return "", false
+	}
+	return strings.ToLower(parsedURL.Host), true
+}
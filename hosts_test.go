@@ -0,0 +1,247 @@
+package sitemap
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestS_GetURLCountsByHost(t *testing.T) {
+	tests := []struct {
+		name string
+		s    *S
+		want map[string]int64
+	}{
+		{
+			name: "nil receiver",
+			s:    nil,
+			want: map[string]int64{},
+		},
+		{
+			name: "no URLs",
+			s:    &S{},
+			want: map[string]int64{},
+		},
+		{
+			name: "multiple hosts",
+			s: &S{urls: []URL{
+				{Loc: "https://www.sitemaps.org/1"},
+				{Loc: "https://WWW.Sitemaps.org/2"},
+				{Loc: "https://staging.sitemaps.org:8080/1"},
+				{Loc: "https://www.sitemaps.org/%zz"},
+				{Loc: "/relative"},
+			}},
+			want: map[string]int64{
+				"www.sitemaps.org":          2,
+				"staging.sitemaps.org:8080": 1,
+				InvalidHostKey:              2,
+			},
+		},
+		{
+			name: "loc-only mode",
+			s: &S{locs: []string{
+				"https://www.sitemaps.org/1",
+				"https://WWW.Sitemaps.org/2",
+				"https://staging.sitemaps.org:8080/1",
+				"/relative",
+			}},
+			want: map[string]int64{
+				"www.sitemaps.org":          2,
+				"staging.sitemaps.org:8080": 1,
+				InvalidHostKey:              1,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.s.GetURLCountsByHost()
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestS_GetHosts(t *testing.T) {
+	tests := []struct {
+		name string
+		s    *S
+		want []string
+	}{
+		{
+			name: "nil receiver",
+			s:    nil,
+			want: []string{},
+		},
+		{
+			name: "no URLs",
+			s:    &S{},
+			want: []string{},
+		},
+		{
+			name: "multiple hosts",
+			s: &S{urls: []URL{
+				{Loc: "https://www.sitemaps.org/1"},
+				{Loc: "https://WWW.Sitemaps.org/2"},
+				{Loc: "https://blog.sitemaps.org/1"},
+				{Loc: "https://www.sitemaps.org/%zz"},
+				{Loc: "/relative"},
+			}},
+			want: []string{"blog.sitemaps.org", "www.sitemaps.org"},
+		},
+		{
+			name: "loc-only mode",
+			s: &S{locs: []string{
+				"https://www.sitemaps.org/1",
+				"https://BLOG.sitemaps.org/1",
+				"/relative",
+			}},
+			want: []string{"blog.sitemaps.org", "www.sitemaps.org"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.s.GetHosts()
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestS_GetSitemapHosts(t *testing.T) {
+	tests := []struct {
+		name string
+		s    *S
+		want []string
+	}{
+		{
+			name: "nil receiver",
+			s:    nil,
+			want: []string{},
+		},
+		{
+			name: "no sitemap locations",
+			s:    &S{},
+			want: []string{},
+		},
+		{
+			name: "multiple hosts",
+			s: &S{sitemapLocations: []string{
+				"https://www.sitemaps.org/sitemapindex.xml",
+				"https://CDN.sitemaps.org/sitemap-01.xml",
+				"https://www.sitemaps.org/sitemap-02.xml",
+			}},
+			want: []string{"cdn.sitemaps.org", "www.sitemaps.org"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.s.GetSitemapHosts()
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestS_GetURLsGroupedByHost(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	s, err := New().Parse(server.URL+"/sitemap-multiple-hosts.xml", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverHost := strings.TrimPrefix(server.URL, "http://")
+
+	want := map[string][]string{
+		serverHost:              {server.URL + "/page-01", server.URL + "/page-02"},
+		"www.example.com":       {"https://www.example.com/page-01", "https://WWW.Example.com/page-02"},
+		"shop.example.com:8443": {"https://shop.example.com:8443/page-01"},
+		InvalidHostKey:          {"/relative-page", "https://www.example.com/%zz"},
+	}
+
+	groups := s.GetURLsGroupedByHost()
+	got := map[string][]string{}
+	for host, urls := range groups {
+		got[host] = locsOf(urls)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	// the groups are copies
+	groups["www.example.com"][0].Loc = "changed"
+	if s.GetURLsGroupedByHost()["www.example.com"][0].Loc != "https://www.example.com/page-01" {
+		t.Error("expected the groups to be copies")
+	}
+	if s.GetURLs()[1].Loc != "https://www.example.com/page-01" {
+		t.Error("expected the parsed URLs to be unchanged")
+	}
+
+	var nilS *S
+	if got := nilS.GetURLsGroupedByHost(); got == nil || len(got) != 0 {
+		t.Errorf("expected an empty map, got %v", got)
+	}
+}
+
+func TestS_GetURLCountsByHost_DuringParse(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	s := New()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = s.Parse(server.URL+"/robots-with-sitemapindex/robots.txt", nil)
+	}()
+	for parsing := true; parsing; {
+		select {
+		case <-done:
+			parsing = false
+		default:
+		}
+		s.GetURLCountsByHost()
+	}
+
+	var total int64
+	for _, count := range s.GetURLCountsByHost() {
+		total += count
+	}
+	if total != s.GetURLCount() {
+		t.Errorf("expected %d URLs, got %d", s.GetURLCount(), total)
+	}
+}
+
+func TestS_GetHosts_DuringParse(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	s := New()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = s.Parse(server.URL+"/robots-with-sitemapindex/robots.txt", nil)
+	}()
+	for parsing := true; parsing; {
+		select {
+		case <-done:
+			parsing = false
+		default:
+		}
+		s.GetHosts()
+		s.GetSitemapHosts()
+	}
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	if got := s.GetHosts(); !reflect.DeepEqual(got, []string{host}) {
+		t.Errorf("expected %v, got %v", []string{host}, got)
+	}
+	if got := s.GetSitemapHosts(); !reflect.DeepEqual(got, []string{host}) {
+		t.Errorf("expected %v, got %v", []string{host}, got)
+	}
+}
@@ -0,0 +1,56 @@
+package sitemap
+
+import "strings"
+
+// GetAlternatesByLanguage returns the parsed URLs declaring an <xhtml:link rel="alternate"> for the given hreflang value.
+// The hreflang values are compared case-insensitively, "x-default" matches the default alternates.
+// If the S object is nil or no URL matches, an empty slice is returned.
+func (s *S) GetAlternatesByLanguage(lang string) []URL {
+	urls := []URL{}
+	if s == nil {
+		return urls
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.urls {
+		if hasAlternate(u, lang) {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// GetMissingAlternates returns the parsed URLs missing an <xhtml:link rel="alternate"> for any of the required hreflang values.
+// The hreflang values are compared case-insensitively, "x-default" can be required like any language.
+// If the S object is nil, no languages are required or no URL misses any, an empty slice is returned.
+func (s *S) GetMissingAlternates(requiredLangs []string) []URL {
+	urls := []URL{}
+	if s == nil || len(requiredLangs) == 0 {
+		return urls
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.urls {
+		for _, lang := range requiredLangs {
+			if !hasAlternate(u, lang) {
+				urls = append(urls, u)
+				break
+			}
+		}
+	}
+	return urls
+}
+
+// hasAlternate reports whether the URL declares an alternate link for the given hreflang value.
+func hasAlternate(u URL, lang string) bool {
+	lang = strings.TrimSpace(lang)
+	for _, alternate := range u.Alternates {
+		if strings.EqualFold(strings.TrimSpace(alternate.Rel), "alternate") &&
+			strings.EqualFold(strings.TrimSpace(alternate.Hreflang), lang) {
+			return true
+		}
+	}
+	return false
+}
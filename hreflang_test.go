@@ -0,0 +1,134 @@
+package sitemap
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestS_GetAlternatesByLanguage(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	s, err := New().Parse(fmt.Sprintf("%s/sitemap-hreflang.xml", server.URL), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := func(name string) string {
+		return fmt.Sprintf("%s/en/%s", server.URL, name)
+	}
+
+	tests := []struct {
+		name string
+		s    *S
+		lang string
+		want []string
+	}{
+		{
+			name: "nil receiver",
+			s:    nil,
+			lang: "en",
+			want: []string{},
+		},
+		{
+			name: "case-insensitive",
+			s:    s,
+			lang: "en",
+			want: []string{page("page-01"), page("page-02")},
+		},
+		{
+			name: "region",
+			s:    s,
+			lang: "DE-at",
+			want: []string{page("page-02")},
+		},
+		{
+			name: "not rel alternate",
+			s:    s,
+			lang: "de",
+			want: []string{page("page-01")},
+		},
+		{
+			name: "x-default",
+			s:    s,
+			lang: "x-default",
+			want: []string{page("page-01"), page("page-03")},
+		},
+		{
+			name: "no match",
+			s:    s,
+			lang: "fr",
+			want: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := locsOf(test.s.GetAlternatesByLanguage(test.lang)); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestS_GetMissingAlternates(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	s, err := New().Parse(fmt.Sprintf("%s/sitemap-hreflang.xml", server.URL), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := func(name string) string {
+		return fmt.Sprintf("%s/en/%s", server.URL, name)
+	}
+
+	tests := []struct {
+		name          string
+		s             *S
+		requiredLangs []string
+		want          []string
+	}{
+		{
+			name:          "nil receiver",
+			s:             nil,
+			requiredLangs: []string{"en"},
+			want:          []string{},
+		},
+		{
+			name:          "no required languages",
+			s:             s,
+			requiredLangs: nil,
+			want:          []string{},
+		},
+		{
+			name:          "en and de",
+			s:             s,
+			requiredLangs: []string{"EN", "de"},
+			want:          []string{page("page-02"), page("page-03"), page("page-04")},
+		},
+		{
+			name:          "x-default",
+			s:             s,
+			requiredLangs: []string{"X-DEFAULT"},
+			want:          []string{page("page-02"), page("page-04")},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := locsOf(test.s.GetMissingAlternates(test.requiredLangs)); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+// locsOf returns the Loc values of the given URLs.
+func locsOf(urls []URL) []string {
+	locs := make([]string, 0, len(urls))
+	for _, u := range urls {
+		locs = append(locs, u.Loc)
+	}
+	return locs
+}
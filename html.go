@@ -0,0 +1,181 @@
+package sitemap
+
+import (
+	"html"
+	neturl "net/url"
+	"strings"
+)
+
+// SetDiscoverFromHTML sets whether the sitemaps linked from an HTML page are parsed when the main URL returns one,
+// for the sites advertising their sitemaps only with a <link rel="sitemap" href="..."> element on their homepage.
+// The hrefs of the <link> elements whose rel attribute contains the "sitemap" token are resolved against the main URL,
+// rewritten by the sitemap URL rewriter (see SetSitemapURLRewriter), and parsed like the sitemaps listed in a robots.txt file.
+// The root node of the sitemap tree then has the SitemapKindHTML kind. A page linking to no sitemap is reported like any other invalid content.
+// The page is scanned with a lightweight tokenizer, looking at the <link> elements only, the other hints of a page (e.g. footer links) are not used.
+// By default, it is off, and an HTML page is reported as invalid content.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetDiscoverFromHTML(discover bool) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.discoverFromHTML = discover
+
+	return s
+}
+
+// parseHTML records the sitemaps linked from the HTML page of the main URL as the children of the root node of the sitemap tree.
+// It returns the locations of the sitemaps, nil if the page links to none.
+func (s *S) parseHTML(content string) []string {
+	links := extractHTMLSitemapLinks(content, s.mainURL)
+	var locations []string
+	for _, loc := range links {
+		if loc = s.rewriteSitemapLoc(loc); loc != "" {
+			locations = append(locations, loc)
+		}
+	}
+	if len(locations) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tree.Kind = SitemapKindHTML
+	s.tree.fetched = true
+	for _, loc := range locations {
+		s.addChildNode(s.tree, loc, nil)
+	}
+	s.traceDocument(s.mainURL, content, len(links), len(locations))
+	return locations
+}
+
+// isHTML reports whether the content is an HTML page, i.e. it starts with an HTML doctype or an <html> element.
+func isHTML(content string) bool {
+	content = strings.TrimLeft(strings.TrimPrefix(content, "\ufeff"), " \t\r\n")
+	return hasPrefixFold(content, "<!doctype html") || hasPrefixFold(content, "<html")
+}
+
+// extractHTMLSitemapLinks returns the distinct hrefs of the <link rel="sitemap"> elements of the HTML page, resolved against baseURL.
+// The elements in comments and the hrefs not resolving to an http or https URL are ignored.
+func extractHTMLSitemapLinks(content string, baseURL string) []string {
+	base, err := neturl.Parse(baseURL)
+	if err != nil {
+		return nil
+	}
+
+	var links []string
+	seen := map[string]bool{}
+	for i := 0; i < len(content); {
+		next := strings.IndexByte(content[i:], '<')
+		if next < 0 {
+			break
+		}
+		i += next
+		switch {
+		case strings.HasPrefix(content[i:], "<!--"):
+			end := strings.Index(content[i+4:], "-->")
+			if end < 0 {
+				return links
+			}
+			i += 4 + end + 3
+		case hasPrefixFold(content[i:], "<link") && len(content) > i+5 && isHTMLSpace(content[i+5]):
+			attrs, end := htmlAttributes(content[i+5:])
+			if end < 0 {
+				// the tag is unterminated, like the page
+				return links
+			}
+			i += 5 + end
+			if !hasHTMLToken(attrs["rel"], "sitemap") || attrs["href"] == "" {
+				continue
+			}
+			ref, err := neturl.Parse(strings.TrimSpace(attrs["href"]))
+			if err != nil {
+				continue
+			}
+			link := base.ResolveReference(ref)
+			if (link.Scheme != "http" && link.Scheme != "https") || link.Host == "" || seen[link.String()] {
+				continue
+			}
+			seen[link.String()] = true
+			links = append(links, link.String())
+		default:
+			i++
+		}
+	}
+	return links
+}
+
+// htmlAttributes returns the attributes of the tag starting at the beginning of the given content, after the tag name,
+// and the length of the tag up to and including its closing ">", -1 if the tag is not closed.
+// The names are lowercased and the values unescaped, the first occurrence of a repeated attribute is kept.
+func htmlAttributes(tag string) (map[string]string, int) {
+	attrs := map[string]string{}
+	i := 0
+	for i < len(tag) {
+		for i < len(tag) && (isHTMLSpace(tag[i]) || tag[i] == '/') {
+			i++
+		}
+		if i == len(tag) {
+			break
+		}
+		if tag[i] == '>' {
+			return attrs, i + 1
+		}
+
+		start := i
+		for i < len(tag) && !isHTMLSpace(tag[i]) && tag[i] != '=' && tag[i] != '>' && tag[i] != '/' {
+			i++
+		}
+		name := strings.ToLower(tag[start:i])
+		for i < len(tag) && isHTMLSpace(tag[i]) {
+			i++
+		}
+		var value string
+		if i < len(tag) && tag[i] == '=' {
+			i++
+			for i < len(tag) && isHTMLSpace(tag[i]) {
+				i++
+			}
+			if i < len(tag) && (tag[i] == '"' || tag[i] == '\'') {
+				quote := tag[i]
+				i++
+				end := strings.IndexByte(tag[i:], quote)
+				if end < 0 {
+					end = len(tag) - i
+				}
+				value = tag[i : i+end]
+				i += end + 1
+			} else {
+				start = i
+				for i < len(tag) && !isHTMLSpace(tag[i]) && tag[i] != '>' {
+					i++
+				}
+				value = tag[start:i]
+			}
+		}
+		if _, ok := attrs[name]; !ok && name != "" {
+			attrs[name] = html.UnescapeString(value)
+		}
+	}
+	return attrs, -1
+}
+
+// hasHTMLToken reports whether the space-separated list of tokens of an attribute (e.g. rel) contains the token, ignoring case.
+func hasHTMLToken(list string, token string) bool {
+	for _, t := range strings.FieldsFunc(list, func(r rune) bool { return r < 0x80 && isHTMLSpace(byte(r)) }) {
+		if strings.EqualFold(t, token) {
+			return true
+		}
+	}
+	return false
+}
+
+// isHTMLSpace reports whether the byte is an ASCII whitespace character of HTML.
+func isHTMLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// hasPrefixFold reports whether the string starts with the ASCII prefix, ignoring case.
+func hasPrefixFold(s string, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
@@ -0,0 +1,164 @@
+package sitemap
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestS_SetDiscoverFromHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		discover bool
+	}{
+		{name: "enabled", discover: true},
+		{name: "disabled", discover: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New().SetDiscoverFromHTML(test.discover)
+			if s.cfg.discoverFromHTML != test.discover {
+				t.Errorf("expected %v, got %v", test.discover, s.cfg.discoverFromHTML)
+			}
+		})
+	}
+}
+
+func TestS_Parse_DiscoverFromHTML(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	robots, err := New().Parse(fmt.Sprintf("%s/robots-with-sitemapindex/robots.txt", server.URL), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantURLs := sortedLocs(robots.GetURLs())
+
+	tests := []struct {
+		name        string
+		url         string
+		discover    bool
+		multiThread bool
+		wantKind    SitemapKind
+		wantURLs    []string
+		wantErrs    int
+	}{
+		{
+			name:        "page with sitemap link",
+			url:         fmt.Sprintf("%s/page-with-sitemap-link.html", server.URL),
+			discover:    true,
+			multiThread: true,
+			wantKind:    SitemapKindHTML,
+			wantURLs:    wantURLs,
+		},
+		{
+			name:     "page with sitemap link, single thread",
+			url:      fmt.Sprintf("%s/page-with-sitemap-link.html", server.URL),
+			discover: true,
+			wantKind: SitemapKindHTML,
+			wantURLs: wantURLs,
+		},
+		{
+			name:     "page with sitemap link, discovery off",
+			url:      fmt.Sprintf("%s/page-with-sitemap-link.html", server.URL),
+			wantKind: SitemapKindUnknown,
+			wantErrs: 1,
+		},
+		{
+			name:     "page without sitemap link",
+			url:      fmt.Sprintf("%s/page-without-sitemap-link.html", server.URL),
+			discover: true,
+			wantKind: SitemapKindUnknown,
+			wantErrs: 1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, _ := New().SetDiscoverFromHTML(test.discover).SetMultiThread(test.multiThread).Parse(test.url, nil)
+			if got := s.GetErrorsCount(); got != int64(test.wantErrs) {
+				t.Errorf("expected %d errors, got %d: %v", test.wantErrs, got, s.GetErrors())
+			}
+			tree := s.GetSitemapTree()
+			if tree.Kind != test.wantKind {
+				t.Errorf("expected the kind %q, got %q", test.wantKind, tree.Kind)
+			}
+			if got := sortedLocs(s.GetURLs()); !reflect.DeepEqual(got, test.wantURLs) && len(got)+len(test.wantURLs) > 0 {
+				t.Errorf("expected %v, got %v", test.wantURLs, got)
+			}
+			if test.wantKind == SitemapKindHTML {
+				if len(tree.Children) != 1 || tree.Children[0].Loc != fmt.Sprintf("%s/sitemapindex-1.xml", server.URL) {
+					t.Errorf("expected the linked sitemap index as the only child, got %v", tree.Children)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractHTMLSitemapLinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "relative href",
+			content: `<html><head><link rel="sitemap" href="/sitemap.xml"></head></html>`,
+			want:    []string{"https://www.example.com/sitemap.xml"},
+		},
+		{
+			name:    "absolute and scheme-relative hrefs",
+			content: `<link rel="sitemap" href="https://cdn.example.com/sitemap.xml"><link rel="sitemap" href="//www.example.com/news.xml">`,
+			want:    []string{"https://cdn.example.com/sitemap.xml", "https://www.example.com/news.xml"},
+		},
+		{
+			name:    "case, token list, quotes and entities",
+			content: "<LINK\n  HREF='/a.xml?x=1&amp;y=2' REL=\"alternate Sitemap\" /><link rel=sitemap href=/b.xml>",
+			want:    []string{"https://www.example.com/a.xml?x=1&y=2", "https://www.example.com/b.xml"},
+		},
+		{
+			name:    "duplicates",
+			content: `<link rel="sitemap" href="/sitemap.xml"><link rel="sitemap" href="https://www.example.com/sitemap.xml">`,
+			want:    []string{"https://www.example.com/sitemap.xml"},
+		},
+		{
+			name:    "ignored",
+			content: `<!-- <link rel="sitemap" href="/old.xml"> --><link rel="stylesheet" href="/style.css"><link rel="sitemaps" href="/x.xml"><linked rel="sitemap" href="/y.xml"><link rel="sitemap" href="javascript:void(0)"><link rel="sitemap"><a rel="sitemap" href="/z.xml">`,
+			want:    nil,
+		},
+		{
+			name:    "unterminated",
+			content: `<link rel="sitemap" href="/sitemap.xml`,
+			want:    nil,
+		},
+		{
+			name:    "unterminated comment",
+			content: `<link rel="sitemap" href="/sitemap.xml"><!-- <link rel="sitemap" href="/old.xml">`,
+			want:    []string{"https://www.example.com/sitemap.xml"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := extractHTMLSitemapLinks(test.content, "https://www.example.com/index.html"); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestIsHTML(t *testing.T) {
+	tests := []struct {
+		content string
+		want    bool
+	}{
+		{content: "<!DOCTYPE html>\n<html></html>", want: true},
+		{content: "\ufeff\n  <HTML lang=\"en\">", want: true},
+		{content: "<?xml version=\"1.0\"?><urlset></urlset>", want: false},
+		{content: "User-agent: *", want: false},
+		{content: "", want: false},
+	}
+	for _, test := range tests {
+		if got := isHTML(test.content); got != test.want {
+			t.Errorf("%q: expected %v, got %v", test.content, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package sitemap
+
+import (
+	"strings"
+)
+
+// SetUpgradeToHTTPS sets whether the http locations are fetched over https, e.g. for old sitemap indexes of an HTTPS-only site
+// whose http locations redirect or fail. Every http location fetched, including the main URL passed to Parse, is requested with
+// the https scheme first; if that request fails without a response (e.g. the connection is refused or the TLS handshake fails),
+// the original http location is requested instead. The fetch metadata records the upgrade or the fallback, see GetFetchMetadata.
+// The locations are recorded unchanged, see SetUpgradeLocsToHTTPS for the stored URLs. By default, the locations are fetched as they are.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetUpgradeToHTTPS(upgrade bool) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.upgradeToHTTPS = upgrade
+
+	return s
+}
+
+// SetUpgradeLocsToHTTPS sets whether the http locations of the URLs decoded from a sitemap are stored with the https scheme.
+// The upgrade is applied before the other rewrites, see SetPunycodeHosts, SetHostRewrite and SetURLRewriter.
+// By default, the locations are stored as they are.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetUpgradeLocsToHTTPS(upgrade bool) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.upgradeLocsToHTTPS = upgrade
+
+	return s
+}
+
+// isHTTP reports whether the location has the http scheme, case-insensitively.
+func isHTTP(loc string) bool {
+	return len(loc) >= len("http://") && strings.EqualFold(loc[:len("http://")], "http://")
+}
+
+// upgradeToHTTPS returns the location with the https scheme if it has the http scheme, otherwise it is returned unchanged.
+func upgradeToHTTPS(loc string) string {
+	if !isHTTP(loc) {
+		return loc
+	}
+	return "https" + loc[len("http"):]
+}
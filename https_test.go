@@ -0,0 +1,141 @@
+package sitemap
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestS_SetUpgradeToHTTPS(t *testing.T) {
+	tests := []struct {
+		name    string
+		upgrade bool
+	}{
+		{name: "enabled", upgrade: true},
+		{name: "disabled", upgrade: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New().SetUpgradeToHTTPS(test.upgrade).SetUpgradeLocsToHTTPS(test.upgrade)
+			if s.cfg.upgradeToHTTPS != test.upgrade || s.cfg.upgradeLocsToHTTPS != test.upgrade {
+				t.Errorf("expected %v, got %v and %v", test.upgrade, s.cfg.upgradeToHTTPS, s.cfg.upgradeLocsToHTTPS)
+			}
+		})
+	}
+}
+
+func Test_upgradeToHTTPS(t *testing.T) {
+	tests := []struct {
+		loc  string
+		want string
+	}{
+		{loc: "http://example.com/sitemap.xml", want: "https://example.com/sitemap.xml"},
+		{loc: "HTTP://example.com/sitemap.xml", want: "https://example.com/sitemap.xml"},
+		{loc: "https://example.com/sitemap.xml", want: "https://example.com/sitemap.xml"},
+		{loc: "ftp://example.com/sitemap.xml", want: "ftp://example.com/sitemap.xml"},
+		{loc: "/sitemap.xml", want: "/sitemap.xml"},
+		{loc: "http:", want: "http:"},
+	}
+	for _, test := range tests {
+		t.Run(test.loc, func(t *testing.T) {
+			if got := upgradeToHTTPS(test.loc); got != test.want {
+				t.Errorf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestS_Parse_UpgradeToHTTPS(t *testing.T) {
+	plain := testServer()
+	defer plain.Close()
+	// the sitemap index served over TLS references its sitemaps with http locations on the same address
+	tlsServer := httptest.NewTLSServer(plain.Config.Handler)
+	defer tlsServer.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(tlsServer.Certificate())
+	tlsConfig := &tls.Config{RootCAs: roots}
+
+	tests := []struct {
+		name          string
+		s             *S
+		url           string
+		wantErrs      int
+		wantURLs      int
+		wantUpgrade   bool
+		wantFallback  bool
+		wantLocPrefix string
+	}{
+		{
+			name:     "not upgraded",
+			s:        New().SetTLSConfig(tlsConfig),
+			url:      tlsServer.URL + "/sitemapindex-1.xml",
+			wantErrs: 3,
+		},
+		{
+			name:          "upgraded",
+			s:             New().SetTLSConfig(tlsConfig).SetUpgradeToHTTPS(true),
+			url:           tlsServer.URL + "/sitemapindex-1.xml",
+			wantURLs:      6,
+			wantUpgrade:   true,
+			wantLocPrefix: "http://",
+		},
+		{
+			name:          "upgraded with locations",
+			s:             New().SetTLSConfig(tlsConfig).SetUpgradeToHTTPS(true).SetUpgradeLocsToHTTPS(true),
+			url:           tlsServer.URL + "/sitemapindex-1.xml",
+			wantURLs:      6,
+			wantUpgrade:   true,
+			wantLocPrefix: "https://",
+		},
+		{
+			name:          "fallback",
+			s:             New().SetUpgradeToHTTPS(true),
+			url:           plain.URL + "/sitemapindex-1.xml",
+			wantURLs:      6,
+			wantFallback:  true,
+			wantLocPrefix: "http://",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := test.s.Parse(test.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if errs := s.GetErrors(); len(errs) != test.wantErrs {
+				t.Fatalf("expected %d errors, got %v", test.wantErrs, errs)
+			}
+			if got := len(s.GetURLs()); got != test.wantURLs {
+				t.Errorf("expected %d URLs, got %d", test.wantURLs, got)
+			}
+			for _, loc := range s.GetLocs() {
+				if !strings.HasPrefix(loc, test.wantLocPrefix) {
+					t.Errorf("expected %s to start with %s", loc, test.wantLocPrefix)
+				}
+			}
+
+			// the sitemaps are recorded with their http locations
+			metadata := s.GetFetchMetadata()
+			sitemaps := s.GetSitemapLocations()[1:]
+			if len(sitemaps) != 3 {
+				t.Fatalf("expected 3 sitemaps, got %v", sitemaps)
+			}
+			for _, loc := range sitemaps {
+				if !strings.HasPrefix(loc, "http://") {
+					t.Errorf("expected %s to be recorded unchanged", loc)
+				}
+				meta, ok := metadata[loc]
+				if !ok {
+					t.Fatalf("%s: missing metadata", loc)
+				}
+				if meta.HTTPSUpgrade != test.wantUpgrade || meta.HTTPSFallback != test.wantFallback {
+					t.Errorf("%s: expected upgrade %v and fallback %v, got %+v", loc, test.wantUpgrade, test.wantFallback, meta)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package sitemap
+
+import (
+	neturl "net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// SetPunycodeHosts sets whether the hosts of the stored URLs are normalized to their ASCII (punycode) form,
+// e.g. "münchen.example" to "xn--mnchen-3ya.example", so that the Unicode and the punycode form of the same host
+// are deduplicated and grouped together. The hosts are lowercased as well.
+// The normalization is applied before the host rewrite (see SetHostRewrite) and the URL rewriter (see SetURLRewriter).
+// The locations fetched are not affected. By default, the hosts are stored as they are.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetPunycodeHosts(punycodeHosts bool) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.punycodeHosts = punycodeHosts
+
+	return s
+}
+
+// punycodeLoc returns the given location with its host converted to the ASCII form, see asciiHost.
+// The rest of the location is kept as it is. The location is returned unchanged if it cannot be parsed or has no host.
+func punycodeLoc(loc string) string {
+	u, err := neturl.Parse(loc)
+	if err != nil || u.Host == "" {
+		return loc
+	}
+	host := asciiHost(u.Host)
+	prefix := len(u.Scheme) + len("://")
+	if u.User == nil && len(loc) >= prefix+len(u.Host) && loc[prefix:prefix+len(u.Host)] == u.Host {
+		return loc[:prefix] + host + loc[prefix+len(u.Host):]
+	}
+	u.Host = host
+	return u.String()
+}
+
+// asciiHost returns the lowercased ASCII form of the given host (including the port, if any):
+// each label containing non-ASCII characters is encoded with punycode and prefixed with "xn--".
+// Only lowercasing is applied as the mapping of the labels, not the full IDNA mapping of UTS #46.
+func asciiHost(host string) string {
+	host = strings.ToLower(host)
+	name, port := host, ""
+	if i := strings.LastIndexByte(host, ':'); i >= 0 && i > strings.LastIndexByte(host, ']') {
+		name, port = host[:i], host[i:]
+	}
+	if isASCII(name) {
+		return host
+	}
+
+	labels := strings.Split(name, ".")
+	for i, label := range labels {
+		if !isASCII(label) {
+			labels[i] = "xn--" + punycodeEncode(label)
+		}
+	}
+	return strings.Join(labels, ".") + port
+}
+
+// isASCII reports whether the given string contains ASCII characters only.
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// The parameters of the punycode bootstring encoding, see RFC 3492.
+const (
+	punycodeBase        = 36
+	punycodeTMin        = 1
+	punycodeTMax        = 26
+	punycodeSkew        = 38
+	punycodeDamp        = 700
+	punycodeInitialBias = 72
+	punycodeInitialN    = 128
+)
+
+// punycodeEncode returns the punycode encoding of the given label, without the "xn--" prefix, as specified in RFC 3492.
+func punycodeEncode(label string) string {
+	runes := []rune(label)
+	out := make([]byte, 0, len(label))
+	for _, r := range runes {
+		if r < utf8.RuneSelf {
+			out = append(out, byte(r))
+		}
+	}
+	basic := len(out)
+	handled := basic
+	if basic > 0 {
+		out = append(out, '-')
+	}
+
+	n, delta, bias := rune(punycodeInitialN), 0, punycodeInitialBias
+	for handled < len(runes) {
+		// the smallest code point not handled yet
+		m := rune(utf8.MaxRune)
+		for _, r := range runes {
+			if r >= n && r < m {
+				m = r
+			}
+		}
+		delta += int(m-n) * (handled + 1)
+		n = m
+
+		for _, r := range runes {
+			if r < n {
+				delta++
+			}
+			if r != n {
+				continue
+			}
+			q := delta
+			for k := punycodeBase; ; k += punycodeBase {
+				t := k - bias
+				if t < punycodeTMin {
+					t = punycodeTMin
+				} else if t > punycodeTMax {
+					t = punycodeTMax
+				}
+				if q < t {
+					break
+				}
+				out = append(out, punycodeDigit(t+(q-t)%(punycodeBase-t)))
+				q = (q - t) / (punycodeBase - t)
+			}
+			out = append(out, punycodeDigit(q))
+			bias = punycodeAdapt(delta, handled+1, handled == basic)
+			delta = 0
+			handled++
+		}
+		delta++
+		n++
+	}
+
+	return string(out)
+}
+
+// punycodeAdapt returns the bias of the next code point, see RFC 3492 section 6.1.
+func punycodeAdapt(delta, numPoints int, first bool) int {
+	if first {
+		delta /= punycodeDamp
+	} else {
+		delta /= 2
+	}
+	delta += delta / numPoints
+	k := 0
+	for delta > ((punycodeBase-punycodeTMin)*punycodeTMax)/2 {
+		delta /= punycodeBase - punycodeTMin
+		k += punycodeBase
+	}
+	return k + (punycodeBase-punycodeTMin+1)*delta/(delta+punycodeSkew)
+}
+
+// punycodeDigit returns the basic code point of the given digit value, "a" to "z" for 0 to 25 and "0" to "9" for 26 to 35.
+func punycodeDigit(d int) byte {
+	if d < 26 {
+		return byte('a' + d)
+	}
+	return byte('0' + d - 26)
+}
@@ -0,0 +1,138 @@
+package sitemap
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestS_SetPunycodeHosts(t *testing.T) {
+	tests := []struct {
+		name          string
+		punycodeHosts bool
+	}{
+		{
+			name:          "Punycode",
+			punycodeHosts: true,
+		},
+		{
+			name:          "Unchanged",
+			punycodeHosts: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New().SetPunycodeHosts(test.punycodeHosts)
+			if s.cfg.punycodeHosts != test.punycodeHosts {
+				t.Errorf("expected %v, got %v", test.punycodeHosts, s.cfg.punycodeHosts)
+			}
+		})
+	}
+}
+
+func TestS_Parse_PunycodeHosts(t *testing.T) {
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+    <url><loc>https://münchen.example/page</loc></url>
+    <url><loc>https://xn--mnchen-3ya.example/page</loc></url>
+    <url><loc>https://MÜNCHEN.example/other</loc></url>
+    <url><loc>https://www.example.com/page</loc></url>
+</urlset>`
+
+	tests := []struct {
+		name          string
+		punycodeHosts bool
+		wantLocs      []string
+		wantUnique    int64
+		wantHosts     []string
+	}{
+		{
+			name:          "normalized",
+			punycodeHosts: true,
+			wantLocs: []string{
+				"https://xn--mnchen-3ya.example/page",
+				"https://xn--mnchen-3ya.example/page",
+				"https://xn--mnchen-3ya.example/other",
+				"https://www.example.com/page",
+			},
+			wantUnique: 3,
+			wantHosts:  []string{"www.example.com", "xn--mnchen-3ya.example"},
+		},
+		{
+			name:          "unchanged",
+			punycodeHosts: false,
+			wantLocs: []string{
+				"https://münchen.example/page",
+				"https://xn--mnchen-3ya.example/page",
+				"https://MÜNCHEN.example/other",
+				"https://www.example.com/page",
+			},
+			wantUnique: 4,
+			wantHosts:  []string{"münchen.example", "www.example.com", "xn--mnchen-3ya.example"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := New().SetPunycodeHosts(test.punycodeHosts).Parse("https://www.example.com/sitemap.xml", &content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := locsOf(s.GetURLs()); !reflect.DeepEqual(got, test.wantLocs) {
+				t.Errorf("expected %v, got %v", test.wantLocs, got)
+			}
+			if got := s.GetUniqueURLCount(); got != test.wantUnique {
+				t.Errorf("expected %d unique URLs, got %d", test.wantUnique, got)
+			}
+			if got := s.GetHosts(); !reflect.DeepEqual(got, test.wantHosts) {
+				t.Errorf("expected hosts %v, got %v", test.wantHosts, got)
+			}
+		})
+	}
+}
+
+func TestAsciiHost(t *testing.T) {
+	tests := []struct {
+		name string
+		host string
+		want string
+	}{
+		{name: "ASCII", host: "www.Example.com", want: "www.example.com"},
+		{name: "Unicode", host: "münchen.example", want: "xn--mnchen-3ya.example"},
+		{name: "uppercase Unicode", host: "MÜNCHEN.example", want: "xn--mnchen-3ya.example"},
+		{name: "punycode", host: "XN--MNCHEN-3YA.example", want: "xn--mnchen-3ya.example"},
+		{name: "port", host: "bücher.example:8080", want: "xn--bcher-kva.example:8080"},
+		{name: "non-Latin labels", host: "例え.テスト", want: "xn--r8jz45g.xn--zckzah"},
+		{name: "IPv6", host: "[::1]:8080", want: "[::1]:8080"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := asciiHost(test.host); got != test.want {
+				t.Errorf("expected %s, got %s", test.want, got)
+			}
+		})
+	}
+}
+
+func TestPunycodeEncode(t *testing.T) {
+	// the samples of RFC 3492 section 7.1, lowercased
+	tests := []struct {
+		name  string
+		label string
+		want  string
+	}{
+		{name: "Arabic (Egyptian)", label: "ليهمابتكلموشعربي؟", want: "egbpdaj6bu4bxfgehfvwxn"},
+		{name: "Chinese (simplified)", label: "他们为什么不说中文", want: "ihqwcrb4cv8a8dqg056pqjye"},
+		{name: "Japanese", label: "パフィーdeルンバ", want: "de-jg4avhby1noc0d"},
+		{name: "Spanish", label: "porquénopuedensimplementehablarenespañol", want: "porqunopuedensimplementehablarenespaol-fmd56a"},
+		{name: "mixed", label: "3年b組金八先生", want: "3b-ww4c5e180e575a65lsy2b"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := punycodeEncode(test.label); got != test.want {
+				t.Errorf("expected %s, got %s", test.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package sitemap
+
+// ImageEntry is an entry of the image inventory returned by GetImages.
+// It embeds the <image:image> values, the PageLoc field is the <loc> of the page declaring the image,
+// and the SourceSitemap field is the location of the sitemap the page was found in.
+type ImageEntry struct {
+	Image
+	PageLoc       string
+	SourceSitemap string
+}
+
+// GetImages returns the images of the parsed URLs, one entry per <image:image> in the order they were parsed.
+// If dedup is true, only the first entry of each image Loc is returned.
+// If the S object is nil or there are no images, an empty slice is returned.
+func (s *S) GetImages(dedup bool) []ImageEntry {
+	images := []ImageEntry{}
+	if s == nil {
+		return images
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var seen map[string]bool
+	if dedup {
+		seen = map[string]bool{}
+	}
+	for _, u := range s.urls {
+		for _, image := range u.Images {
+			if dedup {
+				if seen[image.Loc] {
+					continue
+				}
+				seen[image.Loc] = true
+			}
+			images = append(images, ImageEntry{Image: image, PageLoc: u.Loc, SourceSitemap: u.source})
+		}
+	}
+	return images
+}
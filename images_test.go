@@ -0,0 +1,71 @@
+package sitemap
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestS_GetImages(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	s, err := New().SetMultiThread(false).Parse(fmt.Sprintf("%s/sitemapindex-images.xml", server.URL), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := func(image, caption, page, sitemap string) ImageEntry {
+		return ImageEntry{
+			Image:         Image{Loc: fmt.Sprintf("%s/images/%s", server.URL, image), Caption: caption},
+			PageLoc:       fmt.Sprintf("%s/%s", server.URL, page),
+			SourceSitemap: fmt.Sprintf("%s/%s", server.URL, sitemap),
+		}
+	}
+
+	tests := []struct {
+		name  string
+		s     *S
+		dedup bool
+		want  []ImageEntry
+	}{
+		{
+			name: "nil receiver",
+			s:    nil,
+			want: []ImageEntry{},
+		},
+		{
+			name: "no images",
+			s:    &S{urls: []URL{{Loc: "https://www.sitemaps.org/"}}},
+			want: []ImageEntry{},
+		},
+		{
+			name: "all",
+			s:    s,
+			want: []ImageEntry{
+				entry("logo.png", "", "page-01", "sitemap-images-01.xml"),
+				entry("01.jpg", "First", "page-01", "sitemap-images-01.xml"),
+				entry("logo.png", "", "page-02", "sitemap-images-01.xml"),
+				entry("01.jpg", "", "page-04", "sitemap-images-02.xml"),
+				entry("02.jpg", "", "page-04", "sitemap-images-02.xml"),
+			},
+		},
+		{
+			name:  "dedup",
+			s:     s,
+			dedup: true,
+			want: []ImageEntry{
+				entry("logo.png", "", "page-01", "sitemap-images-01.xml"),
+				entry("01.jpg", "First", "page-01", "sitemap-images-01.xml"),
+				entry("02.jpg", "", "page-04", "sitemap-images-02.xml"),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.s.GetImages(test.dedup); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
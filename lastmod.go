@@ -0,0 +1,123 @@
+package sitemap
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// defaultLastModFormats is the list of the layouts of the <lastmod> values accepted by default:
+// a date or an RFC3339 date and time, see parseLastModFormats.
+var defaultLastModFormats = []string{time.DateOnly, time.RFC3339}
+
+// LastMod is the value of a <lastmod> element: a date ("2006-01-02") or an RFC3339 date and time.
+// It embeds time.Time, so the methods of time.Time can be called on it directly, and its Time field is the parsed value.
+// It is marshalled to JSON as an RFC3339 string, and unmarshalled from a date or an RFC3339 string.
+type LastMod struct {
+	time.Time
+}
+
+// NewLastMod creates a new LastMod of the given time, e.g. to set the LastMod field of a URL.
+func NewLastMod(t time.Time) *LastMod {
+	return &LastMod{t}
+}
+
+// UnmarshalXML decodes a <lastmod> element, which is either a date ("2006-01-02") or an RFC3339 date and time.
+// When decoding for a parser, the layouts set with SetLastModFormats are used instead,
+// and the values without a zone are in the location set with SetDefaultLastModLocation.
+func (l *LastMod) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var v string
+	err := d.DecodeElement(&v, &start)
+	if err != nil {
+		return err
+	}
+
+	opts := optionsOf(d)
+	parsedTime, err := parseLastModFormats(v, opts.lastModFormats, opts.lastModLocation)
+	if err != nil {
+		return err
+	}
+
+	*l = LastMod{parsedTime}
+
+	return nil
+}
+
+// MarshalJSON encodes the LastMod as an RFC3339 string.
+func (l LastMod) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.Format(time.RFC3339))
+}
+
+// UnmarshalJSON decodes a LastMod from a date ("2006-01-02") or an RFC3339 string.
+func (l *LastMod) UnmarshalJSON(data []byte) error {
+	var v string
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	parsedTime, err := parseLastModFormats(v, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	*l = LastMod{parsedTime}
+
+	return nil
+}
+
+// parseLastModFormats parses a <lastmod> value with the first matching layout of the given list, or, if the list is nil,
+// as a date ("2006-01-02") or an RFC3339 date and time, with a "Z" or a numeric zone and optional fractional seconds.
+// A value without zone information is in the given location, in UTC if it is nil.
+func parseLastModFormats(v string, formats []string, loc *time.Location) (time.Time, error) {
+	if formats == nil {
+		if len(v) == len(time.DateOnly) {
+			return parseTimeIn(time.DateOnly, v, loc)
+		}
+		return time.Parse(time.RFC3339, v)
+	}
+	for _, format := range formats {
+		if t, err := parseTimeIn(format, v, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("parsing lastmod %q: it does not match any of the formats %q", v, formats)
+}
+
+// parseTimeIn parses the value with the layout like time.Parse, or like time.ParseInLocation in the given location if it is not nil.
+func parseTimeIn(layout string, v string, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		return time.Parse(layout, v)
+	}
+	return time.ParseInLocation(layout, v, loc)
+}
+
+// SetLastModFallback sets whether the URLs without a <lastmod> value inherit the Last-Modified header of the response of their sitemap,
+// as a proxy for the freshness of the URLs of sitemaps omitting <lastmod> altogether.
+// The inherited values have the LastModInferred field set, and they count as missing in GetReport and GetURLsWithoutLastMod.
+// URLs of a sitemap without a valid Last-Modified header, passed to Parse as content or served from the body cache (see SetBodyCache), are left without a value.
+// By default, it is off.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetLastModFallback(fallback bool) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.lastModFallback = fallback
+
+	return s
+}
+
+// fallbackLastMod returns the value inherited by the URLs of the given sitemap without a <lastmod> value, see SetLastModFallback,
+// nil if the fallback is turned off or the response of the sitemap has no valid Last-Modified header.
+// It must be called with s.mu held.
+func (s *S) fallbackLastMod(loc string) *LastMod {
+	if !s.cfg.lastModFallback {
+		return nil
+	}
+	lastModified, err := http.ParseTime(s.fetchMeta[loc].LastModified)
+	if err != nil {
+		return nil
+	}
+	return NewLastMod(lastModified)
+}
@@ -0,0 +1,488 @@
+package sitemap
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLastMod_UnmarshalXML(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			name: "date",
+			data: "<lastmod>2024-02-12</lastmod>",
+			want: time.Date(2024, 2, 12, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "date and time",
+			data: "<lastmod>2024-02-12T12:34:56+01:00</lastmod>",
+			want: time.Date(2024, 2, 12, 11, 34, 56, 0, time.UTC),
+		},
+		{
+			name: "UTC designator",
+			data: "<lastmod>2024-02-12T12:34:56Z</lastmod>",
+			want: time.Date(2024, 2, 12, 12, 34, 56, 0, time.UTC),
+		},
+		{
+			name: "fractional seconds",
+			data: "<lastmod>2024-02-12T12:34:56.789+01:00</lastmod>",
+			want: time.Date(2024, 2, 12, 11, 34, 56, 789000000, time.UTC),
+		},
+		{
+			name:    "date and time without zone",
+			data:    "<lastmod>2024-02-12T12:34:56</lastmod>",
+			wantErr: true,
+		},
+		{
+			name:    "invalid",
+			data:    "<lastmod>yesterday</lastmod>",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var l LastMod
+			err := xml.Unmarshal([]byte(test.data), &l)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("expected error %v, got %v", test.wantErr, err)
+			}
+			if err == nil && !l.Time.Equal(test.want) {
+				t.Errorf("expected %v, got %v", test.want, l.Time)
+			}
+		})
+	}
+}
+
+func TestS_Parse_LastModRFC3339(t *testing.T) {
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+    <url><loc>https://www.example.com/1</loc><lastmod>2024-02-12T12:34:56Z</lastmod></url>
+    <url><loc>https://www.example.com/2</loc><lastmod>2024-02-12T12:34:56.5Z</lastmod></url>
+    <url><loc>https://www.example.com/3</loc><lastmod>2024-02-12T12:34:56-05:00</lastmod></url>
+</urlset>`
+	want := []time.Time{
+		time.Date(2024, 2, 12, 12, 34, 56, 0, time.UTC),
+		time.Date(2024, 2, 12, 12, 34, 56, 500000000, time.UTC),
+		time.Date(2024, 2, 12, 17, 34, 56, 0, time.UTC),
+	}
+
+	s, err := New().Parse("https://www.example.com/sitemap.xml", &content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := s.GetErrors(); len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+	urls := s.GetURLs()
+	if len(urls) != len(want) {
+		t.Fatalf("expected %d URLs, got %d", len(want), len(urls))
+	}
+	for i, u := range urls {
+		if u.LastMod == nil || !u.LastMod.Equal(want[i]) {
+			t.Errorf("%s: expected %v, got %v", u.Loc, want[i], u.LastMod)
+		}
+	}
+}
+
+func TestLastMod_JSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		want     *LastMod
+		wantJSON string
+		wantErr  bool
+	}{
+		{
+			name:     "date and time",
+			data:     `{"lastmod":"2024-02-12T12:34:56+01:00"}`,
+			want:     NewLastMod(time.Date(2024, 2, 12, 11, 34, 56, 0, time.UTC)),
+			wantJSON: `{"lastmod":"2024-02-12T12:34:56+01:00"}`,
+		},
+		{
+			name:     "date",
+			data:     `{"lastmod":"2024-02-12"}`,
+			want:     NewLastMod(time.Date(2024, 2, 12, 0, 0, 0, 0, time.UTC)),
+			wantJSON: `{"lastmod":"2024-02-12T00:00:00Z"}`,
+		},
+		{
+			name:     "null",
+			data:     `{"lastmod":null}`,
+			want:     nil,
+			wantJSON: `{"lastmod":null}`,
+		},
+		{
+			name:    "invalid",
+			data:    `{"lastmod":"yesterday"}`,
+			wantErr: true,
+		},
+		{
+			name:    "not a string",
+			data:    `{"lastmod":1707737696}`,
+			wantErr: true,
+		},
+	}
+
+	type document struct {
+		LastMod *LastMod `json:"lastmod"`
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var doc document
+			err := json.Unmarshal([]byte(test.data), &doc)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("expected error %v, got %v", test.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if (doc.LastMod == nil) != (test.want == nil) || (doc.LastMod != nil && !doc.LastMod.Equal(test.want.Time)) {
+				t.Errorf("expected %v, got %v", test.want, doc.LastMod)
+			}
+
+			data, err := json.Marshal(doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != test.wantJSON {
+				t.Errorf("expected %s, got %s", test.wantJSON, data)
+			}
+		})
+	}
+}
+
+func TestNewLastMod(t *testing.T) {
+	now := time.Now()
+	u := URL{Loc: "https://www.sitemaps.org/", LastMod: NewLastMod(now)}
+	if !u.LastMod.Time.Equal(now) || !u.LastMod.Equal(now) {
+		t.Errorf("expected %v, got %v", now, u.LastMod.Time)
+	}
+}
+
+func TestS_SetLastModFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		layouts []string
+		replace bool
+		want    []string
+	}{
+		{
+			name:    "append",
+			layouts: []string{"02.01.2006"},
+			replace: false,
+			want:    []string{time.DateOnly, time.RFC3339, "02.01.2006"},
+		},
+		{
+			name:    "replace",
+			layouts: []string{"02.01.2006", "2006/01/02"},
+			replace: true,
+			want:    []string{"02.01.2006", "2006/01/02"},
+		},
+		{
+			name:    "replace with no layouts",
+			layouts: nil,
+			replace: true,
+			want:    nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New().SetLastModFormats(test.layouts, test.replace)
+			if !reflect.DeepEqual(s.cfg.lastModFormats, test.want) {
+				t.Errorf("expected %q, got %q", test.want, s.cfg.lastModFormats)
+			}
+		})
+	}
+}
+
+func TestS_Parse_LastModFormats(t *testing.T) {
+	urlSet := `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+    <url><loc>https://www.example.com/a</loc><lastmod>12.02.2024</lastmod></url>
+    <url><loc>https://www.example.com/b</loc><lastmod>2024-02-13T12:34:56Z</lastmod></url>
+</urlset>`
+	index := `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+    <sitemap><loc>https://www.example.com/sitemap-01.xml</loc><lastmod>2024/02/12</lastmod></sitemap>
+</sitemapindex>`
+
+	tests := []struct {
+		name         string
+		layouts      []string
+		replace      bool
+		wantLastMods []string
+		wantIndex    string
+	}{
+		{
+			name:         "defaults",
+			wantLastMods: nil,
+			wantIndex:    "",
+		},
+		{
+			name:         "appended",
+			layouts:      []string{"02.01.2006", "2006/01/02"},
+			wantLastMods: []string{"2024-02-12T00:00:00Z", "2024-02-13T12:34:56Z"},
+			wantIndex:    "2024-02-12T00:00:00Z",
+		},
+		{
+			name:         "replaced",
+			layouts:      []string{"02.01.2006", "2006/01/02"},
+			replace:      true,
+			wantLastMods: nil,
+			wantIndex:    "2024-02-12T00:00:00Z",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := New().SetFollowIndexes(false).SetLastModFormats(test.layouts, test.replace).Parse("https://www.example.com/sitemap.xml", &urlSet)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var lastMods []string
+			for _, u := range s.GetURLs() {
+				lastMods = append(lastMods, u.LastMod.Format(time.RFC3339))
+			}
+			if !reflect.DeepEqual(lastMods, test.wantLastMods) {
+				t.Errorf("expected lastmods %v, got %v (errors: %v)", test.wantLastMods, lastMods, s.GetErrors())
+			}
+			if (test.wantLastMods == nil) != (s.GetErrorsCount() > 0) {
+				t.Errorf("unexpected errors %v", s.GetErrors())
+			}
+
+			s, err = New().SetFollowIndexes(false).SetLastModFormats(test.layouts, test.replace).Parse("https://www.example.com/sitemapindex.xml", &index)
+			if err != nil {
+				t.Fatal(err)
+			}
+			indexLastMod := ""
+			if children := s.GetSitemapTree().Children; len(children) == 1 && children[0].LastMod != nil {
+				indexLastMod = children[0].LastMod.Format(time.RFC3339)
+			}
+			if indexLastMod != test.wantIndex {
+				t.Errorf("expected index lastmod %q, got %q", test.wantIndex, indexLastMod)
+			}
+		})
+	}
+
+	// the layouts of a parser do not leak into the standalone decoding
+	if _, err := DecodeURLSet(strings.NewReader(urlSet)); err == nil {
+		t.Error("expected DecodeURLSet to reject the custom layout")
+	}
+}
+
+func TestS_SetDefaultLastModLocation(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	tests := []struct {
+		name string
+		loc  *time.Location
+	}{
+		{name: "location", loc: berlin},
+		{name: "nil", loc: nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New().SetDefaultLastModLocation(test.loc)
+			if s.cfg.lastModLocation != test.loc {
+				t.Errorf("expected %v, got %v", test.loc, s.cfg.lastModLocation)
+			}
+		})
+	}
+}
+
+func TestS_Parse_DefaultLastModLocation(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+	urlSet := `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+    <url><loc>https://www.example.com/a</loc><lastmod>2024-02-12</lastmod></url>
+    <url><loc>https://www.example.com/b</loc><lastmod>2024-07-12T12:34</lastmod></url>
+    <url><loc>https://www.example.com/c</loc><lastmod>2024-02-13T12:34:56Z</lastmod></url>
+    <url><loc>https://www.example.com/d</loc><lastmod>2024-02-13T12:34:56-05:00</lastmod></url>
+</urlset>`
+	index := `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+    <sitemap><loc>https://www.example.com/sitemap-01.xml</loc><lastmod>2024-02-12</lastmod></sitemap>
+</sitemapindex>`
+
+	tests := []struct {
+		name         string
+		loc          *time.Location
+		layouts      []string
+		wantLastMods []string
+		wantIndex    string
+	}{
+		{
+			name:         "zoned and zone-less values, UTC by default",
+			layouts:      []string{"2006-01-02T15:04"},
+			wantLastMods: []string{"2024-02-12T00:00:00Z", "2024-07-12T12:34:00Z", "2024-02-13T12:34:56Z", "2024-02-13T12:34:56-05:00"},
+			wantIndex:    "2024-02-12T00:00:00Z",
+		},
+		{
+			name:         "zoned and zone-less values in a location",
+			loc:          berlin,
+			layouts:      []string{"2006-01-02T15:04"},
+			wantLastMods: []string{"2024-02-12T00:00:00+01:00", "2024-07-12T12:34:00+02:00", "2024-02-13T12:34:56Z", "2024-02-13T12:34:56-05:00"},
+			wantIndex:    "2024-02-12T00:00:00+01:00",
+		},
+		{
+			name:         "replaced layouts in a location",
+			loc:          berlin,
+			layouts:      []string{"2006-01-02T15:04", time.DateOnly, time.RFC3339},
+			wantLastMods: []string{"2024-02-12T00:00:00+01:00", "2024-07-12T12:34:00+02:00", "2024-02-13T12:34:56Z", "2024-02-13T12:34:56-05:00"},
+			wantIndex:    "2024-02-12T00:00:00+01:00",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := New().SetFollowIndexes(false).SetLastModFormats(test.layouts, len(test.layouts) > 1).SetDefaultLastModLocation(test.loc).Parse("https://www.example.com/sitemap.xml", &urlSet)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var lastMods []string
+			for _, u := range s.GetURLs() {
+				lastMods = append(lastMods, u.LastMod.Format(time.RFC3339))
+			}
+			if !reflect.DeepEqual(lastMods, test.wantLastMods) {
+				t.Errorf("expected lastmods %v, got %v (errors: %v)", test.wantLastMods, lastMods, s.GetErrors())
+			}
+
+			s, err = New().SetFollowIndexes(false).SetDefaultLastModLocation(test.loc).Parse("https://www.example.com/sitemapindex.xml", &index)
+			if err != nil {
+				t.Fatal(err)
+			}
+			indexLastMod := ""
+			if children := s.GetSitemapTree().Children; len(children) == 1 && children[0].LastMod != nil {
+				indexLastMod = children[0].LastMod.Format(time.RFC3339)
+			}
+			if indexLastMod != test.wantIndex {
+				t.Errorf("expected index lastmod %q, got %q", test.wantIndex, indexLastMod)
+			}
+		})
+	}
+
+	// the location of a parser does not leak into the standalone decoding
+	decoded, err := DecodeURLSet(strings.NewReader(`<urlset><url><loc>https://www.example.com/a</loc><lastmod>2024-02-12</lastmod></url></urlset>`))
+	if err != nil || decoded.URL[0].LastMod.Location() != time.UTC {
+		t.Errorf("expected DecodeURLSet to parse the lastmod in UTC, got %v (%v)", decoded.URL, err)
+	}
+}
+
+func TestS_SetLastModFallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		fallback bool
+	}{
+		{name: "enabled", fallback: true},
+		{name: "disabled", fallback: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New().SetLastModFallback(test.fallback)
+			if s.cfg.lastModFallback != test.fallback {
+				t.Errorf("expected %v, got %v", test.fallback, s.cfg.lastModFallback)
+			}
+		})
+	}
+}
+
+func TestS_Parse_LastModFallback(t *testing.T) {
+	fixtures := testServer()
+	defer fixtures.Close()
+
+	tests := []struct {
+		name         string
+		fallback     bool
+		lastModified string
+		wantLastMods []string
+		wantInferred []bool
+	}{
+		{
+			name:         "fallback",
+			fallback:     true,
+			lastModified: "Mon, 19 Feb 2024 08:00:00 GMT",
+			wantLastMods: []string{"2024-02-12T12:34:56+01:00", "2024-02-19T08:00:00Z", "2024-02-13T00:00:00Z", "2024-02-19T08:00:00Z"},
+			wantInferred: []bool{false, true, false, true},
+		},
+		{
+			name:         "fallback off",
+			fallback:     false,
+			lastModified: "Mon, 19 Feb 2024 08:00:00 GMT",
+			wantLastMods: []string{"2024-02-12T12:34:56+01:00", "", "2024-02-13T00:00:00Z", ""},
+			wantInferred: []bool{false, false, false, false},
+		},
+		{
+			name:         "invalid header",
+			fallback:     true,
+			lastModified: "yesterday",
+			wantLastMods: []string{"2024-02-12T12:34:56+01:00", "", "2024-02-13T00:00:00Z", ""},
+			wantInferred: []bool{false, false, false, false},
+		},
+		{
+			name:         "no header",
+			fallback:     true,
+			wantLastMods: []string{"2024-02-12T12:34:56+01:00", "", "2024-02-13T00:00:00Z", ""},
+			wantInferred: []bool{false, false, false, false},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if test.lastModified != "" {
+					w.Header().Set("Last-Modified", test.lastModified)
+				}
+				fixtures.Config.Handler.ServeHTTP(w, r)
+			}))
+			defer server.Close()
+
+			s, err := New().SetLastModFallback(test.fallback).Parse(fmt.Sprintf("%s/sitemap-partial-metadata.xml", server.URL), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var lastMods []string
+			var inferred []bool
+			for _, u := range s.GetURLs() {
+				lastMod := ""
+				if u.LastMod != nil {
+					lastMod = u.LastMod.Format(time.RFC3339)
+				}
+				lastMods = append(lastMods, lastMod)
+				inferred = append(inferred, u.LastModInferred)
+			}
+			if !reflect.DeepEqual(lastMods, test.wantLastMods) {
+				t.Errorf("expected lastmods %v, got %v", test.wantLastMods, lastMods)
+			}
+			if !reflect.DeepEqual(inferred, test.wantInferred) {
+				t.Errorf("expected inferred %v, got %v", test.wantInferred, inferred)
+			}
+
+			// the inferred values count as missing
+			if got := len(s.GetURLsWithoutLastMod()); got != 2 {
+				t.Errorf("expected 2 URLs without lastmod, got %d", got)
+			}
+			if report := s.GetReport(); report.MissingLastMod != 2 || report.LastModMax == nil || !report.LastModMax.Equal(time.Date(2024, 2, 13, 0, 0, 0, 0, time.UTC)) {
+				t.Errorf("expected the report to ignore the inferred lastmods, got %d missing and max %v", report.MissingLastMod, report.LastModMax)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package sitemap
+
+// SetDropLongLocs sets whether the URLs with a location over 2048 characters, the limit of the sitemaps.org protocol, are dropped.
+// The length of each location is checked after the rewriters (see SetURLRewriter), and a *LocTooLongError warning
+// is recorded for each offending URL either way, see GetWarnings. The dropped URLs are counted under FilterLocLength,
+// see GetFilteredURLCounts. By default, the URLs are kept and only warned about.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetDropLongLocs(dropLongLocs bool) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.dropLongLocs = dropLongLocs
+
+	return s
+}
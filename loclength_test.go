@@ -0,0 +1,111 @@
+package sitemap
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestS_SetDropLongLocs(t *testing.T) {
+	tests := []struct {
+		name         string
+		dropLongLocs bool
+	}{
+		{
+			name:         "Drop",
+			dropLongLocs: true,
+		},
+		{
+			name:         "Warn",
+			dropLongLocs: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New().SetDropLongLocs(test.dropLongLocs)
+			if s.cfg.dropLongLocs != test.dropLongLocs {
+				t.Errorf("expected %v, got %v", test.dropLongLocs, s.cfg.dropLongLocs)
+			}
+		})
+	}
+}
+
+func TestS_Parse_LongLocs(t *testing.T) {
+	prefix := "https://www.example.com/"
+	under := prefix + strings.Repeat("a", maxLocLength-len(prefix))
+	over := prefix + strings.Repeat("b", maxLocLength-len(prefix)+1)
+	short := prefix + "page"
+
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+    <url><loc>` + under + `</loc></url>
+    <url><loc>` + over + `</loc></url>
+    <url><loc>` + short + `</loc></url>
+</urlset>`
+
+	tests := []struct {
+		name         string
+		s            *S
+		wantLocs     []string
+		wantLongLocs int64
+		wantFiltered map[Filter]int64
+		wantWarnings []string
+	}{
+		{
+			name:         "warn",
+			s:            New(),
+			wantLocs:     []string{under, over, short},
+			wantLongLocs: 1,
+			wantFiltered: map[Filter]int64{},
+			wantWarnings: []string{over},
+		},
+		{
+			name:         "drop",
+			s:            New().SetDropLongLocs(true),
+			wantLocs:     []string{under, short},
+			wantLongLocs: 0,
+			wantFiltered: map[Filter]int64{FilterLocLength: 1},
+			wantWarnings: []string{over},
+		},
+		{
+			name:         "checked after the URL rewriter",
+			s:            New().SetDropLongLocs(true).SetURLRewriter(func(loc string) string { return strings.TrimSuffix(loc, "b") }),
+			wantLocs:     []string{under, over[:maxLocLength], short},
+			wantLongLocs: 0,
+			wantFiltered: map[Filter]int64{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := test.s.Parse("https://www.example.com/sitemap.xml", &content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := locsOf(s.GetURLs()); !reflect.DeepEqual(got, test.wantLocs) {
+				t.Errorf("expected %d URLs, got %d", len(test.wantLocs), len(got))
+			}
+			if got := s.GetReport().LongLocs; got != test.wantLongLocs {
+				t.Errorf("expected %d long locs in the report, got %d", test.wantLongLocs, got)
+			}
+			if got := s.GetFilteredURLCounts(); !reflect.DeepEqual(got, test.wantFiltered) {
+				t.Errorf("expected %v, got %v", test.wantFiltered, got)
+			}
+
+			var longLocs []string
+			for _, warning := range s.GetWarnings() {
+				var locErr *LocTooLongError
+				if errors.As(warning, &locErr) {
+					if locErr.Location != "https://www.example.com/sitemap.xml" || locErr.Length != len(locErr.Loc) {
+						t.Errorf("unexpected warning %+v", locErr)
+					}
+					longLocs = append(longLocs, locErr.Loc)
+				}
+			}
+			if !reflect.DeepEqual(longLocs, test.wantWarnings) {
+				t.Errorf("expected warnings for %d locs, got %d", len(test.wantWarnings), len(longLocs))
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package sitemap
+
+// SetLocOnly sets whether the Sitemap Parser stores only the locations of the URLs, as plain strings.
+// In loc-only mode, only the <loc> of the URLs is decoded and stored, without the overhead of a URL structure per URL:
+// GetLocs returns the locations, the getters based on the locations only include them (e.g. GetURLCount, GetUniqueURLCount,
+// GetURLCountsByHost, GetHosts, GroupURLsByPathPrefix, GetDisallowedURLs and WriteCSV), and the limits and rules apply as usual.
+// GetURLs, GetURLsShared and the getters returning URL values, GetURLsGroupedByHost, GetRandomURLs, TopURLsByPriority,
+// GetCrawlSchedule and NewSampler, return empty results, as do the getters based on the other fields of the URLs.
+// The default is false.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetLocOnly(locOnly bool) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.locOnly = locOnly
+
+	return s
+}
+
+// GetLocs returns a copy of the locations of the parsed URLs, in the order the URLs were parsed.
+// In loc-only mode, see SetLocOnly, these are the locations stored, otherwise the Loc values of the URLs.
+// If the S object is nil or there are no URLs, an empty slice is returned.
+func (s *S) GetLocs() []string {
+	if s == nil {
+		return []string{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	locs := make([]string, 0, len(s.locs)+len(s.urls))
+	locs = append(locs, s.locs...)
+	for _, u := range s.urls {
+		locs = append(locs, u.Loc)
+	}
+	return locs
+}
+
+// GetURLsAsStrings returns the Loc values of the parsed URLs as a fresh slice, in the same order as GetURLs,
+// e.g. to feed them to a queue. It is equivalent to GetLocs, so the locations stored in loc-only mode are included.
+// The locations are returned as stored, i.e. after the rewriters and the host normalization, see SetPunycodeHosts.
+// If the S object is nil or there are no URLs, an empty slice is returned.
+func (s *S) GetURLsAsStrings() []string {
+	return s.GetLocs()
+}
+
+// GetUniqueURLsAsStrings returns the distinct Loc values of the parsed URLs as a fresh slice, compared exactly,
+// in the order of their first occurrence. Its length is GetUniqueURLCount.
+// If the S object is nil or there are no URLs, an empty slice is returned.
+func (s *S) GetUniqueURLsAsStrings() []string {
+	locs := s.GetLocs()
+	seen := make(map[string]struct{}, len(locs))
+	unique := locs[:0]
+	for _, loc := range locs {
+		if _, ok := seen[loc]; ok {
+			continue
+		}
+		seen[loc] = struct{}{}
+		unique = append(unique, loc)
+	}
+	return unique
+}
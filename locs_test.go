@@ -0,0 +1,247 @@
+package sitemap
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestS_SetLocOnly(t *testing.T) {
+	tests := []struct {
+		name    string
+		locOnly bool
+	}{
+		{
+			name:    "LocOnly",
+			locOnly: true,
+		},
+		{
+			name:    "URLs",
+			locOnly: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New().SetLocOnly(test.locOnly)
+			if s.cfg.locOnly != test.locOnly {
+				t.Errorf("expected %v, got %v", test.locOnly, s.cfg.locOnly)
+			}
+		})
+	}
+}
+
+func TestS_GetLocs(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	tests := []struct {
+		name           string
+		s              *S
+		url            string
+		wantLocs       []string
+		wantUniqueURLs int64
+		wantURLs       int
+	}{
+		{
+			name:           "loc only",
+			s:              New().SetLocOnly(true),
+			url:            "/sitemap-duplicates.xml",
+			wantLocs:       []string{"/page-01", "/page-02", "/page-01", "/page-03", "/page-02", "/page-01"},
+			wantUniqueURLs: 3,
+		},
+		{
+			name:           "loc only with rules",
+			s:              New().SetLocOnly(true).SetRules([]string{`page-0[12]$`}),
+			url:            "/sitemap-duplicates.xml",
+			wantLocs:       []string{"/page-01", "/page-02", "/page-01", "/page-02", "/page-01"},
+			wantUniqueURLs: 2,
+		},
+		{
+			name:           "loc only with max URLs",
+			s:              New().SetLocOnly(true).SetMaxURLs(2),
+			url:            "/sitemap-duplicates.xml",
+			wantLocs:       []string{"/page-01", "/page-02"},
+			wantUniqueURLs: 2,
+		},
+		{
+			name:           "loc only via index",
+			s:              New().SetLocOnly(true).SetMultiThread(false),
+			url:            "/sitemapindex-1.xml",
+			wantLocs:       []string{"/page-01", "/page-02", "/page-03", "/page-04", "/page-05", "/page-06"},
+			wantUniqueURLs: 6,
+		},
+		{
+			name:           "URLs",
+			s:              New(),
+			url:            "/sitemap-duplicates.xml",
+			wantLocs:       []string{"/page-01", "/page-02", "/page-01", "/page-03", "/page-02", "/page-01"},
+			wantUniqueURLs: 3,
+			wantURLs:       6,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := test.s.Parse(server.URL+test.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := trimPrefixes(s.GetLocs(), server.URL); !reflect.DeepEqual(got, test.wantLocs) {
+				t.Errorf("expected %v, got %v", test.wantLocs, got)
+			}
+			if got := s.GetURLCount(); got != int64(len(test.wantLocs)) {
+				t.Errorf("expected %d URLs counted, got %d", len(test.wantLocs), got)
+			}
+			if got := s.GetUniqueURLCount(); got != test.wantUniqueURLs {
+				t.Errorf("expected %d unique URLs, got %d", test.wantUniqueURLs, got)
+			}
+			if got := len(s.GetURLs()); got != test.wantURLs {
+				t.Errorf("expected %d URLs, got %d", test.wantURLs, got)
+			}
+		})
+	}
+
+	var nilS *S
+	if got := nilS.GetLocs(); got == nil || len(got) != 0 {
+		t.Errorf("expected empty slice, got %v", got)
+	}
+}
+
+func TestS_LocOnly_Getters(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	s, err := New().SetLocOnly(true).Parse(server.URL+"/sitemap-duplicates.xml", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, _ := hostOf(server.URL)
+
+	// the getters based on the locations only include the locations stored
+	if got, want := s.GetURLCountsByHost(), map[string]int64{host: 6}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetURLCountsByHost: expected %v, got %v", want, got)
+	}
+	if got, want := s.GetHosts(), []string{host}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetHosts: expected %v, got %v", want, got)
+	}
+	if got, want := s.GroupURLsByPathPrefix(1), map[string]int64{"/page-01": 3, "/page-02": 2, "/page-03": 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GroupURLsByPathPrefix: expected %v, got %v", want, got)
+	}
+
+	// the getters returning URL values return empty results
+	if got := s.GetURLsGroupedByHost(); len(got) != 0 {
+		t.Errorf("GetURLsGroupedByHost: expected an empty map, got %v", got)
+	}
+	if got := s.GetRandomURLs(3); len(got) != 0 {
+		t.Errorf("GetRandomURLs: expected an empty slice, got %v", got)
+	}
+	if got := s.GetCrawlSchedule(time.Now()); len(got) != 0 {
+		t.Errorf("GetCrawlSchedule: expected an empty slice, got %v", got)
+	}
+	if got := s.NewSampler(1).Next(3); len(got) != 0 {
+		t.Errorf("NewSampler: expected an empty slice, got %v", got)
+	}
+}
+
+func TestS_ParseFromCheckpoint_LocOnly(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	url := server.URL + "/robots-with-sitemapindex/robots.txt"
+	s, err := New().SetLocOnly(true).SetMultiThread(false).SetMaxSitemaps(2).Parse(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp := s.GetCheckpoint()
+	if len(cp.URLs) != 1 || cp.URLs[0].Loc != server.URL+"/page-01" {
+		t.Fatalf("expected the location in the checkpoint, got %v", cp.URLs)
+	}
+
+	resumed, err := New().SetLocOnly(true).ParseFromCheckpointContext(context.Background(), cp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	full, err := New().Parse(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sortedLocs(locURLs(resumed.GetLocs())), sortedLocs(full.GetURLs()); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if got := len(resumed.GetURLs()); got != 0 {
+		t.Errorf("expected no URLs, got %d", got)
+	}
+}
+
+// locURLs returns URLs with the given locations.
+func locURLs(locs []string) []URL {
+	urls := make([]URL, 0, len(locs))
+	for _, loc := range locs {
+		urls = append(urls, URL{Loc: loc})
+	}
+	return urls
+}
+
+func TestS_GetURLsAsStrings(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		s          *S
+		want       []string
+		wantUnique []string
+	}{
+		{
+			name:       "URLs",
+			s:          New(),
+			want:       []string{"/page-01", "/page-02", "/page-01", "/page-03", "/page-02", "/page-01"},
+			wantUnique: []string{"/page-01", "/page-02", "/page-03"},
+		},
+		{
+			name:       "loc only",
+			s:          New().SetLocOnly(true),
+			want:       []string{"/page-01", "/page-02", "/page-01", "/page-03", "/page-02", "/page-01"},
+			wantUnique: []string{"/page-01", "/page-02", "/page-03"},
+		},
+		{
+			name:       "with rules",
+			s:          New().SetRules([]string{`page-0[13]$`}),
+			want:       []string{"/page-01", "/page-01", "/page-03", "/page-01"},
+			wantUnique: []string{"/page-01", "/page-03"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := test.s.Parse(server.URL+"/sitemap-duplicates.xml", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := s.GetURLsAsStrings()
+			if !reflect.DeepEqual(trimPrefixes(got, server.URL), test.want) {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+			if !test.s.cfg.locOnly && !reflect.DeepEqual(got, locsOf(s.GetURLs())) {
+				t.Errorf("expected the order of GetURLs, got %v", got)
+			}
+			unique := s.GetUniqueURLsAsStrings()
+			if !reflect.DeepEqual(trimPrefixes(unique, server.URL), test.wantUnique) {
+				t.Errorf("expected %v, got %v", test.wantUnique, unique)
+			}
+			if int64(len(unique)) != s.GetUniqueURLCount() {
+				t.Errorf("expected %d unique URLs, got %d", s.GetUniqueURLCount(), len(unique))
+			}
+
+			// the slices are fresh copies
+			got[0] = "changed"
+			unique[0] = "changed"
+			if s.GetURLsAsStrings()[0] == "changed" || s.GetUniqueURLsAsStrings()[0] == "changed" {
+				t.Error("expected a fresh slice")
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package sitemap
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"strings"
+)
+
+type (
+	// matcher reports whether a location matches any of a list of regular expressions, see SetRules and SetFollow.
+	// It gives the same result as matching the regular expressions one by one, but it avoids running most of them:
+	// the expressions anchored at the start of the location are indexed by their literal prefix in the prefixes trie,
+	// the expressions that are a plain literal are kept in the literals field and matched as substrings,
+	// and only the remaining ones, in the regexes field, are run on every location.
+	matcher struct {
+		prefixes *prefixNode
+		literals []string
+		regexes  []*regexp.Regexp
+	}
+
+	// prefixNode is a node of the trie of the literal prefixes of the anchored regular expressions.
+	// The complete field reports whether the prefix of the node is a whole expression, any location starting with it matches.
+	// The regexes field holds the expressions with the prefix of the node which have to be run to decide the match.
+	prefixNode struct {
+		children map[byte]*prefixNode
+		complete bool
+		regexes  []*regexp.Regexp
+	}
+)
+
+// newMatcher returns the matcher of the given regular expressions, nil if there are none.
+func newMatcher(regexes []*regexp.Regexp) *matcher {
+	if len(regexes) == 0 {
+		return nil
+	}
+	m := &matcher{}
+	for _, re := range regexes {
+		prefix, anchored, complete := literalPrefix(re)
+		switch {
+		case anchored:
+			if m.prefixes == nil {
+				m.prefixes = &prefixNode{}
+			}
+			m.prefixes.insert(prefix, re, complete)
+		case complete:
+			m.literals = append(m.literals, prefix)
+		default:
+			m.regexes = append(m.regexes, re)
+		}
+	}
+	return m
+}
+
+// match reports whether the location matches any of the regular expressions of the matcher.
+// A nil matcher matches every location.
+func (m *matcher) match(loc string) bool {
+	if m == nil {
+		return true
+	}
+	for node, i := m.prefixes, 0; node != nil; i++ {
+		if node.complete {
+			return true
+		}
+		for _, re := range node.regexes {
+			if re.MatchString(loc) {
+				return true
+			}
+		}
+		if i == len(loc) {
+			break
+		}
+		node = node.children[loc[i]]
+	}
+	for _, literal := range m.literals {
+		if strings.Contains(loc, literal) {
+			return true
+		}
+	}
+	for _, re := range m.regexes {
+		if re.MatchString(loc) {
+			return true
+		}
+	}
+	return false
+}
+
+// insert adds the regular expression with the given literal prefix to the trie.
+func (n *prefixNode) insert(prefix string, re *regexp.Regexp, complete bool) {
+	for i := 0; i < len(prefix); i++ {
+		child := n.children[prefix[i]]
+		if child == nil {
+			if n.children == nil {
+				n.children = map[byte]*prefixNode{}
+			}
+			child = &prefixNode{}
+			n.children[prefix[i]] = child
+		}
+		n = child
+	}
+	if complete {
+		n.complete = true
+		return
+	}
+	n.regexes = append(n.regexes, re)
+}
+
+// literalPrefix returns the case-sensitive literal every match of the regular expression starts with,
+// whether the expression is anchored at the start of the text, and whether the literal is the whole expression.
+// An expression it cannot analyze is reported as not anchored and not complete, so it is run on every location.
+func literalPrefix(re *regexp.Regexp) (prefix string, anchored bool, complete bool) {
+	if re == nil {
+		return "", false, false
+	}
+	parsed, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return "", false, false
+	}
+	parsed = parsed.Simplify()
+
+	subs := []*syntax.Regexp{parsed}
+	if parsed.Op == syntax.OpConcat {
+		subs = parsed.Sub
+	}
+	if subs[0].Op == syntax.OpBeginText {
+		anchored = true
+		subs = subs[1:]
+	}
+	var literal strings.Builder
+	for len(subs) > 0 && subs[0].Op == syntax.OpLiteral && subs[0].Flags&syntax.FoldCase == 0 {
+		literal.WriteString(string(subs[0].Rune))
+		subs = subs[1:]
+	}
+	complete = len(subs) == 0
+	if !anchored && (!complete || literal.Len() == 0) {
+		return "", false, false
+	}
+	return literal.String(), anchored, complete
+}
+
+// SetURLMatcher sets a function deciding whether a URL decoded from a sitemap is kept, e.g. by looking up the product ID of its location in a database.
+// It is called with the location of each URL after the rewriters (see SetURLRewriter) and before the URL is stored,
+// so the URLs it rejects are counted as filtered by FilterURLMatcher and never retained.
+// If rules are set as well (see SetRules), a URL is kept only if it is accepted by both.
+// The function is called concurrently from several goroutines when multi-threading is on, so it must be safe for concurrent use;
+// it is called without holding the lock of the S structure, but it must not call the methods of the S structure being parsed.
+// A nil function (the default) keeps every URL.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetURLMatcher(fn func(loc string) bool) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.urlMatcher = fn
+
+	return s
+}
+
+// SetSitemapMatcher sets a function deciding whether a sitemap referenced by a sitemap index is followed.
+// It is called with the location of each sitemap of an index after the sitemap URL rewriter (see SetSitemapURLRewriter),
+// and the sitemaps it rejects are skipped and reported by FilterSitemapMatcher. The sitemaps listed in a robots.txt file are not matched.
+// If follow patterns are set as well (see SetFollow), a sitemap is followed only if it is accepted by both.
+// The function is called concurrently from several goroutines when multi-threading is on, so it must be safe for concurrent use;
+// it is called without holding the lock of the S structure, but it must not call the methods of the S structure being parsed.
+// A nil function (the default) follows every sitemap.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetSitemapMatcher(fn func(loc string) bool) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.sitemapMatcher = fn
+
+	return s
+}
+
+// matchURLs returns the given URLs accepted by the URL matcher, filtered in place, and the number of the URLs it rejected.
+func (s *S) matchURLs(urls []URL) ([]URL, int) {
+	if s.cfg.urlMatcher == nil {
+		return urls, 0
+	}
+	kept := urls[:0]
+	for _, u := range urls {
+		if s.cfg.urlMatcher(u.Loc) {
+			kept = append(kept, u)
+		}
+	}
+	return kept, len(urls) - len(kept)
+}
+
+// matchSitemaps returns the given sitemaps of an index accepted by the sitemap matcher, filtered in place,
+// and the locations of the sitemaps it rejected.
+func (s *S) matchSitemaps(sitemaps []IndexSitemap) ([]IndexSitemap, []string) {
+	if s.cfg.sitemapMatcher == nil {
+		return sitemaps, nil
+	}
+	var rejected []string
+	kept := sitemaps[:0]
+	for _, sm := range sitemaps {
+		if s.cfg.sitemapMatcher(sm.Loc) {
+			kept = append(kept, sm)
+		} else {
+			rejected = append(rejected, sm.Loc)
+		}
+	}
+	return kept, rejected
+}
@@ -0,0 +1,272 @@
+package sitemap
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// matchAny reports whether the location matches any of the regular expressions, one by one.
+func matchAny(regexes []*regexp.Regexp, loc string) bool {
+	for _, re := range regexes {
+		if re.MatchString(loc) {
+			return true
+		}
+	}
+	return false
+}
+
+func compileAll(t testing.TB, patterns []string) []*regexp.Regexp {
+	t.Helper()
+	var regexes []*regexp.Regexp
+	for _, pattern := range patterns {
+		regexes = append(regexes, regexp.MustCompile(pattern))
+	}
+	return regexes
+}
+
+func TestMatcher_match(t *testing.T) {
+	locs := []string{
+		"",
+		"https://www.example.com/",
+		"https://www.example.com/products/shoes",
+		"https://www.example.com/Products/shoes",
+		"https://www.example.com/blog/2024/02/post",
+		"https://www.example.com/blog/",
+		"https://shop.example.com/products/shoes",
+		"http://www.example.com/products/shoes",
+		"https://www.example.com/search?q=products",
+		"https://www.example.com/p/123",
+		"https://www.example.com/p/abc",
+	}
+
+	tests := []struct {
+		name     string
+		patterns []string
+		want     []string
+	}{
+		{
+			name: "no patterns",
+			want: locs,
+		},
+		{
+			name:     "anchored literal",
+			patterns: []string{`^https://www\.example\.com/products/`},
+			want:     []string{"https://www.example.com/products/shoes"},
+		},
+		{
+			name:     "anchored literals sharing a prefix",
+			patterns: []string{`^https://www\.example\.com/products/`, `^https://www\.example\.com/blog/`, `^https://www\.example\.com/blog/2024/`},
+			want:     []string{"https://www.example.com/products/shoes", "https://www.example.com/blog/2024/02/post", "https://www.example.com/blog/"},
+		},
+		{
+			name:     "anchored with a regular expression suffix",
+			patterns: []string{`^https://www\.example\.com/p/\d+$`, `^https://www\.example\.com/blog/\d{4}/`},
+			want:     []string{"https://www.example.com/blog/2024/02/post", "https://www.example.com/p/123"},
+		},
+		{
+			name:     "anchored and case-insensitive",
+			patterns: []string{`^https://www\.example\.com/(?i)products/`},
+			want:     []string{"https://www.example.com/products/shoes", "https://www.example.com/Products/shoes"},
+		},
+		{
+			name:     "case-insensitive",
+			patterns: []string{`(?i)^HTTPS://WWW\.EXAMPLE\.COM/PRODUCTS/`},
+			want:     []string{"https://www.example.com/products/shoes", "https://www.example.com/Products/shoes"},
+		},
+		{
+			name:     "unanchored literal",
+			patterns: []string{`products`},
+			want:     []string{"https://www.example.com/products/shoes", "https://shop.example.com/products/shoes", "http://www.example.com/products/shoes", "https://www.example.com/search?q=products"},
+		},
+		{
+			name:     "unanchored regular expression",
+			patterns: []string{`/p/[a-z]+`, `blog/$`},
+			want:     []string{"https://www.example.com/blog/", "https://www.example.com/p/abc"},
+		},
+		{
+			name:     "start anchor only",
+			patterns: []string{`^`},
+			want:     locs,
+		},
+		{
+			name:     "empty pattern",
+			patterns: []string{``},
+			want:     locs,
+		},
+		{
+			name:     "multi-line anchor",
+			patterns: []string{`(?m)^http://`},
+			want:     []string{"http://www.example.com/products/shoes"},
+		},
+		{
+			name:     "alternation of anchored literals",
+			patterns: []string{`^https://www\.example\.com/products/|^https://shop\.example\.com/`},
+			want:     []string{"https://www.example.com/products/shoes", "https://shop.example.com/products/shoes"},
+		},
+		{
+			name:     "mixed",
+			patterns: []string{`^http://`, `search`, `/p/\d`},
+			want:     []string{"http://www.example.com/products/shoes", "https://www.example.com/search?q=products", "https://www.example.com/p/123"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			regexes := compileAll(t, test.patterns)
+			m := newMatcher(regexes)
+			var got []string
+			for _, loc := range locs {
+				matched := m.match(loc)
+				if len(regexes) > 0 && matched != matchAny(regexes, loc) {
+					t.Errorf("%q: expected the result of the regular expressions %v, got %v", loc, matchAny(regexes, loc), matched)
+				}
+				if matched {
+					got = append(got, loc)
+				}
+			}
+			if len(got) != len(test.want) {
+				t.Fatalf("expected %q, got %q", test.want, got)
+			}
+			for i := range got {
+				if got[i] != test.want[i] {
+					t.Errorf("expected %q, got %q", test.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestLiteralPrefix(t *testing.T) {
+	tests := []struct {
+		pattern      string
+		wantPrefix   string
+		wantAnchored bool
+		wantComplete bool
+	}{
+		{pattern: `^https://www\.example\.com/`, wantPrefix: "https://www.example.com/", wantAnchored: true, wantComplete: true},
+		{pattern: `^https://www\.example\.com/\d+`, wantPrefix: "https://www.example.com/", wantAnchored: true},
+		{pattern: `^https://www\.example\.com/$`, wantPrefix: "https://www.example.com/", wantAnchored: true},
+		{pattern: `^(?i)https://`, wantAnchored: true},
+		{pattern: `^`, wantAnchored: true, wantComplete: true},
+		{pattern: `products`, wantPrefix: "products", wantComplete: true},
+		{pattern: `products/\d+`},
+		{pattern: `(?m)^products`},
+		{pattern: ``},
+	}
+	for _, test := range tests {
+		t.Run(test.pattern, func(t *testing.T) {
+			prefix, anchored, complete := literalPrefix(regexp.MustCompile(test.pattern))
+			if prefix != test.wantPrefix || anchored != test.wantAnchored || complete != test.wantComplete {
+				t.Errorf("expected (%q, %v, %v), got (%q, %v, %v)", test.wantPrefix, test.wantAnchored, test.wantComplete, prefix, anchored, complete)
+			}
+		})
+	}
+}
+
+// evenNumbered reports whether the last path segment of the location ends with an even number, e.g. "page-02".
+func evenNumbered(loc string) bool {
+	last := loc[len(loc)-1]
+	if strings.HasSuffix(loc, ".xml") {
+		last = loc[len(loc)-len(".xml")-1]
+	}
+	return (last-'0')%2 == 0
+}
+
+func TestS_SetURLMatcher(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	tests := []struct {
+		name                string
+		s                   func() *S
+		wantLocs            []string
+		wantFilteredURLs    map[Filter]int64
+		wantSkippedByFilter map[Filter][]string
+	}{
+		{
+			name:     "no matchers",
+			s:        func() *S { return New() },
+			wantLocs: []string{"/shard-01/page-01", "/shard-01/page-02", "/shard-01/page-03", "/shard-01/page-04", "/shard-01/page-05", "/shard-02/page-01", "/shard-02/page-02", "/shard-02/page-03", "/shard-03/page-01", "/shard-03/page-02", "/shard-04/page-01"},
+		},
+		{
+			name:             "URL matcher",
+			s:                func() *S { return New().SetURLMatcher(evenNumbered) },
+			wantLocs:         []string{"/shard-01/page-02", "/shard-01/page-04", "/shard-02/page-02", "/shard-03/page-02"},
+			wantFilteredURLs: map[Filter]int64{FilterURLMatcher: 7},
+		},
+		{
+			name:             "URL matcher and rules",
+			s:                func() *S { return New().SetURLMatcher(evenNumbered).SetRules([]string{`/shard-0[12]/`}) },
+			wantLocs:         []string{"/shard-01/page-02", "/shard-01/page-04", "/shard-02/page-02"},
+			wantFilteredURLs: map[Filter]int64{FilterURLMatcher: 7, FilterRules: 1},
+		},
+		{
+			name:                "sitemap matcher",
+			s:                   func() *S { return New().SetSitemapMatcher(evenNumbered) },
+			wantLocs:            []string{"/shard-02/page-01", "/shard-02/page-02", "/shard-02/page-03", "/shard-04/page-01"},
+			wantSkippedByFilter: map[Filter][]string{FilterSitemapMatcher: {"/sitemap-shard-01.xml", "/sitemap-shard-03.xml"}},
+		},
+		{
+			name:                "sitemap matcher and follow",
+			s:                   func() *S { return New().SetSitemapMatcher(evenNumbered).SetFollow([]string{`shard-0[1-3]`}) },
+			wantLocs:            []string{"/shard-02/page-01", "/shard-02/page-02", "/shard-02/page-03"},
+			wantSkippedByFilter: map[Filter][]string{FilterSitemapMatcher: {"/sitemap-shard-01.xml", "/sitemap-shard-03.xml"}, FilterFollow: {"/sitemap-shard-04.xml"}},
+		},
+	}
+
+	for _, test := range tests {
+		for _, multiThread := range []bool{true, false} {
+			t.Run(fmt.Sprintf("%s, multiThread=%v", test.name, multiThread), func(t *testing.T) {
+				s, err := test.s().SetMultiThread(multiThread).Parse(server.URL+"/sitemapindex-shards.xml", nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := trimPrefixes(sortedLocs(s.GetURLs()), server.URL); !reflect.DeepEqual(got, test.wantLocs) {
+					t.Errorf("expected %v, got %v", test.wantLocs, got)
+				}
+				wantFilteredURLs := test.wantFilteredURLs
+				if wantFilteredURLs == nil {
+					wantFilteredURLs = map[Filter]int64{}
+				}
+				if got := s.GetFilteredURLCounts(); !reflect.DeepEqual(got, wantFilteredURLs) {
+					t.Errorf("expected filtered URLs %v, got %v", wantFilteredURLs, got)
+				}
+				gotSkipped := map[Filter][]string{}
+				for filter, locs := range s.GetSkippedSitemapsByFilter() {
+					gotSkipped[filter] = trimPrefixes(locs, server.URL)
+					sort.Strings(gotSkipped[filter])
+				}
+				wantSkipped := test.wantSkippedByFilter
+				if wantSkipped == nil {
+					wantSkipped = map[Filter][]string{}
+				}
+				if !reflect.DeepEqual(gotSkipped, wantSkipped) {
+					t.Errorf("expected skipped sitemaps %v, got %v", wantSkipped, gotSkipped)
+				}
+			})
+		}
+	}
+
+	t.Run("called before storing", func(t *testing.T) {
+		var calls int64
+		s, err := New().SetMaxURLs(2).SetURLMatcher(func(loc string) bool {
+			atomic.AddInt64(&calls, 1)
+			return evenNumbered(loc)
+		}).SetMultiThread(false).Parse(server.URL+"/sitemapindex-shards.xml", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the rejected URLs do not count against the limit of URLs
+		if got := trimPrefixes(locsOf(s.GetURLs()), server.URL); !reflect.DeepEqual(got, []string{"/shard-01/page-02", "/shard-01/page-04"}) {
+			t.Errorf("expected the even-numbered pages, got %v", got)
+		}
+		if atomic.LoadInt64(&calls) < 5 {
+			t.Errorf("expected the matcher to be called for every URL of the first sitemap, got %d calls", calls)
+		}
+	})
+}
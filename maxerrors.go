@@ -0,0 +1,88 @@
+package sitemap
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrErrorLimitReached is matched by errors.Is for every ErrorLimitError.
+var ErrErrorLimitReached = errors.New("error limit reached")
+
+// ErrorLimitError is the error returned by Parse when the parse is aborted because the maximum number of errors was reached, see SetMaxErrors.
+// It is also recorded as the last of the errors. The Max field is the maximum number of errors set.
+type ErrorLimitError struct {
+	Max int
+}
+
+// Error returns the message of the error.
+func (e *ErrorLimitError) Error() string {
+	return fmt.Sprintf("error limit reached: %s recorded", plural(int64(e.Max), "error", "errors"))
+}
+
+// Is reports whether target is ErrErrorLimitReached.
+func (e *ErrorLimitError) Is(target error) bool {
+	return target == ErrErrorLimitReached
+}
+
+// SetMaxErrors sets the maximum number of errors of any kind recorded by a parse, for the callers who consider
+// that many errors a sign of something systematically wrong. Once the n-th error is recorded, the parse is aborted:
+// no further sitemaps are fetched, the fetches in progress are cancelled, the errors of the aborted work are dropped,
+// and Parse returns an *ErrorLimitError (also recorded after the n errors) along with the partial results.
+// The parse is reported as truncated by TruncatedByMaxErrors, see GetCompleteness.
+// Unlike SetMaxFailureRate, the limit does not depend on the number of sitemaps processed. The warnings are not counted, unless set otherwise, see SetCountWarnings.
+// A value of 0 (the default) or below means no limit.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetMaxErrors(n int) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.maxErrors = n
+
+	return s
+}
+
+// appendError appends the error to the errs field and aborts the parse if the maximum number of errors is reached, see SetMaxErrors.
+// Once the parse has been aborted this way, the error is dropped.
+// It must be called with s.mu held.
+func (s *S) appendError(err error) {
+	if s.errorLimitErr != nil {
+		return
+	}
+	s.errs = append(s.errs, err)
+	s.observeError(err)
+	s.checkErrorLimit()
+}
+
+// checkErrorLimit aborts the parse if the number of errors recorded, including the warnings if they count (see SetCountWarnings),
+// reached the maximum number of errors.
+// It must be called with s.mu held.
+func (s *S) checkErrorLimit() {
+	count := len(s.errs)
+	if s.cfg.countWarnings {
+		count += len(s.warnings)
+	}
+	if s.cfg.maxErrors <= 0 || count < s.cfg.maxErrors {
+		return
+	}
+
+	s.errorLimitErr = &ErrorLimitError{Max: s.cfg.maxErrors}
+	s.errs = append(s.errs, s.errorLimitErr)
+	s.observeError(s.errorLimitErr)
+	s.truncate(TruncatedByMaxErrors)
+	if s.cancelParse != nil {
+		s.cancelParse()
+	}
+}
+
+// abortErr returns the error the current parse has been aborted with, by the failure rate or the maximum number of errors,
+// the first one if both limits were hit, or nil if it has not been aborted.
+// It must be called with s.mu held.
+func (s *S) abortErr() error {
+	switch {
+	case s.failureRateErr != nil:
+		return s.failureRateErr
+	case s.errorLimitErr != nil:
+		return s.errorLimitErr
+	}
+	return nil
+}
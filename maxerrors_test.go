@@ -0,0 +1,135 @@
+package sitemap
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestS_SetMaxErrors(t *testing.T) {
+	s := New().SetMaxErrors(25)
+	if s.cfg.maxErrors != 25 {
+		t.Errorf("expected 25, got %d", s.cfg.maxErrors)
+	}
+}
+
+func TestErrorLimitError(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", &ErrorLimitError{Max: 25})
+	if !errors.Is(err, ErrErrorLimitReached) {
+		t.Errorf("expected %v to match %v", err, ErrErrorLimitReached)
+	}
+	if got, want := err.Error(), "wrapped: error limit reached: 25 errors recorded"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestS_Parse_MaxErrors(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		s           *S
+		wantErr     bool
+		wantErrs    int
+		wantSkipped int
+	}{
+		{
+			name:        "sequential",
+			s:           New().SetMultiThread(false).SetMaxErrors(3),
+			wantErr:     true,
+			wantErrs:    4,
+			wantSkipped: 7,
+		},
+		{
+			name:     "multi-thread",
+			s:        New().SetMaxErrors(3),
+			wantErr:  true,
+			wantErrs: 4,
+		},
+		{
+			name:     "limit not reached",
+			s:        New().SetMaxErrors(11),
+			wantErrs: 10,
+		},
+		{
+			name:     "disabled",
+			s:        New(),
+			wantErrs: 10,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := test.s.Parse(fmt.Sprintf("%s/sitemapindex-404.xml", server.URL), nil)
+
+			errs := s.GetErrors()
+			if len(errs) != test.wantErrs {
+				t.Fatalf("expected %d errors, got %d: %v", test.wantErrs, len(errs), errs)
+			}
+			completeness := s.GetCompleteness()
+			if !test.wantErr {
+				if err != nil {
+					t.Fatalf("unexpected err: %v", err)
+				}
+				if completeness.TruncatedBy != TruncatedByNone {
+					t.Errorf("expected %s, got %s", TruncatedByNone, completeness.TruncatedBy)
+				}
+				return
+			}
+
+			var limitErr *ErrorLimitError
+			if !errors.As(err, &limitErr) || limitErr.Max != 3 {
+				t.Fatalf("expected an *ErrorLimitError of 3, got %v", err)
+			}
+			if last := errs[len(errs)-1]; last != error(limitErr) {
+				t.Errorf("expected the error to be recorded last, got %v", last)
+			}
+			if completeness.TruncatedBy != TruncatedByMaxErrors {
+				t.Errorf("expected %s, got %s", TruncatedByMaxErrors, completeness.TruncatedBy)
+			}
+			if completeness.FailedSitemaps+completeness.SkippedSitemaps != 10 {
+				t.Errorf("expected 10 failed or skipped sitemaps, got %d and %d", completeness.FailedSitemaps, completeness.SkippedSitemaps)
+			}
+			if test.wantSkipped > 0 && completeness.SkippedSitemaps != test.wantSkipped {
+				t.Errorf("expected %d skipped sitemaps, got %d", test.wantSkipped, completeness.SkippedSitemaps)
+			}
+		})
+	}
+}
+
+func TestS_Parse_MaxErrors_CancelsInFlight(t *testing.T) {
+	var requests int64
+	// sitemaps 1-5 fail at once, sitemaps 6-10 hang until their request is cancelled
+	server := failingIndexServer(10, func(i int) bool { return i <= 5 }, func(i int) bool { return i > 5 }, &requests)
+	defer server.Close()
+
+	start := time.Now()
+	s, err := New().SetFetchTimeout(10).SetMaxErrors(5).Parse(server.URL+"/index.xml", nil)
+	if !errors.Is(err, ErrErrorLimitReached) {
+		t.Fatalf("expected %v, got %v", ErrErrorLimitReached, err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the fetches in progress to be cancelled, the parse took %v", elapsed)
+	}
+	// the errors of the cancelled fetches are dropped
+	if got := s.GetErrorsCount(); got != 6 {
+		t.Errorf("expected 6 errors, got %d: %v", got, s.GetErrors())
+	}
+	if err := s.context().Err(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestS_Parse_MaxErrors_MainURL(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	s, err := New().SetMaxErrors(1).Parse(fmt.Sprintf("%s/missing.xml", server.URL), nil)
+	if err == nil || errors.Is(err, ErrErrorLimitReached) {
+		t.Errorf("expected the error of the main URL, got %v", err)
+	}
+	if errs := s.GetErrors(); len(errs) != 2 || !errors.Is(errs[1], ErrErrorLimitReached) {
+		t.Errorf("expected the error of the main URL and the limit, got %v", errs)
+	}
+}
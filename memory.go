@@ -0,0 +1,34 @@
+package sitemap
+
+// urlMemoryOverhead is the estimated memory retained by a stored URL besides its location:
+// the URL structure itself and the values its pointer fields point to.
+const urlMemoryOverhead = 192
+
+// urlMemorySize returns the estimated memory retained by the given URL once stored.
+// The location of the sitemap the URL was found in is shared by all its URLs, so it is not counted.
+func urlMemorySize(u URL) int64 {
+	return int64(len(u.Loc)) + urlMemoryOverhead
+}
+
+// locMemoryOverhead is the estimated memory retained by a location stored in loc-only mode besides its bytes: the string header.
+const locMemoryOverhead = 16
+
+// storedMemorySize returns the estimated memory retained by the given URL once stored,
+// only its location in loc-only mode, see SetLocOnly.
+func (s *S) storedMemorySize(u URL) int64 {
+	if s.cfg.locOnly {
+		return int64(len(u.Loc)) + locMemoryOverhead
+	}
+	return urlMemorySize(u)
+}
+
+// reserveMemory adds n bytes to the estimated retained memory, if it fits in the memory budget.
+// It reports whether the memory was reserved; without a memory budget, it always succeeds.
+// It must be called with s.mu held.
+func (s *S) reserveMemory(n int64) bool {
+	if s.cfg.memoryBudget > 0 && s.memoryUsed+n > s.cfg.memoryBudget {
+		return false
+	}
+	s.memoryUsed += n
+	return true
+}
@@ -0,0 +1,74 @@
+package sitemap
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestS_Parse_MemoryBudget(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	t.Run("fixtures", func(t *testing.T) {
+		url := fmt.Sprintf("%s/robots-with-sitemapindex/robots.txt", server.URL)
+		full, err := New().SetMultiThread(false).Parse(url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		budget := int64(len(full.mainURLContent))
+		for _, u := range full.GetURLs()[:3] {
+			budget += urlMemorySize(u)
+		}
+
+		s, err := New().SetMultiThread(false).SetMemoryBudget(budget).Parse(url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.GetURLCount() != 3 {
+			t.Errorf("expected 3 URLs, got %d", s.GetURLCount())
+		}
+		if got := s.GetCompleteness().TruncatedBy; got != TruncatedByMemoryBudget {
+			t.Errorf("expected truncation by %s, got %s", TruncatedByMemoryBudget, got)
+		}
+		if s.mainURLContent != "" {
+			t.Errorf("expected the main URL content to be released, got %q", s.mainURLContent)
+		}
+	})
+
+	t.Run("large sitemap", func(t *testing.T) {
+		var content strings.Builder
+		content.WriteString(`<?xml version="1.0" encoding="UTF-8"?><urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`)
+		for i := 0; i < 50000; i++ {
+			_, _ = fmt.Fprintf(&content, "<url><loc>https://www.sitemaps.org/page-%05d</loc></url>", i)
+		}
+		content.WriteString(`</urlset>`)
+		urlSetContent := content.String()
+
+		const budget = 1 << 20
+		s, err := New().SetMemoryBudget(budget).Parse("https://www.sitemaps.org/sitemap.xml", &urlSetContent)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if s.GetURLCount() == 0 || s.GetURLCount() >= 50000 {
+			t.Errorf("expected the URLs to be truncated, got %d", s.GetURLCount())
+		}
+		if s.memoryUsed > budget {
+			t.Errorf("expected the estimated memory to stay within %d, got %d", budget, s.memoryUsed)
+		}
+		if got := s.GetCompleteness().TruncatedBy; got != TruncatedByMemoryBudget {
+			t.Errorf("expected truncation by %s, got %s", TruncatedByMemoryBudget, got)
+		}
+	})
+
+	t.Run("no budget", func(t *testing.T) {
+		s, err := New().Parse(fmt.Sprintf("%s/robots-with-sitemapindex/robots.txt", server.URL), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !s.GetCompleteness().Complete {
+			t.Errorf("expected a complete parse, got %+v", s.GetCompleteness())
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package sitemap
+
+import (
+	"time"
+)
+
+// FetchMeta is a structure that holds metadata of a single fetch performed during parsing.
+// The StatusCode field is the HTTP status code of the response, or 0 if no response was received.
+// The ContentType field is the Content-Type header of the response.
+// The CompressedBytes field is the size of the response body as received.
+// The DecompressedBytes field is the size of the response body after decompression (equal to CompressedBytes if it was not compressed).
+// The Duration field is the time taken by the request, including reading the response body.
+// The ETag and LastModified fields are the ETag and Last-Modified validator headers of the final response (after redirects),
+// which can be used for conditional requests by external caches.
+// The Retries field is the number of times the location was re-fetched because its compressed content arrived corrupted, see SetRetries.
+// The CacheHit field is true if the content was served from the body cache without a request, see SetBodyCache;
+// only the sizes are set in that case.
+// The HTTPSUpgrade field is true if the http location was fetched over https, see SetUpgradeToHTTPS. The HTTPSFallback field is true
+// if the https fetch failed without a response and the original http location was fetched instead; the other fields describe that fetch.
+type FetchMeta struct {
+	StatusCode        int           `json:"status_code"`
+	ContentType       string        `json:"content_type"`
+	CompressedBytes   int64         `json:"compressed_bytes"`
+	DecompressedBytes int64         `json:"decompressed_bytes"`
+	Duration          time.Duration `json:"duration"`
+	ETag              string        `json:"etag,omitempty"`
+	LastModified      string        `json:"last_modified,omitempty"`
+	Retries           int           `json:"retries,omitempty"`
+	CacheHit          bool          `json:"cache_hit,omitempty"`
+	HTTPSUpgrade      bool          `json:"https_upgrade,omitempty"`
+	HTTPSFallback     bool          `json:"https_fallback,omitempty"`
+}
+
+// GetFetchMetadata returns the metadata of every location fetched during parsing, keyed by location.
+// The returned map is a fresh copy. If the S object is nil, an empty map is returned.
+func (s *S) GetFetchMetadata() map[string]FetchMeta {
+	metadata := map[string]FetchMeta{}
+	if s == nil {
+		return metadata
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for loc, meta := range s.fetchMeta {
+		metadata[loc] = meta
+	}
+	return metadata
+}
+
+// recordFetchMeta stores the metadata of the fetch of the given location.
+func (s *S) recordFetchMeta(loc string, meta FetchMeta) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.fetchMeta == nil {
+		s.fetchMeta = map[string]FetchMeta{}
+	}
+	s.fetchMeta[loc] = meta
+}
+
+// recordDecompressedBytes updates the decompressed size in the metadata of the given location, if it has been fetched.
+func (s *S) recordDecompressedBytes(loc string, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	meta, ok := s.fetchMeta[loc]
+	if !ok {
+		return
+	}
+	meta.DecompressedBytes = int64(n)
+	s.fetchMeta[loc] = meta
+}
@@ -0,0 +1,143 @@
+package sitemap
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestS_GetFetchMetadata(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	tests := []struct {
+		name           string
+		url            string
+		content        *string
+		multiThread    bool
+		wantLocations  []string
+		wantStatuses   map[string]int
+		wantCompressed []string
+	}{
+		{
+			name:        "robots.txt with gzipped sitemapindex",
+			url:         fmt.Sprintf("%s/robots-with-sitemapindex-gz/robots.txt", server.URL),
+			multiThread: true,
+			wantLocations: []string{
+				"/robots-with-sitemapindex-gz/robots.txt",
+				"/sitemap-01.xml.gz",
+				"/sitemap-02.xml.gz",
+				"/sitemap-03.xml.gz",
+				"/sitemapindex-1.xml.gz",
+			},
+			wantStatuses: map[string]int{
+				"/robots-with-sitemapindex-gz/robots.txt": 200,
+				"/sitemapindex-1.xml.gz":                  200,
+				"/sitemap-01.xml.gz":                      200,
+			},
+			wantCompressed: []string{"/sitemapindex-1.xml.gz", "/sitemap-01.xml.gz"},
+		},
+		{
+			name:          "sitemapindex with invalid sitemap",
+			url:           fmt.Sprintf("%s/sitemapindex-with-invalid-sitemap.xml", server.URL),
+			multiThread:   false,
+			wantLocations: []string{"/invalid.xml", "/sitemapindex-with-invalid-sitemap.xml"},
+			wantStatuses: map[string]int{
+				"/sitemapindex-with-invalid-sitemap.xml": 200,
+				"/invalid.xml":                           404,
+			},
+		},
+		{
+			name:          "content provided",
+			url:           fmt.Sprintf("%s/sitemap-01.xml", server.URL),
+			content:       pointerOfString("<urlset></urlset>"),
+			multiThread:   false,
+			wantLocations: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, _ := New().SetMultiThread(test.multiThread).Parse(test.url, test.content)
+			metadata := s.GetFetchMetadata()
+
+			locations := make([]string, 0, len(metadata))
+			for loc := range metadata {
+				locations = append(locations, strings.TrimPrefix(loc, server.URL))
+			}
+			sort.Strings(locations)
+			if strings.Join(locations, ",") != strings.Join(test.wantLocations, ",") {
+				t.Fatalf("expected locations %v, got %v", test.wantLocations, locations)
+			}
+
+			for loc, status := range test.wantStatuses {
+				meta := metadata[server.URL+loc]
+				if meta.StatusCode != status {
+					t.Errorf("%s: expected status %d, got %d", loc, status, meta.StatusCode)
+				}
+				if meta.Duration <= 0 {
+					t.Errorf("%s: expected positive duration, got %v", loc, meta.Duration)
+				}
+				if status == 200 && (meta.ContentType == "" || meta.CompressedBytes == 0 || meta.DecompressedBytes == 0) {
+					t.Errorf("%s: incomplete metadata %+v", loc, meta)
+				}
+			}
+			for _, loc := range test.wantCompressed {
+				meta := metadata[server.URL+loc]
+				if meta.DecompressedBytes <= meta.CompressedBytes {
+					t.Errorf("%s: expected decompressed size to exceed compressed size, got %+v", loc, meta)
+				}
+			}
+		})
+	}
+
+	t.Run("nil receiver", func(t *testing.T) {
+		var s *S
+		if len(s.GetFetchMetadata()) != 0 {
+			t.Error("expected empty metadata")
+		}
+	})
+}
+
+func TestS_GetFetchMetadata_Validators(t *testing.T) {
+	fixtures := testServer()
+	defer fixtures.Close()
+
+	lastModified := "Mon, 12 Feb 2024 11:34:56 GMT"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the sitemapindex is served through a redirect, the validators must come from the final response
+		if r.URL.Path == "/sitemapindex-1.xml" {
+			http.Redirect(w, r, "/moved/sitemapindex-1.xml", http.StatusFound)
+			return
+		}
+		r.RequestURI = strings.TrimPrefix(r.RequestURI, "/moved")
+		r.URL.Path = strings.TrimPrefix(r.URL.Path, "/moved")
+		w.Header().Set("ETag", fmt.Sprintf("%q", r.RequestURI))
+		w.Header().Set("Last-Modified", lastModified)
+		fixtures.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	s, err := New().Parse(fmt.Sprintf("%s/robots-with-sitemapindex/robots.txt", server.URL), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metadata := s.GetFetchMetadata()
+	for _, path := range []string{"/robots-with-sitemapindex/robots.txt", "/sitemapindex-1.xml", "/sitemap-01.xml", "/sitemap-02.xml", "/sitemap-03.xml"} {
+		meta, ok := metadata[server.URL+path]
+		if !ok {
+			t.Errorf("%s: missing metadata", path)
+			continue
+		}
+		if meta.ETag != fmt.Sprintf("%q", path) {
+			t.Errorf("%s: expected ETag %q, got %q", path, fmt.Sprintf("%q", path), meta.ETag)
+		}
+		if meta.LastModified != lastModified {
+			t.Errorf("%s: expected Last-Modified %q, got %q", path, lastModified, meta.LastModified)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package sitemap
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// errUnknownContent is the error of a document which is neither a sitemap index nor a sitemap.
+var errUnknownContent = errors.New("the content is neither sitemapindex nor sitemap")
+
+// MetricsCollector receives the metrics of the parses as they run, set with SetMetricsCollector, e.g. to push them to Prometheus counters.
+// IncFetch is called for each HTTP request sent, with the status of the response, 0 if none was received.
+// ObserveFetchDuration is called with the duration of each request, up to the end of reading the body.
+// AddBytes is called with the size of each body read, as received.
+// IncURLs is called with the number of URLs collected from each sitemap, if any.
+// IncErrors is called for each error recorded, with its kind, see SetMetricsCollector.
+// The methods are called concurrently from several goroutines when multi-threading is on, so implementations must be safe for concurrent use.
+// Some of them are called while holding the lock of the S structure, so they must be fast and must not call the methods of the S structure.
+type MetricsCollector interface {
+	IncFetch(status int)
+	ObserveFetchDuration(d time.Duration)
+	AddBytes(n int64)
+	IncURLs(n int)
+	IncErrors(kind string)
+}
+
+// SetMetricsCollector sets the collector receiving the metrics of the parses as they run, e.g. an adapter to Prometheus counters.
+// Unlike the results of the getters, e.g. GetTiming, the metrics are received while the parse is in progress.
+// The kind of an error is the category of a fetch error (see ErrorCategory, e.g. "timeout" or "http_status"),
+// "decode" for a document which cannot be decoded, "decompression", "too_large", "unsupported_scheme", "circuit_open", "panic",
+// "aborted" for the error a parse is aborted with (see SetMaxFailureRate and SetMaxErrors), and "other" for the rest.
+// The files read by ParseFS and the bodies found in the body cache are not fetched, so they are not counted as fetches.
+// A nil collector (the default) collects nothing, at no cost.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetMetricsCollector(mc MetricsCollector) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.metrics = mc
+
+	return s
+}
+
+// observeFetch sends the metrics of a request sent, with the given metadata, to the metrics collector, if any.
+func (s *S) observeFetch(meta FetchMeta) {
+	if s.cfg.metrics == nil {
+		return
+	}
+	s.cfg.metrics.IncFetch(meta.StatusCode)
+	s.cfg.metrics.ObserveFetchDuration(meta.Duration)
+	s.cfg.metrics.AddBytes(meta.CompressedBytes)
+}
+
+// observeError sends the given error recorded to the metrics collector, if any.
+// It must be called with s.mu held.
+func (s *S) observeError(err error) {
+	if s.cfg.metrics == nil {
+		return
+	}
+	s.cfg.metrics.IncErrors(errorKind(err))
+}
+
+// errorKind returns the kind of the given error reported to the metrics collector, see SetMetricsCollector.
+func errorKind(err error) string {
+	var fetchErr *FetchError
+	var decompressionErr *DecompressionError
+	var decodeLimitErr *DecodeLimitError
+	var tooLargeErr *TooLargeError
+	var unsupportedSchemeErr *UnsupportedSchemeError
+	var circuitOpenErr *CircuitOpenError
+	var panicErr *PanicError
+
+	switch {
+	case errors.As(err, &fetchErr):
+		return string(fetchErr.Category())
+	case errors.As(err, &decodeLimitErr),
+		errors.Is(err, ErrDoctypeNotAllowed),
+		errors.Is(err, errUnknownContent):
+		return "decode"
+	case errors.As(err, &decompressionErr):
+		return "decompression"
+	case errors.As(err, &tooLargeErr):
+		return "too_large"
+	case errors.As(err, &unsupportedSchemeErr):
+		return "unsupported_scheme"
+	case errors.As(err, &circuitOpenErr):
+		return "circuit_open"
+	case errors.As(err, &panicErr):
+		return "panic"
+	case errors.Is(err, ErrFailureRateExceeded),
+		errors.Is(err, ErrErrorLimitReached):
+		return "aborted"
+	}
+	return "other"
+}
+
+type (
+	// MemoryMetrics is a thread-safe MetricsCollector keeping the metrics in memory, e.g. to check them in tests, see Snapshot.
+	// The zero value is ready to use. The metrics field holds the metrics collected so far.
+	MemoryMetrics struct {
+		mu      sync.Mutex
+		metrics Metrics
+	}
+
+	// Metrics holds the metrics collected by a MemoryMetrics.
+	// The Fetches field counts the requests sent per response status, 0 for the requests without a response.
+	// The FetchDuration field is the total duration of the requests, the Bytes field is the total size of the bodies read.
+	// The URLs field is the number of URLs collected. The Errors field counts the errors recorded per kind, see SetMetricsCollector.
+	Metrics struct {
+		Fetches       map[int]int64    `json:"fetches"`
+		FetchDuration time.Duration    `json:"fetch_duration"`
+		Bytes         int64            `json:"bytes"`
+		URLs          int64            `json:"urls"`
+		Errors        map[string]int64 `json:"errors"`
+	}
+)
+
+// IncFetch counts a request sent, with the given response status.
+func (m *MemoryMetrics) IncFetch(status int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.metrics.Fetches == nil {
+		m.metrics.Fetches = map[int]int64{}
+	}
+	m.metrics.Fetches[status]++
+}
+
+// ObserveFetchDuration adds the duration of a request.
+func (m *MemoryMetrics) ObserveFetchDuration(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.metrics.FetchDuration += d
+}
+
+// AddBytes adds the size of a body read.
+func (m *MemoryMetrics) AddBytes(n int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.metrics.Bytes += n
+}
+
+// IncURLs adds the number of URLs collected from a sitemap.
+func (m *MemoryMetrics) IncURLs(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.metrics.URLs += int64(n)
+}
+
+// IncErrors counts an error recorded, with the given kind.
+func (m *MemoryMetrics) IncErrors(kind string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.metrics.Errors == nil {
+		m.metrics.Errors = map[string]int64{}
+	}
+	m.metrics.Errors[kind]++
+}
+
+// Snapshot returns a copy of the metrics collected so far, the maps are empty if nothing was counted.
+func (m *MemoryMetrics) Snapshot() Metrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := m.metrics
+	snapshot.Fetches = make(map[int]int64, len(m.metrics.Fetches))
+	for status, n := range m.metrics.Fetches {
+		snapshot.Fetches[status] = n
+	}
+	snapshot.Errors = make(map[string]int64, len(m.metrics.Errors))
+	for kind, n := range m.metrics.Errors {
+		snapshot.Errors[kind] = n
+	}
+	return snapshot
+}
@@ -0,0 +1,170 @@
+package sitemap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestS_SetMetricsCollector(t *testing.T) {
+	mc := &MemoryMetrics{}
+	s := New().SetMetricsCollector(mc)
+	if s.cfg.metrics != mc {
+		t.Errorf("expected %v, got %v", mc, s.cfg.metrics)
+	}
+}
+
+func TestS_Parse_Metrics(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		s           *S
+		path        string
+		wantFetches map[int]int64
+		wantURLs    int64
+		wantErrors  map[string]int64
+	}{
+		{
+			name:        "sitemap index",
+			s:           New(),
+			path:        "sitemapindex-1.xml",
+			wantFetches: map[int]int64{200: 4},
+			wantURLs:    6,
+			wantErrors:  map[string]int64{},
+		},
+		{
+			name:        "missing sitemaps",
+			s:           New(),
+			path:        "sitemapindex-404.xml",
+			wantFetches: map[int]int64{200: 1, 404: 10},
+			wantErrors:  map[string]int64{"http_status": 10},
+		},
+		{
+			name:        "missing sitemaps, aborted",
+			s:           New().SetMultiThread(false).SetMaxErrors(3),
+			path:        "sitemapindex-404.xml",
+			wantFetches: map[int]int64{200: 1, 404: 3},
+			wantErrors:  map[string]int64{"http_status": 3, "aborted": 1},
+		},
+		{
+			name:        "invalid content",
+			s:           New(),
+			path:        "example",
+			wantFetches: map[int]int64{200: 1},
+			wantErrors:  map[string]int64{"decode": 1},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mc := &MemoryMetrics{}
+			s, _ := test.s.SetMetricsCollector(mc).Parse(fmt.Sprintf("%s/%s", server.URL, test.path), nil)
+
+			metrics := mc.Snapshot()
+			if !reflect.DeepEqual(metrics.Fetches, test.wantFetches) {
+				t.Errorf("expected fetches %v, got %v", test.wantFetches, metrics.Fetches)
+			}
+			if metrics.URLs != test.wantURLs || metrics.URLs != s.GetURLCount() {
+				t.Errorf("expected %d URLs, got %d", test.wantURLs, metrics.URLs)
+			}
+			if !reflect.DeepEqual(metrics.Errors, test.wantErrors) {
+				t.Errorf("expected errors %v, got %v", test.wantErrors, metrics.Errors)
+			}
+			if metrics.FetchDuration <= 0 {
+				t.Errorf("expected a positive fetch duration, got %v", metrics.FetchDuration)
+			}
+			var bytes int64
+			for _, meta := range s.GetFetchMetadata() {
+				bytes += meta.CompressedBytes
+			}
+			if metrics.Bytes != bytes {
+				t.Errorf("expected %d bytes, got %d", bytes, metrics.Bytes)
+			}
+		})
+	}
+}
+
+func TestErrorKind(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "fetch error",
+			err:  locationError("https://www.example.com/sitemap.xml", &FetchError{StatusCode: 404, Err: errors.New("received HTTP status 404")}),
+			want: "http_status",
+		},
+		{
+			name: "canceled fetch",
+			err:  &FetchError{Err: context.Canceled},
+			want: "canceled",
+		},
+		{
+			name: "unknown content",
+			err:  locationError("https://www.example.com/sitemap.xml", errUnknownContent),
+			want: "decode",
+		},
+		{
+			name: "decode limit",
+			err:  &DecodeLimitError{Limit: DecodeLimitDepth, Max: 64},
+			want: "decode",
+		},
+		{
+			name: "decompression",
+			err:  &DecompressionError{Truncated: true, Discarded: true},
+			want: "decompression",
+		},
+		{
+			name: "too large",
+			err:  &TooLargeError{},
+			want: "too_large",
+		},
+		{
+			name: "unsupported scheme",
+			err:  &UnsupportedSchemeError{Scheme: "ftp"},
+			want: "unsupported_scheme",
+		},
+		{
+			name: "panic",
+			err:  &PanicError{Value: "boom"},
+			want: "panic",
+		},
+		{
+			name: "error limit",
+			err:  &ErrorLimitError{Max: 3},
+			want: "aborted",
+		},
+		{
+			name: "other",
+			err:  errors.New("invalid sitemap URL"),
+			want: "other",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := errorKind(test.err); got != test.want {
+				t.Errorf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestMemoryMetrics_Snapshot(t *testing.T) {
+	mc := &MemoryMetrics{}
+	if got := mc.Snapshot(); got.Fetches == nil || len(got.Fetches) != 0 || got.Errors == nil || len(got.Errors) != 0 {
+		t.Errorf("expected empty maps, got %v", got)
+	}
+
+	mc.IncFetch(200)
+	mc.IncErrors("timeout")
+	snapshot := mc.Snapshot()
+	snapshot.Fetches[200] = 10
+	snapshot.Errors["timeout"] = 10
+	if got := mc.Snapshot(); got.Fetches[200] != 1 || got.Errors["timeout"] != 1 {
+		t.Errorf("expected the snapshot to be a copy, got %v", got)
+	}
+}
@@ -0,0 +1,91 @@
+package sitemap
+
+import (
+	"strings"
+	"time"
+)
+
+// newsMaxAge is the maximum age of the articles of a news sitemap, relative to the time of the parse.
+const newsMaxAge = 48 * time.Hour
+
+// newsPublicationDateFormats are the layouts of the W3C datetime precisions of the <news:publication_date> values:
+// the year, the month, the date, the date with hours and minutes, and the date with seconds and optional fractions of a second.
+var newsPublicationDateFormats = []string{
+	"2006",
+	"2006-01",
+	time.DateOnly,
+	"2006-01-02T15:04Z07:00",
+	time.RFC3339,
+}
+
+// NewsValidation is the result of the validation of the news URLs returned by ValidateNews.
+// The Stale field holds the news URLs with a publication date more than 48 hours before the parse.
+// The InvalidPublicationDates field is the number of news URLs with a missing or invalid publication date.
+type NewsValidation struct {
+	Stale                   []URL
+	InvalidPublicationDates int
+}
+
+// GetNewsURLsSince returns the parsed URLs with a <news:news> publication date at or after t, in the order they were parsed.
+// The news URLs with a missing or invalid publication date are excluded, ValidateNews reports their number.
+// If the S object is nil or no URL matches, an empty slice is returned.
+func (s *S) GetNewsURLsSince(t time.Time) []URL {
+	urls := []URL{}
+	if s == nil {
+		return urls
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.urls {
+		if publicationDate, ok := newsPublicationDate(u); ok && !publicationDate.Before(t) {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// ValidateNews checks the news URLs against the rule of news sitemaps to only contain articles from the last 48 hours.
+// The age of the articles is relative to the start of the parse, or to the current time if no parse has been started.
+// If the S object is nil, an empty NewsValidation is returned.
+func (s *S) ValidateNews() NewsValidation {
+	validation := NewsValidation{Stale: []URL{}}
+	if s == nil {
+		return validation
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	parsedAt := s.parsedAt
+	if parsedAt.IsZero() {
+		parsedAt = time.Now()
+	}
+	cutoff := parsedAt.Add(-newsMaxAge)
+	for _, u := range s.urls {
+		if u.News == nil {
+			continue
+		}
+		publicationDate, ok := newsPublicationDate(u)
+		switch {
+		case !ok:
+			validation.InvalidPublicationDates++
+		case publicationDate.Before(cutoff):
+			validation.Stale = append(validation.Stale, u)
+		}
+	}
+	return validation
+}
+
+// newsPublicationDate returns the <news:publication_date> value of the URL.
+// The value is trimmed and parsed in any of the W3C datetime precisions, see newsPublicationDateFormats.
+// The second return value is false if the URL has no <news:news> or its publication date is missing or invalid.
+func newsPublicationDate(u URL) (time.Time, bool) {
+	if u.News == nil {
+		return time.Time{}, false
+	}
+	publicationDate, err := parseLastModFormats(strings.TrimSpace(u.News.PublicationDate), newsPublicationDateFormats, nil)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return publicationDate, true
+}
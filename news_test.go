@@ -0,0 +1,126 @@
+package sitemap
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestS_GetNewsURLsSince(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	s, err := New().Parse(fmt.Sprintf("%s/sitemap-extensions.xml", server.URL), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		s     *S
+		since time.Time
+		want  []string
+	}{
+		{
+			name:  "nil receiver",
+			s:     nil,
+			since: time.Time{},
+			want:  []string{},
+		},
+		{
+			name:  "at the publication date",
+			s:     s,
+			since: time.Date(2024, 2, 12, 11, 34, 56, 0, time.UTC),
+			want:  []string{fmt.Sprintf("%s/page-03", server.URL)},
+		},
+		{
+			name:  "after the publication date",
+			s:     s,
+			since: time.Date(2024, 2, 12, 11, 34, 57, 0, time.UTC),
+			want:  []string{},
+		},
+		{
+			name: "missing and invalid publication dates",
+			s: &S{urls: []URL{
+				{Loc: "https://www.sitemaps.org/1", News: &News{PublicationDate: "2024-02-12"}},
+				{Loc: "https://www.sitemaps.org/2", News: &News{}},
+				{Loc: "https://www.sitemaps.org/3", News: &News{PublicationDate: "yesterday"}},
+				{Loc: "https://www.sitemaps.org/4"},
+			}},
+			since: time.Time{},
+			want:  []string{"https://www.sitemaps.org/1"},
+		},
+		{
+			name: "W3C datetime precisions",
+			s: &S{urls: []URL{
+				{Loc: "https://www.sitemaps.org/year", News: &News{PublicationDate: "2024"}},
+				{Loc: "https://www.sitemaps.org/month", News: &News{PublicationDate: "2024-02"}},
+				{Loc: "https://www.sitemaps.org/date", News: &News{PublicationDate: "2024-02-12"}},
+				{Loc: "https://www.sitemaps.org/minutes", News: &News{PublicationDate: "2024-02-12T13:30+01:00"}},
+				{Loc: "https://www.sitemaps.org/minutes-utc", News: &News{PublicationDate: "2024-02-12T12:29Z"}},
+				{Loc: "https://www.sitemaps.org/seconds", News: &News{PublicationDate: "2024-02-12T12:30:00Z"}},
+				{Loc: "https://www.sitemaps.org/fraction", News: &News{PublicationDate: "2024-02-12T12:30:00.5Z"}},
+				{Loc: "https://www.sitemaps.org/padded", News: &News{PublicationDate: "\n\t 2024-02-12T13:45+01:00 \n"}},
+			}},
+			since: time.Date(2024, 2, 12, 12, 30, 0, 0, time.UTC),
+			want: []string{
+				"https://www.sitemaps.org/minutes",
+				"https://www.sitemaps.org/seconds",
+				"https://www.sitemaps.org/fraction",
+				"https://www.sitemaps.org/padded",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := locsOf(test.s.GetNewsURLsSince(test.since)); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestS_ValidateNews(t *testing.T) {
+	parsedAt := time.Date(2024, 2, 12, 12, 0, 0, 0, time.UTC)
+	s := &S{
+		parsedAt: parsedAt,
+		urls: []URL{
+			{Loc: "https://www.sitemaps.org/fresh", News: &News{PublicationDate: parsedAt.Add(-47 * time.Hour).Format(time.RFC3339)}},
+			{Loc: "https://www.sitemaps.org/limit", News: &News{PublicationDate: parsedAt.Add(-48 * time.Hour).Format(time.RFC3339)}},
+			{Loc: "https://www.sitemaps.org/minutes", News: &News{PublicationDate: "2024-02-11T10:00Z"}},
+			{Loc: "https://www.sitemaps.org/padded", News: &News{PublicationDate: " 2024-02-11T10:00:00+00:00\n"}},
+			{Loc: "https://www.sitemaps.org/stale", News: &News{PublicationDate: "2024-02-09"}},
+			{Loc: "https://www.sitemaps.org/missing", News: &News{}},
+			{Loc: "https://www.sitemaps.org/invalid", News: &News{PublicationDate: "12/02/2024"}},
+			{Loc: "https://www.sitemaps.org/not-news"},
+		},
+	}
+
+	got := s.ValidateNews()
+	if want := []string{"https://www.sitemaps.org/stale"}; !reflect.DeepEqual(locsOf(got.Stale), want) {
+		t.Errorf("expected stale %v, got %v", want, locsOf(got.Stale))
+	}
+	if got.InvalidPublicationDates != 2 {
+		t.Errorf("expected 2 invalid publication dates, got %d", got.InvalidPublicationDates)
+	}
+
+	t.Run("nil receiver", func(t *testing.T) {
+		var s *S
+		if got := s.ValidateNews(); got.Stale == nil || len(got.Stale) != 0 || got.InvalidPublicationDates != 0 {
+			t.Errorf("expected empty validation, got %+v", got)
+		}
+	})
+
+	t.Run("parse time", func(t *testing.T) {
+		before := time.Now()
+		s, err := New().Parse("https://www.sitemaps.org/sitemap.xml", pointerOfString(`<?xml version="1.0" encoding="UTF-8"?><urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"></urlset>`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.parsedAt.Before(before) {
+			t.Errorf("expected the parse time to be recorded, got %v", s.parsedAt)
+		}
+	})
+}
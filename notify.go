@@ -0,0 +1,291 @@
+package sitemap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultIndexNowEndpoint is the default endpoint URLs are submitted to by IndexNow, shared by the participating search engines.
+	DefaultIndexNowEndpoint = "https://api.indexnow.org/indexnow"
+
+	// maxIndexNowURLs is the maximum number of URLs of a single IndexNow submission.
+	maxIndexNowURLs = 10000
+
+	// defaultRetryBackoff is the delay before the first retry of a request of Ping or IndexNow, see SetRetryBackoff.
+	defaultRetryBackoff = time.Second
+
+	// maxRetryBackoff is the maximum delay between two retries of a request of Ping or IndexNow, unless set by Retry-After.
+	maxRetryBackoff = time.Minute
+)
+
+// DefaultPingEndpoints are the default endpoints notified by Ping, the location of the sitemap is appended to them query-escaped.
+var DefaultPingEndpoints = []string{
+	"https://www.bing.com/ping?sitemap=",
+	"https://webmaster.yandex.com/ping?sitemap=",
+}
+
+// SubmissionResult is the result of a single request of Ping or IndexNow.
+// The Endpoint field is the URL of the endpoint the request was sent to, the URLs field is the number of URLs submitted,
+// the StatusCode field is the HTTP status of the last response (0 if there was none),
+// and the Err field is nil on success, otherwise a *FetchError.
+type SubmissionResult struct {
+	Endpoint   string
+	URLs       int
+	StatusCode int
+	Err        error
+}
+
+// indexNowRequest is the JSON body of an IndexNow submission.
+type indexNowRequest struct {
+	Host    string   `json:"host"`
+	Key     string   `json:"key"`
+	URLList []string `json:"urlList"`
+}
+
+// submitter sends the requests of a single Ping or IndexNow call, throttled like the fetches of a parse.
+type submitter struct {
+	s                *S
+	ctx              context.Context
+	client           *http.Client
+	rateLimiter      *rateLimiter
+	hostRateLimiters *hostRateLimiters
+	hostDelays       *hostDelays
+}
+
+// SetPingEndpoints sets the endpoints notified by Ping, the location of the sitemap is appended to each of them query-escaped.
+// A nil slice restores DefaultPingEndpoints, an empty slice disables pinging.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetPingEndpoints(endpoints []string) *S {
+	if s == nil {
+		return nil
+	}
+	if endpoints == nil {
+		s.cfg.pingEndpoints = nil
+	} else {
+		s.cfg.pingEndpoints = append([]string{}, endpoints...)
+	}
+
+	return s
+}
+
+// SetIndexNowEndpoint sets the endpoint URLs are submitted to by IndexNow, e.g. the endpoint of a single search engine.
+// An empty string restores DefaultIndexNowEndpoint.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetIndexNowEndpoint(endpoint string) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.indexNowEndpoint = endpoint
+
+	return s
+}
+
+// SetRetryBackoff sets the delay before the first retry of a failed request of Ping and IndexNow, see SetRetries.
+// The delay doubles with each retry, up to 1 minute, and the delay set by the Retry-After header of a 429 (Too Many Requests)
+// or 5xx response is waited instead, if any. A value of 0 or less (the default) means 1 second.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetRetryBackoff(backoff time.Duration) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.retryBackoff = backoff
+
+	return s
+}
+
+// Ping notifies the search engines of the (re)published sitemap at sitemapURL with a GET request to each ping endpoint,
+// see SetPingEndpoints. It returns one result per endpoint, in their order; a response with HTTP status 200 (OK) is a success.
+// The requests use the user agent, the timeouts, the TLS configuration and the rate limits of the parser, and a request failing
+// with a network error, HTTP status 429 or a 5xx status is retried up to the number of retries set by SetRetries,
+// after a backoff honoring the Retry-After header of the response, see SetRetryBackoff.
+// It is equivalent to PingContext with context.Background().
+// If the S object is nil, nil is returned.
+func (s *S) Ping(sitemapURL string) []SubmissionResult {
+	return s.PingContext(context.Background(), sitemapURL)
+}
+
+// PingContext notifies the search engines like Ping, sending the requests and waiting for the backoffs with the given context.
+// If the S object is nil, nil is returned.
+func (s *S) PingContext(ctx context.Context, sitemapURL string) []SubmissionResult {
+	if s == nil {
+		return nil
+	}
+	endpoints := s.cfg.pingEndpoints
+	if endpoints == nil {
+		endpoints = DefaultPingEndpoints
+	}
+
+	sub := s.newSubmitter(ctx)
+	results := make([]SubmissionResult, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		result := SubmissionResult{Endpoint: endpoint + neturl.QueryEscape(sitemapURL), URLs: 1}
+		result.StatusCode, result.Err = sub.send(http.MethodGet, result.Endpoint, nil, http.StatusOK)
+		results = append(results, result)
+	}
+	return results
+}
+
+// IndexNow submits the locations of the given URLs, e.g. the changed ones, with the IndexNow protocol:
+// a POST request with a JSON body of the host, the key and the URL list to the IndexNow endpoint, see SetIndexNowEndpoint.
+// The key must be the one published at https://<host>/<key>.txt. The URLs are submitted in batches of at most 10000,
+// and one result is returned per batch, in order; a response with HTTP status 200 (OK) or 202 (Accepted) is a success.
+// The requests are sent, throttled and retried like those of Ping. No request is sent for an empty list of URLs.
+// It is equivalent to IndexNowContext with context.Background().
+// If the S object is nil, nil is returned.
+func (s *S) IndexNow(urls []URL, key string, host string) []SubmissionResult {
+	return s.IndexNowContext(context.Background(), urls, key, host)
+}
+
+// IndexNowContext submits the URLs like IndexNow, sending the requests and waiting for the backoffs with the given context.
+// If the S object is nil, nil is returned.
+func (s *S) IndexNowContext(ctx context.Context, urls []URL, key string, host string) []SubmissionResult {
+	if s == nil {
+		return nil
+	}
+	endpoint := s.cfg.indexNowEndpoint
+	if endpoint == "" {
+		endpoint = DefaultIndexNowEndpoint
+	}
+
+	sub := s.newSubmitter(ctx)
+	results := []SubmissionResult{}
+	for start := 0; start < len(urls); start += maxIndexNowURLs {
+		batch := urls[start:min(start+maxIndexNowURLs, len(urls))]
+		request := indexNowRequest{Host: host, Key: key, URLList: make([]string, 0, len(batch))}
+		for _, u := range batch {
+			request.URLList = append(request.URLList, u.Loc)
+		}
+
+		result := SubmissionResult{Endpoint: endpoint, URLs: len(batch)}
+		body, err := json.Marshal(request)
+		if err != nil {
+			result.Err = &FetchError{URL: endpoint, Err: err}
+		} else {
+			result.StatusCode, result.Err = sub.send(http.MethodPost, endpoint, body, http.StatusOK, http.StatusAccepted)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// newSubmitter creates a submitter sending its requests with the given context and the settings of the parser.
+// The transport of the parses is shared, see initTransport.
+func (s *S) newSubmitter(ctx context.Context) *submitter {
+	s.initTransport()
+	return &submitter{
+		s:   s,
+		ctx: ctx,
+		client: &http.Client{
+			Transport: s.transport,
+			Timeout:   time.Duration(s.cfg.fetchTimeout) * time.Second,
+		},
+		rateLimiter:      newRateLimiter(s.cfg.rateLimit),
+		hostRateLimiters: newHostRateLimiters(s.cfg.perHostRateLimit),
+		hostDelays:       newHostDelays(s.cfg.requestDelay, s.cfg.requestDelayJitter, s.randInt63n),
+	}
+}
+
+// send sends a request with the given method and body to the endpoint, retrying it as long as the failure is transient
+// and retries are left, after a backoff, see SetRetryBackoff. The backoff is cut short when the context of the submitter is done.
+// It returns the HTTP status of the last response and a *FetchError unless the status is one of accepted.
+func (sub *submitter) send(method string, endpoint string, body []byte, accepted ...int) (int, error) {
+	backoff := sub.s.cfg.retryBackoff
+	if backoff <= 0 {
+		backoff = defaultRetryBackoff
+	}
+	for retries := 0; ; retries++ {
+		statusCode, retryAfter, err := sub.sendOnce(method, endpoint, body, accepted)
+		if err == nil || retries >= sub.s.cfg.retries || !retryableStatus(statusCode) {
+			return statusCode, err
+		}
+		delay := backoff
+		if retryAfter >= 0 {
+			delay = retryAfter
+		}
+		if sleepErr := sleep(sub.ctx, delay); sleepErr != nil {
+			return statusCode, err
+		}
+		backoff = min(2*backoff, maxRetryBackoff)
+	}
+}
+
+// sendOnce sends a single request, see send. It returns the delay set by the Retry-After header of the response, -1 if there is none.
+func (sub *submitter) sendOnce(method string, endpoint string, body []byte, accepted []int) (int, time.Duration, error) {
+	req, err := http.NewRequestWithContext(sub.ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, -1, &FetchError{URL: endpoint, Err: err}
+	}
+
+	host := strings.ToLower(req.URL.Host)
+	release, err := sub.hostDelays.acquire(req.Context(), host)
+	if err != nil {
+		return 0, -1, &FetchError{URL: endpoint, Err: err}
+	}
+	defer release()
+
+	if err = sub.rateLimiter.wait(req.Context()); err != nil {
+		return 0, -1, &FetchError{URL: endpoint, Err: err}
+	}
+	if err = sub.hostRateLimiters.wait(req.Context(), host); err != nil {
+		return 0, -1, &FetchError{URL: endpoint, Err: err}
+	}
+
+	req.Header.Set("User-Agent", sub.s.cfg.userAgent)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	}
+
+	response, err := sub.client.Do(req)
+	if err != nil {
+		return 0, -1, &FetchError{URL: endpoint, Err: err}
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(response.Body)
+	// drain the body, so that the connection can be reused
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	for _, statusCode := range accepted {
+		if response.StatusCode == statusCode {
+			return response.StatusCode, -1, nil
+		}
+	}
+	retryAfter := parseRetryAfter(response.Header.Get("Retry-After"), time.Now())
+	return response.StatusCode, retryAfter, &FetchError{URL: endpoint, StatusCode: response.StatusCode, Err: fmt.Errorf("received HTTP status %d", response.StatusCode)}
+}
+
+// parseRetryAfter returns the delay set by the given value of a Retry-After header, a number of seconds or an HTTP date,
+// relative to now. It returns -1 if the value is empty or invalid, and 0 for a date in the past.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return -1
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return -1
+		}
+		return time.Duration(min(seconds, int64(math.MaxInt64/time.Second))) * time.Second
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return -1
+	}
+	return max(date.Sub(now), 0)
+}
+
+// retryableStatus reports whether a request ending with the given HTTP status may succeed when retried:
+// if there was no response (0), or the status is 429 (Too Many Requests) or a 5xx status.
+func retryableStatus(statusCode int) bool {
+	return statusCode == 0 || statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+}
@@ -0,0 +1,432 @@
+package sitemap
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// submissionServer is a test server recording the requests it receives.
+// The status of the responses is taken from the "status" query parameter, the "fail" query parameter
+// is the number of requests answered with 503 (Service Unavailable) first, with the Retry-After header
+// of the "retryAfter" query parameter, if any.
+type submissionServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []submissionRequest
+	failed   map[string]int
+}
+
+// submissionRequest is a request received by a submissionServer.
+type submissionRequest struct {
+	method      string
+	path        string
+	sitemap     string
+	userAgent   string
+	contentType string
+	body        []byte
+	status      int
+}
+
+func newSubmissionServer() *submissionServer {
+	ss := &submissionServer{failed: map[string]int{}}
+	ss.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		ss.mu.Lock()
+		defer ss.mu.Unlock()
+
+		status := http.StatusOK
+		_, _ = fmt.Sscan(r.URL.Query().Get("status"), &status)
+		var fail int
+		_, _ = fmt.Sscan(r.URL.Query().Get("fail"), &fail)
+		if ss.failed[r.URL.Path] < fail {
+			ss.failed[r.URL.Path]++
+			status = http.StatusServiceUnavailable
+			if retryAfter := r.URL.Query().Get("retryAfter"); retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+		}
+
+		ss.requests = append(ss.requests, submissionRequest{
+			method:      r.Method,
+			path:        r.URL.Path,
+			sitemap:     r.URL.Query().Get("sitemap"),
+			userAgent:   r.UserAgent(),
+			contentType: r.Header.Get("Content-Type"),
+			body:        body,
+			status:      status,
+		})
+		w.WriteHeader(status)
+	}))
+	return ss
+}
+
+func TestS_SetPingEndpoints(t *testing.T) {
+	tests := []struct {
+		name      string
+		endpoints []string
+		want      []string
+	}{
+		{
+			name:      "endpoints",
+			endpoints: []string{"https://www.example.com/ping?sitemap="},
+			want:      []string{"https://www.example.com/ping?sitemap="},
+		},
+		{
+			name:      "disabled",
+			endpoints: []string{},
+			want:      []string{},
+		},
+		{
+			name:      "defaults",
+			endpoints: nil,
+			want:      nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New().SetPingEndpoints(test.endpoints)
+			if !reflect.DeepEqual(s.cfg.pingEndpoints, test.want) {
+				t.Errorf("expected %v, got %v", test.want, s.cfg.pingEndpoints)
+			}
+		})
+	}
+}
+
+func TestS_SetIndexNowEndpoint(t *testing.T) {
+	s := New().SetIndexNowEndpoint("https://www.bing.com/indexnow")
+	if s.cfg.indexNowEndpoint != "https://www.bing.com/indexnow" {
+		t.Errorf("expected %s, got %s", "https://www.bing.com/indexnow", s.cfg.indexNowEndpoint)
+	}
+}
+
+func TestS_SetRetryBackoff(t *testing.T) {
+	s := New().SetRetryBackoff(2 * time.Second)
+	if s.cfg.retryBackoff != 2*time.Second {
+		t.Errorf("expected %v, got %v", 2*time.Second, s.cfg.retryBackoff)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{name: "seconds", value: "120", want: 2 * time.Minute},
+		{name: "padded seconds", value: " 3 ", want: 3 * time.Second},
+		{name: "date", value: "Tue, 02 Jan 2024 03:04:35 GMT", want: 30 * time.Second},
+		{name: "past date", value: "Mon, 01 Jan 2024 03:04:05 GMT", want: 0},
+		{name: "empty", value: "", want: -1},
+		{name: "negative", value: "-1", want: -1},
+		{name: "invalid", value: "soon", want: -1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := parseRetryAfter(test.value, now); got != test.want {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestS_Ping_RetryBackoff(t *testing.T) {
+	ss := newSubmissionServer()
+	defer ss.Close()
+
+	t.Run("backoff", func(t *testing.T) {
+		start := time.Now()
+		results := New().SetRetries(2).SetRetryBackoff(50 * time.Millisecond).SetPingEndpoints([]string{ss.URL + "/backoff?fail=2&sitemap="}).Ping("https://www.example.com/sitemap.xml")
+		if len(results) != 1 || results[0].Err != nil {
+			t.Fatalf("expected a successful result, got %+v", results)
+		}
+		// 50ms before the first retry, 100ms before the second one
+		if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+			t.Errorf("expected the retries after at least %v, got %v", 150*time.Millisecond, elapsed)
+		}
+	})
+
+	t.Run("Retry-After", func(t *testing.T) {
+		start := time.Now()
+		results := New().SetRetries(1).SetRetryBackoff(time.Millisecond).SetPingEndpoints([]string{ss.URL + "/retry-after?fail=1&retryAfter=1&sitemap="}).Ping("https://www.example.com/sitemap.xml")
+		if len(results) != 1 || results[0].Err != nil {
+			t.Fatalf("expected a successful result, got %+v", results)
+		}
+		if elapsed := time.Since(start); elapsed < time.Second {
+			t.Errorf("expected the retry after at least %v, got %v", time.Second, elapsed)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		results := New().SetRetries(1).SetPingEndpoints([]string{ss.URL + "/canceled?fail=1&retryAfter=60&sitemap="}).PingContext(ctx, "https://www.example.com/sitemap.xml")
+		if elapsed := time.Since(start); elapsed > 10*time.Second {
+			t.Errorf("expected the backoff cut short, got %v", elapsed)
+		}
+		var fetchErr *FetchError
+		if len(results) != 1 || !errors.As(results[0].Err, &fetchErr) || results[0].StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("expected a 503 *FetchError, got %+v", results)
+		}
+	})
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if len(ss.requests) != 6 {
+		t.Errorf("expected %d requests, got %d", 6, len(ss.requests))
+	}
+}
+
+func TestS_Ping(t *testing.T) {
+	ss := newSubmissionServer()
+	defer ss.Close()
+
+	sitemapURL := "https://www.example.com/sitemap.xml?page=1&lang=en"
+
+	tests := []struct {
+		name         string
+		s            *S
+		endpoints    []string
+		wantStatus   []int
+		wantErr      []bool
+		wantRequests int
+	}{
+		{
+			name:         "success",
+			s:            New(),
+			endpoints:    []string{"/bing?sitemap=", "/yandex?sitemap="},
+			wantStatus:   []int{200, 200},
+			wantErr:      []bool{false, false},
+			wantRequests: 2,
+		},
+		{
+			name:         "failure",
+			s:            New().SetRetries(2),
+			endpoints:    []string{"/bing?status=404&sitemap=", "/yandex?sitemap="},
+			wantStatus:   []int{404, 200},
+			wantErr:      []bool{true, false},
+			wantRequests: 2,
+		},
+		{
+			name:         "transient failure retried",
+			s:            New().SetRetries(2).SetRetryBackoff(time.Millisecond),
+			endpoints:    []string{"/bing?fail=2&sitemap="},
+			wantStatus:   []int{200},
+			wantErr:      []bool{false},
+			wantRequests: 3,
+		},
+		{
+			name:         "transient failure without retries",
+			s:            New(),
+			endpoints:    []string{"/yandex?fail=1&sitemap="},
+			wantStatus:   []int{503},
+			wantErr:      []bool{true},
+			wantRequests: 1,
+		},
+		{
+			name:         "disabled",
+			s:            New(),
+			endpoints:    []string{},
+			wantStatus:   []int{},
+			wantErr:      []bool{},
+			wantRequests: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ss.mu.Lock()
+			ss.requests = nil
+			ss.failed = map[string]int{}
+			ss.mu.Unlock()
+
+			endpoints := make([]string, 0, len(test.endpoints))
+			for _, endpoint := range test.endpoints {
+				endpoints = append(endpoints, ss.URL+endpoint)
+			}
+			results := test.s.SetUserAgent("test-agent").SetPingEndpoints(endpoints).Ping(sitemapURL)
+
+			status := []int{}
+			errs := []bool{}
+			for i, result := range results {
+				if want := endpoints[i] + "https%3A%2F%2Fwww.example.com%2Fsitemap.xml%3Fpage%3D1%26lang%3Den"; result.Endpoint != want {
+					t.Errorf("expected endpoint %s, got %s", want, result.Endpoint)
+				}
+				var fetchErr *FetchError
+				if result.Err != nil && !errors.As(result.Err, &fetchErr) {
+					t.Errorf("expected a *FetchError, got %v", result.Err)
+				}
+				status = append(status, result.StatusCode)
+				errs = append(errs, result.Err != nil)
+			}
+			if !reflect.DeepEqual(status, test.wantStatus) {
+				t.Errorf("expected status %v, got %v", test.wantStatus, status)
+			}
+			if !reflect.DeepEqual(errs, test.wantErr) {
+				t.Errorf("expected errors %v, got %v", test.wantErr, errs)
+			}
+
+			ss.mu.Lock()
+			defer ss.mu.Unlock()
+			if len(ss.requests) != test.wantRequests {
+				t.Errorf("expected %d requests, got %d", test.wantRequests, len(ss.requests))
+			}
+			for _, r := range ss.requests {
+				if r.method != http.MethodGet || r.sitemap != sitemapURL || r.userAgent != "test-agent" {
+					t.Errorf("unexpected request %+v", r)
+				}
+			}
+		})
+	}
+
+	var nilS *S
+	if got := nilS.Ping(sitemapURL); got != nil {
+		t.Errorf("expected nil, got %v", got)
+	}
+	if got := nilS.PingContext(context.Background(), sitemapURL); got != nil {
+		t.Errorf("expected nil, got %v", got)
+	}
+}
+
+func TestS_IndexNow(t *testing.T) {
+	ss := newSubmissionServer()
+	defer ss.Close()
+
+	urls := func(n int) []URL {
+		urls := make([]URL, 0, n)
+		for i := 0; i < n; i++ {
+			urls = append(urls, URL{Loc: fmt.Sprintf("https://www.example.com/page-%06d", i+1)})
+		}
+		return urls
+	}
+
+	tests := []struct {
+		name       string
+		s          *S
+		endpoint   string
+		urls       []URL
+		wantURLs   []int
+		wantStatus []int
+		wantErr    []bool
+	}{
+		{
+			name:       "single batch",
+			s:          New(),
+			endpoint:   "/indexnow?status=202",
+			urls:       urls(3),
+			wantURLs:   []int{3},
+			wantStatus: []int{202},
+			wantErr:    []bool{false},
+		},
+		{
+			name:       "batches",
+			s:          New(),
+			endpoint:   "/indexnow",
+			urls:       urls(2*maxIndexNowURLs + 1),
+			wantURLs:   []int{maxIndexNowURLs, maxIndexNowURLs, 1},
+			wantStatus: []int{200, 200, 200},
+			wantErr:    []bool{false, false, false},
+		},
+		{
+			name:       "rejected",
+			s:          New(),
+			endpoint:   "/indexnow?status=403",
+			urls:       urls(1),
+			wantURLs:   []int{1},
+			wantStatus: []int{403},
+			wantErr:    []bool{true},
+		},
+		{
+			name:       "retried",
+			s:          New().SetRetries(1).SetRetryBackoff(time.Millisecond),
+			endpoint:   "/indexnow?fail=1&status=202",
+			urls:       urls(1),
+			wantURLs:   []int{1},
+			wantStatus: []int{202},
+			wantErr:    []bool{false},
+		},
+		{
+			name:       "empty",
+			s:          New(),
+			endpoint:   "/indexnow",
+			urls:       nil,
+			wantURLs:   []int{},
+			wantStatus: []int{},
+			wantErr:    []bool{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ss.mu.Lock()
+			ss.requests = nil
+			ss.failed = map[string]int{}
+			ss.mu.Unlock()
+
+			results := test.s.SetUserAgent("test-agent").SetIndexNowEndpoint(ss.URL+test.endpoint).IndexNow(test.urls, "0123456789abcdef", "www.example.com")
+
+			gotURLs := []int{}
+			status := []int{}
+			errs := []bool{}
+			for _, result := range results {
+				if result.Endpoint != ss.URL+test.endpoint {
+					t.Errorf("expected endpoint %s, got %s", ss.URL+test.endpoint, result.Endpoint)
+				}
+				gotURLs = append(gotURLs, result.URLs)
+				status = append(status, result.StatusCode)
+				errs = append(errs, result.Err != nil)
+			}
+			if !reflect.DeepEqual(gotURLs, test.wantURLs) {
+				t.Errorf("expected batches %v, got %v", test.wantURLs, gotURLs)
+			}
+			if !reflect.DeepEqual(status, test.wantStatus) {
+				t.Errorf("expected status %v, got %v", test.wantStatus, status)
+			}
+			if !reflect.DeepEqual(errs, test.wantErr) {
+				t.Errorf("expected errors %v, got %v", test.wantErr, errs)
+			}
+
+			// the URL lists of the requests not failing transiently add up to the URLs submitted
+			ss.mu.Lock()
+			defer ss.mu.Unlock()
+			var submitted []string
+			for _, r := range ss.requests {
+				if r.method != http.MethodPost || r.userAgent != "test-agent" || r.contentType != "application/json; charset=utf-8" {
+					t.Errorf("unexpected request %+v", r)
+				}
+				var body indexNowRequest
+				if err := json.Unmarshal(r.body, &body); err != nil {
+					t.Fatal(err)
+				}
+				if body.Host != "www.example.com" || body.Key != "0123456789abcdef" {
+					t.Errorf("unexpected host %s or key %s", body.Host, body.Key)
+				}
+				if r.status != http.StatusServiceUnavailable {
+					submitted = append(submitted, body.URLList...)
+				}
+			}
+			if want := locsOf(test.urls); len(want) > 0 && !reflect.DeepEqual(submitted, want) {
+				t.Errorf("expected %d URLs submitted, got %d", len(want), len(submitted))
+			}
+		})
+	}
+
+	var nilS *S
+	if got := nilS.IndexNow(urls(1), "key", "www.example.com"); got != nil {
+		t.Errorf("expected nil, got %v", got)
+	}
+	if got := nilS.IndexNowContext(context.Background(), urls(1), "key", "www.example.com"); got != nil {
+		t.Errorf("expected nil, got %v", got)
+	}
+}
@@ -0,0 +1,99 @@
+package sitemap
+
+import (
+	"context"
+	"errors"
+)
+
+// Option is a function that configures an S structure, passed to New or ParseURL.
+// Besides the With functions, any setter can be used as an option, e.g. func(s *sitemap.S) { s.SetRetries(2) }.
+type Option func(*S)
+
+// WithUserAgent sets the user agent, see SetUserAgent.
+func WithUserAgent(userAgent string) Option {
+	return func(s *S) {
+		s.SetUserAgent(userAgent)
+	}
+}
+
+// WithFetchTimeout sets the fetch timeout in seconds, see SetFetchTimeout.
+func WithFetchTimeout(fetchTimeout uint8) Option {
+	return func(s *S) {
+		s.SetFetchTimeout(fetchTimeout)
+	}
+}
+
+// WithMultiThread sets the multi-threading, see SetMultiThread.
+func WithMultiThread(multiThread bool) Option {
+	return func(s *S) {
+		s.SetMultiThread(multiThread)
+	}
+}
+
+// WithFollow sets the regular expressions of the sitemap locations to follow, see SetFollow.
+func WithFollow(regexes []string) Option {
+	return func(s *S) {
+		s.SetFollow(regexes)
+	}
+}
+
+// WithRules sets the regular expressions of the URLs to include, see SetRules.
+func WithRules(regexes []string) Option {
+	return func(s *S) {
+		s.SetRules(regexes)
+	}
+}
+
+// WithMaxURLs sets the maximum number of URLs stored, see SetMaxURLs.
+func WithMaxURLs(maxURLs int) Option {
+	return func(s *S) {
+		s.SetMaxURLs(maxURLs)
+	}
+}
+
+// WithMaxURLsPerSitemap sets the maximum number of URLs collected from each sitemap, see SetMaxURLsPerSitemap.
+func WithMaxURLsPerSitemap(maxURLsPerSitemap int) Option {
+	return func(s *S) {
+		s.SetMaxURLsPerSitemap(maxURLsPerSitemap)
+	}
+}
+
+// WithMaxSitemaps sets the maximum number of sitemaps fetched, see SetMaxSitemaps.
+func WithMaxSitemaps(maxSitemaps int) Option {
+	return func(s *S) {
+		s.SetMaxSitemaps(maxSitemaps)
+	}
+}
+
+// WithRateLimit sets the overall rate limit in requests per second, see SetRateLimit.
+func WithRateLimit(rps float64) Option {
+	return func(s *S) {
+		s.SetRateLimit(rps)
+	}
+}
+
+// WithRetries sets the number of re-fetches of corrupted compressed content, see SetRetries.
+func WithRetries(retries int) Option {
+	return func(s *S) {
+		s.SetRetries(retries)
+	}
+}
+
+// WithBodyCache sets the cache of the fetched bodies, see SetBodyCache.
+func WithBodyCache(c Cache) Option {
+	return func(s *S) {
+		s.SetBodyCache(c)
+	}
+}
+
+// ParseURL parses the sitemaps of the given URL (a sitemap, a sitemap index or a robots.txt file) with a new S structure
+// configured with the given options, and returns the URLs collected.
+// The returned error joins all errors encountered (see GetErrors), it is nil if there were none.
+// The URLs collected are returned even if there were errors.
+func ParseURL(ctx context.Context, url string, opts ...Option) ([]URL, error) {
+	s, err := New(opts...).ParseContext(ctx, url, nil)
+	if errs := s.GetErrors(); len(errs) > 0 {
+		err = errors.Join(errs...)
+	}
+	return s.GetURLs(), err
+}
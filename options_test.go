@@ -0,0 +1,119 @@
+package sitemap
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNew_Options(t *testing.T) {
+	cache := NewMemoryCache(1 << 20)
+	s := New(
+		WithUserAgent("test-agent"),
+		WithFetchTimeout(10),
+		WithMultiThread(false),
+		WithFollow([]string{`sitemap-\d+\.xml`}),
+		WithRules([]string{`/page-`}),
+		WithMaxURLs(100),
+		WithMaxSitemaps(10),
+		WithRateLimit(5),
+		WithRetries(2),
+		WithBodyCache(cache),
+		func(s *S) { s.SetStrictDecompression(true) },
+	)
+
+	if s.cfg.userAgent != "test-agent" {
+		t.Errorf("expected user agent %q, got %q", "test-agent", s.cfg.userAgent)
+	}
+	if s.cfg.fetchTimeout != 10 {
+		t.Errorf("expected fetch timeout 10, got %d", s.cfg.fetchTimeout)
+	}
+	if s.cfg.multiThread {
+		t.Error("expected multi-threading off")
+	}
+	if !reflect.DeepEqual(s.cfg.follow, []string{`sitemap-\d+\.xml`}) || len(s.cfg.followRegexes) != 1 {
+		t.Errorf("unexpected follow %v", s.cfg.follow)
+	}
+	if !reflect.DeepEqual(s.cfg.rules, []string{`/page-`}) || len(s.cfg.rulesRegexes) != 1 {
+		t.Errorf("unexpected rules %v", s.cfg.rules)
+	}
+	if s.cfg.maxURLs != 100 || s.cfg.maxSitemaps != 10 {
+		t.Errorf("expected limits 100 and 10, got %d and %d", s.cfg.maxURLs, s.cfg.maxSitemaps)
+	}
+	if s.cfg.rateLimit != 5 || s.cfg.retries != 2 {
+		t.Errorf("expected rate limit 5 and 2 retries, got %v and %d", s.cfg.rateLimit, s.cfg.retries)
+	}
+	if s.cfg.bodyCache != cache {
+		t.Error("expected the body cache to be set")
+	}
+	if !s.cfg.strictDecompression {
+		t.Error("expected strict decompression")
+	}
+	if !s.cfg.followIndexes {
+		t.Error("expected the defaults to be kept")
+	}
+}
+
+func TestParseURL(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		url        string
+		opts       []Option
+		wantURLs   int
+		wantErrors int
+	}{
+		{
+			name:     "robots.txt",
+			url:      server.URL + "/robots-with-sitemapindex/robots.txt",
+			wantURLs: 6,
+		},
+		{
+			name:     "with options",
+			url:      server.URL + "/robots-with-sitemapindex/robots.txt",
+			opts:     []Option{WithMultiThread(false), WithMaxURLs(2)},
+			wantURLs: 2,
+		},
+		{
+			name:       "errors",
+			url:        server.URL + "/sitemapindex-with-invalid-sitemap.xml",
+			wantURLs:   0,
+			wantErrors: 1,
+		},
+		{
+			name:       "invalid option",
+			url:        server.URL + "/sitemap-01.xml",
+			opts:       []Option{WithRules([]string{"("})},
+			wantURLs:   0,
+			wantErrors: 1,
+		},
+		{
+			name:       "invalid URL",
+			url:        "",
+			wantURLs:   0,
+			wantErrors: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			urls, err := ParseURL(context.Background(), test.url, test.opts...)
+			if len(urls) != test.wantURLs {
+				t.Errorf("expected %d URLs, got %d", test.wantURLs, len(urls))
+			}
+			if test.wantErrors == 0 {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			var joined interface{ Unwrap() []error }
+			if !errors.As(err, &joined) || len(joined.Unwrap()) != test.wantErrors {
+				t.Errorf("expected %d joined errors, got %v", test.wantErrors, err)
+			}
+		})
+	}
+}
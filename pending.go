@@ -0,0 +1,27 @@
+package sitemap
+
+// GetPendingSitemaps returns the locations of the sitemaps that have been discovered, but not processed, in tree order:
+// the sitemaps skipped because the parse was cut short (by a limit, the deadline or the cancellation of the context,
+// or the maximum failure rate), those skipped by the circuit breaker, and those whose fetch failed because of the cancellation.
+// The sitemaps referenced by the main URL are pending if following the indexes is turned off, see SetFollowIndexes.
+// The list is the same as the Pending field of GetCheckpoint, in both sequential and multi-thread mode.
+// If the S object is nil, Parse has not been called or every sitemap has been processed, an empty slice is returned.
+func (s *S) GetPendingSitemaps() []string {
+	if s == nil {
+		return []string{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending := []string{}
+	if s.tree == nil {
+		return pending
+	}
+	walkNodes(s.tree, func(n *SitemapNode) {
+		if !n.fetched {
+			pending = append(pending, n.Loc)
+		}
+	})
+
+	return pending
+}
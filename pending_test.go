@@ -0,0 +1,100 @@
+package sitemap
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestS_GetPendingSitemaps(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		s           *S
+		url         string
+		wantPending []string
+		// wantOneOf is set when the pending sitemaps depend on the order of the fetches in multi-thread mode:
+		// wantCount of its locations are expected to be pending, in tree order
+		wantOneOf []string
+		wantCount int
+	}{
+		{
+			name:        "max sitemaps sequential",
+			s:           New().SetMultiThread(false).SetMaxSitemaps(2),
+			url:         server.URL + "/robots-with-sitemapindex/robots.txt",
+			wantPending: []string{"/sitemap-02.xml", "/sitemap-03.xml"},
+		},
+		{
+			name:      "max sitemaps multi-thread",
+			s:         New().SetMaxSitemaps(2),
+			url:       server.URL + "/robots-with-sitemapindex/robots.txt",
+			wantOneOf: []string{"/sitemap-01.xml", "/sitemap-02.xml", "/sitemap-03.xml"},
+			wantCount: 2,
+		},
+		{
+			name:        "max sitemaps of 1",
+			s:           New().SetMaxSitemaps(1),
+			url:         server.URL + "/robots-with-sitemapindex/robots.txt",
+			wantPending: []string{"/sitemap-01.xml", "/sitemap-02.xml", "/sitemap-03.xml"},
+		},
+		{
+			name:        "indexes not followed",
+			s:           New().SetFollowIndexes(false),
+			url:         server.URL + "/robots-with-sitemapindex/robots.txt",
+			wantPending: []string{"/sitemapindex-1.xml"},
+		},
+		{
+			name:        "complete",
+			s:           New(),
+			url:         server.URL + "/robots-with-sitemapindex/robots.txt",
+			wantPending: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := test.s.Parse(test.url, nil); err != nil {
+				t.Fatal(err)
+			}
+
+			got := trimPrefixes(test.s.GetPendingSitemaps(), server.URL)
+			if want := trimPrefixes(test.s.GetCheckpoint().Pending, server.URL); !reflect.DeepEqual(got, want) {
+				t.Errorf("expected the pending sitemaps of the checkpoint %v, got %v", want, got)
+			}
+			if test.wantOneOf == nil {
+				if !reflect.DeepEqual(got, test.wantPending) {
+					t.Errorf("expected %v, got %v", test.wantPending, got)
+				}
+				return
+			}
+			if len(got) != test.wantCount || !sort.SliceIsSorted(got, func(i, j int) bool { return got[i] < got[j] }) {
+				t.Fatalf("expected %d of %v in order, got %v", test.wantCount, test.wantOneOf, got)
+			}
+			for _, loc := range got {
+				found := false
+				for _, want := range test.wantOneOf {
+					found = found || loc == want
+				}
+				if !found {
+					t.Errorf("expected %d of %v, got %v", test.wantCount, test.wantOneOf, got)
+				}
+			}
+		})
+	}
+}
+
+func TestS_GetPendingSitemaps_MaxFailureRate(t *testing.T) {
+	var requests int64
+	server := failingIndexServer(10, func(i int) bool { return true }, func(int) bool { return false }, &requests)
+	defer server.Close()
+
+	s, _ := New().SetMultiThread(false).SetMaxFailureRate(0.5, 4).Parse(server.URL+"/index.xml", nil)
+
+	// the main URL succeeds, the rate exceeds 0.5 after the 3rd failure: 3 of 4
+	want := []string{"/sitemap-4.xml", "/sitemap-5.xml", "/sitemap-6.xml", "/sitemap-7.xml", "/sitemap-8.xml", "/sitemap-9.xml", "/sitemap-10.xml"}
+	if got := trimPrefixes(s.GetPendingSitemaps(), server.URL); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
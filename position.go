@@ -0,0 +1,41 @@
+package sitemap
+
+import (
+	"fmt"
+)
+
+// Position is the position of a <url> element in the document it was decoded from, see SetTrackPositions.
+// The Offset field is the byte offset of the start of the element ('<') in the uncompressed document,
+// the Line and Column fields are its line and column, both starting at 1, the column counted in bytes.
+type Position struct {
+	Offset int64 `json:"offset"`
+	Line   int   `json:"line"`
+	Column int   `json:"column"`
+}
+
+// String returns the position as "line:column".
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// SetTrackPositions sets whether the position of each <url> element in its sitemap is recorded in the Position field of the URL,
+// e.g. to point the owner of a large sitemap to an offending entry. The warnings about a URL, such as *LocTooLongError
+// and *LocEncodedError, include its position as well. It costs memory, and the locations stored in loc-only mode
+// (see SetLocOnly) have no position. By default, the positions are not recorded.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetTrackPositions(trackPositions bool) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.trackPositions = trackPositions
+
+	return s
+}
+
+// positionSuffix returns the suffix of an error message about the element at the given position, empty if there is no position.
+func positionSuffix(p *Position) string {
+	if p == nil {
+		return ""
+	}
+	return " at " + p.String()
+}
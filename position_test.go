@@ -0,0 +1,123 @@
+package sitemap
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestS_SetTrackPositions(t *testing.T) {
+	tests := []struct {
+		name           string
+		trackPositions bool
+	}{
+		{name: "enabled", trackPositions: true},
+		{name: "disabled", trackPositions: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New().SetTrackPositions(test.trackPositions)
+			if s.cfg.trackPositions != test.trackPositions {
+				t.Errorf("expected %v, got %v", test.trackPositions, s.cfg.trackPositions)
+			}
+		})
+	}
+}
+
+func TestS_Parse_TrackPositions(t *testing.T) {
+	data, err := os.ReadFile("./test/sitemap-positions.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+
+	// the <url> commented out at 8:8 is not an element
+	positions := []Position{
+		{Offset: 102, Line: 3, Column: 3},
+		{Offset: 152, Line: 4, Column: 3},
+		{Offset: 273, Line: 9, Column: 2},
+	}
+
+	tests := []struct {
+		name          string
+		s             *S
+		wantPositions []*Position
+		wantWarning   string
+	}{
+		{
+			name:          "not tracked",
+			s:             New().SetEncodeLocs(true),
+			wantPositions: []*Position{nil, nil, nil},
+			wantWarning:   `https://www.example.com/sitemap.xml: loc "https://www.example.com/c d" contains characters not allowed in a URL, encoded as "https://www.example.com/c%20d"`,
+		},
+		{
+			name:          "tracked",
+			s:             New().SetEncodeLocs(true).SetTrackPositions(true),
+			wantPositions: []*Position{&positions[0], &positions[1], &positions[2]},
+			wantWarning:   `https://www.example.com/sitemap.xml: loc "https://www.example.com/c d" contains characters not allowed in a URL, encoded as "https://www.example.com/c%20d" at 9:2`,
+		},
+		{
+			name:          "tracked with a limit of URLs",
+			s:             New().SetTrackPositions(true).SetMaxURLsPerSitemap(2),
+			wantPositions: []*Position{&positions[0], &positions[1]},
+		},
+		{
+			name:          "tracked with fields",
+			s:             New().SetTrackPositions(true).SetFields(FieldLoc),
+			wantPositions: []*Position{&positions[0], &positions[1], &positions[2]},
+		},
+		{
+			name:        "tracked in loc-only mode",
+			s:           New().SetEncodeLocs(true).SetTrackPositions(true).SetLocOnly(true),
+			wantWarning: `https://www.example.com/sitemap.xml: loc "https://www.example.com/c d" contains characters not allowed in a URL, encoded as "https://www.example.com/c%20d" at 9:2`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := test.s.Parse("https://www.example.com/sitemap.xml", &content)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []*Position
+			for _, u := range s.GetURLs() {
+				got = append(got, u.Position)
+			}
+			if !reflect.DeepEqual(got, test.wantPositions) {
+				t.Errorf("expected %v, got %v", test.wantPositions, got)
+			}
+
+			var warning string
+			for _, w := range s.GetWarnings() {
+				var encodedErr *LocEncodedError
+				if errors.As(w, &encodedErr) {
+					warning = w.Error()
+				}
+			}
+			if warning != test.wantWarning {
+				t.Errorf("expected warning %q, got %q", test.wantWarning, warning)
+			}
+		})
+	}
+}
+
+func TestS_Parse_TrackPositions_LocTooLong(t *testing.T) {
+	content := "<urlset>\n<url><loc>https://www.example.com/</loc></url>\n<url><loc>https://www.example.com/" + strings.Repeat("a", maxLocLength) + "</loc></url>\n</urlset>"
+	s, err := New().SetTrackPositions(true).Parse("https://www.example.com/sitemap.xml", &content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tooLongErr *LocTooLongError
+	warnings := s.GetWarnings()
+	if len(warnings) != 1 || !errors.As(warnings[0], &tooLongErr) {
+		t.Fatalf("expected a *LocTooLongError, got %v", warnings)
+	}
+	want := Position{Offset: 56, Line: 3, Column: 1}
+	if tooLongErr.Position == nil || *tooLongErr.Position != want {
+		t.Errorf("expected %v, got %v", want, tooLongErr.Position)
+	}
+}
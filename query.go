@@ -0,0 +1,96 @@
+package sitemap
+
+import (
+	"fmt"
+	neturl "net/url"
+	"sort"
+	"strings"
+)
+
+// SetStripQueryParams sets the query parameters removed from the locations of the stored URLs, e.g. the tracking parameters
+// ("utm_*", "gclid") of the variants of a page listed by a sitemap, so that they do not count as distinct URLs.
+// A pattern is either the exact name of a parameter, or a prefix followed by "*", matching the names starting with it; the names are
+// compared after unescaping, case-sensitively. A pattern with a "*" anywhere else, or an empty one, is invalid and appended to the error list.
+// The parameters are stripped after the host rewrite (see SetHostRewrite) and before the URL rewriter (see SetURLRewriter), so
+// the rules (see SetRules), the URL matcher and the deduplication (e.g. GetUniqueURLCount) see the stripped locations.
+// The remaining parameters are sorted by name, keeping the order of the repeated ones, and each keeps its original encoding;
+// a location whose query becomes empty loses its "?". The fragment, if any, is kept. The locations of the sitemaps are not affected.
+// A nil or empty list (the default) leaves the queries unchanged.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetStripQueryParams(patterns []string) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.stripQueryParams = nil
+	for _, pattern := range patterns {
+		if i := strings.IndexByte(pattern, '*'); pattern == "" || i >= 0 && i != len(pattern)-1 {
+			s.errs = append(s.errs, fmt.Errorf("invalid query parameter pattern %q: only a trailing * is allowed", pattern))
+			continue
+		}
+		s.cfg.stripQueryParams = append(s.cfg.stripQueryParams, pattern)
+	}
+
+	return s
+}
+
+// stripQuery removes the query parameters matching any of the given patterns from the location, see SetStripQueryParams,
+// and sorts the remaining ones by name. The location is returned unchanged if it has no query.
+func stripQuery(loc string, patterns []string) string {
+	start := strings.IndexByte(loc, '?')
+	if start < 0 {
+		return loc
+	}
+	query, fragment := loc[start+1:], ""
+	if end := strings.IndexByte(query, '#'); end >= 0 {
+		query, fragment = query[:end], query[end:]
+	}
+
+	type param struct {
+		name string
+		raw  string
+	}
+	var kept []param
+	for _, raw := range strings.Split(query, "&") {
+		if raw == "" {
+			continue
+		}
+		name, _, _ := strings.Cut(raw, "=")
+		if unescaped, err := neturl.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if !matchQueryParam(name, patterns) {
+			kept = append(kept, param{name: name, raw: raw})
+		}
+	}
+	sort.SliceStable(kept, func(i, j int) bool {
+		return kept[i].name < kept[j].name
+	})
+
+	if len(kept) == 0 {
+		return loc[:start] + fragment
+	}
+	var stripped strings.Builder
+	stripped.WriteString(loc[:start+1])
+	for i, p := range kept {
+		if i > 0 {
+			stripped.WriteByte('&')
+		}
+		stripped.WriteString(p.raw)
+	}
+	stripped.WriteString(fragment)
+	return stripped.String()
+}
+
+// matchQueryParam reports whether the name of a query parameter matches any of the given patterns, see SetStripQueryParams.
+func matchQueryParam(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if name == pattern {
+			return true
+		}
+	}
+	return false
+}
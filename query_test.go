@@ -0,0 +1,206 @@
+package sitemap
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestS_SetStripQueryParams(t *testing.T) {
+	tests := []struct {
+		name         string
+		patterns     []string
+		wantPatterns []string
+		wantErrs     int
+	}{
+		{
+			name:         "valid",
+			patterns:     []string{"utm_*", "gclid", "*"},
+			wantPatterns: []string{"utm_*", "gclid", "*"},
+		},
+		{
+			name:         "invalid",
+			patterns:     []string{"", "utm_*_id", "*source", "fbclid"},
+			wantPatterns: []string{"fbclid"},
+			wantErrs:     3,
+		},
+		{
+			name:         "none",
+			patterns:     nil,
+			wantPatterns: nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New().SetStripQueryParams(test.patterns)
+			if !reflect.DeepEqual(s.cfg.stripQueryParams, test.wantPatterns) {
+				t.Errorf("expected %v, got %v", test.wantPatterns, s.cfg.stripQueryParams)
+			}
+			if got := len(s.GetErrors()); got != test.wantErrs {
+				t.Errorf("expected %d errors, got %v", test.wantErrs, s.GetErrors())
+			}
+		})
+	}
+}
+
+func TestStripQuery(t *testing.T) {
+	patterns := []string{"utm_*", "gclid"}
+
+	tests := []struct {
+		name string
+		loc  string
+		want string
+	}{
+		{
+			name: "no query",
+			loc:  "https://www.example.com/page",
+			want: "https://www.example.com/page",
+		},
+		{
+			name: "only matching parameters",
+			loc:  "https://www.example.com/page?utm_source=news&utm_medium=email&gclid=123",
+			want: "https://www.example.com/page",
+		},
+		{
+			name: "mixed parameters",
+			loc:  "https://www.example.com/page?utm_source=news&size=m&color=red&gclid=123",
+			want: "https://www.example.com/page?color=red&size=m",
+		},
+		{
+			name: "no matching parameters are sorted",
+			loc:  "https://www.example.com/page?b=2&a=1",
+			want: "https://www.example.com/page?a=1&b=2",
+		},
+		{
+			name: "repeated parameters keep their order",
+			loc:  "https://www.example.com/page?tag=z&id=1&tag=a",
+			want: "https://www.example.com/page?id=1&tag=z&tag=a",
+		},
+		{
+			name: "encoding preserved",
+			loc:  "https://www.example.com/search?q=caf%C3%A9+au+lait&utm_campaign=x&page=%32&empty=&flag",
+			want: "https://www.example.com/search?empty=&flag&page=%32&q=caf%C3%A9+au+lait",
+		},
+		{
+			name: "encoded name",
+			loc:  "https://www.example.com/page?utm%5Fsource=news&id=1",
+			want: "https://www.example.com/page?id=1",
+		},
+		{
+			name: "prefix is case-sensitive",
+			loc:  "https://www.example.com/page?UTM_source=news",
+			want: "https://www.example.com/page?UTM_source=news",
+		},
+		{
+			name: "exact name",
+			loc:  "https://www.example.com/page?gclid_extra=1&gclid=2",
+			want: "https://www.example.com/page?gclid_extra=1",
+		},
+		{
+			name: "fragment kept",
+			loc:  "https://www.example.com/page?utm_source=news#section",
+			want: "https://www.example.com/page#section",
+		},
+		{
+			name: "empty query and empty parts",
+			loc:  "https://www.example.com/page?&&id=1&",
+			want: "https://www.example.com/page?id=1",
+		},
+		{
+			name: "question mark only",
+			loc:  "https://www.example.com/page?",
+			want: "https://www.example.com/page",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := stripQuery(test.loc, patterns); got != test.want {
+				t.Errorf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestS_Parse_StripQueryParams(t *testing.T) {
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+    <url><loc>https://www.example.com/page?utm_source=news</loc></url>
+    <url><loc>https://www.example.com/page?utm_source=email&amp;utm_medium=cpc</loc></url>
+    <url><loc>https://www.example.com/page</loc></url>
+    <url><loc>https://www.example.com/shoes?size=42&amp;gclid=abc&amp;color=red</loc></url>
+    <url><loc>https://www.example.com/shoes?color=red&amp;size=42</loc></url>
+</urlset>`
+
+	tests := []struct {
+		name       string
+		s          *S
+		wantLocs   []string
+		wantUnique int64
+	}{
+		{
+			name: "stripped",
+			s:    New().SetStripQueryParams([]string{"utm_*", "gclid"}),
+			wantLocs: []string{
+				"https://www.example.com/page",
+				"https://www.example.com/page",
+				"https://www.example.com/page",
+				"https://www.example.com/shoes?color=red&size=42",
+				"https://www.example.com/shoes?color=red&size=42",
+			},
+			wantUnique: 2,
+		},
+		{
+			name: "rules match the stripped locations",
+			s:    New().SetStripQueryParams([]string{"utm_*", "gclid"}).SetRules([]string{`/page$`}),
+			wantLocs: []string{
+				"https://www.example.com/page",
+				"https://www.example.com/page",
+				"https://www.example.com/page",
+			},
+			wantUnique: 1,
+		},
+		{
+			name: "URL rewriter sees the stripped locations",
+			s: New().SetStripQueryParams([]string{"utm_*", "gclid"}).SetURLRewriter(func(loc string) string {
+				if strings.Contains(loc, "utm_") {
+					return ""
+				}
+				return loc
+			}),
+			wantLocs: []string{
+				"https://www.example.com/page",
+				"https://www.example.com/page",
+				"https://www.example.com/page",
+				"https://www.example.com/shoes?color=red&size=42",
+				"https://www.example.com/shoes?color=red&size=42",
+			},
+			wantUnique: 2,
+		},
+		{
+			name: "disabled",
+			s:    New(),
+			wantLocs: []string{
+				"https://www.example.com/page?utm_source=news",
+				"https://www.example.com/page?utm_source=email&utm_medium=cpc",
+				"https://www.example.com/page",
+				"https://www.example.com/shoes?size=42&gclid=abc&color=red",
+				"https://www.example.com/shoes?color=red&size=42",
+			},
+			wantUnique: 5,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := test.s.Parse("https://www.example.com/sitemap.xml", &content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.GetLocs(); !reflect.DeepEqual(got, test.wantLocs) {
+				t.Errorf("expected %v, got %v", test.wantLocs, got)
+			}
+			if got := s.GetUniqueURLCount(); got != test.wantUnique {
+				t.Errorf("expected %d unique URLs, got %d", test.wantUnique, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package sitemap
+
+import "math/rand"
+
+// SetRandomSource sets the source of randomness of GetRandomURLs, GetRandomSitemaps and the jitter of the request delay
+// (see SetRequestDelay), e.g. rand.NewSource(seed) for samples reproducible across runs.
+// The source is only used under a lock of the S structure, so it need not be safe for concurrent use.
+// A nil source (the default) means the shared source of the math/rand package.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetRandomSource(src rand.Source) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.rnd = nil
+	if src != nil {
+		s.cfg.rnd = rand.New(src)
+	}
+
+	return s
+}
+
+// GetRandomSitemaps returns up to n distinct locations randomly selected from the sitemap locations (see GetSitemapLocations),
+// e.g. to re-parse a few sitemaps of a huge site individually as a cheap spot-check.
+// The function selects from a copy of the locations, which are left unchanged, and returns all of them in random order if there are fewer than n.
+// If the S object is nil or n is not positive, an empty slice is returned.
+func (s *S) GetRandomSitemaps(n int) []string {
+	if s == nil || n <= 0 {
+		return []string{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	locations := append(make([]string, 0, len(s.sitemapLocations)), s.sitemapLocations...)
+	n = min(n, len(locations))
+	for i := 0; i < n; i++ {
+		j := i + s.randIntn(len(locations)-i)
+		locations[i], locations[j] = locations[j], locations[i]
+	}
+
+	return locations[:n:n]
+}
+
+// randIntn returns a random number in [0,n) from the source of randomness set, see SetRandomSource.
+func (s *S) randIntn(n int) int {
+	if s.cfg.rnd == nil {
+		return rand.Intn(n)
+	}
+	s.rndMu.Lock()
+	defer s.rndMu.Unlock()
+
+	return s.cfg.rnd.Intn(n)
+}
+
+// randInt63n returns a random number in [0,n) from the source of randomness set, see SetRandomSource.
+func (s *S) randInt63n(n int64) int64 {
+	if s.cfg.rnd == nil {
+		return rand.Int63n(n)
+	}
+	s.rndMu.Lock()
+	defer s.rndMu.Unlock()
+
+	return s.cfg.rnd.Int63n(n)
+}
@@ -0,0 +1,120 @@
+package sitemap
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// sitemapLocations returns an S object with n sitemap locations.
+func sitemapLocations(n int) *S {
+	s := New()
+	for i := 0; i < n; i++ {
+		s.sitemapLocations = append(s.sitemapLocations, fmt.Sprintf("https://www.example.com/sitemap-%02d.xml", i))
+	}
+	return s
+}
+
+func TestS_SetRandomSource(t *testing.T) {
+	s := New().SetRandomSource(rand.NewSource(1))
+	if s.cfg.rnd == nil {
+		t.Errorf("expected a source, got nil")
+	}
+	if s.SetRandomSource(nil); s.cfg.rnd != nil {
+		t.Errorf("expected nil, got %v", s.cfg.rnd)
+	}
+}
+
+func TestS_GetRandomSitemaps(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       *S
+		n       int
+		wantLen int
+	}{
+		{
+			name:    "nil receiver",
+			s:       nil,
+			n:       5,
+			wantLen: 0,
+		},
+		{
+			name:    "no sitemaps",
+			s:       sitemapLocations(0),
+			n:       5,
+			wantLen: 0,
+		},
+		{
+			name:    "n is greater than the sitemaps",
+			s:       sitemapLocations(3),
+			n:       5,
+			wantLen: 3,
+		},
+		{
+			name:    "n is less than the sitemaps",
+			s:       sitemapLocations(10),
+			n:       4,
+			wantLen: 4,
+		},
+		{
+			name:    "non-positive n",
+			s:       sitemapLocations(10),
+			n:       -1,
+			wantLen: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			want := test.s.GetSitemapLocations()
+			got := test.s.GetRandomSitemaps(test.n)
+			if got == nil || len(got) != test.wantLen {
+				t.Fatalf("expected %d locations, got %v", test.wantLen, got)
+			}
+
+			seen := map[string]bool{}
+			for _, loc := range got {
+				if seen[loc] {
+					t.Errorf("%s returned twice", loc)
+				}
+				seen[loc] = true
+			}
+			if len(got) > 0 {
+				got[0] = "https://www.example.com/modified.xml"
+			}
+			if locations := test.s.GetSitemapLocations(); !reflect.DeepEqual(locations, want) {
+				t.Errorf("expected the sitemap locations to be unchanged, got %v", locations)
+			}
+		})
+	}
+}
+
+func TestS_GetRandomSitemaps_All(t *testing.T) {
+	s := sitemapLocations(20)
+	got := s.GetRandomSitemaps(20)
+	sort.Strings(got)
+	if want := s.GetSitemapLocations(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected every location once, got %v", got)
+	}
+}
+
+func TestS_RandomSource(t *testing.T) {
+	newS := func(seed int64) *S {
+		s := sitemapLocations(20).SetRandomSource(rand.NewSource(seed))
+		s.urls = sampledURLs(20).urls
+		return s
+	}
+	a, b, c := newS(7), newS(7), newS(8)
+
+	if sitemapsA, sitemapsB := a.GetRandomSitemaps(5), b.GetRandomSitemaps(5); !reflect.DeepEqual(sitemapsA, sitemapsB) {
+		t.Errorf("GetRandomSitemaps: expected the same sample for the same seed, got %v and %v", sitemapsA, sitemapsB)
+	}
+	if urlsA, urlsB := locsOf(a.GetRandomURLs(5)), locsOf(b.GetRandomURLs(5)); !reflect.DeepEqual(urlsA, urlsB) {
+		t.Errorf("GetRandomURLs: expected the same sample for the same seed, got %v and %v", urlsA, urlsB)
+	}
+	if sitemapsA, sitemapsC := a.GetRandomSitemaps(10), c.GetRandomSitemaps(10); reflect.DeepEqual(sitemapsA, sitemapsC) {
+		t.Errorf("GetRandomSitemaps: expected different samples for different seeds, got %v", sitemapsA)
+	}
+}
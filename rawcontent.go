@@ -0,0 +1,69 @@
+package sitemap
+
+import "bytes"
+
+// SetKeepRawContent sets whether the Sitemap Parser keeps the content of every document it processes, for forensic debugging,
+// e.g. to see exactly what a server returned for a sitemap that failed to parse. The content is kept after decompression,
+// including the content of the main URL, and it is available via GetContent. A document fetched again replaces its content.
+// The content counts neither against the memory budget (see SetMemoryBudget) nor towards the limits of the results,
+// so set a limit with SetMaxRawContentBytes when parsing large sites. By default, it is off.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetKeepRawContent(keep bool) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.keepRawContent = keep
+
+	return s
+}
+
+// SetMaxRawContentBytes sets the maximum total size in bytes of the content kept, see SetKeepRawContent.
+// The content of a document which does not fit in the limit is not kept, while the smaller documents processed later may still be.
+// A value of 0 (the default) means no limit.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetMaxRawContentBytes(n int64) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.maxRawContentBytes = n
+
+	return s
+}
+
+// GetContent returns a copy of the decompressed content of the document at the given location, and whether it has been kept.
+// Nothing is kept unless SetKeepRawContent is turned on. If the S object is nil, nil and false are returned.
+func (s *S) GetContent(location string) ([]byte, bool) {
+	if s == nil {
+		return nil, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	content, ok := s.rawContent[location]
+	if !ok {
+		return nil, false
+	}
+	return bytes.Clone(content), true
+}
+
+// recordRawContent keeps a copy of the decompressed content of the given location, if the content is kept and it fits in the limit.
+func (s *S) recordRawContent(location string, content []byte) {
+	if !s.cfg.keepRawContent {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if previous, ok := s.rawContent[location]; ok {
+		s.rawContentBytes -= int64(len(previous))
+		delete(s.rawContent, location)
+	}
+	if s.cfg.maxRawContentBytes > 0 && s.rawContentBytes+int64(len(content)) > s.cfg.maxRawContentBytes {
+		return
+	}
+	if s.rawContent == nil {
+		s.rawContent = map[string][]byte{}
+	}
+	s.rawContent[location] = bytes.Clone(content)
+	s.rawContentBytes += int64(len(content))
+}
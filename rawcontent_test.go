@@ -0,0 +1,144 @@
+package sitemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// fixtureContent returns the decompressed content of the given fixture as served by the test server at host,
+// which ends the uncompressed files with a newline, see sitemaptest.NewHandler.
+func fixtureContent(t *testing.T, name string, host string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("./test/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.HasSuffix(name, ".gz") {
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data, err = io.ReadAll(r); err != nil {
+			t.Fatal(err)
+		}
+	} else {
+		data = append(data, '\n')
+	}
+	return bytes.ReplaceAll(data, []byte("HOST"), []byte(host))
+}
+
+func TestS_SetKeepRawContent(t *testing.T) {
+	tests := []struct {
+		name string
+		keep bool
+	}{
+		{name: "enabled", keep: true},
+		{name: "disabled", keep: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New().SetKeepRawContent(test.keep)
+			if s.cfg.keepRawContent != test.keep {
+				t.Errorf("expected %v, got %v", test.keep, s.cfg.keepRawContent)
+			}
+		})
+	}
+}
+
+func TestS_SetMaxRawContentBytes(t *testing.T) {
+	s := New().SetMaxRawContentBytes(1 << 20)
+	if s.cfg.maxRawContentBytes != 1<<20 {
+		t.Errorf("expected %d, got %d", 1<<20, s.cfg.maxRawContentBytes)
+	}
+}
+
+func TestS_GetContent(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	tests := []struct {
+		name     string
+		robots   string
+		fixtures []string
+	}{
+		{
+			name:     "uncompressed",
+			robots:   "robots-with-sitemapindex",
+			fixtures: []string{"robots-with-sitemapindex/robots.txt", "sitemapindex-1.xml", "sitemap-01.xml", "sitemap-02.xml", "sitemap-03.xml"},
+		},
+		{
+			name:     "compressed",
+			robots:   "robots-with-sitemapindex-gz",
+			fixtures: []string{"robots-with-sitemapindex-gz/robots.txt", "sitemapindex-1.xml.gz", "sitemap-01.xml.gz", "sitemap-02.xml.gz", "sitemap-03.xml.gz"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, keep := range []bool{true, false} {
+				s, err := New().SetKeepRawContent(keep).Parse(fmt.Sprintf("%s/%s/robots.txt", server.URL, test.robots), nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, fixture := range test.fixtures {
+					content, ok := s.GetContent(fmt.Sprintf("%s/%s", server.URL, fixture))
+					if !keep {
+						if ok || content != nil {
+							t.Errorf("%s: expected nothing to be kept, got %q", fixture, content)
+						}
+						continue
+					}
+					if want := fixtureContent(t, fixture, host); !ok || !bytes.Equal(content, want) {
+						t.Errorf("%s: expected the content of the fixture, got %q (%v)", fixture, content, ok)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestS_GetContent_MaxRawContentBytes(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	s, err := New().SetKeepRawContent(true).SetMultiThread(false).SetMaxRawContentBytes(1).Parse(fmt.Sprintf("%s/sitemapindex-1.xml", server.URL), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, loc := range []string{"sitemapindex-1.xml", "sitemap-01.xml"} {
+		if _, ok := s.GetContent(fmt.Sprintf("%s/%s", server.URL, loc)); ok {
+			t.Errorf("%s: expected the content over the limit not to be kept", loc)
+		}
+	}
+
+	index := fixtureContent(t, "sitemapindex-1.xml", strings.TrimPrefix(server.URL, "http://"))
+	s, err = New().SetKeepRawContent(true).SetMaxRawContentBytes(int64(len(index))).Parse(fmt.Sprintf("%s/sitemapindex-1.xml", server.URL), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content, ok := s.GetContent(fmt.Sprintf("%s/sitemapindex-1.xml", server.URL)); !ok || !bytes.Equal(content, index) {
+		t.Errorf("expected the content of the index to be kept, got %q", content)
+	}
+	if s.rawContentBytes != int64(len(index)) || len(s.rawContent) != 1 {
+		t.Errorf("expected only the index to be kept, got %d documents of %d bytes", len(s.rawContent), s.rawContentBytes)
+	}
+}
+
+func TestS_GetContent_Copy(t *testing.T) {
+	content := "<urlset><url><loc>https://www.example.com/</loc></url></urlset>"
+	s, _ := New().SetKeepRawContent(true).Parse("https://www.example.com/sitemap.xml", &content)
+
+	got, ok := s.GetContent("https://www.example.com/sitemap.xml")
+	if !ok || string(got) != content {
+		t.Fatalf("expected %q, got %q", content, got)
+	}
+	got[0] = 'x'
+	if again, _ := s.GetContent("https://www.example.com/sitemap.xml"); string(again) != content {
+		t.Errorf("expected the kept content to be unchanged, got %q", again)
+	}
+}
@@ -0,0 +1,26 @@
+package sitemap
+
+import (
+	"strings"
+)
+
+// resolveSchemeRelative resolves the given location if it is a scheme-relative reference ("//host/path"),
+// with the scheme of the location of the document it appeared in, e.g. "https://example.com/robots.txt".
+// Other locations, and scheme-relative ones in a document without a scheme, are returned unchanged.
+func resolveSchemeRelative(loc string, documentLoc string) string {
+	if !strings.HasPrefix(loc, "//") {
+		return loc
+	}
+	i := strings.Index(documentLoc, "://")
+	if i <= 0 {
+		return loc
+	}
+	return strings.ToLower(documentLoc[:i]) + ":" + loc
+}
+
+// resolveSitemapLocs resolves the scheme-relative locations of the given sitemaps of the index at location in place, see resolveSchemeRelative.
+func resolveSitemapLocs(location string, sitemaps []IndexSitemap) {
+	for i := range sitemaps {
+		sitemaps[i].Loc = resolveSchemeRelative(sitemaps[i].Loc, location)
+	}
+}
@@ -0,0 +1,83 @@
+package sitemap
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_resolveSchemeRelative(t *testing.T) {
+	tests := []struct {
+		name        string
+		loc         string
+		documentLoc string
+		want        string
+	}{
+		{name: "https document", loc: "//cdn.example.com/sitemap.xml", documentLoc: "https://www.example.com/robots.txt", want: "https://cdn.example.com/sitemap.xml"},
+		{name: "http document", loc: "//cdn.example.com/sitemap.xml", documentLoc: "http://www.example.com/sitemap_index.xml", want: "http://cdn.example.com/sitemap.xml"},
+		{name: "uppercase scheme", loc: "//cdn.example.com/sitemap.xml", documentLoc: "HTTPS://www.example.com/robots.txt", want: "https://cdn.example.com/sitemap.xml"},
+		{name: "absolute", loc: "http://cdn.example.com/sitemap.xml", documentLoc: "https://www.example.com/robots.txt", want: "http://cdn.example.com/sitemap.xml"},
+		{name: "path", loc: "/sitemap.xml", documentLoc: "https://www.example.com/robots.txt", want: "/sitemap.xml"},
+		{name: "document without a scheme", loc: "//cdn.example.com/sitemap.xml", documentLoc: "robots.txt", want: "//cdn.example.com/sitemap.xml"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := resolveSchemeRelative(test.loc, test.documentLoc); got != test.want {
+				t.Errorf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestS_Parse_SchemeRelative(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	tests := []struct {
+		name            string
+		url             string
+		wantSitemapLocs []string
+		wantURLCount    int
+	}{
+		{
+			name:            "robots.txt",
+			url:             server.URL + "/robots-scheme-relative/robots.txt",
+			wantSitemapLocs: []string{"/sitemapindex-scheme-relative.xml", "/sitemap-01.xml", "/sitemap-02.xml"},
+			wantURLCount:    3,
+		},
+		{
+			name:            "sitemap index",
+			url:             server.URL + "/sitemapindex-scheme-relative.xml",
+			wantSitemapLocs: []string{"/sitemapindex-scheme-relative.xml", "/sitemap-01.xml", "/sitemap-02.xml"},
+			wantURLCount:    3,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var rewritten []string
+			s, err := New().SetMultiThread(false).SetSitemapURLRewriter(func(loc string) string {
+				rewritten = append(rewritten, loc)
+				return loc
+			}).Parse(test.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if errs := s.GetErrors(); len(errs) != 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if got := trimPrefixes(s.GetSitemapLocations(), server.URL); !reflect.DeepEqual(got, test.wantSitemapLocs) {
+				t.Errorf("expected %v, got %v", test.wantSitemapLocs, got)
+			}
+			if got := len(s.GetURLs()); got != test.wantURLCount {
+				t.Errorf("expected %d URLs, got %d", test.wantURLCount, got)
+			}
+			// the sitemap URL rewriter receives the resolved locations
+			for _, loc := range rewritten {
+				if !strings.HasPrefix(loc, server.URL) {
+					t.Errorf("expected %s to be resolved", loc)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package sitemap
+
+import (
+	"maps"
+	"math"
+	"slices"
+	"time"
+)
+
+// Report is a structure that holds summary statistics of the parsed URLs.
+// The URLCount field is the number of URLs, including the locations stored in loc-only mode (see SetLocOnly),
+// which have no metadata and are not in the other statistics.
+// The ChangeFreqs field holds the number of URLs per <changefreq> value.
+// The PriorityHistogram field holds the number of URLs per <priority> value rounded to 0.1, from 0.0 (index 0) to 1.0 (index 10).
+// The InvalidPriority field is the number of URLs with a <priority> value outside the range 0.0-1.0.
+// The LongLocs field is the number of URLs with a location over the 2048 characters allowed by the protocol.
+// The MissingLastMod, MissingChangeFreq and MissingPriority fields are the number of URLs without the given field,
+// a <lastmod> value inferred from the Last-Modified header of the sitemap (see SetLastModFallback) counts as missing and is not in the statistics.
+// The LastModMin, LastModMax and LastModMedian fields are the earliest, latest and median <lastmod> values,
+// or nil if none of the URLs has a <lastmod> value. For an even number of values, the lower median is used.
+// The Extensions field holds the usage of the sitemap extensions.
+type Report struct {
+	URLCount          int64            `json:"url_count"`
+	ChangeFreqs       map[string]int64 `json:"changefreqs"`
+	PriorityHistogram [11]int64        `json:"priority_histogram"`
+	InvalidPriority   int64            `json:"invalid_priority"`
+	LongLocs          int64            `json:"long_locs"`
+	MissingLastMod    int64            `json:"missing_lastmod"`
+	MissingChangeFreq int64            `json:"missing_changefreq"`
+	MissingPriority   int64            `json:"missing_priority"`
+	LastModMin        *time.Time       `json:"lastmod_min,omitempty"`
+	LastModMax        *time.Time       `json:"lastmod_max,omitempty"`
+	LastModMedian     *time.Time       `json:"lastmod_median,omitempty"`
+	Extensions        ExtensionStats   `json:"extensions"`
+}
+
+// ExtensionUsage is the usage of a sitemap extension.
+// The URLs field is the number of URLs carrying the extension, the Entries field is the total number of its elements.
+type ExtensionUsage struct {
+	URLs    int64 `json:"urls"`
+	Entries int64 `json:"entries"`
+}
+
+// ExtensionStats holds the usage of the sitemap extensions: <image:image>, <video:video>, <news:news>,
+// <xhtml:link> (hreflang alternates) and <mobile:mobile>.
+type ExtensionStats struct {
+	Image    ExtensionUsage `json:"image"`
+	Video    ExtensionUsage `json:"video"`
+	News     ExtensionUsage `json:"news"`
+	Hreflang ExtensionUsage `json:"hreflang"`
+	Mobile   ExtensionUsage `json:"mobile"`
+}
+
+// add counts a URL carrying the given number of elements of the extension, if any.
+func (u *ExtensionUsage) add(entries int) {
+	if entries > 0 {
+		u.URLs++
+		u.Entries += int64(entries)
+	}
+}
+
+// GetReport returns summary statistics of the parsed URLs.
+// The report is computed in a single pass over the URLs on the first call and cached until the URLs change.
+// The ChangeFreqs map of the returned report is a copy, so the cached report is not affected by changes to it.
+// If the S object is nil, an empty report is returned.
+func (s *S) GetReport() Report {
+	if s == nil {
+		return newReport(nil)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.report == nil {
+		report := newReport(s.urls)
+		report.URLCount += int64(len(s.locs))
+		s.report = &report
+	}
+	report := *s.report
+	report.ChangeFreqs = maps.Clone(s.report.ChangeFreqs)
+	return report
+}
+
+// GetExtensionStats returns the usage of the sitemap extensions by the parsed URLs, see ExtensionStats.
+// It is the Extensions field of the report returned by GetReport.
+// If the S object is nil, empty statistics are returned.
+func (s *S) GetExtensionStats() ExtensionStats {
+	return s.GetReport().Extensions
+}
+
+// GetURLsWithoutLastMod returns the parsed URLs without a valid <lastmod> value, in the order they were parsed,
+// e.g. to track down the page templates omitting it. The Report.MissingLastMod field is their number, see GetReport,
+// which also counts the URLs missing a <changefreq> or a <priority> value.
+// The URLs whose LastMod field is inferred from the Last-Modified header of their sitemap are included, see SetLastModFallback.
+// The locations stored in loc-only mode (see SetLocOnly) have no metadata and are not included.
+// If the S object is nil or every URL has a <lastmod> value, an empty slice is returned.
+func (s *S) GetURLsWithoutLastMod() []URL {
+	urls := []URL{}
+	if s == nil {
+		return urls
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.urls {
+		if u.LastMod == nil || u.LastModInferred {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// GetUniqueURLCount returns the number of distinct Loc values of the parsed URLs, compared exactly,
+// including the locations stored in loc-only mode, see SetLocOnly.
+// The difference from GetURLCount is the number of duplicated URLs.
+// The count is computed on the first call and cached until the URLs change.
+// If the S object is nil, 0 is returned.
+func (s *S) GetUniqueURLCount() int64 {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.uniqueURLCount == nil {
+		count := uniqueLocCount(s.urls, s.locs)
+		s.uniqueURLCount = &count
+	}
+	return *s.uniqueURLCount
+}
+
+// uniqueLocCount returns the number of distinct values among the Loc values of the given URLs and the given locations.
+// The keys of the set share the memory of the Loc values, only the set itself is allocated.
+func uniqueLocCount(urls []URL, locs []string) int64 {
+	set := make(map[string]struct{}, len(urls)+len(locs))
+	for _, u := range urls {
+		set[u.Loc] = struct{}{}
+	}
+	for _, loc := range locs {
+		set[loc] = struct{}{}
+	}
+	return int64(len(set))
+}
+
+// newReport computes the summary statistics of the given URLs.
+func newReport(urls []URL) Report {
+	report := Report{
+		URLCount:    int64(len(urls)),
+		ChangeFreqs: map[string]int64{},
+	}
+
+	lastMods := make([]time.Time, 0, len(urls))
+	for _, u := range urls {
+		if u.ChangeFreq != nil {
+			report.ChangeFreqs[string(*u.ChangeFreq)]++
+		} else {
+			report.MissingChangeFreq++
+		}
+
+		if u.Priority != nil {
+			if *u.Priority >= 0 && *u.Priority <= 1 {
+				report.PriorityHistogram[int(math.Round(float64(*u.Priority)*10))]++
+			} else {
+				report.InvalidPriority++
+			}
+		} else {
+			report.MissingPriority++
+		}
+
+		if len(u.Loc) > maxLocLength {
+			report.LongLocs++
+		}
+
+		if u.LastMod != nil && !u.LastModInferred {
+			lastMods = append(lastMods, u.LastMod.Time)
+		} else {
+			report.MissingLastMod++
+		}
+
+		report.Extensions.Image.add(len(u.Images))
+		report.Extensions.Video.add(len(u.Videos))
+		if u.News != nil {
+			report.Extensions.News.add(1)
+		}
+		report.Extensions.Hreflang.add(len(u.Alternates))
+		if u.Mobile != nil {
+			report.Extensions.Mobile.add(1)
+		}
+	}
+
+	if len(lastMods) > 0 {
+		slices.SortFunc(lastMods, func(a, b time.Time) int {
+			return a.Compare(b)
+		})
+		report.LastModMin = &lastMods[0]
+		report.LastModMax = &lastMods[len(lastMods)-1]
+		report.LastModMedian = &lastMods[(len(lastMods)-1)/2]
+	}
+
+	return report
+}
@@ -0,0 +1,391 @@
+package sitemap
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestS_GetReport(t *testing.T) {
+	lastModUTC := time.Date(2024, time.February, 12, 0, 0, 0, 0, time.UTC)
+	lastModCET := time.Date(2024, time.February, 12, 12, 34, 56, 0, time.FixedZone("", 3600))
+
+	tests := []struct {
+		name     string
+		setup    func() *S
+		fixtures []string
+		want     Report
+	}{
+		{
+			name:  "nil receiver",
+			setup: func() *S { return nil },
+			want:  Report{ChangeFreqs: map[string]int64{}},
+		},
+		{
+			name:  "no URLs",
+			setup: func() *S { return New() },
+			want:  Report{ChangeFreqs: map[string]int64{}},
+		},
+		{
+			name:     "fixtures",
+			setup:    func() *S { return New() },
+			fixtures: []string{"./test/sitemap-03.xml", "./test/sitemap-extensions.xml"},
+			want: Report{
+				URLCount: 8,
+				ChangeFreqs: map[string]int64{
+					"weekly":  1,
+					"monthly": 1,
+					"yearly":  1,
+					"daily":   1,
+				},
+				PriorityHistogram: [11]int64{5: 3, 8: 1},
+				MissingLastMod:    4,
+				MissingChangeFreq: 4,
+				MissingPriority:   4,
+				LastModMin:        &lastModUTC,
+				LastModMax:        &lastModCET,
+				LastModMedian:     &lastModCET,
+				Extensions: ExtensionStats{
+					Image:    ExtensionUsage{URLs: 1, Entries: 2},
+					Video:    ExtensionUsage{URLs: 1, Entries: 1},
+					News:     ExtensionUsage{URLs: 1, Entries: 1},
+					Hreflang: ExtensionUsage{URLs: 1, Entries: 2},
+				},
+			},
+		},
+		{
+			name: "invalid priority",
+			setup: func() *S {
+				return &S{urls: []URL{{Priority: pointerOfFloat32(1.5)}, {Priority: pointerOfFloat32(0.04)}}}
+			},
+			want: Report{
+				URLCount:          2,
+				ChangeFreqs:       map[string]int64{},
+				PriorityHistogram: [11]int64{0: 1},
+				InvalidPriority:   1,
+				MissingLastMod:    2,
+				MissingChangeFreq: 2,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := test.setup()
+			for _, fixture := range test.fixtures {
+				content, err := os.ReadFile(fixture)
+				if err != nil {
+					t.Fatal(err)
+				}
+				s.parse(fixture, string(content))
+			}
+
+			got := s.GetReport()
+			if !reportsEqual(got, test.want) {
+				t.Errorf("expected %+v, got %+v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestS_GetReport_Cache(t *testing.T) {
+	s := &S{urls: []URL{{Loc: "https://www.sitemaps.org/1"}}}
+	if s.GetReport().URLCount != 1 {
+		t.Fatalf("expected 1 URL, got %d", s.GetReport().URLCount)
+	}
+
+	content, err := os.ReadFile("./test/sitemap-02.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.parse("./test/sitemap-02.xml", string(content))
+
+	if s.GetReport().URLCount != 3 {
+		t.Errorf("expected 3 URLs after parse, got %d", s.GetReport().URLCount)
+	}
+}
+
+func TestS_GetReport_Copy(t *testing.T) {
+	daily := changeFreqDaily
+	s := &S{urls: []URL{{Loc: "https://www.sitemaps.org/1", ChangeFreq: &daily}}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.GetReport().ChangeFreqs["daily"]++
+		}()
+	}
+	wg.Wait()
+
+	if got := s.GetReport().ChangeFreqs["daily"]; got != 1 {
+		t.Errorf("expected the cached report to be unchanged, got %d daily URLs", got)
+	}
+}
+
+func TestS_GetReport_LocOnly(t *testing.T) {
+	content, err := os.ReadFile("./test/sitemap-02.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := New().SetLocOnly(true)
+	s.parse("./test/sitemap-02.xml", string(content))
+
+	got := s.GetReport()
+	if got.URLCount != 2 {
+		t.Errorf("expected 2 URLs, got %d", got.URLCount)
+	}
+	if got.MissingLastMod != 0 {
+		t.Errorf("expected the locations not to be in the other statistics, got %d URLs without lastmod", got.MissingLastMod)
+	}
+}
+
+func TestS_GetReport_Large(t *testing.T) {
+	const count = 1000000
+
+	changeFreqs := []urlChangeFreq{changeFreqAlways, changeFreqHourly, changeFreqDaily, changeFreqWeekly, changeFreqMonthly}
+	s := &S{urls: make([]URL, 0, count)}
+	for i := 0; i < count; i++ {
+		u := URL{Loc: fmt.Sprintf("https://www.sitemaps.org/page-%d", i)}
+		if i%2 == 0 {
+			u.LastMod = &LastMod{time.Unix(int64(i), 0).UTC()}
+		}
+		if i%5 != 0 {
+			u.ChangeFreq = &changeFreqs[i%5]
+		}
+		u.Priority = pointerOfFloat32(float32(i%11) / 10)
+		s.urls = append(s.urls, u)
+	}
+
+	got := s.GetReport()
+
+	if got.URLCount != count {
+		t.Errorf("expected %d URLs, got %d", count, got.URLCount)
+	}
+	if got.MissingLastMod != count/2 {
+		t.Errorf("expected %d URLs without lastmod, got %d", count/2, got.MissingLastMod)
+	}
+	if got.MissingChangeFreq != count/5 || got.ChangeFreqs["hourly"] != count/5 {
+		t.Errorf("unexpected changefreq distribution: %v, missing %d", got.ChangeFreqs, got.MissingChangeFreq)
+	}
+	var histogramSum int64
+	for _, bucket := range got.PriorityHistogram {
+		histogramSum += bucket
+	}
+	if histogramSum != count {
+		t.Errorf("expected %d URLs in the priority histogram, got %d", count, histogramSum)
+	}
+	if !got.LastModMin.Equal(time.Unix(0, 0)) || !got.LastModMax.Equal(time.Unix(count-2, 0)) || !got.LastModMedian.Equal(time.Unix(count/2-2, 0)) {
+		t.Errorf("unexpected lastmod spread: %v, %v, %v", got.LastModMin, got.LastModMax, got.LastModMedian)
+	}
+
+	if _, err := json.Marshal(got); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func reportsEqual(r1, r2 Report) bool {
+	timesEqual := func(t1, t2 *time.Time) bool {
+		if t1 == nil || t2 == nil {
+			return t1 == t2
+		}
+		return t1.Equal(*t2)
+	}
+	return r1.URLCount == r2.URLCount &&
+		reflect.DeepEqual(r1.ChangeFreqs, r2.ChangeFreqs) &&
+		r1.PriorityHistogram == r2.PriorityHistogram &&
+		r1.InvalidPriority == r2.InvalidPriority &&
+		r1.MissingLastMod == r2.MissingLastMod &&
+		r1.MissingChangeFreq == r2.MissingChangeFreq &&
+		r1.MissingPriority == r2.MissingPriority &&
+		timesEqual(r1.LastModMin, r2.LastModMin) &&
+		timesEqual(r1.LastModMax, r2.LastModMax) &&
+		timesEqual(r1.LastModMedian, r2.LastModMedian) &&
+		r1.Extensions == r2.Extensions
+}
+
+func TestS_GetExtensionStats(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	s, err := New().Parse(fmt.Sprintf("%s/sitemap-extension-stats.xml", server.URL), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		s    *S
+		want ExtensionStats
+	}{
+		{
+			name: "nil receiver",
+			s:    nil,
+			want: ExtensionStats{},
+		},
+		{
+			name: "plain URLs",
+			s:    &S{urls: []URL{{Loc: "https://www.sitemaps.org/1"}, {Loc: "https://www.sitemaps.org/2"}}},
+			want: ExtensionStats{},
+		},
+		{
+			name: "annotated and plain URLs",
+			s:    s,
+			want: ExtensionStats{
+				Image:    ExtensionUsage{URLs: 2, Entries: 4},
+				Video:    ExtensionUsage{URLs: 1, Entries: 1},
+				News:     ExtensionUsage{URLs: 1, Entries: 1},
+				Hreflang: ExtensionUsage{URLs: 1, Entries: 2},
+				Mobile:   ExtensionUsage{URLs: 2, Entries: 2},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.s.GetExtensionStats(); got != test.want {
+				t.Errorf("expected %+v, got %+v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestS_GetUniqueURLCount(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		s         *S
+		url       string
+		urlsCount int64
+		want      int64
+	}{
+		{
+			name: "nil receiver",
+			s:    nil,
+		},
+		{
+			name:      "duplicates",
+			s:         New(),
+			url:       fmt.Sprintf("%s/sitemap-duplicates.xml", server.URL),
+			urlsCount: 6,
+			want:      3,
+		},
+		{
+			name:      "no duplicates",
+			s:         New(),
+			url:       fmt.Sprintf("%s/robots-with-sitemapindex/robots.txt", server.URL),
+			urlsCount: 6,
+			want:      6,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := test.s
+			if s != nil {
+				var err error
+				if s, err = s.Parse(test.url, nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := s.GetURLCount(); got != test.urlsCount {
+				t.Errorf("expected %d URLs, got %d", test.urlsCount, got)
+			}
+			if got := s.GetUniqueURLCount(); got != test.want {
+				t.Errorf("expected %d unique URLs, got %d", test.want, got)
+			}
+		})
+	}
+}
+
+func TestS_GetUniqueURLCount_Cache(t *testing.T) {
+	s := &S{urls: []URL{{Loc: "https://www.sitemaps.org/1"}, {Loc: "https://www.sitemaps.org/1"}}}
+	if got := s.GetUniqueURLCount(); got != 1 {
+		t.Fatalf("expected 1 unique URL, got %d", got)
+	}
+
+	content, err := os.ReadFile("./test/sitemap-02.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.parse("./test/sitemap-02.xml", string(content))
+
+	if got := s.GetUniqueURLCount(); got != 3 {
+		t.Errorf("expected 3 unique URLs after parse, got %d", got)
+	}
+}
+
+func TestS_GetURLsWithoutLastMod(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	tests := []struct {
+		name              string
+		s                 *S
+		url               string
+		want              []string
+		wantMissingCounts [3]int64
+	}{
+		{
+			name: "nil receiver",
+			s:    nil,
+			want: []string{},
+		},
+		{
+			name:              "partial metadata",
+			s:                 New(),
+			url:               fmt.Sprintf("%s/sitemap-partial-metadata.xml", server.URL),
+			want:              []string{"/product-02", "/product-04"},
+			wantMissingCounts: [3]int64{2, 2, 2},
+		},
+		{
+			name:              "complete metadata",
+			s:                 New(),
+			url:               fmt.Sprintf("%s/sitemap-01.xml", server.URL),
+			want:              []string{},
+			wantMissingCounts: [3]int64{0, 0, 0},
+		},
+		{
+			name: "loc-only mode",
+			s:    New().SetLocOnly(true),
+			url:  fmt.Sprintf("%s/sitemap-partial-metadata.xml", server.URL),
+			want: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := test.s
+			if s != nil {
+				var err error
+				if s, err = s.Parse(test.url, nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			urls := s.GetURLsWithoutLastMod()
+			if got := trimPrefixes(locsOf(urls), server.URL); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+			report := s.GetReport()
+			if got := [3]int64{report.MissingLastMod, report.MissingChangeFreq, report.MissingPriority}; got != test.wantMissingCounts {
+				t.Errorf("expected missing lastmod, changefreq and priority counts %v, got %v", test.wantMissingCounts, got)
+			}
+
+			// the returned slice is a copy
+			if len(urls) > 0 {
+				urls[0].Loc = "modified"
+				if got := s.GetURLsWithoutLastMod()[0].Loc; got == "modified" {
+					t.Error("expected a copy of the URLs")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package sitemap
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+)
+
+type (
+	// Result is a JSON-serializable copy of the outcome of a parse, e.g. to cache it between the stages of a pipeline, see GetResult.
+	// It embeds the Checkpoint of the parse, with the sitemap tree, the URLs and the errors, so it can be resumed with ParseFromCheckpoint.
+	// The LocOnly field reports whether the URLs hold only their locations, see SetLocOnly.
+	// The SitemapLocations and RobotsTxtSitemapURLs fields are the locations returned by GetSitemapLocations and listed by the robots.txt file.
+	// The Warnings field holds the messages of the warnings, see GetWarnings.
+	// The FetchMetadata field is the metadata of the fetches, see GetFetchMetadata.
+	// The Completeness and Timing fields are the completeness and the timing of the parse, see GetCompleteness and GetTiming.
+	// The FilteredURLCounts field counts the URLs rejected per filter, see GetFilteredURLCounts.
+	// The SkippedSitemaps field lists the sitemaps rejected by a filter, see GetSkippedSitemaps.
+	// The ParsedAt field is the time the parse started.
+	Result struct {
+		Checkpoint
+		LocOnly              bool                 `json:"loc_only,omitempty"`
+		SitemapLocations     []string             `json:"sitemap_locations"`
+		RobotsTxtSitemapURLs []string             `json:"robots_txt_sitemap_urls"`
+		Warnings             []string             `json:"warnings"`
+		FetchMetadata        map[string]FetchMeta `json:"fetch_metadata"`
+		Completeness         Completeness         `json:"completeness"`
+		Timing               Timing               `json:"timing"`
+		FilteredURLCounts    map[Filter]int64     `json:"filtered_url_counts"`
+		SkippedSitemaps      []SkippedSitemap     `json:"skipped_sitemaps"`
+		ParsedAt             time.Time            `json:"parsed_at"`
+	}
+
+	// SkippedSitemap is a sitemap location of an index rejected by a filter, see GetSkippedSitemapsByFilter.
+	SkippedSitemap struct {
+		Loc    string `json:"loc"`
+		Filter Filter `json:"filter"`
+	}
+)
+
+// GetResult returns a copy of the outcome of the parse, which can be saved and restored with LoadResult, see SaveResult.
+// The errors and the warnings are kept as their messages, and the errors of the nodes of the sitemap tree,
+// the raw content (see SetKeepRawContent) and the debug trace (see SetDebugTrace) are not included.
+// If the S object is nil, a Result without any results is returned.
+func (s *S) GetResult() Result {
+	r := Result{
+		Checkpoint:           s.GetCheckpoint(),
+		SitemapLocations:     []string{},
+		RobotsTxtSitemapURLs: []string{},
+		Warnings:             []string{},
+		FetchMetadata:        s.GetFetchMetadata(),
+		Completeness:         s.GetCompleteness(),
+		Timing:               s.GetTiming(),
+		FilteredURLCounts:    s.GetFilteredURLCounts(),
+		SkippedSitemaps:      []SkippedSitemap{},
+	}
+	if s == nil {
+		return r
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r.LocOnly = s.cfg.locOnly || len(s.locs) > 0
+	r.SitemapLocations = append(r.SitemapLocations, s.sitemapLocations...)
+	r.RobotsTxtSitemapURLs = append(r.RobotsTxtSitemapURLs, s.robotsTxtSitemapURLs...)
+	for _, warning := range s.warnings {
+		r.Warnings = append(r.Warnings, warning.Error())
+	}
+	for _, sm := range s.filteredSitemaps {
+		r.SkippedSitemaps = append(r.SkippedSitemaps, SkippedSitemap{Loc: sm.loc, Filter: sm.filter})
+	}
+	r.ParsedAt = s.parsedAt
+
+	return r
+}
+
+// MarshalJSON encodes the outcome of the parse as JSON, see GetResult.
+func (s *S) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.GetResult())
+}
+
+// UnmarshalJSON replaces the results of the S object with the outcome of a parse encoded by MarshalJSON or SaveResult.
+// The configuration of the S object is kept, and the errors and the warnings are restored as errors with the saved messages.
+func (s *S) UnmarshalJSON(data []byte) error {
+	if s == nil {
+		return ErrNilReceiver
+	}
+	var r Result
+	if err := json.Unmarshal(data, &r); err != nil {
+		return err
+	}
+	s.restoreResult(r)
+
+	return nil
+}
+
+// SaveResult writes the outcome of the parse to w as indented JSON, see GetResult. It can be restored with LoadResult.
+// If the S object is nil, it returns ErrNilReceiver.
+func (s *S) SaveResult(w io.Writer) error {
+	if s == nil {
+		return ErrNilReceiver
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(s.GetResult())
+}
+
+// LoadResult reads the outcome of a parse written by SaveResult and returns a new S object holding it,
+// so the getters return the results of the saved parse, e.g. GetURLs, GetErrors or GetCompleteness.
+// The new S object has the default configuration; to resume the parse with another configuration, see ParseFromCheckpoint.
+func LoadResult(r io.Reader) (*S, error) {
+	var result Result
+	if err := json.NewDecoder(r).Decode(&result); err != nil {
+		return nil, err
+	}
+	s := New()
+	s.restoreResult(result)
+
+	return s, nil
+}
+
+// restoreResult replaces the results of the S object with the given result.
+func (s *S) restoreResult(r Result) {
+	fetched := make(map[string]bool, len(r.Fetched))
+	for _, loc := range r.Fetched {
+		fetched[loc] = true
+	}
+	if r.Tree != nil {
+		s.restoreCheckpoint(r.Checkpoint, fetched)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.mainURL = r.MainURL
+	if r.Tree == nil {
+		s.tree, s.nodes = nil, map[string]*SitemapNode{}
+		s.restoreURLs(r.URLs, r.LocOnly)
+		s.errs = make([]error, 0, len(r.Errors))
+		for _, msg := range r.Errors {
+			s.errs = append(s.errs, errors.New(msg))
+		}
+	} else if r.LocOnly != s.cfg.locOnly {
+		s.restoreURLs(r.URLs, r.LocOnly)
+	}
+	s.sitemapLocations = append([]string(nil), r.SitemapLocations...)
+	s.robotsTxtSitemapURLs = append([]string(nil), r.RobotsTxtSitemapURLs...)
+	s.warnings = make([]error, 0, len(r.Warnings))
+	for _, msg := range r.Warnings {
+		s.warnings = append(s.warnings, errors.New(msg))
+	}
+	s.fetchMeta = map[string]FetchMeta{}
+	for loc, meta := range r.FetchMetadata {
+		s.fetchMeta[loc] = meta
+	}
+	s.truncatedBy = ""
+	if r.Completeness.TruncatedBy != TruncatedByNone {
+		s.truncatedBy = r.Completeness.TruncatedBy
+	}
+	s.failedSitemaps = r.Completeness.FailedSitemaps
+	s.skippedSitemaps = r.Completeness.SkippedSitemaps
+	s.timing = r.Timing
+	s.filteredURLs = nil
+	for filter, n := range r.FilteredURLCounts {
+		if s.filteredURLs == nil {
+			s.filteredURLs = map[Filter]int64{}
+		}
+		s.filteredURLs[filter] = n
+	}
+	s.filteredSitemaps = nil
+	for _, sm := range r.SkippedSitemaps {
+		s.filteredSitemaps = append(s.filteredSitemaps, filteredSitemap{loc: sm.Loc, filter: sm.Filter})
+	}
+	s.parsedAt = r.ParsedAt
+}
@@ -0,0 +1,183 @@
+package sitemap
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// normalizeResult clears the durations and times of the parse, which differ from run to run,
+// and the sizes of the bodies, which depend on the address of the test server.
+func normalizeResult(s *S) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.timing = Timing{Fetches: s.timing.Fetches}
+	s.parsedAt = time.Time{}
+	for loc, meta := range s.fetchMeta {
+		meta.Duration = 0
+		meta.CompressedBytes, meta.DecompressedBytes = 0, 0
+		s.fetchMeta[loc] = meta
+	}
+}
+
+func TestS_SaveResult_Golden(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	want, err := os.ReadFile("./test/result-robots-with-sitemapindex.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := New().SetMultiThread(false).Parse(server.URL+"/robots-with-sitemapindex/robots.txt", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	normalizeResult(s)
+
+	var buf bytes.Buffer
+	if err := s.SaveResult(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.ReplaceAll(buf.String(), server.URL, "http://HOST"); got != string(want) {
+		t.Errorf("expected\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestLoadResult_RoundTrip(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		s    *S
+		path string
+	}{
+		{
+			name: "robots.txt",
+			s:    New(),
+			path: "/robots-with-sitemapindex/robots.txt",
+		},
+		{
+			name: "sitemap index with errors",
+			s:    New(),
+			path: "/sitemapindex-with-invalid-sitemap.xml",
+		},
+		{
+			name: "missing sitemaps, aborted",
+			s:    New().SetMultiThread(false).SetMaxErrors(3),
+			path: "/sitemapindex-404.xml",
+		},
+		{
+			name: "filtered",
+			s:    New().SetFollow([]string{`sitemap-0[12]`}).SetRules([]string{`page-0[1-4]`}),
+			path: "/sitemapindex-1.xml",
+		},
+		{
+			name: "extensions",
+			s:    New().SetTrackPositions(true),
+			path: "/sitemap-extensions.xml",
+		},
+		{
+			name: "loc-only",
+			s:    New().SetLocOnly(true),
+			path: "/sitemapindex-1.xml",
+		},
+		{
+			name: "unexpected elements",
+			s:    New().SetStrictElements(true),
+			path: "/sitemap-unexpected-elements.xml",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, _ := test.s.Parse(server.URL+test.path, nil)
+
+			var saved bytes.Buffer
+			if err := s.SaveResult(&saved); err != nil {
+				t.Fatal(err)
+			}
+			loaded, err := LoadResult(bytes.NewReader(saved.Bytes()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var resaved bytes.Buffer
+			if err := loaded.SaveResult(&resaved); err != nil {
+				t.Fatal(err)
+			}
+			if saved.String() != resaved.String() {
+				t.Errorf("expected\n%s\ngot\n%s", saved.String(), resaved.String())
+			}
+
+			if got, want := loaded.GetLocs(), s.GetLocs(); !reflect.DeepEqual(got, want) {
+				t.Errorf("GetLocs: expected %v, got %v", want, got)
+			}
+			if got, want := len(loaded.GetURLs()), len(s.GetURLs()); got != want {
+				t.Errorf("GetURLs: expected %d URLs, got %d", want, got)
+			}
+			if got, want := loaded.GetSitemapLocations(), s.GetSitemapLocations(); !reflect.DeepEqual(got, want) {
+				t.Errorf("GetSitemapLocations: expected %v, got %v", want, got)
+			}
+			if got, want := loaded.GetErrorsCount(), s.GetErrorsCount(); got != want {
+				t.Errorf("GetErrorsCount: expected %d, got %d", want, got)
+			}
+			if got, want := len(loaded.GetWarnings()), len(s.GetWarnings()); got != want {
+				t.Errorf("GetWarnings: expected %d, got %d", want, got)
+			}
+			if got, want := loaded.GetCompleteness(), s.GetCompleteness(); got != want {
+				t.Errorf("GetCompleteness: expected %v, got %v", want, got)
+			}
+			if got, want := loaded.GetTiming(), s.GetTiming(); got != want {
+				t.Errorf("GetTiming: expected %v, got %v", want, got)
+			}
+			if got, want := loaded.GetFetchMetadata(), s.GetFetchMetadata(); !reflect.DeepEqual(got, want) {
+				t.Errorf("GetFetchMetadata: expected %v, got %v", want, got)
+			}
+			if got, want := loaded.GetFilteredURLCounts(), s.GetFilteredURLCounts(); !reflect.DeepEqual(got, want) {
+				t.Errorf("GetFilteredURLCounts: expected %v, got %v", want, got)
+			}
+			if got, want := loaded.GetSkippedSitemaps(), s.GetSkippedSitemaps(); !reflect.DeepEqual(got, want) {
+				t.Errorf("GetSkippedSitemaps: expected %v, got %v", want, got)
+			}
+			if got, want := loaded.String(), s.String(); got != want {
+				t.Errorf("String: expected %q, got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestS_JSON(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	s, _ := New().Parse(server.URL+"/sitemapindex-1.xml", nil)
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := New()
+	if err := json.Unmarshal(data, loaded); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sortedLocs(loaded.GetURLs()), sortedLocs(s.GetURLs()); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	var nilS *S
+	if err := nilS.UnmarshalJSON(data); !errors.Is(err, ErrNilReceiver) {
+		t.Errorf("UnmarshalJSON: expected %v, got %v", ErrNilReceiver, err)
+	}
+	if err := nilS.SaveResult(&bytes.Buffer{}); !errors.Is(err, ErrNilReceiver) {
+		t.Errorf("SaveResult: expected %v, got %v", ErrNilReceiver, err)
+	}
+	if _, err := LoadResult(strings.NewReader("{")); err == nil {
+		t.Errorf("LoadResult: expected an error, got nil")
+	}
+}
@@ -0,0 +1,153 @@
+package sitemap
+
+import (
+	neturl "net/url"
+	"strings"
+)
+
+// SetURLRewriter sets a function rewriting the location of each URL decoded from a sitemap,
+// e.g. to strip a mirror prefix, to swap http to https or to map a CDN host to the canonical domain.
+// It is applied before the URL is matched against the rules (see SetRules) and stored; if it returns an empty string, the URL is dropped.
+// The function may be called concurrently from several goroutines, so it must be safe for concurrent use and should be pure.
+// A nil function (the default) leaves the locations unchanged.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetURLRewriter(fn func(loc string) string) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.urlRewriter = fn
+
+	return s
+}
+
+// SetSitemapURLRewriter sets a function rewriting the location of each sitemap referenced by a sitemap index or a robots.txt file.
+// It is applied before the location is matched against the follow patterns (see SetFollow) and fetched,
+// after a scheme-relative location ("//host/path") has been resolved with the scheme of the document it appeared in;
+// if it returns an empty string, the sitemap is dropped. The main URL passed to Parse is not rewritten.
+// The function may be called concurrently from several goroutines, so it must be safe for concurrent use and should be pure.
+// A nil function (the default) leaves the locations unchanged.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetSitemapURLRewriter(fn func(loc string) string) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.sitemapURLRewriter = fn
+
+	return s
+}
+
+// SetHostRewrite sets the mapping of the hosts of the stored URLs, e.g. "staging.example.com" to "www.example.com",
+// so that the URLs of a staging environment can be compared to the URLs of production directly.
+// The host of the location of each URL decoded from a sitemap is replaced according to the map before the URL rewriter (see SetURLRewriter) is applied.
+// The hosts are matched case-insensitively, including the port, if any, and the Unicode and the punycode form of a host match each other.
+// The locations fetched are not affected, see SetFetchHostRewrite.
+// A nil or empty map (the default) leaves the hosts unchanged.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetHostRewrite(hosts map[string]string) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.hostRewrite = lowerHosts(hosts)
+
+	return s
+}
+
+// SetFetchHostRewrite sets the mapping of the hosts of the locations fetched, e.g. "www.example.com" to "staging.example.com",
+// so that the sitemaps referenced with the production host are fetched from a staging environment.
+// The host of every location is replaced according to the map when it is fetched, including the main URL passed to Parse;
+// the locations are recorded unchanged (e.g. in GetSitemapLocations and GetFetchMetadata).
+// The hosts are matched case-insensitively, including the port, if any. A nil or empty map (the default) leaves the hosts unchanged.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetFetchHostRewrite(hosts map[string]string) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.fetchHostRewrite = lowerHosts(hosts)
+
+	return s
+}
+
+// lowerHosts returns a copy of the given host mapping with lowercase ASCII keys (see asciiHost), nil if it is empty.
+func lowerHosts(hosts map[string]string) map[string]string {
+	if len(hosts) == 0 {
+		return nil
+	}
+	lowered := make(map[string]string, len(hosts))
+	for from, to := range hosts {
+		lowered[asciiHost(from)] = to
+	}
+	return lowered
+}
+
+// rewriteHost replaces the host of the given location according to the given mapping.
+// The rest of the location is kept as it is. The location is returned unchanged if it cannot be parsed or its host is not mapped.
+func rewriteHost(loc string, hosts map[string]string) string {
+	if hosts == nil {
+		return loc
+	}
+	u, err := neturl.Parse(loc)
+	if err != nil || u.Host == "" {
+		return loc
+	}
+	to, ok := hosts[asciiHost(u.Host)]
+	if !ok {
+		return loc
+	}
+	prefix := len(u.Scheme) + len("://")
+	if u.User == nil && len(loc) >= prefix+len(u.Host) && strings.EqualFold(loc[prefix:prefix+len(u.Host)], u.Host) {
+		return loc[:prefix] + to + loc[prefix+len(u.Host):]
+	}
+	u.Host = to
+	return u.String()
+}
+
+// rewriteURLs applies the https upgrade, the punycode host normalization, the host rewrite, the query parameter stripping and the URL rewriter
+// to the locations of the given URLs in place, and returns the URLs the URL rewriter has not dropped.
+func (s *S) rewriteURLs(urls []URL) []URL {
+	if !s.cfg.upgradeLocsToHTTPS && !s.cfg.punycodeHosts && s.cfg.hostRewrite == nil && s.cfg.stripQueryParams == nil && s.cfg.urlRewriter == nil {
+		return urls
+	}
+	kept := urls[:0]
+	for _, u := range urls {
+		if s.cfg.upgradeLocsToHTTPS {
+			u.Loc = upgradeToHTTPS(u.Loc)
+		}
+		if s.cfg.punycodeHosts {
+			u.Loc = punycodeLoc(u.Loc)
+		}
+		u.Loc = rewriteHost(u.Loc, s.cfg.hostRewrite)
+		if s.cfg.stripQueryParams != nil {
+			u.Loc = stripQuery(u.Loc, s.cfg.stripQueryParams)
+		}
+		if s.cfg.urlRewriter != nil {
+			u.Loc = s.cfg.urlRewriter(u.Loc)
+		}
+		if u.Loc != "" {
+			kept = append(kept, u)
+		}
+	}
+	return kept
+}
+
+// rewriteSitemaps applies the sitemap URL rewriter to the locations of the given sitemaps of an index in place,
+// and returns the sitemaps it has not dropped.
+func (s *S) rewriteSitemaps(sitemaps []IndexSitemap) []IndexSitemap {
+	if s.cfg.sitemapURLRewriter == nil {
+		return sitemaps
+	}
+	kept := sitemaps[:0]
+	for _, sm := range sitemaps {
+		if sm.Loc = s.cfg.sitemapURLRewriter(sm.Loc); sm.Loc != "" {
+			kept = append(kept, sm)
+		}
+	}
+	return kept
+}
+
+// rewriteSitemapLoc applies the sitemap URL rewriter to the given location, an empty string means the sitemap is dropped.
+func (s *S) rewriteSitemapLoc(loc string) string {
+	if s.cfg.sitemapURLRewriter == nil {
+		return loc
+	}
+	return s.cfg.sitemapURLRewriter(loc)
+}
@@ -0,0 +1,294 @@
+package sitemap
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestS_SetURLRewriter(t *testing.T) {
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+    <url><loc>http://www.example.com/a</loc></url>
+    <url><loc>https://mirror.example.com/b</loc></url>
+    <url><loc>https://www.example.com/private/c</loc></url>
+    <url><loc>https://www.example.com/d</loc></url>
+</urlset>`
+	rewriter := func(loc string) string {
+		if strings.Contains(loc, "/private/") {
+			return ""
+		}
+		loc = strings.Replace(loc, "http://", "https://", 1)
+		return strings.Replace(loc, "https://mirror.example.com/", "https://www.example.com/", 1)
+	}
+
+	tests := []struct {
+		name     string
+		s        *S
+		wantLocs []string
+	}{
+		{
+			name:     "no rewriter",
+			s:        New(),
+			wantLocs: []string{"http://www.example.com/a", "https://mirror.example.com/b", "https://www.example.com/private/c", "https://www.example.com/d"},
+		},
+		{
+			name:     "rewriter",
+			s:        New().SetURLRewriter(rewriter),
+			wantLocs: []string{"https://www.example.com/a", "https://www.example.com/b", "https://www.example.com/d"},
+		},
+		{
+			name:     "rewriter before rules",
+			s:        New().SetURLRewriter(rewriter).SetRules([]string{`^https://www\.example\.com/[ab]$`}),
+			wantLocs: []string{"https://www.example.com/a", "https://www.example.com/b"},
+		},
+		{
+			name:     "rewriter with URLs only counted",
+			s:        New().SetURLRewriter(rewriter).SetCollectURLs(false),
+			wantLocs: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := test.s.Parse("https://www.example.com/sitemap.xml", &content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := locsOf(s.GetURLs()); !reflect.DeepEqual(got, test.wantLocs) {
+				t.Errorf("expected %v, got %v", test.wantLocs, got)
+			}
+		})
+	}
+
+	s, err := New().SetURLRewriter(rewriter).SetCollectURLs(false).Parse("https://www.example.com/sitemap.xml", &content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.GetSitemapTree().URLCount; got != 3 {
+		t.Errorf("expected 3 URLs counted, got %d", got)
+	}
+}
+
+func TestS_SetSitemapURLRewriter(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	index := `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+    <sitemap><loc>https://cdn.example.com/sitemap-01.xml</loc></sitemap>
+    <sitemap><loc>https://cdn.example.com/sitemap-02.xml</loc></sitemap>
+    <sitemap><loc>https://cdn.example.com/sitemap-03.xml</loc></sitemap>
+</sitemapindex>`
+	toServer := func(loc string) string {
+		if strings.HasSuffix(loc, "/sitemap-03.xml") {
+			return ""
+		}
+		return strings.Replace(loc, "https://cdn.example.com", server.URL, 1)
+	}
+
+	tests := []struct {
+		name          string
+		url           string
+		content       *string
+		s             *S
+		wantSitemaps  []string
+		wantURLsCount int64
+	}{
+		{
+			name:          "sitemap index",
+			url:           server.URL + "/sitemapindex.xml",
+			content:       &index,
+			s:             New().SetSitemapURLRewriter(toServer),
+			wantSitemaps:  []string{"/sitemap-01.xml", "/sitemap-02.xml"},
+			wantURLsCount: 3,
+		},
+		{
+			name:          "rewriter before follow",
+			url:           server.URL + "/sitemapindex.xml",
+			content:       &index,
+			s:             New().SetSitemapURLRewriter(toServer).SetFollow([]string{`^http://127\.0\.0\.1:\d+/sitemap-02\.xml$`}),
+			wantSitemaps:  []string{"/sitemap-02.xml"},
+			wantURLsCount: 2,
+		},
+		{
+			name: "robots.txt",
+			url:  server.URL + "/robots-with-sitemapindex/robots.txt",
+			s: New().SetSitemapURLRewriter(func(loc string) string {
+				if strings.HasSuffix(loc, "/sitemap-02.xml") {
+					return ""
+				}
+				return loc
+			}),
+			wantSitemaps:  []string{"/sitemapindex-1.xml"},
+			wantURLsCount: 4,
+		},
+		{
+			name: "robots.txt dropped",
+			url:  server.URL + "/robots-with-sitemapindex/robots.txt",
+			s: New().SetSitemapURLRewriter(func(loc string) string {
+				return ""
+			}),
+			wantSitemaps:  []string{},
+			wantURLsCount: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := test.s.Parse(test.url, test.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			children := []string{}
+			for _, child := range s.GetSitemapTree().Children {
+				children = append(children, strings.TrimPrefix(child.Loc, server.URL))
+			}
+			if !reflect.DeepEqual(children, test.wantSitemaps) {
+				t.Errorf("expected sitemaps %v, got %v", test.wantSitemaps, children)
+			}
+			if s.GetURLCount() != test.wantURLsCount || s.GetErrorsCount() != 0 {
+				t.Errorf("expected %d URLs without errors, got %d and %v", test.wantURLsCount, s.GetURLCount(), s.GetErrors())
+			}
+		})
+	}
+}
+
+func TestRewriteHost(t *testing.T) {
+	hosts := lowerHosts(map[string]string{
+		"Staging.Example.com":  "www.example.com",
+		"cdn.example.com:8080": "www.example.com",
+		"münchen.example":      "www.example.com",
+	})
+
+	tests := []struct {
+		name string
+		loc  string
+		want string
+	}{
+		{
+			name: "mapped",
+			loc:  "https://staging.example.com/a?b=c#d",
+			want: "https://www.example.com/a?b=c#d",
+		},
+		{
+			name: "mapped case-insensitively",
+			loc:  "https://STAGING.example.com/A",
+			want: "https://www.example.com/A",
+		},
+		{
+			name: "mapped with port",
+			loc:  "http://cdn.example.com:8080/a",
+			want: "http://www.example.com/a",
+		},
+		{
+			name: "port not mapped",
+			loc:  "http://cdn.example.com/a",
+			want: "http://cdn.example.com/a",
+		},
+		{
+			name: "user info",
+			loc:  "https://user@staging.example.com/a",
+			want: "https://user@www.example.com/a",
+		},
+		{
+			name: "mapped by the Unicode form",
+			loc:  "https://München.example/a",
+			want: "https://www.example.com/a",
+		},
+		{
+			name: "mapped by the punycode form",
+			loc:  "https://xn--mnchen-3ya.example/a",
+			want: "https://www.example.com/a",
+		},
+		{
+			name: "not mapped",
+			loc:  "https://www.example.org/a",
+			want: "https://www.example.org/a",
+		},
+		{
+			name: "relative",
+			loc:  "/a",
+			want: "/a",
+		},
+		{
+			name: "invalid",
+			loc:  "https://staging.example.com/%zz",
+			want: "https://staging.example.com/%zz",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := rewriteHost(test.loc, hosts); got != test.want {
+				t.Errorf("expected %s, got %s", test.want, got)
+			}
+		})
+	}
+}
+
+func TestS_SetHostRewrite(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	// the test server substitutes its own host into the fixtures, so the sitemaps served
+	// via "localhost" reference "localhost", and "127.0.0.1" is the staging host
+	staging := strings.TrimPrefix(server.URL, "http://")
+	production := strings.Replace(staging, "127.0.0.1", "localhost", 1)
+
+	index := `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+    <sitemap><loc>http://` + production + `/sitemap-01.xml</loc></sitemap>
+    <sitemap><loc>http://` + production + `/sitemap-02.xml</loc></sitemap>
+</sitemapindex>`
+
+	tests := []struct {
+		name      string
+		s         *S
+		url       string
+		content   *string
+		wantHosts []string
+	}{
+		{
+			name:      "stored locations",
+			s:         New().SetMultiThread(false).SetHostRewrite(map[string]string{staging: "www.example.com"}),
+			url:       server.URL + "/sitemapindex-1.xml",
+			wantHosts: []string{"www.example.com"},
+		},
+		{
+			name:      "not rewritten",
+			s:         New().SetMultiThread(false),
+			url:       server.URL + "/sitemapindex-1.xml",
+			wantHosts: []string{staging},
+		},
+		{
+			name: "fetched and stored locations",
+			s: New().SetMultiThread(false).
+				SetFetchHostRewrite(map[string]string{production: staging}).
+				SetHostRewrite(map[string]string{staging: "www.example.com"}),
+			url:       "http://" + production + "/sitemapindex.xml",
+			content:   &index,
+			wantHosts: []string{"www.example.com"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := test.s.Parse(test.url, test.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.GetErrorsCount() != 0 || s.GetURLCount() == 0 {
+				t.Fatalf("expected URLs without errors, got %d URLs and %v", s.GetURLCount(), s.GetErrors())
+			}
+			if got := s.GetHosts(); !reflect.DeepEqual(got, test.wantHosts) {
+				t.Errorf("expected hosts %v, got %v", test.wantHosts, got)
+			}
+			for loc := range s.GetFetchMetadata() {
+				if !strings.HasPrefix(loc, "http://"+staging) && !strings.HasPrefix(loc, "http://"+production) {
+					t.Errorf("unexpected fetched location %s", loc)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package sitemap
+
+import (
+	neturl "net/url"
+	"strings"
+)
+
+// robotsSitemap is a Sitemap directive of a robots.txt file.
+// The value field is the value of the directive as found, without the comment and the surrounding whitespace,
+// the loc field is the value percent-encoded (see encodeLoc) and resolved against the location of the robots.txt file,
+// and the encoded field reports whether encoding has changed the value.
+type robotsSitemap struct {
+	value   string
+	loc     string
+	encoded bool
+}
+
+// ExtractSitemapURLs returns the sitemap URLs listed by the Sitemap directives of the given robots.txt content, in order,
+// e.g. to get them from a robots.txt file fetched by another component without parsing it with an S object.
+// The parsing is tolerant: the directive names are case-insensitive, the lines may end with CRLF, LF or CR,
+// the comments (from "#" to the end of the line) and the whitespace around the names and values are ignored,
+// and the lines that are not Sitemap directives are skipped. The characters not allowed in a URL are percent-encoded,
+// and the relative URLs, including scheme-relative ones ("//host/path"), are resolved against baseURL,
+// the location of the robots.txt file; they are returned unchanged if baseURL is not an absolute URL.
+// Parse processes the robots.txt files the same way.
+func ExtractSitemapURLs(robotsTxt string, baseURL string) []string {
+	var urls []string
+	for _, sitemap := range extractRobotsSitemaps(robotsTxt, baseURL) {
+		urls = append(urls, sitemap.loc)
+	}
+	return urls
+}
+
+// extractRobotsSitemaps returns the Sitemap directives of the given robots.txt content, see ExtractSitemapURLs.
+func extractRobotsSitemaps(robotsTxt string, baseURL string) []robotsSitemap {
+	var sitemaps []robotsSitemap
+	robotsTxt = strings.TrimPrefix(robotsTxt, "\ufeff")
+	lines := strings.FieldsFunc(robotsTxt, func(r rune) bool { return r == '\n' || r == '\r' })
+	for _, line := range lines {
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		name, value, found := strings.Cut(line, ":")
+		if !found || !strings.EqualFold(strings.TrimSpace(name), "sitemap") {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		loc, encoded := encodeLoc(value)
+		sitemaps = append(sitemaps, robotsSitemap{value: value, loc: resolveRobotsLoc(loc, baseURL), encoded: encoded})
+	}
+	return sitemaps
+}
+
+// resolveRobotsLoc resolves the given location of a Sitemap directive against the location of the robots.txt file.
+// The absolute locations are returned unchanged, and so are the relative ones if baseURL is not an absolute URL.
+func resolveRobotsLoc(loc string, baseURL string) string {
+	if strings.HasPrefix(loc, "//") {
+		return resolveSchemeRelative(loc, baseURL)
+	}
+	ref, err := neturl.Parse(loc)
+	if err != nil || ref.IsAbs() {
+		return loc
+	}
+	base, err := neturl.Parse(baseURL)
+	if err != nil || !base.IsAbs() {
+		return loc
+	}
+	return base.ResolveReference(ref).String()
+}
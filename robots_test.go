@@ -0,0 +1,104 @@
+package sitemap
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractSitemapURLs(t *testing.T) {
+	tests := []struct {
+		name      string
+		robotsTxt string
+		baseURL   string
+		want      []string
+	}{
+		{
+			name:      "empty",
+			robotsTxt: "",
+			baseURL:   "https://www.example.com/robots.txt",
+		},
+		{
+			name:      "without Sitemap",
+			robotsTxt: "User-agent: *\nDisallow: /",
+			baseURL:   "https://www.example.com/robots.txt",
+		},
+		{
+			name:      "Sitemap directives",
+			robotsTxt: "User-agent: *\nDisallow: /private/\n\nSitemap: https://www.example.com/sitemap-01.xml\nSitemap: https://www.example.com/sitemap-02.xml\n",
+			baseURL:   "https://www.example.com/robots.txt",
+			want:      []string{"https://www.example.com/sitemap-01.xml", "https://www.example.com/sitemap-02.xml"},
+		},
+		{
+			name:      "case-insensitive names and whitespace",
+			robotsTxt: "SITEMAP: https://www.example.com/sitemap-01.xml\nsitemap:https://www.example.com/sitemap-02.xml\n  SiteMap  :\thttps://www.example.com/sitemap-03.xml  \n",
+			baseURL:   "https://www.example.com/robots.txt",
+			want:      []string{"https://www.example.com/sitemap-01.xml", "https://www.example.com/sitemap-02.xml", "https://www.example.com/sitemap-03.xml"},
+		},
+		{
+			name:      "CRLF and CR line endings",
+			robotsTxt: "User-agent: *\r\nSitemap: https://www.example.com/sitemap-01.xml\r\nSitemap: https://www.example.com/sitemap-02.xml\rSitemap: https://www.example.com/sitemap-03.xml",
+			baseURL:   "https://www.example.com/robots.txt",
+			want:      []string{"https://www.example.com/sitemap-01.xml", "https://www.example.com/sitemap-02.xml", "https://www.example.com/sitemap-03.xml"},
+		},
+		{
+			name:      "comments",
+			robotsTxt: "# Sitemap: https://www.example.com/commented.xml\nSitemap: https://www.example.com/sitemap-01.xml # the main sitemap\nSitemap: # nothing\n",
+			baseURL:   "https://www.example.com/robots.txt",
+			want:      []string{"https://www.example.com/sitemap-01.xml"},
+		},
+		{
+			name:      "byte order mark",
+			robotsTxt: "\ufeffSitemap: https://www.example.com/sitemap-01.xml",
+			baseURL:   "https://www.example.com/robots.txt",
+			want:      []string{"https://www.example.com/sitemap-01.xml"},
+		},
+		{
+			name:      "other directives containing sitemap",
+			robotsTxt: "Sitemaps: https://www.example.com/a.xml\nX-Sitemap: https://www.example.com/b.xml\nSitemap https://www.example.com/c.xml\n",
+			baseURL:   "https://www.example.com/robots.txt",
+		},
+		{
+			name:      "relative URLs",
+			robotsTxt: "Sitemap: /sitemap-01.xml\nSitemap: sitemaps/sitemap-02.xml\nSitemap: //cdn.example.com/sitemap-03.xml\nSitemap: ../sitemap-04.xml\n",
+			baseURL:   "https://www.example.com/seo/robots.txt",
+			want:      []string{"https://www.example.com/sitemap-01.xml", "https://www.example.com/seo/sitemaps/sitemap-02.xml", "https://cdn.example.com/sitemap-03.xml", "https://www.example.com/sitemap-04.xml"},
+		},
+		{
+			name:      "relative URLs without base URL",
+			robotsTxt: "Sitemap: /sitemap-01.xml\nSitemap: //cdn.example.com/sitemap-02.xml\n",
+			want:      []string{"/sitemap-01.xml", "//cdn.example.com/sitemap-02.xml"},
+		},
+		{
+			name:      "absolute URLs unchanged",
+			robotsTxt: "Sitemap: https://www.example.com/a/../sitemap.xml?x=1&y=%41\nSitemap: file:///etc/sitemap.xml\n",
+			baseURL:   "https://www.example.com/robots.txt",
+			want:      []string{"https://www.example.com/a/../sitemap.xml?x=1&y=%41", "file:///etc/sitemap.xml"},
+		},
+		{
+			name:      "characters not allowed in a URL",
+			robotsTxt: "Sitemap: https://www.example.com/sitemap with space.xml\n",
+			baseURL:   "https://www.example.com/robots.txt",
+			want:      []string{"https://www.example.com/sitemap%20with%20space.xml"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := ExtractSitemapURLs(test.robotsTxt, test.baseURL)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %q, got %q", test.want, got)
+			}
+
+			// Parse processes the robots.txt files the same way
+			s := New().SetFollowIndexes(false)
+			content := test.robotsTxt
+			if _, err := s.Parse("https://www.example.com/seo/robots.txt", &content); err != nil {
+				t.Fatal(err)
+			}
+			want := ExtractSitemapURLs(test.robotsTxt, "https://www.example.com/seo/robots.txt")
+			if got := s.robotsTxtSitemapURLs; len(got) != len(want) || (len(want) > 0 && !reflect.DeepEqual(got, want)) {
+				t.Errorf("expected Parse to find %q, got %q", want, got)
+			}
+		})
+	}
+}
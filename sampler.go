@@ -0,0 +1,86 @@
+package sitemap
+
+import "math/rand"
+
+type (
+	// Sampler is a structure to draw random URLs from the parsed URLs without repeats across calls, see NewSampler.
+	// The urls field is the copy of the parsed URLs it draws from, its first drawn elements are the URLs returned in the current pass.
+	// The drawn field is the number of URLs returned in the current pass.
+	// The reshuffle field reports whether a new pass is started once every URL was returned, see SetReshuffle.
+	// The rnd field is the source of randomness, seeded with the seed given to NewSampler.
+	// A Sampler is not safe for concurrent use.
+	Sampler struct {
+		urls      []URL
+		drawn     int
+		reshuffle bool
+		rnd       *rand.Rand
+	}
+)
+
+// NewSampler creates a new Sampler of the URLs parsed so far and returns a pointer to it.
+// Unlike GetRandomURLs, which samples independently on every call, the Sampler remembers the URLs it returned,
+// so successive Next calls yield fresh URLs until every URL was returned once.
+// The Sampler draws from a copy of the URLs: it does not change the parsed URLs, and it does not see the URLs of later parses.
+// The same seed over the same URLs gives the same sequence of samples.
+// If the S object is nil, the Sampler has no URLs.
+func (s *S) NewSampler(seed int64) *Sampler {
+	return &Sampler{
+		urls: s.GetURLs(),
+		rnd:  rand.New(rand.NewSource(seed)),
+	}
+}
+
+// SetReshuffle sets whether the Sampler starts a new pass over all the URLs once every URL was returned.
+// By default, it is off, and Next returns an empty slice once the Sampler is exhausted.
+// The function returns a pointer to the Sampler structure to allow method chaining.
+func (sp *Sampler) SetReshuffle(reshuffle bool) *Sampler {
+	if sp == nil {
+		return nil
+	}
+	sp.reshuffle = reshuffle
+
+	return sp
+}
+
+// Next returns up to n randomly selected URLs not returned by the previous calls of the current pass.
+// It returns fewer than n URLs when the pass has fewer URLs left, and an empty slice once every URL was returned.
+// With reshuffling on (see SetReshuffle), a call on an exhausted Sampler starts a new pass instead,
+// so the URLs of a single call are always distinct.
+// If the Sampler is nil or n is not positive, an empty slice is returned.
+func (sp *Sampler) Next(n int) []URL {
+	if sp == nil || n <= 0 {
+		return []URL{}
+	}
+	if sp.drawn == len(sp.urls) && sp.reshuffle {
+		sp.drawn = 0
+	}
+
+	n = min(n, len(sp.urls)-sp.drawn)
+	sample := make([]URL, 0, n)
+	for ; n > 0; n-- {
+		// Move a random URL of the ones not returned yet to the end of the drawn ones.
+		i := sp.drawn + sp.rnd.Intn(len(sp.urls)-sp.drawn)
+		sp.urls[sp.drawn], sp.urls[i] = sp.urls[i], sp.urls[sp.drawn]
+		sample = append(sample, sp.urls[sp.drawn])
+		sp.drawn++
+	}
+
+	return sample
+}
+
+// Remaining returns the number of URLs not returned yet in the current pass.
+// If the Sampler is nil, 0 is returned.
+func (sp *Sampler) Remaining() int {
+	if sp == nil {
+		return 0
+	}
+	return len(sp.urls) - sp.drawn
+}
+
+// Reset starts a new pass, making every URL available to Next again.
+func (sp *Sampler) Reset() {
+	if sp == nil {
+		return
+	}
+	sp.drawn = 0
+}
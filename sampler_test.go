@@ -0,0 +1,136 @@
+package sitemap
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// sampledURLs returns an S object with n parsed URLs.
+func sampledURLs(n int) *S {
+	s := New()
+	for i := 0; i < n; i++ {
+		s.urls = append(s.urls, URL{Loc: fmt.Sprintf("https://www.example.com/page-%02d", i)})
+	}
+	return s
+}
+
+func TestSampler_Next(t *testing.T) {
+	tests := []struct {
+		name      string
+		urls      int
+		reshuffle bool
+		calls     []int
+		wantLens  []int
+	}{
+		{
+			name:     "successive calls",
+			urls:     10,
+			calls:    []int{3, 3, 3, 3, 3},
+			wantLens: []int{3, 3, 3, 1, 0},
+		},
+		{
+			name:     "n greater than the URLs",
+			urls:     4,
+			calls:    []int{10, 10},
+			wantLens: []int{4, 0},
+		},
+		{
+			name:      "reshuffle",
+			urls:      5,
+			reshuffle: true,
+			calls:     []int{2, 2, 2, 2, 10},
+			wantLens:  []int{2, 2, 1, 2, 3},
+		},
+		{
+			name:     "non-positive n",
+			urls:     5,
+			calls:    []int{0, -1, 5},
+			wantLens: []int{0, 0, 5},
+		},
+		{
+			name:      "no URLs",
+			urls:      0,
+			reshuffle: true,
+			calls:     []int{1, 1},
+			wantLens:  []int{0, 0},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sp := sampledURLs(test.urls).NewSampler(1).SetReshuffle(test.reshuffle)
+			seen := map[string]bool{}
+			for i, n := range test.calls {
+				if sp.Remaining() == 0 {
+					// A new pass may return the URLs of the previous one.
+					seen = map[string]bool{}
+				}
+				sample := sp.Next(n)
+				if len(sample) != test.wantLens[i] {
+					t.Errorf("call %d: expected %d URLs, got %d", i, test.wantLens[i], len(sample))
+				}
+				for _, u := range sample {
+					if seen[u.Loc] {
+						t.Errorf("call %d: %s returned again", i, u.Loc)
+					}
+					seen[u.Loc] = true
+				}
+			}
+		})
+	}
+}
+
+func TestSampler_NoRepeats(t *testing.T) {
+	s := sampledURLs(50)
+	want := s.GetURLs()
+	sp := s.NewSampler(42)
+
+	var got []URL
+	for sp.Remaining() > 0 {
+		got = append(got, sp.Next(7)...)
+	}
+	if !reflect.DeepEqual(sortedLocs(got), sortedLocs(want)) {
+		t.Errorf("expected every URL exactly once, got %v", locsOf(got))
+	}
+	if got := s.GetURLs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the parsed URLs to be unchanged, got %v", locsOf(got))
+	}
+
+	sp.Reset()
+	if got := sp.Remaining(); got != 50 {
+		t.Errorf("expected 50 URLs after Reset, got %d", got)
+	}
+}
+
+func TestSampler_Seed(t *testing.T) {
+	s := sampledURLs(20)
+	a, b, c := s.NewSampler(7), s.NewSampler(7), s.NewSampler(8)
+
+	sampleA, sampleB, sampleC := locsOf(a.Next(10)), locsOf(b.Next(10)), locsOf(c.Next(10))
+	if !reflect.DeepEqual(sampleA, sampleB) {
+		t.Errorf("expected the same sample for the same seed, got %v and %v", sampleA, sampleB)
+	}
+	if reflect.DeepEqual(sampleA, sampleC) {
+		t.Errorf("expected different samples for different seeds, got %v", sampleA)
+	}
+}
+
+func TestSampler_NilReceiver(t *testing.T) {
+	var s *S
+	if got := s.NewSampler(1).Next(5); got == nil || len(got) != 0 {
+		t.Errorf("NewSampler: expected empty slice, got %v", got)
+	}
+
+	var sp *Sampler
+	if got := sp.SetReshuffle(true); got != nil {
+		t.Errorf("SetReshuffle: expected nil, got %v", got)
+	}
+	if got := sp.Next(5); got == nil || len(got) != 0 {
+		t.Errorf("Next: expected empty slice, got %v", got)
+	}
+	if got := sp.Remaining(); got != 0 {
+		t.Errorf("Remaining: expected 0, got %d", got)
+	}
+	sp.Reset()
+}
@@ -0,0 +1,105 @@
+package sitemap
+
+import (
+	"sort"
+	"time"
+)
+
+// ScheduledURL is a URL of a crawl schedule returned by GetCrawlSchedule.
+// The Due field is the recommended time of the next fetch of the URL, see NextCrawlTime.
+type ScheduledURL struct {
+	URL URL       `json:"url"`
+	Due time.Time `json:"due"`
+}
+
+// DefaultCrawlIntervals returns the default intervals between the fetches of a URL per <changefreq> value, see SetCrawlIntervals:
+// "always" is 0, "hourly" is 1 hour, "daily" is 24 hours, "weekly" is 7 days, "monthly" is 30 days and "yearly" is 365 days.
+// The empty key, used for a missing or invalid <changefreq> value, is 24 hours, while "never" is not mapped,
+// so such URLs are not scheduled. The returned map is a fresh copy, it may be modified and passed to SetCrawlIntervals.
+func DefaultCrawlIntervals() map[string]time.Duration {
+	return map[string]time.Duration{
+		string(changeFreqAlways):  0,
+		string(changeFreqHourly):  time.Hour,
+		string(changeFreqDaily):   24 * time.Hour,
+		string(changeFreqWeekly):  7 * 24 * time.Hour,
+		string(changeFreqMonthly): 30 * 24 * time.Hour,
+		string(changeFreqYearly):  365 * 24 * time.Hour,
+		"":                        24 * time.Hour,
+	}
+}
+
+// SetCrawlIntervals sets the intervals between the fetches of a URL per <changefreq> value used by NextCrawlTime and GetCrawlSchedule.
+// The empty key is used for the URLs with a missing or invalid <changefreq> value. The URLs whose value is not mapped are not scheduled.
+// The map replaces the defaults as a whole, start from DefaultCrawlIntervals to change some of them. A nil map restores the defaults.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetCrawlIntervals(intervals map[string]time.Duration) *S {
+	if s == nil {
+		return nil
+	}
+	if intervals == nil {
+		s.cfg.crawlIntervals = nil
+		return s
+	}
+	s.cfg.crawlIntervals = make(map[string]time.Duration, len(intervals))
+	for changeFreq, interval := range intervals {
+		s.cfg.crawlIntervals[changeFreq] = interval
+	}
+
+	return s
+}
+
+// NextCrawlTime returns the recommended time of the next fetch of the given URL: its <lastmod> plus the interval of its <changefreq>,
+// see SetCrawlIntervals. A URL without a <lastmod> value has never been seen to change, so it is due at now.
+// A returned time before now means the URL is overdue. The second return value is false if the URL is not to be fetched again,
+// i.e. its <changefreq> value (by default "never") is not mapped to an interval.
+// If the S object is nil, the default intervals are used.
+func (s *S) NextCrawlTime(u URL, now time.Time) (time.Time, bool) {
+	intervals := DefaultCrawlIntervals()
+	if s != nil && s.cfg.crawlIntervals != nil {
+		intervals = s.cfg.crawlIntervals
+	}
+	return nextCrawlTime(u, now, intervals)
+}
+
+// GetCrawlSchedule returns the parsed URLs with the recommended time of their next fetch, see NextCrawlTime, sorted by the due time.
+// URLs with the same due time keep their parse order, the URLs not to be fetched again are left out.
+// If the S object is nil or there are no URLs, an empty slice is returned.
+func (s *S) GetCrawlSchedule(now time.Time) []ScheduledURL {
+	if s == nil {
+		return []ScheduledURL{}
+	}
+	intervals := DefaultCrawlIntervals()
+	if s.cfg.crawlIntervals != nil {
+		intervals = s.cfg.crawlIntervals
+	}
+
+	s.mu.Lock()
+	schedule := make([]ScheduledURL, 0, len(s.urls))
+	for _, u := range s.urls {
+		if due, ok := nextCrawlTime(u, now, intervals); ok {
+			schedule = append(schedule, ScheduledURL{URL: u, Due: due})
+		}
+	}
+	s.mu.Unlock()
+
+	sort.SliceStable(schedule, func(i, j int) bool {
+		return schedule[i].Due.Before(schedule[j].Due)
+	})
+	return schedule
+}
+
+// nextCrawlTime returns the recommended time of the next fetch of the URL with the given intervals, see NextCrawlTime.
+func nextCrawlTime(u URL, now time.Time, intervals map[string]time.Duration) (time.Time, bool) {
+	changeFreq := ""
+	if u.ChangeFreq != nil && u.ChangeFreq.valid() {
+		changeFreq = string(*u.ChangeFreq)
+	}
+	interval, ok := intervals[changeFreq]
+	if !ok {
+		return time.Time{}, false
+	}
+	if u.LastMod == nil {
+		return now, true
+	}
+	return u.LastMod.Add(interval), true
+}
@@ -0,0 +1,129 @@
+package sitemap
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestS_SetCrawlIntervals(t *testing.T) {
+	intervals := map[string]time.Duration{"daily": 12 * time.Hour}
+
+	s := New().SetCrawlIntervals(intervals)
+	intervals["daily"] = time.Hour
+	if want := map[string]time.Duration{"daily": 12 * time.Hour}; !reflect.DeepEqual(s.cfg.crawlIntervals, want) {
+		t.Errorf("expected %v, got %v", want, s.cfg.crawlIntervals)
+	}
+
+	s.SetCrawlIntervals(nil)
+	if s.cfg.crawlIntervals != nil {
+		t.Errorf("expected nil, got %v", s.cfg.crawlIntervals)
+	}
+}
+
+func TestS_NextCrawlTime(t *testing.T) {
+	now := time.Date(2024, time.March, 10, 12, 0, 0, 0, time.UTC)
+	lastMod := pointerOfLastModTime(LastMod{time.Date(2024, time.March, 1, 8, 0, 0, 0, time.UTC)})
+
+	tests := []struct {
+		name       string
+		s          *S
+		changeFreq *urlChangeFreq
+		lastMod    *LastMod
+		want       time.Time
+		wantOK     bool
+	}{
+		{name: "always", s: New(), changeFreq: pointerOfURLChangeFreq(changeFreqAlways), lastMod: lastMod, want: lastMod.Time, wantOK: true},
+		{name: "hourly", s: New(), changeFreq: pointerOfURLChangeFreq(changeFreqHourly), lastMod: lastMod, want: lastMod.Add(time.Hour), wantOK: true},
+		{name: "daily", s: New(), changeFreq: pointerOfURLChangeFreq(changeFreqDaily), lastMod: lastMod, want: lastMod.Add(24 * time.Hour), wantOK: true},
+		{name: "weekly", s: New(), changeFreq: pointerOfURLChangeFreq(changeFreqWeekly), lastMod: lastMod, want: lastMod.Add(7 * 24 * time.Hour), wantOK: true},
+		{name: "monthly", s: New(), changeFreq: pointerOfURLChangeFreq(changeFreqMonthly), lastMod: lastMod, want: lastMod.Add(30 * 24 * time.Hour), wantOK: true},
+		{name: "yearly", s: New(), changeFreq: pointerOfURLChangeFreq(changeFreqYearly), lastMod: lastMod, want: lastMod.Add(365 * 24 * time.Hour), wantOK: true},
+		{name: "never", s: New(), changeFreq: pointerOfURLChangeFreq(changeFreqNever), lastMod: lastMod, wantOK: false},
+		{name: "missing changefreq", s: New(), lastMod: lastMod, want: lastMod.Add(24 * time.Hour), wantOK: true},
+		{name: "invalid changefreq", s: New(), changeFreq: pointerOfURLChangeFreq("fortnightly"), lastMod: lastMod, want: lastMod.Add(24 * time.Hour), wantOK: true},
+		{name: "missing lastmod", s: New(), changeFreq: pointerOfURLChangeFreq(changeFreqWeekly), want: now, wantOK: true},
+		{name: "missing both", s: New(), want: now, wantOK: true},
+		{name: "never without lastmod", s: New(), changeFreq: pointerOfURLChangeFreq(changeFreqNever), wantOK: false},
+		{
+			name:       "custom interval",
+			s:          New().SetCrawlIntervals(map[string]time.Duration{"daily": 6 * time.Hour}),
+			changeFreq: pointerOfURLChangeFreq(changeFreqDaily),
+			lastMod:    lastMod,
+			want:       lastMod.Add(6 * time.Hour),
+			wantOK:     true,
+		},
+		{
+			name:       "not mapped",
+			s:          New().SetCrawlIntervals(map[string]time.Duration{"daily": 6 * time.Hour}),
+			changeFreq: pointerOfURLChangeFreq(changeFreqWeekly),
+			lastMod:    lastMod,
+			wantOK:     false,
+		},
+		{
+			name:    "missing changefreq not mapped",
+			s:       New().SetCrawlIntervals(map[string]time.Duration{"daily": 6 * time.Hour}),
+			lastMod: lastMod,
+			wantOK:  false,
+		},
+		{
+			name:       "never mapped",
+			s:          New().SetCrawlIntervals(map[string]time.Duration{"never": 10 * 365 * 24 * time.Hour}),
+			changeFreq: pointerOfURLChangeFreq(changeFreqNever),
+			lastMod:    lastMod,
+			want:       lastMod.Add(10 * 365 * 24 * time.Hour),
+			wantOK:     true,
+		},
+		{name: "nil receiver", s: nil, changeFreq: pointerOfURLChangeFreq(changeFreqHourly), lastMod: lastMod, want: lastMod.Add(time.Hour), wantOK: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := test.s.NextCrawlTime(URL{Loc: "https://www.example.com/", ChangeFreq: test.changeFreq, LastMod: test.lastMod}, now)
+			if ok != test.wantOK {
+				t.Fatalf("expected %v, got %v", test.wantOK, ok)
+			}
+			if !got.Equal(test.want) {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestS_GetCrawlSchedule(t *testing.T) {
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+    <url><loc>https://www.example.com/yearly</loc><lastmod>2024-01-01</lastmod><changefreq>yearly</changefreq></url>
+    <url><loc>https://www.example.com/never</loc><lastmod>2024-01-01</lastmod><changefreq>never</changefreq></url>
+    <url><loc>https://www.example.com/hourly</loc><lastmod>2024-03-10</lastmod><changefreq>hourly</changefreq></url>
+    <url><loc>https://www.example.com/new</loc><changefreq>weekly</changefreq></url>
+    <url><loc>https://www.example.com/daily</loc><lastmod>2024-03-09</lastmod><changefreq>daily</changefreq></url>
+    <url><loc>https://www.example.com/unknown</loc></url>
+</urlset>`
+	now := time.Date(2024, time.March, 10, 0, 30, 0, 0, time.UTC)
+
+	s, err := New().Parse("https://www.example.com/sitemap.xml", &content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type entry struct {
+		loc string
+		due time.Time
+	}
+	want := []entry{
+		{loc: "https://www.example.com/daily", due: time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC)},
+		{loc: "https://www.example.com/new", due: now},
+		{loc: "https://www.example.com/unknown", due: now},
+		{loc: "https://www.example.com/hourly", due: time.Date(2024, time.March, 10, 1, 0, 0, 0, time.UTC)},
+		{loc: "https://www.example.com/yearly", due: time.Date(2024, time.December, 31, 0, 0, 0, 0, time.UTC)},
+	}
+
+	got := []entry{}
+	for _, scheduled := range s.GetCrawlSchedule(now) {
+		got = append(got, entry{loc: scheduled.URL.Loc, due: scheduled.Due.UTC()})
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
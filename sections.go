@@ -0,0 +1,71 @@
+package sitemap
+
+import (
+	"net/url"
+	"strings"
+)
+
+const (
+	// InvalidPathKey is the key under which URLs with an unparseable location are grouped by GroupURLsByPathPrefix.
+	InvalidPathKey = "invalid"
+)
+
+// GroupURLsByPathPrefix returns the number of parsed URLs per path prefix, made of the first depth segments of their Loc path.
+// For example, with depth 2, "https://www.example.com/blog/2024/post" is counted under "/blog/2024".
+// URLs with fewer segments are counted under their whole path, the root path and a depth of 0 or less under "/".
+// Empty segments are ignored, so "/blog/" and "/blog" share the "/blog" prefix.
+// URLs with an unparseable Loc are counted under the InvalidPathKey key. The locations stored in loc-only mode are counted too, see SetLocOnly.
+// If the S object is nil, an empty map is returned.
+func (s *S) GroupURLsByPathPrefix(depth int) map[string]int64 {
+	counts := map[string]int64{}
+	if s == nil {
+		return counts
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, loc := range s.locs {
+		counts[pathPrefixKey(loc, depth)]++
+	}
+	for _, u := range s.urls {
+		counts[pathPrefixKey(u.Loc, depth)]++
+	}
+
+	return counts
+}
+
+// pathPrefixKey returns the path prefix of the given location as returned by pathPrefixOf, or InvalidPathKey if it can not be parsed.
+func pathPrefixKey(loc string, depth int) string {
+	prefix, ok := pathPrefixOf(loc, depth)
+	if !ok {
+		return InvalidPathKey
+	}
+	return prefix
+}
+
+// pathPrefixOf returns the first depth segments of the path of the given location, joined with and prefixed by "/".
+// The second return value is false if the location can not be parsed.
+func pathPrefixOf(loc string, depth int) (string, bool) {
+	parsedURL, err := url.Parse(loc)
+	if err != nil {
+		return "", false
+	}
+
+	var prefix strings.Builder
+	segments := 0
+	for _, segment := range strings.Split(parsedURL.EscapedPath(), "/") {
+		if segments >= depth {
+			break
+		}
+		if segment == "" {
+			continue
+		}
+		prefix.WriteString("/")
+		prefix.WriteString(segment)
+		segments++
+	}
+	if prefix.Len() == 0 {
+		return "/", true
+	}
+	return prefix.String(), true
+}
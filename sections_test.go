@@ -0,0 +1,84 @@
+package sitemap
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestS_GroupURLsByPathPrefix(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	s, err := New().Parse(fmt.Sprintf("%s/sitemap-sections.xml", server.URL), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		s     *S
+		depth int
+		want  map[string]int64
+	}{
+		{
+			name:  "nil receiver",
+			s:     nil,
+			depth: 1,
+			want:  map[string]int64{},
+		},
+		{
+			name:  "no URLs",
+			s:     &S{},
+			depth: 1,
+			want:  map[string]int64{},
+		},
+		{
+			name:  "loc-only mode",
+			s:     &S{locs: []string{"https://www.example.com/blog/2024/post", "https://www.example.com/blog", "https://www.example.com/%zz"}},
+			depth: 1,
+			want:  map[string]int64{"/blog": 2, InvalidPathKey: 1},
+		},
+		{
+			name:  "depth 0",
+			s:     s,
+			depth: 0,
+			want:  map[string]int64{"/": 8, InvalidPathKey: 1},
+		},
+		{
+			name:  "depth 1",
+			s:     s,
+			depth: 1,
+			want: map[string]int64{
+				"/":            1,
+				"/products":    3,
+				"/blog":        3,
+				"/docs":        1,
+				InvalidPathKey: 1,
+			},
+		},
+		{
+			name:  "depth 2",
+			s:     s,
+			depth: 2,
+			want: map[string]int64{
+				"/":               1,
+				"/products/shoes": 2,
+				"/products/hats":  1,
+				"/blog/2024":      1,
+				"/blog/2023":      1,
+				"/blog":           1,
+				"/docs":           1,
+				InvalidPathKey:    1,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.s.GroupURLsByPathPrefix(test.depth); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
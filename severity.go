@@ -0,0 +1,60 @@
+package sitemap
+
+import "errors"
+
+// Severity represents how serious a problem recorded by a parse is, see ErrorSeverity.
+type Severity string
+
+const (
+	// SeverityError is the severity of the failures, recorded as errors: a sitemap or a part of it could not be processed.
+	SeverityError Severity = "error"
+
+	// SeverityWarning is the severity of the problems the parse recovered from, recorded as warnings:
+	// the content was processed, but something about it should be known.
+	SeverityWarning Severity = "warning"
+)
+
+// ErrorSeverity returns the severity of the given error, as reported by the Severity method of the first error in its chain
+// that has one, e.g. *LocTooLongError, or SeverityError if none has. The errors returned by GetErrors are all of SeverityError,
+// the ones returned by GetWarnings of SeverityWarning, so it classifies the problems of both lists when they are handled together.
+// If the error is nil, an empty Severity is returned.
+func ErrorSeverity(err error) Severity {
+	if err == nil {
+		return ""
+	}
+	var severe interface{ Severity() Severity }
+	if errors.As(err, &severe) {
+		return severe.Severity()
+	}
+	return SeverityError
+}
+
+// SetCountWarnings sets whether the warnings count toward the maximum number of errors of a parse, see SetMaxErrors,
+// for the callers treating a flood of repaired or tolerated content like a flood of failures.
+// The warnings are still recorded as warnings, see GetWarnings. The failure rate (see SetMaxFailureRate) is not affected,
+// as a sitemap with warnings has not failed.
+// By default, it is off, and only the errors are counted.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetCountWarnings(countWarnings bool) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.countWarnings = countWarnings
+
+	return s
+}
+
+// appendWarning appends the warning to the warnings field. If the warnings count toward the maximum number of errors
+// (see SetCountWarnings), the parse is aborted once it is reached, and the warning is dropped if it has been already.
+// It must be called with s.mu held.
+func (s *S) appendWarning(err error) {
+	if !s.cfg.countWarnings {
+		s.warnings = append(s.warnings, err)
+		return
+	}
+	if s.errorLimitErr != nil {
+		return
+	}
+	s.warnings = append(s.warnings, err)
+	s.checkErrorLimit()
+}
@@ -0,0 +1,167 @@
+package sitemap
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestErrorSeverity(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Severity
+	}{
+		{
+			name: "nil",
+			err:  nil,
+			want: "",
+		},
+		{
+			name: "plain error",
+			err:  errors.New("received HTTP status 404"),
+			want: SeverityError,
+		},
+		{
+			name: "fetch error",
+			err:  &FetchError{URL: "https://www.example.com/sitemap.xml", StatusCode: 500, Err: errors.New("received HTTP status 500")},
+			want: SeverityError,
+		},
+		{
+			name: "wrapped loc too long",
+			err:  locationError("https://www.example.com/sitemap.xml", &LocTooLongError{Length: 3000}),
+			want: SeverityWarning,
+		},
+		{
+			name: "loc encoded",
+			err:  &LocEncodedError{Loc: "https://www.example.com/a b", Encoded: "https://www.example.com/a%20b"},
+			want: SeverityWarning,
+		},
+		{
+			name: "unexpected element",
+			err:  &UnexpectedElementError{Parent: "url"},
+			want: SeverityWarning,
+		},
+		{
+			name: "truncated content parsed",
+			err:  &DecompressionError{Truncated: true, Err: io.ErrUnexpectedEOF},
+			want: SeverityWarning,
+		},
+		{
+			name: "truncated content discarded",
+			err:  &DecompressionError{Truncated: true, Discarded: true, Err: io.ErrUnexpectedEOF},
+			want: SeverityError,
+		},
+		{
+			name: "corrupted content",
+			err:  &DecompressionError{Err: errors.New("gzip: invalid checksum")},
+			want: SeverityError,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ErrorSeverity(test.err); got != test.want {
+				t.Errorf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestS_SetCountWarnings(t *testing.T) {
+	s := New().SetCountWarnings(true)
+	if !s.cfg.countWarnings {
+		t.Errorf("expected true, got false")
+	}
+}
+
+func TestS_Parse_Severity(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+	truncated, err := os.ReadFile("./test/sitemap-truncated.xml.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// rawServer serves the truncated content as it is, the test server would recompress it
+	rawServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(truncated)
+	}))
+	defer rawServer.Close()
+
+	tests := []struct {
+		name         string
+		s            *S
+		raw          bool
+		path         string
+		wantErr      error
+		wantErrs     []Severity
+		wantWarnings []Severity
+	}{
+		{
+			name:         "truncated content",
+			s:            New(),
+			raw:          true,
+			path:         "sitemap-truncated.xml.gz",
+			wantErrs:     []Severity{SeverityError}, // the partial content is not a valid document
+			wantWarnings: []Severity{SeverityWarning},
+		},
+		{
+			name:     "truncated content, strict decompression",
+			s:        New().SetStrictDecompression(true),
+			raw:      true,
+			path:     "sitemap-truncated.xml.gz",
+			wantErr:  io.ErrUnexpectedEOF,
+			wantErrs: []Severity{SeverityError},
+		},
+		{
+			name:         "unexpected elements, warnings not counted",
+			s:            New().SetStrictElements(true).SetMaxErrors(2),
+			path:         "sitemap-unexpected-elements.xml",
+			wantWarnings: []Severity{SeverityWarning, SeverityWarning, SeverityWarning},
+		},
+		{
+			name:         "unexpected elements, warnings counted",
+			s:            New().SetStrictElements(true).SetMaxErrors(2).SetCountWarnings(true),
+			path:         "sitemap-unexpected-elements.xml",
+			wantErr:      ErrErrorLimitReached,
+			wantErrs:     []Severity{SeverityError},
+			wantWarnings: []Severity{SeverityWarning, SeverityWarning},
+		},
+		{
+			name:     "missing sitemaps",
+			s:        New().SetMultiThread(false),
+			path:     "sitemapindex-404.xml",
+			wantErrs: []Severity{SeverityError, SeverityError, SeverityError, SeverityError, SeverityError, SeverityError, SeverityError, SeverityError, SeverityError, SeverityError},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			url := server.URL
+			if test.raw {
+				url = rawServer.URL
+			}
+			s, err := test.s.Parse(fmt.Sprintf("%s/%s", url, test.path), nil)
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("expected %v, got %v", test.wantErr, err)
+			}
+			if got := severities(s.GetErrors()); fmt.Sprint(got) != fmt.Sprint(test.wantErrs) {
+				t.Errorf("errors: expected %v, got %v: %v", test.wantErrs, got, s.GetErrors())
+			}
+			if got := severities(s.GetWarnings()); fmt.Sprint(got) != fmt.Sprint(test.wantWarnings) {
+				t.Errorf("warnings: expected %v, got %v: %v", test.wantWarnings, got, s.GetWarnings())
+			}
+		})
+	}
+}
+
+// severities returns the severities of the given errors.
+func severities(errs []error) []Severity {
+	var got []Severity
+	for _, err := range errs {
+		got = append(got, ErrorSeverity(err))
+	}
+	return got
+}
@@ -2,14 +2,23 @@ package sitemap
 
 import (
 	"bytes"
+	"compress/flate"
 	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"crypto/tls"
 	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"math/rand"
+	"net"
 	"net/http"
+	neturl "net/url"
 	"regexp"
+	"runtime/debug"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -24,7 +33,39 @@ type (
 	// The robotsTxtSitemapURLs field is a slice of strings that contains the URLs present in the robots.txt file's sitemap directive.
 	// The sitemapLocations field is a slice of strings that represents the locations of the sitemap files.
 	// The urls field is a slice of URL structs that stores the URLs to be processed.
+	// The locs field stores the locations of the URLs instead of the urls field in loc-only mode, see SetLocOnly.
 	// The errs field is a slice of errors that holds any encountered errors during processing.
+	// The warnings field holds the problems the processing recovered from.
+	// The report field caches the summary statistics of the urls field, it is reset when the urls field changes.
+	// The uniqueURLCount field caches the number of distinct locations of the urls field, it is reset when the urls field changes.
+	// The tree field is the root node of the sitemap tree, the nodes field maps the locations to their nodes.
+	// The fetchMeta field holds the metadata of the fetched locations, keyed by location.
+	// The parsedAt field is the time the current parse started, the timing field is the timing of the current or last parse, see GetTiming.
+	// The ctx field is the context of the current parse, the concurrency, rateLimiter, hostRateLimiters and hostDelays fields throttle its fetches.
+	// The followMatcher and rulesMatcher fields match the locations against the follow and rules patterns during the current parse.
+	// The circuits field tracks the consecutive fetch failures per host for the circuit breaker,
+	// the circuitErrs field holds the errors of the circuits opened during the current parse until they are recorded, see publishCircuitErrors.
+	// The transport field is the HTTP transport built for the connection settings, nil means http.DefaultTransport;
+	// it is reused by the parses until the settings change, so that the keep-alive connections are not leaked, see initTransport.
+	// The fsys field is the file system the fs: locations of the current parse are read from, nil unless parsing with ParseFS.
+	// The truncatedBy field is the cause the parse was cut short by, empty if it was not.
+	// The sitemapsStarted, failedSitemaps and skippedSitemaps fields count the sitemaps fetched, failed and skipped.
+	// The outcomesCompleted and outcomesFailed fields count the sitemaps processed and failed during the current parse, see SetMaxFailureRate.
+	// The failureRateErr field is the error the current parse was aborted with because of the failure rate, nil if it was not.
+	// The errorLimitErr field is the error the current parse was aborted with because of the maximum number of errors, nil if it was not.
+	// The cancelParse field cancels the context of the current parse, nil unless the maximum failure rate or the maximum number of errors is set.
+	// The filteredURLs field counts the URLs rejected per filter, nil until a URL is rejected.
+	// The trace field is the decision log recorded when the debug trace is turned on, see SetDebugTrace.
+	// The filteredSitemaps field lists the sitemap locations of the indexes rejected by a filter, in the order they were rejected.
+	// The memoryUsed field is the estimated memory retained by the results, see memoryBudget.
+	// The robotsGroups field holds the groups of the robots.txt file the parse started from, see GetDisallowedURLs.
+	// The rawContent field holds the decompressed content of the documents keyed by location, nil unless it is kept, see SetKeepRawContent;
+	// the rawContentBytes field is its total size.
+	// The mu field guards the fields above that are updated concurrently during parsing.
+	// The rndMu field guards the source of randomness set with SetRandomSource, which is used by the fetches and the getters alike.
+	//
+	// All exported methods are safe to call on a nil *S: the setters return nil, the getters return zero values
+	// (empty slices and maps, where a slice or map is returned), and the Parse methods return ErrNilReceiver.
 	S struct {
 		cfg                  config
 		mainURL              string
@@ -32,7 +73,45 @@ type (
 		robotsTxtSitemapURLs []string
 		sitemapLocations     []string
 		urls                 []URL
+		locs                 []string
 		errs                 []error
+		warnings             []error
+		report               *Report
+		uniqueURLCount       *int64
+		tree                 *SitemapNode
+		nodes                map[string]*SitemapNode
+		fetchMeta            map[string]FetchMeta
+		parsedAt             time.Time
+		timing               Timing
+		ctx                  context.Context
+		concurrency          *concurrencyLimiter
+		rateLimiter          *rateLimiter
+		hostRateLimiters     *hostRateLimiters
+		hostDelays           *hostDelays
+		followMatcher        *matcher
+		rulesMatcher         *matcher
+		circuits             *hostCircuits
+		circuitErrs          []error
+		transport            http.RoundTripper
+		fsys                 fs.FS
+		truncatedBy          TruncationCause
+		sitemapsStarted      int
+		failedSitemaps       int
+		skippedSitemaps      int
+		outcomesCompleted    int
+		outcomesFailed       int
+		failureRateErr       *FailureRateError
+		errorLimitErr        *ErrorLimitError
+		cancelParse          context.CancelFunc
+		filteredURLs         map[Filter]int64
+		filteredSitemaps     []filteredSitemap
+		trace                []TraceEntry
+		memoryUsed           int64
+		robotsGroups         []robotsGroup
+		rawContent           map[string][]byte
+		rawContentBytes      int64
+		mu                   sync.Mutex
+		rndMu                sync.Mutex
 	}
 
 	// config is a structure that holds configuration settings.
@@ -43,43 +122,232 @@ type (
 	// The followRegexes field is a slice of *regexp.Regexp that stores the compiled regular expressions for the follow field.
 	// The rules field is a slice of strings that contains regular expressions to match URLs to include.
 	// The rulesRegexes field is a slice of *regexp.Regexp that stores the compiled regular expressions for the rules field.
+	// The rateLimit field is the maximum number of requests per second overall, 0 means no limit.
+	// The perHostRateLimit field is the maximum number of requests per second per host, 0 means no limit.
+	// The maxConcurrency field is the maximum number of requests in progress overall, 0 means no limit.
+	// The maxConcurrencyPerHost field is the maximum number of requests in progress per host, 0 means no limit.
+	// The requestDelay field is the delay between two requests to the same host, 0 means no delay.
+	// The requestDelayJitter field is the maximum random deviation of requestDelay.
+	// The respectCrawlDelay field determines whether the Crawl-delay of the robots.txt rules is respected, see SetRespectCrawlDelay.
+	// The maxFetchSizeHint field is the maximum size of a document advertised by its response, 0 means no limit, see SetMaxFetchSizeHint.
+	// The circuitBreakerThreshold field is the number of consecutive failures after which a host is skipped, 0 means disabled.
+	// The followIndexes field determines whether the sitemaps referenced by the main URL are fetched.
+	// The skipURLs field determines whether the URLs of the sitemaps are only counted instead of being stored.
+	// The locOnly field determines whether only the locations of the URLs are stored, in the locs field.
+	// The maxURLs field is the maximum number of URLs stored, 0 means no limit.
+	// The maxURLsPerSitemap field is the maximum number of URLs collected from each sitemap, 0 means no limit.
+	// The maxSitemaps field is the maximum number of sitemaps fetched besides the main URL, 0 means no limit.
+	// The memoryBudget field is the maximum estimated memory retained by the results in bytes, 0 means no limit.
+	// The keepRawContent field determines whether the decompressed content of the documents is kept, see SetKeepRawContent.
+	// The maxRawContentBytes field is the maximum total size of the content kept, 0 means no limit, see SetMaxRawContentBytes.
+	// The minSitemapLastMod field is the earliest lastmod of the sitemaps fetched from a sitemap index, the zero time means no limit.
+	// The maxSitemapsByLastMod field is the maximum number of the most recently modified sitemaps fetched per sitemap index, 0 means no limit.
+	// The skipSitemapsWithoutLastMod field determines whether the sitemaps without a valid lastmod are skipped when minSitemapLastMod is set.
+	// The allowedSchemes field is the list of URL schemes of the locations fetched, empty means http and https.
+	// The retries field is the number of times a location is re-fetched when its compressed content arrives corrupted.
+	// The strictDecompression field determines whether truncated or corrupted compressed content is discarded.
+	// The maxDecompressedSize field is the maximum size of a decompressed document, 0 means no limit, see SetMaxDecompressedSize.
+	// The decompressors field is the list of codecs registered besides the built-in gzip, zlib and deflate support.
+	// The urlMatcher and sitemapMatcher fields are the functions deciding whether a URL is kept and a sitemap is followed, nil means all of them.
+	// The urlRewriter and sitemapURLRewriter fields are the functions rewriting the locations of the URLs and of the sitemaps, nil means no rewriting.
+	// The hostRewrite and fetchHostRewrite fields map the hosts of the stored URLs and of the locations fetched, with lowercase ASCII keys.
+	// The pingEndpoints field is the list of endpoints notified by Ping, nil means DefaultPingEndpoints.
+	// The indexNowEndpoint field is the endpoint of the IndexNow submissions, empty means DefaultIndexNowEndpoint.
+	// The retryBackoff field is the delay before the first retry of a request of Ping and IndexNow, 0 means 1 second, see SetRetryBackoff.
+	// The maxFailureRate field is the maximum rate of the sitemaps failed, 0 means no limit, see SetMaxFailureRate.
+	// The failureRateMinSamples field is the number of sitemaps processed before the failure rate is checked.
+	// The maxErrors field is the maximum number of errors recorded by a parse before it is aborted, 0 means no limit, see SetMaxErrors.
+	// The countWarnings field determines whether the warnings count toward the maximum number of errors, see SetCountWarnings.
+	// The rnd field is the source of randomness of the random selections, nil means the shared source of math/rand, see SetRandomSource.
+	// The robotsGroups field holds the groups of the robots.txt content set with SetRobotsTxt, nil means the robots.txt file the parse started from.
+	// The fetcher field retrieves the content of the locations instead of the HTTP client, nil means the HTTP client, see SetFetcher.
+	// The metrics field is the collector of the metrics of the parses, nil means none, see SetMetricsCollector.
+	// The crawlIntervals field maps the <changefreq> values to the intervals between the fetches of a URL, nil means DefaultCrawlIntervals.
+	// The dropLongLocs field determines whether the URLs with a location over 2048 characters are dropped instead of only being warned about.
+	// The strictElements field determines whether the unexpected elements of a <urlset> are reported, see SetStrictElements.
+	// The discoverFromHTML field determines whether the sitemaps linked from a main URL returning an HTML page are parsed, see SetDiscoverFromHTML.
+	// The trackPositions field determines whether the position of each <url> element is recorded, see SetTrackPositions.
+	// The upgradeToHTTPS field determines whether the http locations are fetched over https first, see SetUpgradeToHTTPS.
+	// The upgradeLocsToHTTPS field determines whether the http locations of the stored URLs are upgraded to https, see SetUpgradeLocsToHTTPS.
+	// The encodeLocs field determines whether the locations of the stored URLs are repaired by percent-encoding, see SetEncodeLocs.
+	// The punycodeHosts field determines whether the hosts of the stored URLs are normalized to their ASCII (punycode) form.
+	// The stripQueryParams field is the list of the patterns of the query parameters removed from the stored URLs, nil means none, see SetStripQueryParams.
+	// The lastModFormats field is the list of layouts of the <lastmod> values, nil means the defaults.
+	// The lastModLocation field is the location of the <lastmod> values without zone information, nil means UTC, see SetDefaultLastModLocation.
+	// The lastModFallback field determines whether the URLs without a <lastmod> value inherit the Last-Modified header of their sitemap, see SetLastModFallback.
+	// The fields field is the list of the child elements of <url> decoded, empty means all of them.
+	// The debugTrace field determines whether a decision log entry is recorded per location, see SetDebugTrace.
+	// The decodeLimits field holds the limits of the decoding of the documents, the zero fields mean the defaults.
+	// The bodyCache field is the cache of the fetched bodies, nil means no caching.
+	// The connectTimeout field is the timeout of establishing a connection, including the TLS handshake, 0 means the defaults of http.DefaultTransport.
+	// The tlsConfig field is the TLS configuration of the connections, nil means the defaults of http.DefaultTransport.
+	// The clientCertificate field is the certificate presented to the servers requesting one, nil means none.
 	config struct {
-		userAgent     string
-		fetchTimeout  uint8
-		multiThread   bool
-		follow        []string
-		followRegexes []*regexp.Regexp
-		rules         []string
-		rulesRegexes  []*regexp.Regexp
+		userAgent                  string
+		fetchTimeout               uint8
+		multiThread                bool
+		follow                     []string
+		followRegexes              []*regexp.Regexp
+		rules                      []string
+		rulesRegexes               []*regexp.Regexp
+		rateLimit                  float64
+		perHostRateLimit           float64
+		maxConcurrency             int
+		maxConcurrencyPerHost      int
+		requestDelay               time.Duration
+		requestDelayJitter         time.Duration
+		respectCrawlDelay          bool
+		maxFetchSizeHint           int64
+		circuitBreakerThreshold    int
+		connectTimeout             time.Duration
+		tlsConfig                  *tls.Config
+		clientCertificate          *tls.Certificate
+		followIndexes              bool
+		skipURLs                   bool
+		locOnly                    bool
+		maxURLs                    int
+		maxURLsPerSitemap          int
+		maxSitemaps                int
+		allowedSchemes             []string
+		memoryBudget               int64
+		keepRawContent             bool
+		maxRawContentBytes         int64
+		minSitemapLastMod          time.Time
+		skipSitemapsWithoutLastMod bool
+		maxSitemapsByLastMod       int
+		decompressors              []Decompressor
+		strictDecompression        bool
+		maxDecompressedSize        int64
+		retries                    int
+		bodyCache                  Cache
+		lastModFormats             []string
+		lastModLocation            *time.Location
+		lastModFallback            bool
+		decodeLimits               DecodeLimits
+		debugTrace                 bool
+		fields                     []Field
+		urlMatcher                 func(loc string) bool
+		sitemapMatcher             func(loc string) bool
+		urlRewriter                func(loc string) string
+		sitemapURLRewriter         func(loc string) string
+		hostRewrite                map[string]string
+		fetchHostRewrite           map[string]string
+		punycodeHosts              bool
+		stripQueryParams           []string
+		encodeLocs                 bool
+		upgradeToHTTPS             bool
+		trackPositions             bool
+		strictElements             bool
+		discoverFromHTML           bool
+		upgradeLocsToHTTPS         bool
+		dropLongLocs               bool
+		pingEndpoints              []string
+		indexNowEndpoint           string
+		retryBackoff               time.Duration
+		crawlIntervals             map[string]time.Duration
+		maxFailureRate             float64
+		failureRateMinSamples      int
+		maxErrors                  int
+		countWarnings              bool
+		rnd                        *rand.Rand
+		metrics                    MetricsCollector
+		fetcher                    Fetcher
+		robotsGroups               []robotsGroup
 	}
 
-	// sitemapIndex is a structure of <sitemapindex>
-	sitemapIndex struct {
-		XMLName xml.Name `xml:"sitemapindex"`
-		Sitemap []struct {
-			Loc     string  `xml:"loc"`
-			LastMod *string `xml:"lastmod"`
-		} `xml:"sitemap"`
+	// SitemapIndex is a structure of <sitemapindex>
+	SitemapIndex struct {
+		XMLName xml.Name       `xml:"sitemapindex"`
+		Sitemap []IndexSitemap `xml:"sitemap"`
+	}
+
+	// IndexSitemap is a structure of <sitemap> in <sitemapindex>
+	// The LastMod field is the parsed <lastmod> value, nil if it is missing or invalid.
+	IndexSitemap struct {
+		Loc     string     `xml:"loc"`
+		LastMod *time.Time `xml:"lastmod"`
 	}
 
 	// URLSet is a structure of <urlset>
 	URLSet struct {
 		XMLName xml.Name `xml:"urlset"`
 		URL     []URL    `xml:"url"`
+
+		// unexpected holds the unexpected elements of the document, see SetStrictElements.
+		unexpected []*UnexpectedElementError
 	}
 
 	// URL is a structure of <url> in <urlset>
+	// The Position field is the position of the <url> element in its sitemap, nil unless the positions are tracked, see SetTrackPositions.
+	// The LastModInferred field reports whether the LastMod field is the Last-Modified header of the sitemap instead of a <lastmod> value, see SetLastModFallback.
 	URL struct {
-		Loc        string         `xml:"loc"`
-		LastMod    *lastModTime   `xml:"lastmod"`
-		ChangeFreq *urlChangeFreq `xml:"changefreq"`
-		Priority   *float32       `xml:"priority"`
+		Loc             string         `xml:"loc"`
+		LastMod         *LastMod       `xml:"lastmod"`
+		ChangeFreq      *urlChangeFreq `xml:"changefreq"`
+		Priority        *float32       `xml:"priority"`
+		Images          []Image        `xml:"http://www.google.com/schemas/sitemap-image/1.1 image"`
+		Videos          []Video        `xml:"http://www.google.com/schemas/sitemap-video/1.1 video"`
+		News            *News          `xml:"http://www.google.com/schemas/sitemap-news/0.9 news"`
+		Alternates      []Alternate    `xml:"http://www.w3.org/1999/xhtml link"`
+		Mobile          *Mobile        `xml:"http://www.google.com/schemas/sitemap-mobile/1.0 mobile"`
+		Position        *Position      `xml:"-" json:"position,omitempty"`
+		LastModInferred bool           `xml:"-" json:"lastmod_inferred,omitempty"`
+
+		// source is the location of the sitemap the URL was found in.
+		source string
+	}
+
+	// Image is a structure of <image:image> in <url>
+	Image struct {
+		Loc         string `xml:"loc"`
+		Caption     string `xml:"caption"`
+		GeoLocation string `xml:"geo_location"`
+		Title       string `xml:"title"`
+		License     string `xml:"license"`
+	}
+
+	// Video is a structure of <video:video> in <url>
+	Video struct {
+		ThumbnailLoc         string   `xml:"thumbnail_loc"`
+		Title                string   `xml:"title"`
+		Description          string   `xml:"description"`
+		ContentLoc           string   `xml:"content_loc"`
+		PlayerLoc            string   `xml:"player_loc"`
+		Duration             string   `xml:"duration"`
+		ExpirationDate       string   `xml:"expiration_date"`
+		Rating               string   `xml:"rating"`
+		ViewCount            string   `xml:"view_count"`
+		PublicationDate      string   `xml:"publication_date"`
+		FamilyFriendly       string   `xml:"family_friendly"`
+		RequiresSubscription string   `xml:"requires_subscription"`
+		Live                 string   `xml:"live"`
+		Tags                 []string `xml:"tag"`
+	}
+
+	// News is a structure of <news:news> in <url>
+	News struct {
+		Publication     NewsPublication `xml:"publication"`
+		PublicationDate string          `xml:"publication_date"`
+		Title           string          `xml:"title"`
+		Keywords        string          `xml:"keywords"`
 	}
 
-	lastModTime struct {
-		time.Time
+	// NewsPublication is a structure of <news:publication> in <news:news>
+	NewsPublication struct {
+		Name     string `xml:"name"`
+		Language string `xml:"language"`
 	}
 
+	// Alternate is a structure of <xhtml:link> in <url>, used to declare the language alternates of the URL.
+	Alternate struct {
+		Rel      string `xml:"rel,attr"`
+		Hreflang string `xml:"hreflang,attr"`
+		Href     string `xml:"href,attr"`
+	}
+
+	// Mobile is a structure of <mobile:mobile> in <url>, its presence marks the URL as a page for feature phones
+	Mobile struct{}
+
 	// URLChangeFreq represents the frequency at which a URL should be crawled and indexed.
 	// Possible values are: "always", "hourly", "daily", "weekly", "monthly", "yearly", and "never".
 	urlChangeFreq string
@@ -108,13 +376,25 @@ const (
 	changeFreqNever urlChangeFreq = "never"
 )
 
+// valid reports whether the change frequency is one of the values defined by the sitemaps.org protocol.
+func (c urlChangeFreq) valid() bool {
+	switch c {
+	case changeFreqAlways, changeFreqHourly, changeFreqDaily, changeFreqWeekly, changeFreqMonthly, changeFreqYearly, changeFreqNever:
+		return true
+	}
+	return false
+}
+
 // New creates a new instance of the S structure.
-// It initializes the structure with default configuration values
+// It initializes the structure with default configuration values, applies the given options in order
 // and returns a pointer to the created instance.
-func New() *S {
+func New(opts ...Option) *S {
 	s := &S{}
 
 	s.setConfigDefaults()
+	for _, opt := range opts {
+		opt(s)
+	}
 
 	return s
 }
@@ -122,16 +402,17 @@ func New() *S {
 // setConfigDefaults sets the default configuration values for the S structure.
 // It initializes the cfg field with the default values for userAgent and fetchTimeout.
 // The default userAgent is "go-sitemap-parser (+https://github.com/aafeher/go-sitemap-parser/blob/main/README.md)",
-// the default fetchTimeout is 3 seconds and multi-thread flag is true.
+// the default fetchTimeout is 3 seconds, multi-thread flag is true and follow indexes flag is true.
 // The follow and rules fields are empty slices.
 // This method does not return any value.
 func (s *S) setConfigDefaults() {
 	s.cfg = config{
-		userAgent:    "go-sitemap-parser (+https://github.com/aafeher/go-sitemap-parser/blob/main/README.md)",
-		fetchTimeout: 3,
-		multiThread:  true,
-		follow:       []string{},
-		rules:        []string{},
+		userAgent:     "go-sitemap-parser (+https://github.com/aafeher/go-sitemap-parser/blob/main/README.md)",
+		fetchTimeout:  3,
+		multiThread:   true,
+		follow:        []string{},
+		rules:         []string{},
+		followIndexes: true,
 	}
 }
 
@@ -140,6 +421,9 @@ func (s *S) setConfigDefaults() {
 // It should be a string representing the user agent header value.
 // The function returns a pointer to the S structure to allow method chaining.
 func (s *S) SetUserAgent(userAgent string) *S {
+	if s == nil {
+		return nil
+	}
 	s.cfg.userAgent = userAgent
 
 	return s
@@ -150,24 +434,282 @@ func (s *S) SetUserAgent(userAgent string) *S {
 // It should be specified in seconds as an uint8 value.
 // The function returns a pointer to the S structure to allow method chaining.
 func (s *S) SetFetchTimeout(fetchTimeout uint8) *S {
+	if s == nil {
+		return nil
+	}
 	s.cfg.fetchTimeout = fetchTimeout
 
 	return s
 }
 
+// SetConnectTimeout sets the connect timeout for the Sitemap Parser.
+// The connect timeout limits dialing the host and the TLS handshake, independently of the fetch timeout,
+// which covers the whole request including reading the body.
+// A value of 0 (the default) keeps the dial and TLS handshake timeouts of http.DefaultTransport.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetConnectTimeout(connectTimeout time.Duration) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.connectTimeout = connectTimeout
+	s.resetTransport()
+
+	return s
+}
+
+// SetTLSConfig sets the TLS configuration of the connections of the Sitemap Parser, e.g. to trust custom root CAs with RootCAs.
+// The configuration is cloned, later changes to it have no effect. The certificate set by SetClientCertificate is added to it.
+// A nil value (the default) keeps the TLS configuration of http.DefaultTransport.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetTLSConfig(tlsConfig *tls.Config) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.tlsConfig = tlsConfig.Clone()
+	s.resetTransport()
+
+	return s
+}
+
+// SetClientCertificate sets the client certificate the Sitemap Parser presents to the servers requesting one, for mutual TLS.
+// It is used alongside the TLS configuration set by SetTLSConfig, including its root CAs.
+// A failed handshake is recorded as a FetchError of the ErrorCategoryTLS category.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetClientCertificate(cert tls.Certificate) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.clientCertificate = &cert
+	s.resetTransport()
+
+	return s
+}
+
 // SetMultiThread sets the multi-threading for the Sitemap Parser.
 // The multi-threading flag determines whether the parser should fetch URLs concurrently using goroutines.
 // The function returns a pointer to the S structure to allow method chaining.
 func (s *S) SetMultiThread(multiThread bool) *S {
+	if s == nil {
+		return nil
+	}
 	s.cfg.multiThread = multiThread
 
 	return s
 }
 
+// SetFollowIndexes sets whether the Sitemap Parser fetches the sitemaps referenced by the main URL.
+// If it is false, only the document of the main URL is parsed: the locations listed by a sitemap index or robots.txt
+// are recorded (see GetSitemapLocations and GetSitemapTree), but they are not fetched. A sitemap is parsed as usual.
+// The default is true.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetFollowIndexes(followIndexes bool) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.followIndexes = followIndexes
+
+	return s
+}
+
+// SetCollectURLs sets whether the Sitemap Parser stores the URLs of the sitemaps.
+// If it is false, the sitemap indexes are followed as usual, but the URLs of the sitemaps are only counted, not stored:
+// GetURLs returns an empty list, while GetSitemapLocations, GetSitemapTree (including the URL counts) and GetFetchMetadata are populated.
+// The default is true.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetCollectURLs(collectURLs bool) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.skipURLs = !collectURLs
+
+	return s
+}
+
+// SetMaxURLs sets the maximum number of URLs stored by the Sitemap Parser.
+// Once the maximum is reached, the remaining URLs are dropped, no further sitemaps are fetched,
+// and GetCompleteness reports the parse as truncated by TruncatedByMaxURLs. A value of 0 (the default) means no limit.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetMaxURLs(maxURLs int) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.maxURLs = maxURLs
+
+	return s
+}
+
+// SetMaxURLsPerSitemap sets the maximum number of URLs collected from each sitemap by the Sitemap Parser.
+// Only the first maxURLsPerSitemap URLs matching the rules are collected from a sitemap, the remaining ones are dropped,
+// and the Truncated field of the node of the sitemap in the sitemap tree is set. Unlike SetMaxURLs, it does not stop the parse.
+// Without rules, the decoding of a sitemap stops after the first entry over the limit,
+// so that syntax errors in the rest of the document are not reported. A value of 0 (the default) means no limit.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetMaxURLsPerSitemap(maxURLsPerSitemap int) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.maxURLsPerSitemap = maxURLsPerSitemap
+
+	return s
+}
+
+// SetMaxSitemaps sets the maximum number of sitemaps fetched by the Sitemap Parser, not counting the main URL.
+// Once the maximum is reached, no further sitemaps are fetched,
+// and GetCompleteness reports the parse as truncated by TruncatedByMaxSitemaps. A value of 0 (the default) means no limit.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetMaxSitemaps(maxSitemaps int) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.maxSitemaps = maxSitemaps
+
+	return s
+}
+
+// SetMinSitemapLastMod sets the earliest lastmod of the sitemaps fetched from a sitemap index by the Sitemap Parser.
+// The sitemaps of an index with a lastmod before t are neither fetched nor descended into, and are counted as skipped by GetCompleteness.
+// The sitemaps without a valid lastmod are fetched, unless SetSkipSitemapsWithoutLastMod is set.
+// The zero time (the default) means no limit.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetMinSitemapLastMod(t time.Time) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.minSitemapLastMod = t
+
+	return s
+}
+
+// SetSkipSitemapsWithoutLastMod sets whether the sitemaps of an index without a valid lastmod are skipped
+// when a minimum lastmod is set with SetMinSitemapLastMod. The default is false, they are fetched.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetSkipSitemapsWithoutLastMod(skip bool) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.skipSitemapsWithoutLastMod = skip
+
+	return s
+}
+
+// SetMaxSitemapsByLastMod sets the maximum number of sitemaps fetched per sitemap index by the Sitemap Parser.
+// The sitemaps of each index (after the follow and minimum lastmod filters) are sorted by lastmod in descending order,
+// sitemaps without a valid lastmod last, and only the first n are fetched, newest first.
+// The others are counted as skipped by GetCompleteness. A value of 0 (the default) means no limit.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetMaxSitemapsByLastMod(n int) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.maxSitemapsByLastMod = n
+
+	return s
+}
+
+// SetLastModFormats sets the layouts (see time.Parse) of the <lastmod> values accepted by the Sitemap Parser,
+// in sitemaps and sitemap indexes. A value is parsed with the first matching layout.
+// If replace is false, the layouts are tried after the defaults, a date ("2006-01-02") and an RFC3339 date and time;
+// otherwise, they replace the defaults. Replacing the defaults with no layouts restores the defaults.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetLastModFormats(layouts []string, replace bool) *S {
+	if s == nil {
+		return nil
+	}
+	switch {
+	case replace && len(layouts) == 0:
+		s.cfg.lastModFormats = nil
+	case replace:
+		s.cfg.lastModFormats = append([]string(nil), layouts...)
+	default:
+		s.cfg.lastModFormats = append(append([]string(nil), defaultLastModFormats...), layouts...)
+	}
+
+	return s
+}
+
+// SetDefaultLastModLocation sets the location of the <lastmod> values parsed with a layout carrying no zone information,
+// e.g. a date ("2006-01-02") or a layout set with SetLastModFormats like "2006-01-02T15:04", for the sites documenting their dates in local time.
+// The values with a zone offset (e.g. RFC3339 date and times) keep their offset. A nil location (the default) means UTC.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetDefaultLastModLocation(loc *time.Location) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.lastModLocation = loc
+
+	return s
+}
+
+// SetRetries sets the number of retries, which has two meanings, one for the parse and one for the notifications:
+//   - the Sitemap Parser re-fetches a location whose compressed content arrives truncated or corrupted
+//     (for example with an invalid gzip checksum), as such corruption is usually transient.
+//     If the content is still corrupted after the last retry, it is handled as without retries, see SetStrictDecompression.
+//     The number of re-fetches of a location is reported in its fetch metadata;
+//   - Ping and IndexNow resend a request failing with a network error, HTTP status 429 (Too Many Requests) or a 5xx status,
+//     after a backoff, see SetRetryBackoff.
+//
+// A value of 0 (the default) means no retries.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetRetries(retries int) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.retries = retries
+
+	return s
+}
+
+// SetStrictDecompression sets whether truncated or corrupted compressed content is discarded by the Sitemap Parser.
+// If strict is true, the failure is recorded as a DecompressionError naming the location, and the sitemap is counted as failed.
+// If strict is false (the default), the content decompressed from a truncated file is parsed and the truncation is recorded as a warning,
+// see GetWarnings, while other decompression errors are recorded as errors and the original content is parsed.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetStrictDecompression(strict bool) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.strictDecompression = strict
+
+	return s
+}
+
+// SetMemoryBudget sets the maximum memory in bytes retained by the results of the Sitemap Parser.
+// The retained memory is estimated from the stored URLs (the length of their locations plus a fixed overhead per URL)
+// and the content of the main URL. Once the budget would be exceeded, no further URLs are stored, no further sitemaps are fetched,
+// the content of the main URL is released, and GetCompleteness reports the parse as truncated by TruncatedByMemoryBudget.
+// A value of 0 (the default) means no limit.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetMemoryBudget(bytes int64) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.memoryBudget = bytes
+
+	return s
+}
+
+// SetAllowedSchemes sets the URL schemes of the locations the Sitemap Parser fetches, compared case-insensitively.
+// Locations with other schemes, including the main URL and the locations found in robots.txt files and sitemap indexes,
+// are not fetched, and an UnsupportedSchemeError is recorded for them. The default is http and https.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetAllowedSchemes(schemes []string) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.allowedSchemes = schemes
+
+	return s
+}
+
 // SetFollow sets the follow patterns using the provided list of regex strings and compiles them into regex objects.
 // Any errors encountered during compilation are appended to the error list in the struct.
+// A location matches if any of the patterns matches it. The patterns anchored with ^ and starting with a literal,
+// e.g. `^https://www\.example\.com/products/`, are matched by their prefix, so hundreds of them are cheap.
 // The function returns a pointer to the S structure to allow method chaining.
 func (s *S) SetFollow(regexes []string) *S {
+	if s == nil {
+		return nil
+	}
 	s.cfg.follow = regexes
 	for _, followPattern := range s.cfg.follow {
 		re, err := regexp.Compile(followPattern)
@@ -183,8 +725,13 @@ func (s *S) SetFollow(regexes []string) *S {
 
 // SetRules sets the rules patterns using the provided list of regex strings and compiles them into regex objects.
 // Any errors encountered during compilation are appended to the error list in the struct.
+// A location matches if any of the patterns matches it. The patterns anchored with ^ and starting with a literal,
+// e.g. `^https://www\.example\.com/products/`, are matched by their prefix, so hundreds of them are cheap.
 // The function returns a pointer to the S structure to allow method chaining.
 func (s *S) SetRules(regexes []string) *S {
+	if s == nil {
+		return nil
+	}
 	s.cfg.rules = regexes
 	for _, rulePattern := range s.cfg.rules {
 		re, err := regexp.Compile(rulePattern)
@@ -197,53 +744,189 @@ func (s *S) SetRules(regexes []string) *S {
 	return s
 }
 
+// SetRateLimit sets the maximum number of requests per second for the Sitemap Parser, shared by all goroutines.
+// Every fetch waits until it is allowed by the limit. A value of 0 (the default) means no limit.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetRateLimit(rps float64) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.rateLimit = rps
+
+	return s
+}
+
+// SetPerHostRateLimit sets the maximum number of requests per second per host for the Sitemap Parser, shared by all goroutines.
+// Every fetch waits until it is allowed by the limit of its host. A value of 0 (the default) means no limit.
+// It can be combined with SetRateLimit, in which case both limits apply.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetPerHostRateLimit(rps float64) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.perHostRateLimit = rps
+
+	return s
+}
+
+// SetMaxConcurrency sets the maximum number of requests in progress at the same time for the Sitemap Parser, shared by all goroutines.
+// Every fetch waits until fewer requests are in progress, including reading their bodies. A value of 0 (the default) means no limit.
+// It can be combined with SetMaxConcurrencyPerHost and the rate limits, in which case all of them apply.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetMaxConcurrency(n int) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.maxConcurrency = n
+
+	return s
+}
+
+// SetMaxConcurrencyPerHost sets the maximum number of requests in progress at the same time per host for the Sitemap Parser,
+// shared by all goroutines, so that a single slow host cannot hold all the slots of SetMaxConcurrency.
+// The hosts are compared case-insensitively, including the port. A value of 0 (the default) means no limit.
+// It can be combined with SetMaxConcurrency and the rate limits, in which case all of them apply.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetMaxConcurrencyPerHost(n int) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.maxConcurrencyPerHost = n
+
+	return s
+}
+
+// SetRequestDelay sets the politeness delay between requests to the same host for the Sitemap Parser.
+// The requests to the same host are serialized across all goroutines, and after a request has finished,
+// the next one to the same host waits for delay, randomly deviated by at most jitter in both directions.
+// A delay of 0 (the default) means no delay. It can be combined with the rate limits, in which case all of them apply,
+// and with the Crawl-delay of the robots.txt rules, in which case the stricter one applies, see SetRespectCrawlDelay.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetRequestDelay(delay time.Duration, jitter time.Duration) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.requestDelay = delay
+	s.cfg.requestDelayJitter = jitter
+
+	return s
+}
+
+// SetCircuitBreaker sets the circuit breaker threshold for the Sitemap Parser.
+// After threshold consecutive failed fetches from a host, all further fetches to that host are skipped for the rest of the parse.
+// A single CircuitOpenError is recorded for the host once all fetches of the parse are done, listing the skipped locations.
+// A threshold of 0 (the default) disables the circuit breaker.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetCircuitBreaker(threshold int) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.circuitBreakerThreshold = threshold
+
+	return s
+}
+
 // Parse is a method of the S structure. It parses the given URL and its content.
+// It is equivalent to ParseContext with context.Background().
+func (s *S) Parse(url string, urlContent *string) (*S, error) {
+	return s.ParseContext(context.Background(), url, urlContent)
+}
+
+// ParseContext is a method of the S structure. It parses the given URL and its content.
+// If the S object is nil, it returns ErrNilReceiver.
 // If the S object has any errors, it returns an error with the message "errors occurred before parsing, see GetErrors() for details".
 // It sets the mainURL field to the given URL and the mainURLContent field to the given URL content.
+// If the URL content is nil, the URL is validated before fetching it, see validateSitemapURL.
 // It returns an error if there was an error setting the content.
 // If the URL ends with "/robots.txt", it parses the robots.txt file and fetches URLs from the sitemap files mentioned in the robots.txt.
+// If following indexes is turned off, the locations referenced by the main URL are recorded, but not fetched.
 // The URLs are fetched concurrently using goroutines and the wait group wg.
 // If there was an error fetching a sitemap file, the error is appended to the errs field.
 // The fetched content is checked and unzipped if necessary.
 // The fetched sitemap file URLs are parsed and fetched.
 // If the URL does not end with "/robots.txt", the mainURLContent is checked and unzipped if necessary.
-// The mainURLContent is then parsed and fetched.
+// The mainURLContent is then parsed and fetched, or, if it is an HTML page and the discovery is turned on, the sitemaps it links to, see SetDiscoverFromHTML.
 // After all URLs are fetched and parsed, the method waits for all goroutines to complete using wg.Wait().
-// It returns the S structure and nil error if the method was able to complete successfully.
-func (s *S) Parse(url string, urlContent *string) (*S, error) {
+// The fetches are performed with the given context, waiting for the rate limits (if any) is also cancelled with it.
+// It returns the S structure and nil error if the method was able to complete successfully,
+// or the error the parse was aborted with, see SetMaxFailureRate and SetMaxErrors, along with the partial results.
+func (s *S) ParseContext(ctx context.Context, url string, urlContent *string) (*S, error) {
 	var err error
 	var mu sync.Mutex
 	var wg sync.WaitGroup
 
+	if s == nil {
+		return nil, ErrNilReceiver
+	}
+
 	if len(s.errs) > 0 {
 		return s, errors.New("errors occurred before parsing, see GetErrors() for details")
 	}
 
+	s.startParse(ctx)
+	defer s.endParse(ctx)
+
 	s.mainURL = url
+	s.mu.Lock()
+	s.tree = s.node(s.mainURL)
+	s.mu.Unlock()
+
+	if urlContent == nil {
+		if err = s.validateSitemapURL(s.mainURL); err != nil {
+			s.failSitemap(s.mainURL)
+			s.setNodeError(s.mainURL, err)
+			s.addError(err)
+			return s, err
+		}
+	}
+
 	s.mainURLContent, err = s.setContent(urlContent)
 	if err != nil {
-		s.errs = append(s.errs, err)
+		s.failSitemap(s.mainURL)
+		s.setNodeError(s.mainURL, err)
+		err = locationError(s.mainURL, err)
+		s.addError(err)
 		return s, err
 	}
 
+	s.mu.Lock()
+	if !s.reserveMemory(int64(len(s.mainURLContent))) {
+		s.truncate(TruncatedByMemoryBudget)
+	}
+	s.mu.Unlock()
+
 	if strings.HasSuffix(s.mainURL, "/robots.txt") {
 		s.parseRobotsTXT(s.mainURLContent)
+		s.applyCrawlDelay()
+		s.recordRawContent(s.mainURL, []byte(s.mainURLContent))
+
+		s.mu.Lock()
+		s.tree.Kind = SitemapKindRobotsTXT
+		s.tree.fetched = true
+		for _, robotsTXTSitemapURL := range s.robotsTxtSitemapURLs {
+			s.addChildNode(s.tree, robotsTXTSitemapURL, nil)
+		}
+		s.traceDocument(s.mainURL, s.mainURLContent, len(s.robotsTxtSitemapURLs), len(s.robotsTxtSitemapURLs))
+		s.mu.Unlock()
+
+		if !s.cfg.followIndexes {
+			return s, nil
+		}
 
 		for _, robotsTXTSitemapURL := range s.robotsTxtSitemapURLs {
 			wg.Add(1)
 			rTXTsmURL := robotsTXTSitemapURL
 			go func() {
 				defer wg.Done()
+				defer s.recoverPanic(rTXTsmURL)
 
 				mu.Lock()
 				defer mu.Unlock()
 
-				robotsTXTSitemapContent, err := s.fetch(rTXTsmURL)
+				robotsTXTSitemapContent, err := s.fetchAndUnzip(rTXTsmURL)
 				if err != nil {
-					s.errs = append(s.errs, err)
 					return
 				}
-				robotsTXTSitemapContent = s.checkAndUnzipContent(robotsTXTSitemapContent)
 
 				if s.cfg.multiThread {
 					s.parseAndFetchUrlsMultiThread(s.parse(rTXTsmURL, string(robotsTXTSitemapContent)))
@@ -253,75 +936,293 @@ func (s *S) Parse(url string, urlContent *string) (*S, error) {
 			}()
 		}
 	} else {
-		mainURLContent := s.checkAndUnzipContent([]byte(s.mainURLContent))
+		s.applyCrawlDelay()
+		mainURLContent, err := s.checkAndUnzipContent(s.mainURL, []byte(s.mainURLContent))
+		if err != nil {
+			s.failSitemap(s.mainURL)
+			s.setNodeError(s.mainURL, err)
+			return s, locationError(s.mainURL, err)
+		}
 		s.mainURLContent = string(mainURLContent)
+		s.recordDecompressedBytes(s.mainURL, len(mainURLContent))
+		s.recordRawContent(s.mainURL, mainURLContent)
+		var sitemapLocations []string
+		if s.cfg.discoverFromHTML && isHTML(s.mainURLContent) {
+			sitemapLocations = s.parseHTML(s.mainURLContent)
+		}
+		if len(sitemapLocations) == 0 {
+			sitemapLocations = s.parse(s.mainURL, s.mainURLContent)
+		}
+		if !s.cfg.followIndexes {
+			return s, nil
+		}
 		if s.cfg.multiThread {
-			s.parseAndFetchUrlsMultiThread(s.parse(s.mainURL, s.mainURLContent))
+			s.parseAndFetchUrlsMultiThread(sitemapLocations)
 		} else {
-			s.parseAndFetchUrlsSequential(s.parse(s.mainURL, s.mainURLContent))
+			s.parseAndFetchUrlsSequential(sitemapLocations)
+		}
+	}
+
+	wg.Wait()
+	s.publishCircuitErrors()
+
+	s.mu.Lock()
+	s.truncateByContext()
+	if s.truncatedBy == TruncatedByMemoryBudget {
+		// release the content of the main URL, it is not needed anymore
+		s.memoryUsed -= int64(len(s.mainURLContent))
+		s.mainURLContent = ""
+	}
+	abortErr := s.abortErr()
+	s.mu.Unlock()
+
+	return s, abortErr
+}
+
+// startParse sets up the state of a parse performed with the given context: the throttling of its fetches,
+// the matchers of the follow and rules patterns, the circuit breaker, the failure rate and error limits and the HTTP transport.
+func (s *S) startParse(ctx context.Context) {
+	s.parsedAt = time.Now()
+	s.mu.Lock()
+	s.timing = Timing{}
+	s.mu.Unlock()
+	s.ctx = ctx
+	s.cancelParse = nil
+	if s.failureRateEnabled() || s.cfg.maxErrors > 0 {
+		s.ctx, s.cancelParse = context.WithCancel(ctx)
+	}
+	s.outcomesCompleted, s.outcomesFailed, s.failureRateErr, s.errorLimitErr = 0, 0, nil, nil
+	s.concurrency = newConcurrencyLimiter(s.cfg.maxConcurrency, s.cfg.maxConcurrencyPerHost)
+	s.rateLimiter = newRateLimiter(s.cfg.rateLimit)
+	s.hostRateLimiters = newHostRateLimiters(s.cfg.perHostRateLimit)
+	s.hostDelays = newHostDelays(s.cfg.requestDelay, s.cfg.requestDelayJitter, s.randInt63n)
+	s.followMatcher = newMatcher(s.cfg.followRegexes)
+	s.rulesMatcher = newMatcher(s.cfg.rulesRegexes)
+	s.circuits = newHostCircuits(s.cfg.circuitBreakerThreshold)
+	s.mu.Lock()
+	s.circuitErrs = nil
+	s.mu.Unlock()
+	s.initTransport()
+}
+
+// initTransport builds the HTTP transport for the connection settings, unless it is already built.
+func (s *S) initTransport() {
+	if s.transport == nil {
+		s.transport = newTransport(s.cfg.connectTimeout, s.tlsClientConfig())
+	}
+}
+
+// resetTransport closes the idle connections of the HTTP transport built for the previous connection settings, if any,
+// and drops it, so that the next parse builds one for the current settings.
+func (s *S) resetTransport() {
+	if transport, ok := s.transport.(*http.Transport); ok {
+		transport.CloseIdleConnections()
+	}
+	s.transport = nil
+}
+
+// endParse records the total duration of the parse, releases the context of the parse set up by startParse,
+// and restores the given context of the parse.
+func (s *S) endParse(ctx context.Context) {
+	s.mu.Lock()
+	s.timing.Total = time.Since(s.parsedAt)
+	s.mu.Unlock()
+	if s.cancelParse != nil {
+		s.cancelParse()
+		s.cancelParse = nil
+		s.ctx = ctx
+	}
+}
+
+// tlsClientConfig returns the TLS configuration of the connections, see SetTLSConfig and SetClientCertificate.
+// It returns nil if neither is set, which means the TLS configuration of http.DefaultTransport is used.
+func (s *S) tlsClientConfig() *tls.Config {
+	if s.cfg.tlsConfig == nil && s.cfg.clientCertificate == nil {
+		return nil
+	}
+	tlsConfig := s.cfg.tlsConfig.Clone()
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+	if s.cfg.clientCertificate != nil {
+		tlsConfig.Certificates = append(tlsConfig.Certificates, *s.cfg.clientCertificate)
+	}
+	return tlsConfig
+}
+
+// validateSitemapURL checks whether the given sitemap URL can be fetched: it must be non-empty, parseable by net/url,
+// and an absolute URL with an allowed scheme and a host. It returns a descriptive error, or nil if the URL is valid.
+func (s *S) validateSitemapURL(location string) error {
+	if location == "" {
+		return errors.New("invalid sitemap URL \"\": empty")
+	}
+	u, err := neturl.Parse(location)
+	if err != nil {
+		return fmt.Errorf("invalid sitemap URL %q: %w", location, err)
+	}
+	if u.Scheme == "" {
+		return fmt.Errorf("invalid sitemap URL %q: missing scheme", location)
+	}
+	if !s.schemeAllowed(u.Scheme) {
+		return fmt.Errorf("invalid sitemap URL %q: %w", location, &UnsupportedSchemeError{Location: location, Scheme: u.Scheme})
+	}
+	if u.Host == "" && !s.isFSLocation(location) {
+		return fmt.Errorf("invalid sitemap URL %q: missing host", location)
+	}
+	return nil
+}
+
+// schemeAllowed reports whether locations with the given URL scheme may be fetched. The fs scheme is allowed while parsing a file system, see ParseFS.
+func (s *S) schemeAllowed(scheme string) bool {
+	if s.fsys != nil && strings.EqualFold(scheme, fsScheme) {
+		return true
+	}
+	if len(s.cfg.allowedSchemes) == 0 {
+		return strings.EqualFold(scheme, "http") || strings.EqualFold(scheme, "https")
+	}
+	for _, allowed := range s.cfg.allowedSchemes {
+		if strings.EqualFold(scheme, allowed) {
+			return true
 		}
 	}
+	return false
+}
 
-	wg.Wait()
+// locationError wraps the error encountered while processing the given location, prefixing its message with the location.
+func locationError(location string, err error) error {
+	return fmt.Errorf("%s: %w", location, err)
+}
+
+// addError appends the error to the errs field, guarded by s.mu.
+func (s *S) addError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.appendError(err)
+}
+
+// addWarning appends the given warning to the warnings of the parse.
+func (s *S) addWarning(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.appendWarning(err)
+}
+
+// GetWarnings returns a copy of the warnings encountered: problems the parse recovered from, such as truncated compressed content.
+// If the S object is nil, nil is returned.
+func (s *S) GetWarnings() []error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	return s, nil
+	return append([]error(nil), s.warnings...)
 }
 
+// GetErrorsCount returns the number of errors encountered. If the S object is nil, 0 is returned.
 func (s *S) GetErrorsCount() int64 {
 	if s == nil {
 		return 0
 	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	return int64(len(s.errs))
 }
 
+// GetErrors returns a copy of the errors encountered. If the S object is nil, nil is returned.
 func (s *S) GetErrors() []error {
 	if s == nil {
 		return nil
 	}
-	return s.errs
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]error(nil), s.errs...)
+}
+
+// GetSitemapLocations returns the locations of the sitemap indexes parsed and of the sitemaps referenced by them.
+// The robots.txt Sitemap: directives are only included once the referenced document turns out to be a sitemap index.
+// If the S object is nil, nil is returned.
+func (s *S) GetSitemapLocations() []string {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.sitemapLocations...)
 }
 
-// GetURLs returns the list of parsed URLs.
+// GetURLs returns a copy of the list of parsed URLs, the caller may sort or modify it without affecting the S object.
+// The copy is shallow: the values the pointer fields of the URLs point to are shared and must not be modified.
+// If the S object is nil or there are no URLs, an empty slice is returned.
 func (s *S) GetURLs() []URL {
+	if s == nil {
+		return []URL{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append(make([]URL, 0, len(s.urls)), s.urls...)
+}
+
+// GetURLsShared returns the list of parsed URLs without copying it, for performance-sensitive callers.
+// The returned slice is owned by the S object: it must be treated as read-only and must not be used while a parse is in progress.
+// If the S object is nil or there are no URLs, an empty slice is returned.
+func (s *S) GetURLsShared() []URL {
+	if s == nil {
+		return []URL{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if len(s.urls) <= 0 {
 		return []URL{}
 	}
 	return s.urls
 }
 
-// GetURLCount returns the count of URLs in the S struct.
+// GetURLCount returns the count of URLs in the S struct, including the locations stored in loc-only mode, see SetLocOnly.
+// If the S object is nil, 0 is returned.
 func (s *S) GetURLCount() int64 {
 	if s == nil {
 		return 0
 	}
-	if len(s.urls) <= 0 {
-		return 0
-	}
-	return int64(len(s.urls))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return int64(len(s.urls) + len(s.locs))
 }
 
 // GetRandomURLs returns a slice of randomly selected URLs from the S object's URL list. The number of URLs to select is specified by the parameter n.
 // If the S object is nil, an empty slice is returned.
 // The function creates a copy of the original URLs list and randomly selects n URLs from it, removing them to avoid duplicates.
-// The selected URLs are returned as a new slice.
+// The selected URLs are returned as a new slice, the S object's URL list is left unchanged.
+// The URLs are selected with the source of randomness set, see SetRandomSource.
 func (s *S) GetRandomURLs(n int) []URL {
 	if s == nil {
 		return []URL{}
 	}
 
-	originalURLs := s.urls
+	originalURLs := s.GetURLs()
 
 	randURLs := make([]URL, 0, n)
 
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	for i := 0; i < n; i++ {
 		if len(originalURLs) == 0 {
 			break
 		}
 
-		index := rand.Intn(len(originalURLs))
+		index := s.randIntn(len(originalURLs))
 		randURLs = append(randURLs, originalURLs[index])
 
-		// Remove the selected URL from the original list to avoid duplicates
+		// Remove the selected URL from the copied list to avoid duplicates
 		originalURLs[index] = originalURLs[len(originalURLs)-1] // Replace it with the last one.
 		originalURLs = originalURLs[:len(originalURLs)-1]       // Remove last element.
 	}
@@ -335,7 +1236,8 @@ func (s *S) setContent(urlContent *string) (string, error) {
 	if urlContent != nil {
 		return *urlContent, nil
 	}
-	mainURLContent, err := s.fetch(s.mainURL)
+	mainURLContent, meta, err := s.fetchBody(s.mainURL)
+	s.recordFetchMeta(s.mainURL, meta)
 
 	if err != nil {
 		return "", err
@@ -343,77 +1245,346 @@ func (s *S) setContent(urlContent *string) (string, error) {
 	return string(mainURLContent), nil
 }
 
-// parseRobotsTXT retrieves the sitemap URLs from the provided robots.txt content.
-// It splits the content into lines and checks for lines beginning with "Sitemap: ".
-// If a line matches, it extracts the URL and adds it to the robotsTxtSitemapURLs slice.
-// The method does not return any values, but it updates the robotsTxtSitemapURLs field of the S struct.
+// parseRobotsTXT retrieves the sitemap URLs from the provided robots.txt content, see ExtractSitemapURLs,
+// and adds them to the robotsTxtSitemapURLs slice.
+// The characters not allowed in a URL are percent-encoded with a warning, see SetEncodeLocs,
+// then the URLs are rewritten by the sitemap URL rewriter, if any, see SetSitemapURLRewriter.
+// The method does not return any values, but it updates the robotsTxtSitemapURLs field of the S struct,
+// and keeps the groups of the file in the robotsGroups field, see GetDisallowedURLs.
 func (s *S) parseRobotsTXT(robotsTXTContent string) {
-	lines := strings.Split(robotsTXTContent, "\n")
-	for _, line := range lines {
-		if !strings.HasPrefix(line, "Sitemap: ") {
+	s.robotsGroups = parseRobotsGroups(robotsTXTContent)
+	for _, sitemap := range extractRobotsSitemaps(robotsTXTContent, s.mainURL) {
+		if sitemap.encoded {
+			s.addWarning(locationError(s.mainURL, &LocEncodedError{Location: s.mainURL, Loc: sitemap.value, Encoded: sitemap.loc}))
+		}
+		url := s.rewriteSitemapLoc(sitemap.loc)
+		if url == "" {
 			continue
 		}
-		url := strings.Split(line, "Sitemap: ")[1]
 		s.robotsTxtSitemapURLs = append(s.robotsTxtSitemapURLs, url)
 	}
 }
 
+// context returns the context of the current parse, or context.Background() if there is none.
+func (s *S) context() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
+
 // fetch retrieves the content of the specified URL using an HTTP GET request.
-// It returns the content as a []byte and an error if there was a problem fetching the URL.
+// It returns the content as a []byte, the metadata of the fetch and an error if there was a problem fetching the URL.
+// The returned error is a *FetchError wrapping the underlying error.
+// The metadata is filled in as far as the request got, even if an error is returned.
 // The HTTP status must be 200 (OK) for the request to be successful.
+// The host of the URL is rewritten for the request, see SetFetchHostRewrite, and an http URL is fetched over https first,
+// falling back to the original URL, if the upgrade is turned on, see SetUpgradeToHTTPS.
 // The response body is automatically closed after reading using a defer statement.
-func (s *S) fetch(url string) ([]byte, error) {
+func (s *S) fetch(url string) ([]byte, FetchMeta, error) {
+	target := rewriteHost(url, s.cfg.fetchHostRewrite)
+	if !s.cfg.upgradeToHTTPS || !isHTTP(target) {
+		return s.fetchTarget(url, target)
+	}
+
+	content, meta, err := s.fetchTarget(url, upgradeToHTTPS(target))
+	// only a failure without a response falls back to the original URL, e.g. a refused connection or a failed TLS handshake
+	if err == nil || meta.StatusCode != 0 || s.context().Err() != nil {
+		meta.HTTPSUpgrade = true
+		return content, meta, err
+	}
+	content, meta, err = s.fetchTarget(url, target)
+	meta.HTTPSFallback = true
+	return content, meta, err
+}
+
+// fetchTarget retrieves the content of the given URL from the target URL, see fetch.
+// The target differs from the URL if the host is rewritten (see SetFetchHostRewrite) or the scheme is upgraded (see SetUpgradeToHTTPS),
+// the errors are reported with the URL.
+func (s *S) fetchTarget(url string, target string) ([]byte, FetchMeta, error) {
 	var body bytes.Buffer
+	var meta FetchMeta
 
 	client := &http.Client{
-		Timeout: time.Duration(s.cfg.fetchTimeout) * time.Second,
+		Transport: s.transport,
+		Timeout:   time.Duration(s.cfg.fetchTimeout) * time.Second,
 	}
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(s.context(), http.MethodGet, target, nil)
 	if err != nil {
-		return nil, err
+		return nil, meta, &FetchError{URL: url, Err: err}
+	}
+
+	release, err := s.throttle(req.Context(), strings.ToLower(req.URL.Host))
+	if err != nil {
+		return nil, meta, &FetchError{URL: url, Err: err}
+	}
+	defer release()
+
+	start := time.Now()
+	defer s.addTiming(&s.timing.Fetching, start)
+	defer func() {
+		s.observeFetch(meta)
+	}()
+	s.mu.Lock()
+	s.timing.Fetches++
+	s.mu.Unlock()
+
+	if s.cfg.fetcher != nil {
+		var content []byte
+		content, meta, err = s.fetchWithFetcher(req.Context(), url, target, start)
+		return content, meta, err
 	}
 
 	req.Header.Set("User-Agent", s.cfg.userAgent)
 
 	response, err := client.Do(req)
 	if err != nil {
-		return nil, err
-	}
-
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("received HTTP status %d", response.StatusCode)
+		meta.Duration = time.Since(start)
+		return nil, meta, &FetchError{URL: url, Err: err}
 	}
 	defer func(Body io.ReadCloser) {
 		_ = Body.Close()
 	}(response.Body)
 
+	meta.StatusCode = response.StatusCode
+	meta.ContentType = response.Header.Get("Content-Type")
+	meta.ETag = response.Header.Get("ETag")
+	meta.LastModified = response.Header.Get("Last-Modified")
+
+	if response.StatusCode != http.StatusOK {
+		meta.Duration = time.Since(start)
+		return nil, meta, &FetchError{URL: url, StatusCode: response.StatusCode, Err: fmt.Errorf("received HTTP status %d", response.StatusCode)}
+	}
+	// the body is closed unread, which aborts the download
+	if err = s.checkSizeHint(url, response.ContentLength); err != nil {
+		meta.Duration = time.Since(start)
+		return nil, meta, err
+	}
+
 	_, err = io.Copy(&body, response.Body)
+	meta.CompressedBytes = int64(body.Len())
+	meta.DecompressedBytes = meta.CompressedBytes
+	meta.Duration = time.Since(start)
 	if err != nil {
+		return nil, meta, &FetchError{URL: url, Err: err}
+	}
+
+	content := body.Bytes()
+	encoding := strings.TrimSpace(response.Header.Get("Content-Encoding"))
+	if d := s.decompressorForEncoding(encoding); d != nil {
+		if content, err = s.decompress(d, content); err != nil {
+			return nil, meta, &FetchError{URL: url, Err: fmt.Errorf("decoding content encoding: %w", err)}
+		}
+		meta.DecompressedBytes = int64(len(content))
+	} else if strings.EqualFold(encoding, "deflate") {
+		if content, err = s.decodeDeflateBody(content); err != nil {
+			return nil, meta, &FetchError{URL: url, Err: fmt.Errorf("decoding deflate content encoding: %w", err)}
+		}
+		meta.DecompressedBytes = int64(len(content))
+	}
+
+	return content, meta, nil
+}
+
+// fetchWithRetries fetches the given URL like fetch, and re-fetches it up to the configured number of retries
+// while its compressed content arrives corrupted, see SetRetries. The Retries field of the returned metadata is the number of re-fetches.
+// The content of the last attempt is returned, it is decompressed again by checkAndUnzipContent.
+func (s *S) fetchWithRetries(url string) ([]byte, FetchMeta, error) {
+	content, meta, err := s.fetch(url)
+	for retries := 1; retries <= s.cfg.retries && err == nil && s.context().Err() == nil; retries++ {
+		if !s.corrupted(url, content) {
+			break
+		}
+		content, meta, err = s.fetch(url)
+		meta.Retries = retries
+	}
+	return content, meta, err
+}
+
+// corrupted reports whether the content fetched from the given URL is compressed, and truncated or corrupted.
+func (s *S) corrupted(url string, content []byte) bool {
+	_, truncated, err := s.uncompress(url, content)
+	return truncated || isCorruption(err)
+}
+
+// isCorruption reports whether the given decompression error is caused by corrupted content, which may be transient.
+func isCorruption(err error) bool {
+	var corruptInputErr flate.CorruptInputError
+	return errors.Is(err, gzip.ErrChecksum) ||
+		errors.Is(err, gzip.ErrHeader) ||
+		errors.Is(err, zlib.ErrChecksum) ||
+		errors.Is(err, zlib.ErrHeader) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.As(err, &corruptInputErr)
+}
+
+// newTransport creates an HTTP transport based on http.DefaultTransport, limiting dialing and the TLS handshake to connectTimeout
+// if it is positive, and using the given TLS configuration if it is not nil.
+// It returns nil if connectTimeout is not positive and tlsConfig is nil, which means http.DefaultTransport is used.
+func newTransport(connectTimeout time.Duration, tlsConfig *tls.Config) http.RoundTripper {
+	if connectTimeout <= 0 && tlsConfig == nil {
+		return nil
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if connectTimeout > 0 {
+		dialer := &net.Dialer{
+			Timeout:   connectTimeout,
+			KeepAlive: 30 * time.Second,
+		}
+		transport.DialContext = dialer.DialContext
+		transport.TLSHandshakeTimeout = connectTimeout
+	}
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return transport
+}
+
+// fetchAndUnzip fetches the content of the given location and unzips it if necessary.
+// The metadata of the fetch is recorded. If there is an error during the fetch operation,
+// the error is recorded in the node of the location and appended to the errs field, and returned.
+// If the circuit of the host of the location is open, the location is skipped without fetching it,
+// only the node of the location records the error.
+// If the scheme of the location is not allowed, the location is skipped without fetching it, and an UnsupportedSchemeError is recorded.
+// If the response advertises a size over the hint (see SetMaxFetchSizeHint), the location is skipped without reading the body,
+// and a TooLargeError is recorded.
+// If the parse has been cut short, the location is skipped silently.
+func (s *S) fetchAndUnzip(location string) ([]byte, error) {
+	if u, err := neturl.Parse(location); err == nil && !s.schemeAllowed(u.Scheme) {
+		err := &UnsupportedSchemeError{Location: location, Scheme: u.Scheme}
+		s.skipSitemap()
+		s.setNodeError(location, err)
+		s.addError(locationError(location, err))
+		return nil, err
+	}
+
+	host, _ := hostOf(location)
+	if err := s.circuits.allow(host, location); err != nil {
+		s.skipSitemap()
+		s.setNodeError(location, err)
+		return nil, err
+	}
+	if err := s.startSitemap(); err != nil {
+		return nil, err
+	}
+
+	content, meta, err := s.fetchBody(location)
+	s.recordFetchMeta(location, meta)
+	var tooLargeErr *TooLargeError
+	if errors.As(err, &tooLargeErr) {
+		s.skipSitemap()
+		s.setNodeError(location, err)
+		s.addError(locationError(location, err))
+		// the host has responded, which ends its run of failures
+		s.circuits.record(host, false)
+		return nil, err
+	}
+	if err != nil {
+		s.failSitemap(location)
+		s.setNodeError(location, err)
+		err = locationError(location, err)
+		s.addError(err)
+	}
+	// failures caused by the cancellation of the parse do not count against the host
+	if circuitErr := s.circuits.record(host, err != nil && s.context().Err() == nil); circuitErr != nil {
+		s.mu.Lock()
+		s.circuitErrs = append(s.circuitErrs, locationError(location, circuitErr))
+		s.mu.Unlock()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	content, err = s.checkAndUnzipContent(location, content)
+	if err != nil {
+		s.failSitemap(location)
+		s.setNodeError(location, err)
 		return nil, err
 	}
+	s.recordDecompressedBytes(location, len(content))
+	s.recordRawContent(location, content)
+
+	return content, nil
+}
 
-	return body.Bytes(), nil
+// recoverPanic recovers a panic while processing the given location, so that a single document cannot crash the process.
+// The panic is recorded as a PanicError in the node of the location and appended to the errs field, and the sitemap is counted as failed.
+// It must be deferred directly, recover has no effect otherwise.
+func (s *S) recoverPanic(location string) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	err := &PanicError{Location: location, Value: r, Stack: debug.Stack()}
+	s.failSitemap(location)
+	s.setNodeError(location, err)
+	s.addError(locationError(location, err))
 }
 
-// checkAndUnzipContent checks if the content is a gzip file and unzips it if necessary
-// If the content is a gzip file, it returns the uncompressed content.
+// checkAndUnzipContent checks if the content is a gzip or zlib file, or matches a codec set with SetDecompressors, and unzips it if necessary
+// If the content is compressed, it returns the uncompressed content.
 // If an error occurs during unzipping or checking, it returns the original content.
-// It updates the internal error list if an error occurs while unzipping.
+// It updates the internal error list if an error occurs while unzipping, wrapping the error with the location,
+// and the internal warning list if the content is truncated, in which case the content decompressed so far is returned.
+// With strict decompression (see SetStrictDecompression), both are recorded as a DecompressionError,
+// which is also returned, and the content is discarded.
+// Content decompressed over the maximum size (see SetMaxDecompressedSize) is discarded and its error is recorded and returned.
 //
+// Param location: The location the content was fetched from
 // Param content: The content to be checked and possibly unzipped
 // Return []byte: The checked and possibly uncompressed content
-func (s *S) checkAndUnzipContent(content []byte) []byte {
+// Return error: The DecompressionError of the discarded content with strict decompression, the error of content over the maximum size, nil otherwise
+func (s *S) checkAndUnzipContent(location string, content []byte) ([]byte, error) {
+	defer s.addTiming(&s.timing.Decoding, time.Now())
+
+	uncompressed, truncated, err := s.uncompress(location, content)
+	if truncated {
+		err = io.ErrUnexpectedEOF
+	}
+	if err == nil {
+		return uncompressed, nil
+	}
+	decompressionErr := &DecompressionError{Location: location, Truncated: truncated, Err: err}
+	switch {
+	case s.cfg.strictDecompression:
+		decompressionErr.Discarded = true
+		s.addError(locationError(location, decompressionErr))
+		return nil, decompressionErr
+	case truncated:
+		s.addWarning(locationError(location, decompressionErr))
+		return uncompressed, nil
+	}
+	if errors.Is(err, ErrDecompressedTooLarge) {
+		s.addError(locationError(location, err))
+		return nil, err
+	}
+	s.addError(locationError(location, err))
+	// return the original content if error
+	return content, nil
+}
+
+// uncompress decompresses the content if it is a gzip or zlib file, or matches a codec set with SetDecompressors,
+// without recording any error. It returns the content unchanged if it is not compressed.
+// If the content is truncated, it returns the content decompressed so far and true.
+// If another error occurs, it returns the original content and the error.
+func (s *S) uncompress(location string, content []byte) ([]byte, bool, error) {
 	gzipPrefix := []byte("\x1f\x8b\x08")
-	if bytes.HasPrefix(content, gzipPrefix) {
-		uncompressed, err := s.unzip(content)
-		if err != nil {
-			s.errs = append(s.errs, err)
-			// return the original content if error
-			return content
-		}
-		content = uncompressed
+	switch {
+	case bytes.HasPrefix(content, gzipPrefix):
+		return s.gunzip(content)
+	case isZlib(content):
+		return s.zinflate(content)
+	}
+	d := s.decompressorFor(location, content)
+	if d == nil {
+		return content, false, nil
+	}
+	uncompressed, err := s.decompress(d, content)
+	if err != nil {
+		return content, false, err
 	}
-	return content
+	return uncompressed, false, nil
 }
 
 // parseAndFetchUrlsMultiThread concurrently parses and fetches the URLs specified in the "locations" parameter.
@@ -422,6 +1593,7 @@ func (s *S) checkAndUnzipContent(content []byte) []byte {
 // If there is an error during the fetch operation, the error is appended to the "errs" field of the S structure.
 // The fetched content is then checked and uncompressed using the checkAndUnzipContent method of the S structure.
 // Finally, the uncompressed content is passed to the parse method of the S structure.
+// A panic while processing a location is recovered and recorded as an error, see recoverPanic.
 // This method does not return any value.
 func (s *S) parseAndFetchUrlsMultiThread(locations []string) {
 	var wg sync.WaitGroup
@@ -431,12 +1603,11 @@ func (s *S) parseAndFetchUrlsMultiThread(locations []string) {
 		loc := location
 		go func() {
 			defer wg.Done()
-			content, err := s.fetch(loc)
+			defer s.recoverPanic(loc)
+			content, err := s.fetchAndUnzip(loc)
 			if err != nil {
-				s.errs = append(s.errs, err)
 				return
 			}
-			content = s.checkAndUnzipContent(content)
 			parsedLocations := s.parse(loc, string(content))
 			if len(parsedLocations) > 0 {
 				s.parseAndFetchUrlsMultiThread(parsedLocations)
@@ -451,143 +1622,429 @@ func (s *S) parseAndFetchUrlsMultiThread(locations []string) {
 // If there is an error during the fetch operation, the error is appended to the "errs" field of the S structure.
 // The fetched content is then checked and uncompressed using the checkAndUnzipContent method of the S structure.
 // Finally, the uncompressed content is passed to the parse method of the S structure.
+// A panic while processing a location is recovered and recorded as an error, see recoverPanic.
 // This method does not return any value.
 func (s *S) parseAndFetchUrlsSequential(locations []string) {
 	for _, location := range locations {
-		content, err := s.fetch(location)
-		if err != nil {
-			s.errs = append(s.errs, err)
-			continue
-		}
-		content = s.checkAndUnzipContent(content)
-		parsedLocations := s.parse(location, string(content))
-		if len(parsedLocations) > 0 {
-			s.parseAndFetchUrlsSequential(parsedLocations)
-		}
+		func() {
+			defer s.recoverPanic(location)
+			content, err := s.fetchAndUnzip(location)
+			if err != nil {
+				return
+			}
+			parsedLocations := s.parse(location, string(content))
+			if len(parsedLocations) > 0 {
+				s.parseAndFetchUrlsSequential(parsedLocations)
+			}
+		}()
 	}
 }
 
 // parse parses the provided URL and its content.
-// It determines whether the content is a sitemap index or a sitemap.
+// It determines whether the content is a sitemap index or a sitemap by its root element, and decodes it accordingly.
 // If it is a sitemap index, it adds the URLs from the sitemap index to the sitemap locations.
-// If it is a sitemap, it adds the URLs from the sitemap to the URL list.
-// If the content is neither a sitemap index nor a sitemap, it adds an error to the error list.
+// If it is a sitemap, it adds the URLs from the sitemap to the URL list, unless collecting URLs is turned off.
+// The locations are repaired (see SetEncodeLocs) and rewritten first, see SetURLRewriter and SetSitemapURLRewriter;
+// the scheme-relative locations of the sitemaps ("//host/path") are resolved with the scheme of the URL before the sitemap URL rewriter.
+// If the content is neither a sitemap index nor a sitemap, or it cannot be decoded, it adds an error to the error list,
+// ErrDoctypeNotAllowed for a document with a DOCTYPE declaration, and a DecodeLimitError for a document exceeding a limit of the decoding.
+// The node of the URL in the sitemap tree is updated accordingly.
 // It returns a slice of sitemap locations that were added.
 func (s *S) parse(url string, content string) []string {
-	smIndex, errSitemapIndex := s.parseSitemapIndex(content)
-	urlSet, errURLSet := s.parseURLSet(content)
-	_ = urlSet
+	var smIndex SitemapIndex
+	var urlSet URLSet
+	var err error
+	decodingStart := time.Now()
+	kind := detectKind(content)
+	switch kind {
+	case SitemapKindIndex:
+		smIndex, err = s.parseSitemapIndex(content)
+	case SitemapKindURLSet:
+		if s.cfg.skipURLs || s.cfg.locOnly {
+			urlSet, err = s.parseURLSetLocs(content)
+		} else {
+			urlSet, err = s.parseURLSet(content)
+		}
+	}
+	if err != nil {
+		kind = SitemapKindUnknown
+	}
+	s.addTiming(&s.timing.Decoding, decodingStart)
+	decoded := len(smIndex.Sitemap) + len(urlSet.URL)
+	// the locations are repaired and rewritten without holding the lock, the rewriters may be slow
+	encodedLocs := append(encodeSitemapLocs(url, smIndex.Sitemap), s.encodeURLLocs(url, urlSet.URL)...)
+	resolveSitemapLocs(url, smIndex.Sitemap)
+	if s.isFSLocation(url) {
+		resolveFSLocs(url, smIndex.Sitemap)
+	}
+	smIndex.Sitemap = s.rewriteSitemaps(smIndex.Sitemap)
+	urlSet.URL = s.rewriteURLs(urlSet.URL)
+	// the custom matchers may be slow as well, e.g. consult a database
+	var unmatchedSitemaps []string
+	var unmatchedURLs int
+	smIndex.Sitemap, unmatchedSitemaps = s.matchSitemaps(smIndex.Sitemap)
+	urlSet.URL, unmatchedURLs = s.matchURLs(urlSet.URL)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, warning := range encodedLocs {
+		s.appendWarning(warning)
+	}
+	for _, loc := range unmatchedSitemaps {
+		s.filterSitemap(loc, FilterSitemapMatcher)
+	}
+	for i := 0; i < unmatchedURLs; i++ {
+		s.filterURL(FilterURLMatcher)
+	}
+	for _, unexpected := range urlSet.unexpected {
+		unexpected.Location = url
+		s.appendWarning(locationError(url, unexpected))
+	}
+
+	node := s.node(url)
+	node.fetched = true
+	urlCount := node.URLCount
 	var sitemapLocationsAdded []string
-	if errSitemapIndex == nil && errURLSet != nil {
+	if kind == SitemapKindIndex {
 		// SitemapIndex
+		node.Kind = SitemapKindIndex
 		s.sitemapLocations = append(s.sitemapLocations, url)
+		var indexSitemaps []IndexSitemap
 		for _, sitemapIndexSitemap := range smIndex.Sitemap {
 			// Check if the sitemapIndexSitemap.Loc matches any of the regular expressions in s.cfg.followRegexes.
-			matches := false
-			if len(s.cfg.followRegexes) > 0 {
-				for _, re := range s.cfg.followRegexes {
-					if re.MatchString(sitemapIndexSitemap.Loc) {
-						matches = true
-						break
-					}
-				}
-			} else {
-				matches = true
+			if !s.followMatcher.match(sitemapIndexSitemap.Loc) {
+				s.filterSitemap(sitemapIndexSitemap.Loc, FilterFollow)
+				continue
+			}
+			if !s.sitemapLastModAllowed(sitemapIndexSitemap.LastMod) {
+				s.skippedSitemaps++
+				s.filterSitemap(sitemapIndexSitemap.Loc, FilterSitemapLastMod)
+				continue
+			}
+			indexSitemaps = append(indexSitemaps, sitemapIndexSitemap)
+		}
+		if s.cfg.maxSitemapsByLastMod > 0 && len(indexSitemaps) > s.cfg.maxSitemapsByLastMod {
+			indexSitemaps = newestSitemaps(indexSitemaps)
+			s.skippedSitemaps += len(indexSitemaps) - s.cfg.maxSitemapsByLastMod
+			for _, sitemapIndexSitemap := range indexSitemaps[s.cfg.maxSitemapsByLastMod:] {
+				s.filterSitemap(sitemapIndexSitemap.Loc, FilterSitemapLastMod)
 			}
-			if !matches {
+			indexSitemaps = indexSitemaps[:s.cfg.maxSitemapsByLastMod]
+		}
+		for _, sitemapIndexSitemap := range indexSitemaps {
+			// a sitemap referencing the index or one of its ancestors is neither added nor fetched again
+			if !s.addChildNode(node, sitemapIndexSitemap.Loc, sitemapIndexSitemap.LastMod) {
 				continue
 			}
 			sitemapLocationsAdded = append(sitemapLocationsAdded, sitemapIndexSitemap.Loc)
 			s.sitemapLocations = append(s.sitemapLocations, sitemapIndexSitemap.Loc)
 		}
-	} else if errSitemapIndex != nil && errURLSet == nil {
+	} else if kind == SitemapKindURLSet {
 		// URLSet
+		node.Kind = SitemapKindURLSet
+		fallbackLastMod := s.fallbackLastMod(url)
 		for _, urlSetURL := range urlSet.URL {
-			// Check if the urlSetURL.Loc matches any of the regular expressions in s.cfg.rulesRegexes.
-			matches := false
-			if len(s.cfg.rulesRegexes) > 0 {
-				for _, re := range s.cfg.rulesRegexes {
-					if re.MatchString(urlSetURL.Loc) {
-						matches = true
-						break
-					}
+			if urlSetURL.LastMod != nil && (node.NewestURLLastMod == nil || urlSetURL.LastMod.After(*node.NewestURLLastMod)) {
+				newest := urlSetURL.LastMod.Time
+				node.NewestURLLastMod = &newest
+			}
+		}
+		for _, urlSetURL := range urlSet.URL {
+			if len(urlSetURL.Loc) > maxLocLength {
+				s.appendWarning(locationError(url, &LocTooLongError{Location: url, Loc: urlSetURL.Loc, Length: len(urlSetURL.Loc), Position: urlSetURL.Position}))
+				if s.cfg.dropLongLocs {
+					s.filterURL(FilterLocLength)
+					continue
 				}
-			} else {
-				matches = true
 			}
-			if !matches {
+			// Check if the urlSetURL.Loc matches any of the regular expressions in s.cfg.rulesRegexes.
+			if !s.rulesMatcher.match(urlSetURL.Loc) {
+				s.filterURL(FilterRules)
+				continue
+			}
+			if s.cfg.maxURLsPerSitemap > 0 && node.URLCount >= int64(s.cfg.maxURLsPerSitemap) {
+				node.Truncated = true
+				break
+			}
+			if !s.cfg.skipURLs && s.cfg.maxURLs > 0 && len(s.urls)+len(s.locs) >= s.cfg.maxURLs {
+				s.truncate(TruncatedByMaxURLs)
+				break
+			}
+			if !s.cfg.skipURLs && !s.reserveMemory(s.storedMemorySize(urlSetURL)) {
+				s.truncate(TruncatedByMemoryBudget)
+				break
+			}
+			node.URLCount++
+			if s.cfg.skipURLs {
 				continue
 			}
-			s.urls = append(s.urls, urlSetURL)
+			if s.cfg.locOnly {
+				s.locs = append(s.locs, urlSetURL.Loc)
+			} else {
+				if urlSetURL.LastMod == nil && fallbackLastMod != nil {
+					urlSetURL.LastMod = fallbackLastMod
+					urlSetURL.LastModInferred = true
+				}
+				urlSetURL.source = url
+				s.urls = append(s.urls, urlSetURL)
+			}
+			s.report = nil
+			s.uniqueURLCount = nil
+		}
+	} else {
+		var limitErr *DecodeLimitError
+		if errors.As(err, &limitErr) {
+			limitErr.Location = url
+		} else if !errors.Is(err, ErrDoctypeNotAllowed) {
+			err = errUnknownContent
 		}
-	} else if errSitemapIndex != nil && errURLSet != nil {
-		s.errs = append(s.errs, errors.New("the content is neither sitemapindex nor sitemap"))
+		s.failedSitemaps++
+		node.Err = err
+		s.appendError(locationError(url, err))
 	}
+	s.recordOutcome(kind == SitemapKindUnknown)
+	if collected := int(node.URLCount - urlCount); collected > 0 && s.cfg.metrics != nil {
+		s.cfg.metrics.IncURLs(collected)
+	}
+	s.traceDocument(url, content, decoded, len(sitemapLocationsAdded)+int(node.URLCount-urlCount))
 	return sitemapLocationsAdded
 }
 
+// detectKind returns the kind of the document by peeking at its root element.
+// It returns SitemapKindIndex for a <sitemapindex> root, SitemapKindURLSet for a <urlset> root, and SitemapKindUnknown otherwise.
+func detectKind(data string) SitemapKind {
+	decoder := xml.NewDecoder(strings.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return SitemapKindUnknown
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			switch start.Name.Local {
+			case "sitemapindex":
+				return SitemapKindIndex
+			case "urlset":
+				return SitemapKindURLSet
+			}
+			return SitemapKindUnknown
+		}
+	}
+}
+
 // parseSitemapIndex parses the sitemap index data and returns a SitemapIndex object and an error.
 // The data parameter contains the XML data of the sitemap index.
 // If the data is empty, it returns an error with the message "sitemapindex is empty".
-// It uses the xml.Unmarshal function to unmarshal the XML data into a SitemapIndex object.
-// The unmarshalling result is stored in the sitemapIndex variable.
-// It returns the sitemapIndex object and any unmarshalling error that occurred.
-func (s *S) parseSitemapIndex(data string) (sitemapIndex, error) {
-	var smIndex sitemapIndex
-
+// Otherwise, it decodes the data like DecodeSitemapIndex, with the options of the parser, and returns its result.
+func (s *S) parseSitemapIndex(data string) (SitemapIndex, error) {
 	if len(data) == 0 {
-		return smIndex, fmt.Errorf("sitemapindex is empty")
+		return SitemapIndex{}, errEmptySitemapIndex
 	}
 
-	err := xml.Unmarshal([]byte(data), &smIndex)
-	return smIndex, err
-
+	return decodeSitemapIndex(strings.NewReader(data), s.decodeOptions())
 }
 
 // parseURLSet takes a string of XML data representing a sitemap and parses it into a URLSet.
 // If the data is empty, it returns an error with the message "sitemap is empty".
-// It uses the xml.Unmarshal function to unmarshal the XML data into the URLSet struct.
-// If there is an error during unmarshalling, it returns the empty URLSet and the unmarshal error.
-// Otherwise, it returns the parsed URLSet and nil error.
+// Otherwise, it decodes the data like DecodeURLSet, with the options of the parser, and returns its result.
 func (s *S) parseURLSet(data string) (URLSet, error) {
+	if len(data) == 0 {
+		return URLSet{}, errEmptyURLSet
+	}
+
+	return decodeURLSet(strings.NewReader(data), s.decodeOptions())
+}
+
+// decodeOptions returns the options of the decoding of the documents, see decodeOptions.
+// With a limit of URLs per sitemap and without rules, the decoding stops after the first entry over the limit,
+// which is enough for parse to record the truncation. With rules, every entry is decoded, as any of them may match.
+func (s *S) decodeOptions() decodeOptions {
+	opts := decodeOptions{lastModFormats: s.cfg.lastModFormats, lastModLocation: s.cfg.lastModLocation, limits: s.cfg.decodeLimits, fields: fieldSet(s.cfg.fields), trackPositions: s.cfg.trackPositions, strictElements: s.cfg.strictElements}
+	if s.cfg.maxURLsPerSitemap > 0 && len(s.cfg.rulesRegexes) == 0 {
+		opts.maxURLs = s.cfg.maxURLsPerSitemap + 1
+	}
+	return opts
+}
+
+// parseURLSetLocs is like parseURLSet, but it decodes only the <loc> of the URLs, skipping all other elements.
+func (s *S) parseURLSetLocs(data string) (URLSet, error) {
 	var urlSet URLSet
 	if len(data) == 0 {
-		return urlSet, fmt.Errorf("sitemap is empty")
+		return urlSet, errEmptyURLSet
 	}
 
-	err := xml.Unmarshal([]byte(data), &urlSet)
-	if err != nil {
+	type urlLoc struct {
+		Loc string `xml:"loc"`
+	}
+	var urlSetLocs struct {
+		XMLName xml.Name `xml:"urlset"`
+		URL     []urlLoc `xml:"url"`
+	}
+	opts := s.decodeOptions()
+	// the other child elements of <url> are dropped before reaching the decoder
+	opts.fields = map[string]bool{string(FieldLoc): true}
+	d, guarded, release := newGuardedDecoder(strings.NewReader(data), opts)
+	defer release()
+	var positions []Position
+	if opts.maxURLs > 0 || opts.trackPositions {
+		name, err := decodeURLElements(d, opts.maxURLs, func(start *xml.StartElement) error {
+			var u urlLoc
+			position := guarded.urlStart
+			if err := d.DecodeElement(&u, start); err != nil {
+				return err
+			}
+			urlSetLocs.URL = append(urlSetLocs.URL, u)
+			if opts.trackPositions {
+				positions = append(positions, position)
+			}
+			return nil
+		})
+		if err != nil {
+			return urlSet, err
+		}
+		urlSetLocs.XMLName = name
+	} else if err := d.Decode(&urlSetLocs); err != nil {
 		return urlSet, err
 	}
 
-	return urlSet, err
+	urlSet.XMLName = urlSetLocs.XMLName
+	urlSet.unexpected = guarded.unexpectedElements()
+	urlSet.URL = make([]URL, len(urlSetLocs.URL))
+	for i, u := range urlSetLocs.URL {
+		urlSet.URL[i].Loc = u.Loc
+		if positions != nil {
+			urlSet.URL[i].Position = &positions[i]
+		}
+	}
+
+	return urlSet, nil
 }
 
 // unzip decompresses the given content using gzip compression.
+// Multi-member streams, such as concatenated gzip files, are read to the end, all members are returned concatenated.
 // It returns the uncompressed content and any error encountered during decompression.
 // If an error occurs and it is not `io.ErrUnexpectedEOF`, the original content is returned.
 func (s *S) unzip(content []byte) ([]byte, error) {
-	reader, err := gzip.NewReader(bytes.NewReader(content))
+	uncompressed, _, err := s.gunzip(content)
+	return uncompressed, err
+}
+
+// gunzip decompresses the given content using gzip compression, see unzip.
+// If the content is truncated, it returns the content decompressed so far and true.
+// If another error occurs, it returns the original content and the error.
+func (s *S) gunzip(content []byte) ([]byte, bool, error) {
+	r := bytes.NewReader(content)
+	reader, err := gzip.NewReader(r)
 	if err != nil {
-		return content, err
+		return content, false, err
 	}
-
 	defer func(reader *gzip.Reader) {
 		_ = reader.Close()
 	}(reader)
 
-	uncompressed, err := io.ReadAll(reader)
-	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
-		return content, err
+	// all members are read, as reading only the first one would silently drop URLs,
+	// but one by one, so that trailing bytes after the last member (e.g. a newline) are ignored as by gzip(1)
+	var uncompressed bytes.Buffer
+	for {
+		reader.Multistream(false)
+		if _, err = io.Copy(&uncompressed, s.limitDecompressed(reader, uncompressed.Len())); err != nil {
+			break
+		}
+		if err = s.checkDecompressedSize(uncompressed.Len()); err != nil {
+			return content, false, err
+		}
+		if !bytes.HasPrefix(content[len(content)-r.Len():], []byte("\x1f\x8b")) {
+			return uncompressed.Bytes(), false, nil
+		}
+		if err = reader.Reset(r); err != nil {
+			break
+		}
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return uncompressed.Bytes(), true, nil
+	}
+	return content, false, err
+}
+
+// inflate decompresses the given content using zlib compression.
+// It returns the uncompressed content and any error encountered during decompression.
+// If an error occurs and it is not `io.ErrUnexpectedEOF`, the original content is returned.
+func (s *S) inflate(content []byte) ([]byte, error) {
+	uncompressed, _, err := s.zinflate(content)
+	return uncompressed, err
+}
+
+// zinflate decompresses the given content using zlib compression, see inflate.
+// If the content is truncated, it returns the content decompressed so far and true.
+// If another error occurs, it returns the original content and the error.
+func (s *S) zinflate(content []byte) ([]byte, bool, error) {
+	reader, err := zlib.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return content, false, err
+	}
+
+	defer func(reader io.ReadCloser) {
+		_ = reader.Close()
+	}(reader)
+
+	return s.readUncompressed(reader, content)
+}
+
+// readUncompressed reads the uncompressed content from the reader of the given compressed content, up to the maximum size, see readDecompressed.
+// If the compressed content is truncated, it returns the content read so far and true.
+// If another error occurs, it returns the compressed content and the error.
+func (s *S) readUncompressed(reader io.Reader, content []byte) ([]byte, bool, error) {
+	uncompressed, err := s.readDecompressed(reader)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return uncompressed, true, nil
+	}
+	if err != nil {
+		return content, false, err
+	}
+	return uncompressed, false, nil
+}
+
+// isZlib reports whether the content starts with a zlib header: the deflate method with a 32K window (0x78),
+// followed by one of the flag bytes of the common compression levels, without a preset dictionary.
+func isZlib(content []byte) bool {
+	if len(content) < 2 || content[0] != 0x78 {
+		return false
+	}
+	switch content[1] {
+	case 0x01, 0x5e, 0x9c, 0xda:
+		return true
+	}
+	return false
+}
+
+// decodeDeflateBody decodes a response body sent with the Content-Encoding: deflate header, up to the maximum size, see readDecompressed.
+// The body is expected to be a zlib stream, as defined by HTTP, but raw deflate streams sent by some servers are accepted as well.
+func (s *S) decodeDeflateBody(body []byte) ([]byte, error) {
+	var reader io.ReadCloser
+	if isZlib(body) {
+		var err error
+		if reader, err = zlib.NewReader(bytes.NewReader(body)); err != nil {
+			return nil, err
+		}
+	} else {
+		reader = flate.NewReader(bytes.NewReader(body))
 	}
+	defer func(reader io.ReadCloser) {
+		_ = reader.Close()
+	}(reader)
+
+	return s.readDecompressed(reader)
+}
 
-	return uncompressed, nil
+// zip compresses the given content using gzip compression, see gzipBytes.
+func (s *S) zip(content []byte) ([]byte, error) {
+	return gzipBytes(content)
 }
 
-// zip compresses the given content using gzip compression.
+// gzipBytes compresses the given content using gzip compression.
 // It returns the compressed content as a byte array.
 // If an error occurs during compression, it returns the original content and the error.
-func (s *S) zip(content []byte) ([]byte, error) {
+func gzipBytes(content []byte) ([]byte, error) {
 	writer := bytes.NewBuffer(nil)
 	gzipWriter := gzip.NewWriter(writer)
 	_, err := gzipWriter.Write(content)
@@ -602,27 +2059,26 @@ func (s *S) zip(content []byte) ([]byte, error) {
 	return compressed, nil
 }
 
-func (l *lastModTime) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
-	var v string
-	err := d.DecodeElement(&v, &start)
-	if err != nil {
-		return err
+// sitemapLastModAllowed reports whether a sitemap of an index with the given lastmod is fetched, see SetMinSitemapLastMod.
+func (s *S) sitemapLastModAllowed(lastMod *time.Time) bool {
+	if s.cfg.minSitemapLastMod.IsZero() {
+		return true
 	}
-
-	var parsedTime time.Time
-	if len(v) == len("2006-01-02") {
-		parsedTime, err = time.Parse("2006-01-02", v)
-		if err != nil {
-			return err
-		}
-	} else {
-		parsedTime, err = time.Parse("2006-01-02T15:04:05-07:00", v)
-		if err != nil {
-			return err
-		}
+	if lastMod == nil {
+		return !s.cfg.skipSitemapsWithoutLastMod
 	}
+	return !lastMod.Before(s.cfg.minSitemapLastMod)
+}
 
-	*l = lastModTime{parsedTime}
-
-	return nil
+// newestSitemaps sorts the sitemaps of an index by lastmod in descending order, the sitemaps without a valid lastmod last.
+// The sort is stable, the sitemaps with equal lastmod values keep their order in the index.
+func newestSitemaps(sitemaps []IndexSitemap) []IndexSitemap {
+	newest := append([]IndexSitemap(nil), sitemaps...)
+	sort.SliceStable(newest, func(i, j int) bool {
+		if (newest[i].LastMod != nil) != (newest[j].LastMod != nil) {
+			return newest[i].LastMod != nil
+		}
+		return newest[i].LastMod != nil && newest[i].LastMod.After(*newest[j].LastMod)
+	})
+	return newest
 }
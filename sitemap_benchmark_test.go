@@ -1,6 +1,10 @@
 package sitemap
 
-import "testing"
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
 
 func Benchmark_New(b *testing.B) {
 	b.Run("New", func(b *testing.B) {
@@ -21,7 +25,7 @@ func Benchmark_Parse(b *testing.B) {
 			s := New()
 			_, err := s.Parse(url, nil)
 			if err != nil {
-				if err.Error() != "Get \"invalid_url\": unsupported protocol scheme \"\"" {
+				if err.Error() != "invalid sitemap URL \"invalid_url\": missing scheme" {
 					b.Error(err)
 				}
 			}
@@ -35,7 +39,7 @@ func Benchmark_Parse(b *testing.B) {
 			s := New()
 			_, err := s.Parse(url, nil)
 			if err != nil {
-				if err.Error() != "received HTTP status 404" {
+				if err.Error() != url+": received HTTP status 404" {
 					b.Error(err)
 				}
 			}
@@ -54,3 +58,107 @@ func Benchmark_Parse(b *testing.B) {
 		}
 	})
 }
+
+func Benchmark_Parse_Fields(b *testing.B) {
+	data := benchmarkURLSet(50000)
+
+	tests := []struct {
+		name string
+		s    func() *S
+	}{
+		{
+			name: "all fields",
+			s:    func() *S { return New() },
+		},
+		{
+			name: "loc and lastmod",
+			s:    func() *S { return New().SetFields(FieldLoc, FieldLastMod) },
+		},
+		{
+			name: "loc only",
+			s:    func() *S { return New().SetLocOnly(true) },
+		},
+	}
+
+	for _, test := range tests {
+		b.Run(test.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				s, err := test.s().Parse("https://www.example.com/sitemap.xml", &data)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if s.GetURLCount() != 50000 {
+					b.Fatalf("expected 50000 URLs, got %d", s.GetURLCount())
+				}
+			}
+		})
+	}
+}
+
+func Benchmark_Parse_Rules(b *testing.B) {
+	data := benchmarkURLSet(50000)
+	// 300 rules of a hundred pages each, matching the pages from 20000 to 49999
+	var rules []string
+	for i := 0; i < 300; i++ {
+		rules = append(rules, fmt.Sprintf(`^https://www\.example\.com/page-%03d\d\d$`, i+200))
+	}
+	regexes := compileAll(b, rules)
+	locs := make([]string, 50000)
+	for i := range locs {
+		locs[i] = fmt.Sprintf("https://www.example.com/page-%05d", i)
+	}
+
+	b.Run("regexes one by one", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			matched := 0
+			for _, loc := range locs {
+				if matchAny(regexes, loc) {
+					matched++
+				}
+			}
+			if matched != 30000 {
+				b.Fatalf("expected 30000 matches, got %d", matched)
+			}
+		}
+	})
+
+	b.Run("matcher", func(b *testing.B) {
+		m := newMatcher(regexes)
+		for i := 0; i < b.N; i++ {
+			matched := 0
+			for _, loc := range locs {
+				if m.match(loc) {
+					matched++
+				}
+			}
+			if matched != 30000 {
+				b.Fatalf("expected 30000 matches, got %d", matched)
+			}
+		}
+	})
+
+	b.Run("parse", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			s, err := New().SetRules(rules).Parse("https://www.example.com/sitemap.xml", &data)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if s.GetURLCount() != 30000 {
+				b.Fatalf("expected 30000 URLs, got %d", s.GetURLCount())
+			}
+		}
+	})
+}
+
+// benchmarkURLSet returns a <urlset> document of n URLs with all the core fields.
+func benchmarkURLSet(n int) string {
+	var content strings.Builder
+	content.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">` + "\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&content, "<url><loc>https://www.example.com/page-%05d</loc><lastmod>2024-02-12T12:34:56+01:00</lastmod><changefreq>daily</changefreq><priority>0.5</priority></url>\n", i)
+	}
+	content.WriteString("</urlset>\n")
+	return content.String()
+}
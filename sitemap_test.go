@@ -2,15 +2,33 @@ package sitemap
 
 import (
 	"bytes"
+	"compress/flate"
 	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/xml"
 	"errors"
 	"fmt"
-	"net/url"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"reflect"
 	"regexp/syntax"
 	"sort"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"testing/fstest"
 	"time"
 )
 
@@ -24,11 +42,12 @@ func TestS_setConfigDefaults(t *testing.T) {
 			name: "default config",
 			s:    &S{},
 			want: config{
-				userAgent:    "go-sitemap-parser (+https://github.com/aafeher/go-sitemap-parser/blob/main/README.md)",
-				fetchTimeout: 3,
-				multiThread:  true,
-				follow:       []string{},
-				rules:        []string{},
+				userAgent:     "go-sitemap-parser (+https://github.com/aafeher/go-sitemap-parser/blob/main/README.md)",
+				fetchTimeout:  3,
+				multiThread:   true,
+				follow:        []string{},
+				rules:         []string{},
+				followIndexes: true,
 			},
 		},
 	}
@@ -97,6 +116,328 @@ func TestS_SetFetchTimeout(t *testing.T) {
 	}
 }
 
+func TestS_SetConnectTimeout(t *testing.T) {
+	tests := []struct {
+		name           string
+		connectTimeout time.Duration
+	}{
+		{
+			name:           "PositiveTimeout",
+			connectTimeout: 2 * time.Second,
+		},
+		{
+			name:           "ZeroTimeout",
+			connectTimeout: 0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New()
+			s.SetConnectTimeout(test.connectTimeout)
+			if s.cfg.connectTimeout != test.connectTimeout {
+				t.Errorf("expected %v, got %v", test.connectTimeout, s.cfg.connectTimeout)
+			}
+		})
+	}
+}
+
+func TestS_fetch_ConnectTimeout(t *testing.T) {
+	// the listener accepts the connections, but never completes the TLS handshake
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	s := New().SetFetchTimeout(3).SetConnectTimeout(200 * time.Millisecond)
+	s.transport = newTransport(s.cfg.connectTimeout, nil)
+
+	start := time.Now()
+	_, _, err = s.fetch(fmt.Sprintf("https://%s/sitemap.xml", listener.Addr()))
+	elapsed := time.Since(start)
+
+	var fetchErr *FetchError
+	if !errors.As(err, &fetchErr) || fetchErr.Category() != ErrorCategoryTimeout {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+	if elapsed > time.Second {
+		t.Errorf("expected to fail within the connect timeout, took %v", elapsed)
+	}
+}
+
+func TestS_Parse_ReusesTransport(t *testing.T) {
+	content, err := os.ReadFile("./test/sitemap-02.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var connections int64
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(content)
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt64(&connections, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	s := New().SetConnectTimeout(time.Second)
+	for i := 0; i < 3; i++ {
+		if _, err := s.Parse(server.URL+"/sitemap.xml", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	transport := s.transport
+	if transport == nil {
+		t.Fatal("expected a transport to be built")
+	}
+	if got := atomic.LoadInt64(&connections); got != 1 {
+		t.Errorf("expected the parses to share 1 connection, got %d", got)
+	}
+
+	s.SetConnectTimeout(2 * time.Second)
+	if s.transport != nil {
+		t.Fatal("expected the transport to be dropped when the connect timeout changes")
+	}
+	if _, err := s.Parse(server.URL+"/sitemap.xml", nil); err != nil {
+		t.Fatal(err)
+	}
+	if s.transport == nil || s.transport == transport {
+		t.Errorf("expected a new transport, got %v", s.transport)
+	}
+}
+
+func TestNewTransport(t *testing.T) {
+	if transport := newTransport(0, nil); transport != nil {
+		t.Errorf("expected nil transport, got %v", transport)
+	}
+
+	transport, ok := newTransport(2*time.Second, nil).(*http.Transport)
+	if !ok {
+		t.Fatal("expected *http.Transport")
+	}
+	if transport.TLSHandshakeTimeout != 2*time.Second {
+		t.Errorf("expected TLS handshake timeout %v, got %v", 2*time.Second, transport.TLSHandshakeTimeout)
+	}
+	if transport.DialContext == nil {
+		t.Error("expected DialContext to be set")
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	transport, ok = newTransport(0, tlsConfig).(*http.Transport)
+	if !ok {
+		t.Fatal("expected *http.Transport")
+	}
+	if transport.TLSClientConfig != tlsConfig {
+		t.Errorf("expected TLS config %v, got %v", tlsConfig, transport.TLSClientConfig)
+	}
+}
+
+func TestS_SetTLSConfig(t *testing.T) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	s := New().SetTLSConfig(tlsConfig)
+	if s.cfg.tlsConfig == tlsConfig || s.cfg.tlsConfig.MinVersion != tls.VersionTLS12 {
+		t.Errorf("expected a copy of %v, got %v", tlsConfig, s.cfg.tlsConfig)
+	}
+
+	s.SetTLSConfig(nil)
+	if s.cfg.tlsConfig != nil {
+		t.Errorf("expected nil, got %v", s.cfg.tlsConfig)
+	}
+
+	s.SetTLSConfig(tlsConfig).initTransport()
+	if s.transport == nil {
+		t.Fatal("expected a transport to be built")
+	}
+	s.SetTLSConfig(&tls.Config{MinVersion: tls.VersionTLS13})
+	if s.transport != nil {
+		t.Errorf("expected the transport to be dropped when the TLS configuration changes, got %v", s.transport)
+	}
+}
+
+func TestS_SetClientCertificate(t *testing.T) {
+	cert, _ := newTestCertificate(t, "client")
+
+	s := New().SetClientCertificate(cert)
+	if s.cfg.clientCertificate == nil || !reflect.DeepEqual(*s.cfg.clientCertificate, cert) {
+		t.Errorf("expected %v, got %v", cert, s.cfg.clientCertificate)
+	}
+
+	s.initTransport()
+	if s.transport == nil {
+		t.Fatal("expected a transport to be built")
+	}
+	s.SetClientCertificate(cert)
+	if s.transport != nil {
+		t.Errorf("expected the transport to be dropped when the client certificate changes, got %v", s.transport)
+	}
+}
+
+func TestS_tlsClientConfig(t *testing.T) {
+	cert, _ := newTestCertificate(t, "client")
+	otherCert, _ := newTestCertificate(t, "other")
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{otherCert}}
+
+	tests := []struct {
+		name             string
+		s                *S
+		wantNil          bool
+		wantCertificates int
+	}{
+		{
+			name:    "defaults",
+			s:       New(),
+			wantNil: true,
+		},
+		{
+			name:             "TLS config",
+			s:                New().SetTLSConfig(tlsConfig),
+			wantCertificates: 1,
+		},
+		{
+			name:             "client certificate",
+			s:                New().SetClientCertificate(cert),
+			wantCertificates: 1,
+		},
+		{
+			name:             "TLS config and client certificate",
+			s:                New().SetTLSConfig(tlsConfig).SetClientCertificate(cert),
+			wantCertificates: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.s.tlsClientConfig()
+			if test.wantNil {
+				if got != nil {
+					t.Errorf("expected nil, got %v", got)
+				}
+				return
+			}
+			if got == nil || len(got.Certificates) != test.wantCertificates {
+				t.Fatalf("expected %d certificates, got %v", test.wantCertificates, got)
+			}
+		})
+	}
+	if len(tlsConfig.Certificates) != 1 {
+		t.Errorf("expected the TLS config to be unchanged, got %d certificates", len(tlsConfig.Certificates))
+	}
+}
+
+func TestS_Parse_ClientCertificate(t *testing.T) {
+	cert, caCert := newTestCertificate(t, "client")
+	otherCert, _ := newTestCertificate(t, "other")
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(caCert)
+
+	content, err := os.ReadFile("./test/sitemap-02.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(content)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(server.Certificate())
+
+	tests := []struct {
+		name     string
+		s        *S
+		wantURLs int64
+		wantErr  bool
+	}{
+		{
+			name:     "client certificate",
+			s:        New().SetTLSConfig(&tls.Config{RootCAs: rootCAs}).SetClientCertificate(cert),
+			wantURLs: 2,
+		},
+		{
+			name:    "no client certificate",
+			s:       New().SetTLSConfig(&tls.Config{RootCAs: rootCAs}),
+			wantErr: true,
+		},
+		{
+			name:    "untrusted client certificate",
+			s:       New().SetTLSConfig(&tls.Config{RootCAs: rootCAs}).SetClientCertificate(otherCert),
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			location := server.URL + "/sitemap.xml"
+			s, err := test.s.Parse(location, nil)
+			if s.GetURLCount() != test.wantURLs {
+				t.Errorf("expected %d URLs, got %d", test.wantURLs, s.GetURLCount())
+			}
+			if !test.wantErr {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var fetchErr *FetchError
+			if !errors.As(err, &fetchErr) {
+				t.Fatalf("expected a fetch error, got %v", err)
+			}
+			if fetchErr.Category() != ErrorCategoryTLS {
+				t.Errorf("expected category %s, got %s", ErrorCategoryTLS, fetchErr.Category())
+			}
+			if fetchErr.URL != location || !strings.Contains(err.Error(), location) {
+				t.Errorf("expected the location in %v", err)
+			}
+		})
+	}
+}
+
+// newTestCertificate returns a self-signed client certificate with the given common name, and its parsed form to trust it.
+func newTestCertificate(t *testing.T, commonName string) (tls.Certificate, *x509.Certificate) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: parsed}, parsed
+}
+
 func TestS_SetMultiThread(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -122,6 +463,232 @@ func TestS_SetMultiThread(t *testing.T) {
 	}
 }
 
+func TestS_SetRateLimit(t *testing.T) {
+	tests := []struct {
+		name string
+		rps  float64
+	}{
+		{
+			name: "NoLimit",
+			rps:  0,
+		},
+		{
+			name: "Limit",
+			rps:  2.5,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New()
+			s.SetRateLimit(test.rps)
+			if s.cfg.rateLimit != test.rps {
+				t.Errorf("expected %v, got %v", test.rps, s.cfg.rateLimit)
+			}
+		})
+	}
+}
+
+func TestS_SetPerHostRateLimit(t *testing.T) {
+	tests := []struct {
+		name string
+		rps  float64
+	}{
+		{
+			name: "NoLimit",
+			rps:  0,
+		},
+		{
+			name: "Limit",
+			rps:  2.5,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New()
+			s.SetPerHostRateLimit(test.rps)
+			if s.cfg.perHostRateLimit != test.rps {
+				t.Errorf("expected %v, got %v", test.rps, s.cfg.perHostRateLimit)
+			}
+		})
+	}
+}
+
+func TestS_SetRequestDelay(t *testing.T) {
+	tests := []struct {
+		name   string
+		delay  time.Duration
+		jitter time.Duration
+	}{
+		{
+			name: "NoDelay",
+		},
+		{
+			name:   "DelayWithJitter",
+			delay:  2 * time.Second,
+			jitter: 500 * time.Millisecond,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New()
+			s.SetRequestDelay(test.delay, test.jitter)
+			if s.cfg.requestDelay != test.delay || s.cfg.requestDelayJitter != test.jitter {
+				t.Errorf("expected %v, %v, got %v, %v", test.delay, test.jitter, s.cfg.requestDelay, s.cfg.requestDelayJitter)
+			}
+		})
+	}
+}
+
+func TestS_SetCircuitBreaker(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold int
+	}{
+		{
+			name:      "Disabled",
+			threshold: 0,
+		},
+		{
+			name:      "Threshold",
+			threshold: 5,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New()
+			s.SetCircuitBreaker(test.threshold)
+			if s.cfg.circuitBreakerThreshold != test.threshold {
+				t.Errorf("expected %d, got %d", test.threshold, s.cfg.circuitBreakerThreshold)
+			}
+		})
+	}
+}
+
+func TestS_SetFollowIndexes(t *testing.T) {
+	tests := []struct {
+		name          string
+		followIndexes bool
+	}{
+		{
+			name:          "Follow",
+			followIndexes: true,
+		},
+		{
+			name:          "SingleDocument",
+			followIndexes: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New()
+			s.SetFollowIndexes(test.followIndexes)
+			if s.cfg.followIndexes != test.followIndexes {
+				t.Errorf("expected %v, got %v", test.followIndexes, s.cfg.followIndexes)
+			}
+		})
+	}
+}
+
+func TestS_SetCollectURLs(t *testing.T) {
+	tests := []struct {
+		name        string
+		collectURLs bool
+	}{
+		{
+			name:        "Collect",
+			collectURLs: true,
+		},
+		{
+			name:        "IndexOnly",
+			collectURLs: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New()
+			s.SetCollectURLs(test.collectURLs)
+			if s.cfg.skipURLs == test.collectURLs {
+				t.Errorf("expected skipURLs %v, got %v", !test.collectURLs, s.cfg.skipURLs)
+			}
+		})
+	}
+}
+
+func TestS_SetMaxURLs(t *testing.T) {
+	tests := []struct {
+		name    string
+		maxURLs int
+	}{
+		{
+			name:    "NoLimit",
+			maxURLs: 0,
+		},
+		{
+			name:    "Limit",
+			maxURLs: 1000,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New()
+			s.SetMaxURLs(test.maxURLs)
+			if s.cfg.maxURLs != test.maxURLs {
+				t.Errorf("expected %d, got %d", test.maxURLs, s.cfg.maxURLs)
+			}
+		})
+	}
+}
+
+func TestS_SetMaxURLsPerSitemap(t *testing.T) {
+	tests := []struct {
+		name              string
+		maxURLsPerSitemap int
+	}{
+		{
+			name:              "NoLimit",
+			maxURLsPerSitemap: 0,
+		},
+		{
+			name:              "Limit",
+			maxURLsPerSitemap: 50,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New()
+			s.SetMaxURLsPerSitemap(test.maxURLsPerSitemap)
+			if s.cfg.maxURLsPerSitemap != test.maxURLsPerSitemap {
+				t.Errorf("expected %d, got %d", test.maxURLsPerSitemap, s.cfg.maxURLsPerSitemap)
+			}
+		})
+	}
+}
+
+func TestS_SetMaxSitemaps(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxSitemaps int
+	}{
+		{
+			name:        "NoLimit",
+			maxSitemaps: 0,
+		},
+		{
+			name:        "Limit",
+			maxSitemaps: 10,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New()
+			s.SetMaxSitemaps(test.maxSitemaps)
+			if s.cfg.maxSitemaps != test.maxSitemaps {
+				t.Errorf("expected %d, got %d", test.maxSitemaps, s.cfg.maxSitemaps)
+			}
+		})
+	}
+}
+
 func TestS_Parse(t *testing.T) {
 	server := testServer()
 	defer server.Close()
@@ -156,18 +723,12 @@ func TestS_Parse(t *testing.T) {
 			multiThread:          true,
 			follow:               []string{},
 			rules:                []string{},
-			err:                  pointerOfString("Get \"invalid_url\": unsupported protocol scheme \"\""),
+			err:                  pointerOfString("invalid sitemap URL \"invalid_url\": missing scheme"),
 			mainURLContent:       pointerOfString(""),
 			robotsTxtSitemapURLs: nil,
 			sitemapLocations:     nil,
 			urls:                 nil,
-			errs: []error{
-				&url.Error{
-					Op:  "Get",
-					URL: "invalid_url",
-					Err: errors.New("unsupported protocol scheme \"\""),
-				},
-			},
+			errs:                 []error{errors.New("invalid sitemap URL \"invalid_url\": missing scheme")},
 		},
 		{
 			name:                 "testServer index page",
@@ -175,12 +736,12 @@ func TestS_Parse(t *testing.T) {
 			multiThread:          false,
 			follow:               []string{},
 			rules:                []string{},
-			err:                  pointerOfString("received HTTP status 404"),
+			err:                  pointerOfString(fmt.Sprintf("%s: received HTTP status 404", server.URL)),
 			mainURLContent:       pointerOfString(""),
 			robotsTxtSitemapURLs: nil,
 			sitemapLocations:     nil,
 			urls:                 nil,
-			errs:                 []error{errors.New("received HTTP status 404")},
+			errs:                 []error{locationError(server.URL, &FetchError{URL: server.URL, StatusCode: 404, Err: errors.New("received HTTP status 404")})},
 		},
 		{
 			name:                 "page not found",
@@ -188,12 +749,12 @@ func TestS_Parse(t *testing.T) {
 			multiThread:          true,
 			follow:               []string{},
 			rules:                []string{},
-			err:                  pointerOfString("received HTTP status 404"),
+			err:                  pointerOfString(fmt.Sprintf("%s/404: received HTTP status 404", server.URL)),
 			mainURLContent:       pointerOfString(""),
 			robotsTxtSitemapURLs: nil,
 			sitemapLocations:     nil,
 			urls:                 nil,
-			errs:                 []error{errors.New("received HTTP status 404")},
+			errs:                 []error{locationError(fmt.Sprintf("%s/404", server.URL), &FetchError{URL: fmt.Sprintf("%s/404", server.URL), StatusCode: 404, Err: errors.New("received HTTP status 404")})},
 		},
 
 		// robots.txt
@@ -248,37 +809,37 @@ func TestS_Parse(t *testing.T) {
 			urls: []URL{
 				{
 					Loc:        fmt.Sprintf("%s/page-01", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqAlways),
 					Priority:   pointerOfFloat32(0.5),
 				},
 				{
 					Loc:        fmt.Sprintf("%s/page-02", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqHourly),
 					Priority:   pointerOfFloat32(0.5),
 				},
 				{
 					Loc:        fmt.Sprintf("%s/page-03", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqDaily),
 					Priority:   pointerOfFloat32(0.5),
 				},
 				{
 					Loc:        fmt.Sprintf("%s/page-04", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 0, 0, 0, 0, timeLocationUTC)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 0, 0, 0, 0, timeLocationUTC)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqWeekly),
 					Priority:   pointerOfFloat32(0.5),
 				},
 				{
 					Loc:        fmt.Sprintf("%s/page-05", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqMonthly),
 					Priority:   pointerOfFloat32(0.5),
 				},
 				{
 					Loc:        fmt.Sprintf("%s/page-06", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqYearly),
 					Priority:   pointerOfFloat32(0.5),
 				},
@@ -308,73 +869,73 @@ func TestS_Parse(t *testing.T) {
 			urls: []URL{
 				{
 					Loc:        fmt.Sprintf("%s/page-01", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqAlways),
 					Priority:   pointerOfFloat32(0.5),
 				},
 				{
 					Loc:        fmt.Sprintf("%s/page-02", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqHourly),
 					Priority:   pointerOfFloat32(0.5),
 				},
 				{
 					Loc:        fmt.Sprintf("%s/page-03", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqDaily),
 					Priority:   pointerOfFloat32(0.5),
 				},
 				{
 					Loc:        fmt.Sprintf("%s/page-04", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 0, 0, 0, 0, timeLocationUTC)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 0, 0, 0, 0, timeLocationUTC)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqWeekly),
 					Priority:   pointerOfFloat32(0.5),
 				},
 				{
 					Loc:        fmt.Sprintf("%s/page-05", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqMonthly),
 					Priority:   pointerOfFloat32(0.5),
 				},
 				{
 					Loc:        fmt.Sprintf("%s/page-06", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqYearly),
 					Priority:   pointerOfFloat32(0.5),
 				},
 				{
 					Loc:        fmt.Sprintf("%s/page-07", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqNever),
 					Priority:   pointerOfFloat32(0.5),
 				},
 				{
 					Loc:        fmt.Sprintf("%s/page-08", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqMonthly),
 					Priority:   pointerOfFloat32(0.5),
 				},
 				{
 					Loc:        fmt.Sprintf("%s/page-09", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqMonthly),
 					Priority:   pointerOfFloat32(0.5),
 				},
 				{
 					Loc:        fmt.Sprintf("%s/page-10", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqMonthly),
 					Priority:   pointerOfFloat32(0.5),
 				},
 				{
 					Loc:        fmt.Sprintf("%s/page-11", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqMonthly),
 					Priority:   pointerOfFloat32(0.5),
 				},
 				{
 					Loc:        fmt.Sprintf("%s/page-12", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqMonthly),
 					Priority:   pointerOfFloat32(0.5),
 				},
@@ -390,7 +951,7 @@ func TestS_Parse(t *testing.T) {
 			robotsTxtSitemapURLs: []string{fmt.Sprintf("%s/invalid.xml", server.URL)},
 			sitemapLocations:     nil,
 			urls:                 nil,
-			errs:                 []error{errors.New("received HTTP status 404")},
+			errs:                 []error{locationError(fmt.Sprintf("%s/invalid.xml", server.URL), &FetchError{URL: fmt.Sprintf("%s/invalid.xml", server.URL), StatusCode: 404, Err: errors.New("received HTTP status 404")})},
 		},
 		{
 			name:                 "robots.txt with sitemapindex.xml.gz",
@@ -409,37 +970,37 @@ func TestS_Parse(t *testing.T) {
 			urls: []URL{
 				{
 					Loc:        fmt.Sprintf("%s/page-01", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqAlways),
 					Priority:   pointerOfFloat32(0.5),
 				},
 				{
 					Loc:        fmt.Sprintf("%s/page-02", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqHourly),
 					Priority:   pointerOfFloat32(0.5),
 				},
 				{
 					Loc:        fmt.Sprintf("%s/page-03", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqDaily),
 					Priority:   pointerOfFloat32(0.5),
 				},
 				{
 					Loc:        fmt.Sprintf("%s/page-04", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 0, 0, 0, 0, timeLocationUTC)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 0, 0, 0, 0, timeLocationUTC)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqWeekly),
 					Priority:   pointerOfFloat32(0.5),
 				},
 				{
 					Loc:        fmt.Sprintf("%s/page-05", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqMonthly),
 					Priority:   pointerOfFloat32(0.5),
 				},
 				{
 					Loc:        fmt.Sprintf("%s/page-06", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqYearly),
 					Priority:   pointerOfFloat32(0.5),
 				},
@@ -457,7 +1018,7 @@ func TestS_Parse(t *testing.T) {
 			robotsTxtSitemapURLs: nil,
 			sitemapLocations:     nil,
 			urls:                 nil,
-			errs:                 []error{errors.New("the content is neither sitemapindex nor sitemap")},
+			errs:                 []error{locationError(fmt.Sprintf("%s/sitemapindex-empty-corrupted.xml.gz", server.URL), errors.New("the content is neither sitemapindex nor sitemap"))},
 		},
 		{
 			name:                 "sitemapindex.xml.gz empty file",
@@ -469,7 +1030,7 @@ func TestS_Parse(t *testing.T) {
 			robotsTxtSitemapURLs: nil,
 			sitemapLocations:     nil,
 			urls:                 nil,
-			errs:                 []error{errors.New("the content is neither sitemapindex nor sitemap")},
+			errs:                 []error{locationError(fmt.Sprintf("%s/sitemapindex-empty.xml.gz", server.URL), errors.New("the content is neither sitemapindex nor sitemap"))},
 		},
 		{
 			name:                 "sitemapindex.xml.gz",
@@ -488,37 +1049,37 @@ func TestS_Parse(t *testing.T) {
 			urls: []URL{
 				{
 					Loc:        fmt.Sprintf("%s/page-01", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqAlways),
 					Priority:   pointerOfFloat32(0.5),
 				},
 				{
 					Loc:        fmt.Sprintf("%s/page-02", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqHourly),
 					Priority:   pointerOfFloat32(0.5),
 				},
 				{
 					Loc:        fmt.Sprintf("%s/page-03", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqDaily),
 					Priority:   pointerOfFloat32(0.5),
 				},
 				{
 					Loc:        fmt.Sprintf("%s/page-04", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 0, 0, 0, 0, timeLocationUTC)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 0, 0, 0, 0, timeLocationUTC)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqWeekly),
 					Priority:   pointerOfFloat32(0.5),
 				},
 				{
 					Loc:        fmt.Sprintf("%s/page-05", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqMonthly),
 					Priority:   pointerOfFloat32(0.5),
 				},
 				{
 					Loc:        fmt.Sprintf("%s/page-06", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqYearly),
 					Priority:   pointerOfFloat32(0.5),
 				},
@@ -536,7 +1097,7 @@ func TestS_Parse(t *testing.T) {
 			robotsTxtSitemapURLs: nil,
 			sitemapLocations:     nil,
 			urls:                 nil,
-			errs:                 []error{errors.New("the content is neither sitemapindex nor sitemap")},
+			errs:                 []error{locationError(fmt.Sprintf("%s/sitemap-empty.xml.gz", server.URL), errors.New("the content is neither sitemapindex nor sitemap"))},
 		},
 		{
 			name:                 "sitemap.xml.gz",
@@ -550,13 +1111,13 @@ func TestS_Parse(t *testing.T) {
 			urls: []URL{
 				{
 					Loc:        fmt.Sprintf("%s/page-02", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqHourly),
 					Priority:   pointerOfFloat32(0.5),
 				},
 				{
 					Loc:        fmt.Sprintf("%s/page-03", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqDaily),
 					Priority:   pointerOfFloat32(0.5),
 				},
@@ -574,7 +1135,7 @@ func TestS_Parse(t *testing.T) {
 			robotsTxtSitemapURLs: nil,
 			sitemapLocations:     nil,
 			urls:                 nil,
-			errs:                 []error{errors.New("the content is neither sitemapindex nor sitemap")},
+			errs:                 []error{locationError(fmt.Sprintf("%s/sitemapindex-empty.xml", server.URL), errors.New("the content is neither sitemapindex nor sitemap"))},
 		},
 		{
 			name:                 "sitemapindex.xml empty content",
@@ -587,7 +1148,7 @@ func TestS_Parse(t *testing.T) {
 			robotsTxtSitemapURLs: nil,
 			sitemapLocations:     nil,
 			urls:                 nil,
-			errs:                 []error{errors.New("the content is neither sitemapindex nor sitemap")},
+			errs:                 []error{locationError(fmt.Sprintf("%s/sitemapindex-empty.xml", server.URL), errors.New("the content is neither sitemapindex nor sitemap"))},
 		},
 		{
 			name:                 "sitemapindex.xml",
@@ -606,37 +1167,37 @@ func TestS_Parse(t *testing.T) {
 			urls: []URL{
 				{
 					Loc:        fmt.Sprintf("%s/page-01", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqAlways),
 					Priority:   pointerOfFloat32(0.5),
 				},
 				{
 					Loc:        fmt.Sprintf("%s/page-02", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqHourly),
 					Priority:   pointerOfFloat32(0.5),
 				},
 				{
 					Loc:        fmt.Sprintf("%s/page-03", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqDaily),
 					Priority:   pointerOfFloat32(0.5),
 				},
 				{
 					Loc:        fmt.Sprintf("%s/page-04", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 0, 0, 0, 0, timeLocationUTC)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 0, 0, 0, 0, timeLocationUTC)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqWeekly),
 					Priority:   pointerOfFloat32(0.5),
 				},
 				{
 					Loc:        fmt.Sprintf("%s/page-05", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqMonthly),
 					Priority:   pointerOfFloat32(0.5),
 				},
 				{
 					Loc:        fmt.Sprintf("%s/page-06", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqYearly),
 					Priority:   pointerOfFloat32(0.5),
 				},
@@ -656,7 +1217,7 @@ func TestS_Parse(t *testing.T) {
 				fmt.Sprintf("%s/invalid.xml", server.URL),
 			},
 			urls: nil,
-			errs: []error{errors.New("received HTTP status 404")},
+			errs: []error{locationError(fmt.Sprintf("%s/invalid.xml", server.URL), &FetchError{URL: fmt.Sprintf("%s/invalid.xml", server.URL), StatusCode: 404, Err: errors.New("received HTTP status 404")})},
 		},
 		{
 			name:                 "sitemapindex with follow and rules",
@@ -674,13 +1235,13 @@ func TestS_Parse(t *testing.T) {
 			urls: []URL{
 				{
 					Loc:        fmt.Sprintf("%s/page-alpha-01", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqAlways),
 					Priority:   pointerOfFloat32(0.5),
 				},
 				{
 					Loc:        fmt.Sprintf("%s/page-alpha-02", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
 					ChangeFreq: pointerOfURLChangeFreq(changeFreqHourly),
 					Priority:   pointerOfFloat32(0.5),
 				},
@@ -705,112 +1266,668 @@ func TestS_Parse(t *testing.T) {
 			},
 		},
 		{
-			name:                 "sitemapindex with follow error",
-			url:                  "",
-			multiThread:          false,
-			follow:               []string{`(`},
-			rules:                []string{},
-			err:                  pointerOfString("errors occurred before parsing, see GetErrors() for details"),
-			mainURLContent:       pointerOfString(""),
-			robotsTxtSitemapURLs: nil,
-			sitemapLocations:     nil,
-			urls:                 nil,
-			errs: []error{
-				&syntax.Error{
-					Code: syntax.ErrorCode("missing closing )"),
-					Expr: "(",
-				},
+			name:                 "sitemapindex with follow error",
+			url:                  "",
+			multiThread:          false,
+			follow:               []string{`(`},
+			rules:                []string{},
+			err:                  pointerOfString("errors occurred before parsing, see GetErrors() for details"),
+			mainURLContent:       pointerOfString(""),
+			robotsTxtSitemapURLs: nil,
+			sitemapLocations:     nil,
+			urls:                 nil,
+			errs: []error{
+				&syntax.Error{
+					Code: syntax.ErrorCode("missing closing )"),
+					Expr: "(",
+				},
+			},
+		},
+
+		// sitemap
+		{
+			name:                 "sitemap.xml empty file",
+			url:                  fmt.Sprintf("%s/sitemap-empty.xml", server.URL),
+			multiThread:          false,
+			follow:               []string{},
+			rules:                []string{},
+			mainURLContent:       pointerOfString("\n"),
+			robotsTxtSitemapURLs: nil,
+			sitemapLocations:     nil,
+			urls:                 nil,
+			errs:                 []error{locationError(fmt.Sprintf("%s/sitemap-empty.xml", server.URL), errors.New("the content is neither sitemapindex nor sitemap"))},
+		},
+		{
+			name:                 "sitemap.xml empty content",
+			url:                  fmt.Sprintf("%s/sitemap-empty.xml", server.URL),
+			multiThread:          true,
+			follow:               []string{},
+			rules:                []string{},
+			content:              pointerOfString("\n"),
+			mainURLContent:       pointerOfString("\n"),
+			robotsTxtSitemapURLs: nil,
+			sitemapLocations:     nil,
+			urls:                 nil,
+			errs:                 []error{locationError(fmt.Sprintf("%s/sitemap-empty.xml", server.URL), errors.New("the content is neither sitemapindex nor sitemap"))},
+		},
+		{
+			name:                 "sitemap.xml",
+			url:                  fmt.Sprintf("%s/sitemap-02.xml.gz", server.URL),
+			multiThread:          false,
+			follow:               []string{},
+			rules:                []string{},
+			mainURLContent:       pointerOfString(fmt.Sprintf("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<urlset xmlns=\"http://www.sitemaps.org/schemas/sitemap/0.9\">\n    <url>\n        <loc>%s/page-02</loc>\n        <lastmod>2024-02-12T12:34:56+01:00</lastmod>\n        <changefreq>hourly</changefreq>\n        <priority>0.5</priority>\n    </url>\n    <url>\n        <loc>%s/page-03</loc>\n        <lastmod>2024-02-12T12:34:56+01:00</lastmod>\n        <changefreq>daily</changefreq>\n        <priority>0.5</priority>\n    </url>\n</urlset>\n", server.URL, server.URL)),
+			robotsTxtSitemapURLs: nil,
+			sitemapLocations:     nil,
+			urls: []URL{
+				{
+					Loc:        fmt.Sprintf("%s/page-02", server.URL),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					ChangeFreq: pointerOfURLChangeFreq(changeFreqHourly),
+					Priority:   pointerOfFloat32(0.5),
+				},
+				{
+					Loc:        fmt.Sprintf("%s/page-03", server.URL),
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
+					ChangeFreq: pointerOfURLChangeFreq(changeFreqDaily),
+					Priority:   pointerOfFloat32(0.5),
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New()
+			sitemap, err := s.SetMultiThread(test.multiThread).SetFollow(test.follow).SetRules(test.rules).Parse(test.url, test.content)
+			if err != nil {
+				if err.Error() != *test.err {
+					t.Errorf("Unexpected error: %v", err)
+				}
+			}
+
+			if sitemap == nil {
+				t.Fatal("Expected not nil object, but got nil")
+			}
+			if sitemap.mainURL != test.url {
+				t.Fatalf("Expected URL to be %s, but got %s", test.url, sitemap.mainURL)
+			}
+
+			if !reflect.DeepEqual(sitemap.mainURLContent, *test.mainURLContent) {
+				t.Error("mainURLContent is not equal to expected value")
+			}
+			if !reflect.DeepEqual(sitemap.robotsTxtSitemapURLs, test.robotsTxtSitemapURLs) {
+				t.Error("robotsTxtSitemapURLs is not equal to expected value")
+			}
+			if !compareSitemapLocationsArray(sitemap.sitemapLocations, test.sitemapLocations) {
+				t.Error("sitemapLocations is not equal to expected value")
+			}
+			if !compareURLsArray(sitemap.urls, test.urls) {
+				t.Error("urls is not equal to expected value")
+			}
+			if !reflect.DeepEqual(sitemap.errs, test.errs) {
+				t.Error("errs is not equal to expected value")
+			}
+		})
+	}
+}
+
+func TestS_NilReceiver(t *testing.T) {
+	var s *S
+
+	setters := map[string]*S{
+		"SetUserAgent":                  s.SetUserAgent("agent"),
+		"SetFetchTimeout":               s.SetFetchTimeout(1),
+		"SetConnectTimeout":             s.SetConnectTimeout(time.Second),
+		"SetDecodeLimits":               s.SetDecodeLimits(DecodeLimits{}),
+		"SetFields":                     s.SetFields(),
+		"SetLocOnly":                    s.SetLocOnly(false),
+		"SetDebugTrace":                 s.SetDebugTrace(false),
+		"SetPunycodeHosts":              s.SetPunycodeHosts(false),
+		"SetDropLongLocs":               s.SetDropLongLocs(false),
+		"SetPingEndpoints":              s.SetPingEndpoints(nil),
+		"SetIndexNowEndpoint":           s.SetIndexNowEndpoint(""),
+		"SetCrawlIntervals":             s.SetCrawlIntervals(nil),
+		"SetMaxFailureRate":             s.SetMaxFailureRate(0, 0),
+		"SetMaxErrors":                  s.SetMaxErrors(1),
+		"SetCountWarnings":              s.SetCountWarnings(true),
+		"SetRandomSource":               s.SetRandomSource(nil),
+		"SetMetricsCollector":           s.SetMetricsCollector(nil),
+		"SetStripQueryParams":           s.SetStripQueryParams(nil),
+		"SetRobotsTxt":                  s.SetRobotsTxt(""),
+		"SetRespectCrawlDelay":          s.SetRespectCrawlDelay(true),
+		"SetFetcher":                    s.SetFetcher(nil),
+		"SetMaxDecompressedSize":        s.SetMaxDecompressedSize(1),
+		"SetEncodeLocs":                 s.SetEncodeLocs(true),
+		"SetUpgradeToHTTPS":             s.SetUpgradeToHTTPS(true),
+		"SetUpgradeLocsToHTTPS":         s.SetUpgradeLocsToHTTPS(true),
+		"SetTrackPositions":             s.SetTrackPositions(true),
+		"SetStrictElements":             s.SetStrictElements(true),
+		"SetURLMatcher":                 s.SetURLMatcher(func(string) bool { return true }),
+		"SetSitemapMatcher":             s.SetSitemapMatcher(func(string) bool { return true }),
+		"SetMaxConcurrency":             s.SetMaxConcurrency(10),
+		"SetMaxConcurrencyPerHost":      s.SetMaxConcurrencyPerHost(2),
+		"SetMaxFetchSizeHint":           s.SetMaxFetchSizeHint(1 << 20),
+		"SetDiscoverFromHTML":           s.SetDiscoverFromHTML(true),
+		"SetDefaultLastModLocation":     s.SetDefaultLastModLocation(time.UTC),
+		"SetLastModFallback":            s.SetLastModFallback(true),
+		"SetKeepRawContent":             s.SetKeepRawContent(true),
+		"SetMaxRawContentBytes":         s.SetMaxRawContentBytes(1 << 20),
+		"SetTLSConfig":                  s.SetTLSConfig(nil),
+		"SetClientCertificate":          s.SetClientCertificate(tls.Certificate{}),
+		"SetMultiThread":                s.SetMultiThread(false),
+		"SetFollowIndexes":              s.SetFollowIndexes(false),
+		"SetCollectURLs":                s.SetCollectURLs(false),
+		"SetMaxURLs":                    s.SetMaxURLs(1),
+		"SetMaxURLsPerSitemap":          s.SetMaxURLsPerSitemap(1),
+		"SetMaxSitemaps":                s.SetMaxSitemaps(1),
+		"SetFollow":                     s.SetFollow([]string{".*"}),
+		"SetRules":                      s.SetRules([]string{".*"}),
+		"SetRateLimit":                  s.SetRateLimit(1),
+		"SetPerHostRateLimit":           s.SetPerHostRateLimit(1),
+		"SetRequestDelay":               s.SetRequestDelay(time.Second, 0),
+		"SetCircuitBreaker":             s.SetCircuitBreaker(1),
+		"SetAllowedSchemes":             s.SetAllowedSchemes([]string{"https"}),
+		"SetMemoryBudget":               s.SetMemoryBudget(1),
+		"SetRetries":                    s.SetRetries(1),
+		"SetRetryBackoff":               s.SetRetryBackoff(time.Second),
+		"SetStrictDecompression":        s.SetStrictDecompression(true),
+		"SetDecompressors":              s.SetDecompressors(fakeZstd),
+		"SetMaxSitemapsByLastMod":       s.SetMaxSitemapsByLastMod(1),
+		"SetMinSitemapLastMod":          s.SetMinSitemapLastMod(time.Now()),
+		"SetSkipSitemapsWithoutLastMod": s.SetSkipSitemapsWithoutLastMod(true),
+	}
+	for name, got := range setters {
+		if got != nil {
+			t.Errorf("%s: expected nil, got %v", name, got)
+		}
+	}
+
+	if _, err := s.Parse("https://www.sitemaps.org/sitemap.xml", nil); !errors.Is(err, ErrNilReceiver) {
+		t.Errorf("Parse: expected %v, got %v", ErrNilReceiver, err)
+	}
+	if _, err := s.ParseContext(context.Background(), "https://www.sitemaps.org/sitemap.xml", nil); !errors.Is(err, ErrNilReceiver) {
+		t.Errorf("ParseContext: expected %v, got %v", ErrNilReceiver, err)
+	}
+	if _, err := s.ParseFS(fstest.MapFS{}, "sitemap.xml"); !errors.Is(err, ErrNilReceiver) {
+		t.Errorf("ParseFS: expected %v, got %v", ErrNilReceiver, err)
+	}
+	if _, err := s.ParseFromCheckpoint(Checkpoint{}); !errors.Is(err, ErrNilReceiver) {
+		t.Errorf("ParseFromCheckpoint: expected %v, got %v", ErrNilReceiver, err)
+	}
+
+	if got := s.GetErrorsCount(); got != 0 {
+		t.Errorf("GetErrorsCount: expected 0, got %d", got)
+	}
+	if got := s.GetErrors(); got != nil {
+		t.Errorf("GetErrors: expected nil, got %v", got)
+	}
+	if got := s.GetWarnings(); got != nil {
+		t.Errorf("GetWarnings: expected nil, got %v", got)
+	}
+	if got := s.GetSitemapLocations(); got != nil {
+		t.Errorf("GetSitemapLocations: expected nil, got %v", got)
+	}
+	if got := s.GetURLs(); got == nil || len(got) != 0 {
+		t.Errorf("GetURLs: expected empty slice, got %v", got)
+	}
+	if got := s.GetURLsShared(); got == nil || len(got) != 0 {
+		t.Errorf("GetURLsShared: expected empty slice, got %v", got)
+	}
+	if got := s.GetURLCount(); got != 0 {
+		t.Errorf("GetURLCount: expected 0, got %d", got)
+	}
+	if got := s.GetRandomURLs(1); got == nil || len(got) != 0 {
+		t.Errorf("GetRandomURLs: expected empty slice, got %v", got)
+	}
+	if got := s.GetRandomSitemaps(1); got == nil || len(got) != 0 {
+		t.Errorf("GetRandomSitemaps: expected empty slice, got %v", got)
+	}
+	if got := s.GetDisallowedURLs(); got == nil || len(got) != 0 {
+		t.Errorf("GetDisallowedURLs: expected empty slice, got %v", got)
+	}
+	if got := s.GetURLCountsByHost(); got == nil || len(got) != 0 {
+		t.Errorf("GetURLCountsByHost: expected empty map, got %v", got)
+	}
+	if got := s.GetFilteredURLCount(); got != 0 {
+		t.Errorf("GetFilteredURLCount: expected 0, got %d", got)
+	}
+	if got := s.GetFilteredURLCounts(); got == nil || len(got) != 0 {
+		t.Errorf("GetFilteredURLCounts: expected empty map, got %v", got)
+	}
+	if got := s.GetSkippedSitemaps(); got == nil || len(got) != 0 {
+		t.Errorf("GetSkippedSitemaps: expected empty slice, got %v", got)
+	}
+	if got := s.GetSkippedSitemapsByFilter(); got == nil || len(got) != 0 {
+		t.Errorf("GetSkippedSitemapsByFilter: expected empty map, got %v", got)
+	}
+	if got := s.GetLocs(); got == nil || len(got) != 0 {
+		t.Errorf("GetLocs: expected empty slice, got %v", got)
+	}
+	if got := s.GetTrace(); got == nil || len(got) != 0 {
+		t.Errorf("GetTrace: expected empty slice, got %v", got)
+	}
+	if got := s.GetURLsAsStrings(); got == nil || len(got) != 0 {
+		t.Errorf("GetURLsAsStrings: expected empty slice, got %v", got)
+	}
+	if got := s.GetUniqueURLsAsStrings(); got == nil || len(got) != 0 {
+		t.Errorf("GetUniqueURLsAsStrings: expected empty slice, got %v", got)
+	}
+	if got := s.GetCrawlSchedule(time.Now()); got == nil || len(got) != 0 {
+		t.Errorf("GetCrawlSchedule: expected empty slice, got %v", got)
+	}
+	if got := s.GetStaleSitemaps(time.Hour, time.Now()); got == nil || len(got) != 0 {
+		t.Errorf("GetStaleSitemaps: expected empty slice, got %v", got)
+	}
+	if got := s.GetPendingSitemaps(); got == nil || len(got) != 0 {
+		t.Errorf("GetPendingSitemaps: expected empty slice, got %v", got)
+	}
+	if got := s.GetURLsWithoutLastMod(); got == nil || len(got) != 0 {
+		t.Errorf("GetURLsWithoutLastMod: expected empty slice, got %v", got)
+	}
+	if got := s.GetURLsGroupedByHost(); got == nil || len(got) != 0 {
+		t.Errorf("GetURLsGroupedByHost: expected empty map, got %v", got)
+	}
+	if got := s.GetHosts(); got == nil || len(got) != 0 {
+		t.Errorf("GetHosts: expected empty slice, got %v", got)
+	}
+	if got := s.GetSitemapHosts(); got == nil || len(got) != 0 {
+		t.Errorf("GetSitemapHosts: expected empty slice, got %v", got)
+	}
+	if got := s.GetFetchMetadata(); got == nil || len(got) != 0 {
+		t.Errorf("GetFetchMetadata: expected empty map, got %v", got)
+	}
+	if got := s.GetReport(); got.URLCount != 0 {
+		t.Errorf("GetReport: expected empty report, got %v", got)
+	}
+	if got := s.GetSitemapTree(); got != nil {
+		t.Errorf("GetSitemapTree: expected nil, got %v", got)
+	}
+	if got := s.GetCompleteness(); got != (Completeness{}) {
+		t.Errorf("GetCompleteness: expected zero value, got %v", got)
+	}
+	if got := s.GetCheckpoint(); got.Tree != nil || got.MainURL != "" {
+		t.Errorf("GetCheckpoint: expected zero value, got %v", got)
+	}
+	if got, ok := s.GetContent("https://www.sitemaps.org/sitemap.xml"); got != nil || ok {
+		t.Errorf("GetContent: expected nil and false, got %v and %v", got, ok)
+	}
+	if got := s.GetDuration(); got != 0 {
+		t.Errorf("GetDuration: expected 0, got %v", got)
+	}
+	if got := s.GetTiming(); got != (Timing{}) {
+		t.Errorf("GetTiming: expected zero value, got %v", got)
+	}
+	if got := s.GetResult(); got.MainURL != "" || len(got.URLs) != 0 || got.Tree != nil {
+		t.Errorf("GetResult: expected no results, got %v", got)
+	}
+	var buf bytes.Buffer
+	if err := s.WriteCSV(&buf); err != nil {
+		t.Errorf("WriteCSV: unexpected error %v", err)
+	}
+
+	var b *Builder
+	if got := b.AddURL("https://www.sitemaps.org/"); got != nil {
+		t.Errorf("AddURL: expected nil, got %v", got)
+	}
+	if got := b.Errors(); got != nil {
+		t.Errorf("Errors: expected nil, got %v", got)
+	}
+	if got := b.Build(); len(got.URL) != 0 {
+		t.Errorf("Build: expected empty URLSet, got %v", got)
+	}
+}
+
+func TestValidateSitemapURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		location string
+		wantErr  string
+	}{
+		{
+			name:     "empty",
+			location: "",
+			wantErr:  "invalid sitemap URL \"\": empty",
+		},
+		{
+			name:     "unparseable",
+			location: "https://www.sitemaps.org/%zz",
+			wantErr:  "invalid sitemap URL \"https://www.sitemaps.org/%zz\": parse \"https://www.sitemaps.org/%zz\": invalid URL escape \"%zz\"",
+		},
+		{
+			name:     "missing scheme",
+			location: "www.sitemaps.org/sitemap.xml",
+			wantErr:  "invalid sitemap URL \"www.sitemaps.org/sitemap.xml\": missing scheme",
+		},
+		{
+			name:     "unsupported scheme",
+			location: "ftp://www.sitemaps.org/sitemap.xml",
+			wantErr:  "invalid sitemap URL \"ftp://www.sitemaps.org/sitemap.xml\": unsupported scheme \"ftp\"",
+		},
+		{
+			name:     "missing host",
+			location: "https:///sitemap.xml",
+			wantErr:  "invalid sitemap URL \"https:///sitemap.xml\": missing host",
+		},
+		{
+			name:     "valid",
+			location: "HTTPS://www.sitemaps.org/sitemap.xml",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := New().validateSitemapURL(test.location)
+			if test.wantErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if test.wantErr != "" && (err == nil || err.Error() != test.wantErr) {
+				t.Errorf("expected error %q, got %v", test.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestS_SetMinSitemapLastMod(t *testing.T) {
+	minLastMod := time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC)
+
+	s := New().SetMinSitemapLastMod(minLastMod).SetSkipSitemapsWithoutLastMod(true)
+	if !s.cfg.minSitemapLastMod.Equal(minLastMod) {
+		t.Errorf("expected %v, got %v", minLastMod, s.cfg.minSitemapLastMod)
+	}
+	if !s.cfg.skipSitemapsWithoutLastMod {
+		t.Error("expected skipSitemapsWithoutLastMod to be true")
+	}
+}
+
+func TestS_Parse_MinSitemapLastMod(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	minLastMod := time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC)
+	sitemapURL := func(name string) string {
+		return fmt.Sprintf("%s/%s", server.URL, name)
+	}
+
+	tests := []struct {
+		name          string
+		s             *S
+		wantLocations []string
+		urlsCount     int64
+		skipped       int
+	}{
+		{
+			name: "no limit",
+			s:    New().SetMultiThread(false),
+			wantLocations: []string{
+				sitemapURL("sitemapindex-lastmod.xml"),
+				sitemapURL("sitemap-01.xml"),
+				sitemapURL("sitemap-02.xml"),
+				sitemapURL("sitemap-03.xml"),
+				sitemapURL("sitemap-04.xml"),
+				sitemapURL("sitemap-05.xml"),
+			},
+			urlsCount: 9,
+		},
+		{
+			name: "include without lastmod",
+			s:    New().SetMultiThread(false).SetMinSitemapLastMod(minLastMod),
+			wantLocations: []string{
+				sitemapURL("sitemapindex-lastmod.xml"),
+				sitemapURL("sitemap-02.xml"),
+				sitemapURL("sitemap-03.xml"),
+				sitemapURL("sitemap-04.xml"),
+				sitemapURL("sitemap-05.xml"),
+			},
+			urlsCount: 8,
+			skipped:   1,
+		},
+		{
+			name: "exclude without lastmod",
+			s:    New().SetMultiThread(false).SetMinSitemapLastMod(minLastMod).SetSkipSitemapsWithoutLastMod(true),
+			wantLocations: []string{
+				sitemapURL("sitemapindex-lastmod.xml"),
+				sitemapURL("sitemap-02.xml"),
+				sitemapURL("sitemap-05.xml"),
 			},
+			urlsCount: 4,
+			skipped:   3,
 		},
+	}
 
-		// sitemap
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := test.s.Parse(sitemapURL("sitemapindex-lastmod.xml"), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.GetSitemapLocations(); !reflect.DeepEqual(got, test.wantLocations) {
+				t.Errorf("expected locations %v, got %v", test.wantLocations, got)
+			}
+			if got := s.GetURLCount(); got != test.urlsCount {
+				t.Errorf("expected %d URLs, got %d", test.urlsCount, got)
+			}
+			if got := s.GetCompleteness().SkippedSitemaps; got != test.skipped {
+				t.Errorf("expected %d skipped sitemaps, got %d", test.skipped, got)
+			}
+		})
+	}
+}
+
+func TestS_Parse_MaxSitemapsByLastMod(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	sitemapURL := func(name string) string {
+		return fmt.Sprintf("%s/%s", server.URL, name)
+	}
+
+	tests := []struct {
+		name          string
+		s             *S
+		wantLocations []string
+		skipped       int
+	}{
 		{
-			name:                 "sitemap.xml empty file",
-			url:                  fmt.Sprintf("%s/sitemap-empty.xml", server.URL),
-			multiThread:          false,
-			follow:               []string{},
-			rules:                []string{},
-			mainURLContent:       pointerOfString("\n"),
-			robotsTxtSitemapURLs: nil,
-			sitemapLocations:     nil,
-			urls:                 nil,
-			errs:                 []error{errors.New("the content is neither sitemapindex nor sitemap")},
+			name: "newest 2",
+			s:    New().SetMultiThread(false).SetMaxSitemapsByLastMod(2),
+			wantLocations: []string{
+				sitemapURL("sitemapindex-lastmod.xml"),
+				sitemapURL("sitemap-02.xml"),
+				sitemapURL("sitemap-05.xml"),
+			},
+			skipped: 3,
 		},
 		{
-			name:                 "sitemap.xml empty content",
-			url:                  fmt.Sprintf("%s/sitemap-empty.xml", server.URL),
-			multiThread:          true,
-			follow:               []string{},
-			rules:                []string{},
-			content:              pointerOfString("\n"),
-			mainURLContent:       pointerOfString("\n"),
-			robotsTxtSitemapURLs: nil,
-			sitemapLocations:     nil,
-			urls:                 nil,
-			errs:                 []error{errors.New("the content is neither sitemapindex nor sitemap")},
+			name: "without lastmod last",
+			s:    New().SetMultiThread(false).SetMaxSitemapsByLastMod(4),
+			wantLocations: []string{
+				sitemapURL("sitemapindex-lastmod.xml"),
+				sitemapURL("sitemap-02.xml"),
+				sitemapURL("sitemap-05.xml"),
+				sitemapURL("sitemap-01.xml"),
+				sitemapURL("sitemap-03.xml"),
+			},
+			skipped: 1,
+		},
+		{
+			name: "more than the sitemaps",
+			s:    New().SetMultiThread(false).SetMaxSitemapsByLastMod(10),
+			wantLocations: []string{
+				sitemapURL("sitemapindex-lastmod.xml"),
+				sitemapURL("sitemap-01.xml"),
+				sitemapURL("sitemap-02.xml"),
+				sitemapURL("sitemap-03.xml"),
+				sitemapURL("sitemap-04.xml"),
+				sitemapURL("sitemap-05.xml"),
+			},
 		},
 		{
-			name:                 "sitemap.xml",
-			url:                  fmt.Sprintf("%s/sitemap-02.xml.gz", server.URL),
-			multiThread:          false,
-			follow:               []string{},
-			rules:                []string{},
-			mainURLContent:       pointerOfString(fmt.Sprintf("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<urlset xmlns=\"http://www.sitemaps.org/schemas/sitemap/0.9\">\n    <url>\n        <loc>%s/page-02</loc>\n        <lastmod>2024-02-12T12:34:56+01:00</lastmod>\n        <changefreq>hourly</changefreq>\n        <priority>0.5</priority>\n    </url>\n    <url>\n        <loc>%s/page-03</loc>\n        <lastmod>2024-02-12T12:34:56+01:00</lastmod>\n        <changefreq>daily</changefreq>\n        <priority>0.5</priority>\n    </url>\n</urlset>\n", server.URL, server.URL)),
-			robotsTxtSitemapURLs: nil,
-			sitemapLocations:     nil,
-			urls: []URL{
-				{
-					Loc:        fmt.Sprintf("%s/page-02", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
-					ChangeFreq: pointerOfURLChangeFreq(changeFreqHourly),
-					Priority:   pointerOfFloat32(0.5),
-				},
-				{
-					Loc:        fmt.Sprintf("%s/page-03", server.URL),
-					LastMod:    pointerOfLastModTime(lastModTime{time.Date(2024, time.February, 12, 12, 34, 56, 0, timeLocationCET)}),
-					ChangeFreq: pointerOfURLChangeFreq(changeFreqDaily),
-					Priority:   pointerOfFloat32(0.5),
-				},
+			name: "with follow",
+			s:    New().SetMultiThread(false).SetFollow([]string{`sitemap-0[1345]\.xml$`}).SetMaxSitemapsByLastMod(2),
+			wantLocations: []string{
+				sitemapURL("sitemapindex-lastmod.xml"),
+				sitemapURL("sitemap-05.xml"),
+				sitemapURL("sitemap-01.xml"),
 			},
+			skipped: 2,
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			s := New()
-			sitemap, err := s.SetMultiThread(test.multiThread).SetFollow(test.follow).SetRules(test.rules).Parse(test.url, test.content)
+			s, err := test.s.Parse(sitemapURL("sitemapindex-lastmod.xml"), nil)
 			if err != nil {
-				if err.Error() != *test.err {
-					t.Errorf("Unexpected error: %v", err)
-				}
+				t.Fatal(err)
 			}
-
-			if sitemap == nil {
-				t.Fatal("Expected not nil object, but got nil")
+			if got := s.GetSitemapLocations(); !reflect.DeepEqual(got, test.wantLocations) {
+				t.Errorf("expected locations %v, got %v", test.wantLocations, got)
 			}
-			if sitemap.mainURL != test.url {
-				t.Fatalf("Expected URL to be %s, but got %s", test.url, sitemap.mainURL)
+			if got := s.GetCompleteness().SkippedSitemaps; got != test.skipped {
+				t.Errorf("expected %d skipped sitemaps, got %d", test.skipped, got)
 			}
+		})
+	}
+}
 
-			if !reflect.DeepEqual(sitemap.mainURLContent, *test.mainURLContent) {
-				t.Error("mainURLContent is not equal to expected value")
-			}
-			if !reflect.DeepEqual(sitemap.robotsTxtSitemapURLs, test.robotsTxtSitemapURLs) {
-				t.Error("robotsTxtSitemapURLs is not equal to expected value")
-			}
-			if !compareSitemapLocationsArray(sitemap.sitemapLocations, test.sitemapLocations) {
-				t.Error("sitemapLocations is not equal to expected value")
+func TestS_SetMemoryBudget(t *testing.T) {
+	tests := []struct {
+		name   string
+		budget int64
+	}{
+		{
+			name:   "NoLimit",
+			budget: 0,
+		},
+		{
+			name:   "Limit",
+			budget: 64 << 20,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New()
+			s.SetMemoryBudget(test.budget)
+			if s.cfg.memoryBudget != test.budget {
+				t.Errorf("expected %d, got %d", test.budget, s.cfg.memoryBudget)
 			}
-			if !compareURLsArray(sitemap.urls, test.urls) {
-				t.Error("urls is not equal to expected value")
+		})
+	}
+}
+
+func TestS_SetAllowedSchemes(t *testing.T) {
+	tests := []struct {
+		name    string
+		schemes []string
+		allowed map[string]bool
+	}{
+		{
+			name:    "Default",
+			schemes: nil,
+			allowed: map[string]bool{"http": true, "HTTPS": true, "ftp": false, "file": false, "": false},
+		},
+		{
+			name:    "Custom",
+			schemes: []string{"https", "file"},
+			allowed: map[string]bool{"http": false, "https": true, "FILE": true, "ftp": false},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New()
+			s.SetAllowedSchemes(test.schemes)
+			if !reflect.DeepEqual(s.cfg.allowedSchemes, test.schemes) {
+				t.Errorf("expected %v, got %v", test.schemes, s.cfg.allowedSchemes)
 			}
-			if !reflect.DeepEqual(sitemap.errs, test.errs) {
-				t.Error("errs is not equal to expected value")
+			for scheme, want := range test.allowed {
+				if got := s.schemeAllowed(scheme); got != want {
+					t.Errorf("scheme %q: expected %v, got %v", scheme, want, got)
+				}
 			}
 		})
 	}
 }
 
+func TestS_Parse_AllowedSchemes(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	indexContent := fmt.Sprintf(`<sitemapindex>
+<sitemap><loc>ftp://%[1]s/sitemap-01.xml</loc></sitemap>
+<sitemap><loc>data:text/xml,urlset</loc></sitemap>
+<sitemap><loc>%[2]s/sitemap-02.xml</loc></sitemap>
+</sitemapindex>`, server.Listener.Addr(), server.URL)
+	robotsContent := "Sitemap: file:///etc/sitemap.xml\n"
+
+	t.Run("sitemapindex", func(t *testing.T) {
+		s, err := New().SetMultiThread(false).Parse(fmt.Sprintf("%s/sitemapindex.xml", server.URL), &indexContent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.GetURLCount() != 2 {
+			t.Errorf("expected 2 URLs, got %d", s.GetURLCount())
+		}
+		wantSchemes := []string{"ftp", "data"}
+		if s.GetErrorsCount() != int64(len(wantSchemes)) {
+			t.Fatalf("expected %d errors, got %v", len(wantSchemes), s.GetErrors())
+		}
+		for i, err := range s.GetErrors() {
+			var schemeErr *UnsupportedSchemeError
+			if !errors.As(err, &schemeErr) || schemeErr.Scheme != wantSchemes[i] {
+				t.Errorf("expected unsupported scheme %q, got %v", wantSchemes[i], err)
+			}
+		}
+		if len(s.GetFetchMetadata()) != 1 {
+			t.Errorf("expected a single fetch, got %v", s.GetFetchMetadata())
+		}
+	})
+
+	t.Run("robots.txt", func(t *testing.T) {
+		s, err := New().Parse(fmt.Sprintf("%s/robots.txt", server.URL), &robotsContent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var schemeErr *UnsupportedSchemeError
+		if s.GetErrorsCount() != 1 || !errors.As(s.GetErrors()[0], &schemeErr) || schemeErr.Scheme != "file" {
+			t.Errorf("expected unsupported scheme \"file\", got %v", s.GetErrors())
+		}
+	})
+
+	t.Run("main URL", func(t *testing.T) {
+		_, err := New().SetAllowedSchemes([]string{"https"}).Parse(fmt.Sprintf("%s/sitemap-01.xml", server.URL), nil)
+		var schemeErr *UnsupportedSchemeError
+		if !errors.As(err, &schemeErr) || schemeErr.Scheme != "http" {
+			t.Errorf("expected unsupported scheme \"http\", got %v", err)
+		}
+	})
+}
+
+func TestS_Parse_MainURLValidation(t *testing.T) {
+	t.Run("invalid URL is not fetched", func(t *testing.T) {
+		s, err := New().Parse("", nil)
+		if err == nil || err.Error() != "invalid sitemap URL \"\": empty" {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if s.GetErrorsCount() != 1 {
+			t.Errorf("expected 1 error, got %v", s.GetErrors())
+		}
+		if len(s.GetFetchMetadata()) != 0 {
+			t.Errorf("expected no fetch, got %v", s.GetFetchMetadata())
+		}
+	})
+
+	t.Run("placeholder URL with content", func(t *testing.T) {
+		content := "<urlset><url><loc>https://www.sitemaps.org/</loc></url></urlset>"
+		s, err := New().Parse("placeholder", &content)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if s.GetURLCount() != 1 {
+			t.Errorf("expected 1 URL, got %d", s.GetURLCount())
+		}
+	})
+}
+
 func TestS_GetErrorsCount(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -903,6 +2020,38 @@ func TestS_GetErrors(t *testing.T) {
 	}
 }
 
+func TestS_GetSitemapLocations(t *testing.T) {
+	tests := []struct {
+		name string
+		s    *S
+		want []string
+	}{
+		{
+			name: "nil receiver",
+			s:    nil,
+			want: nil,
+		},
+		{
+			name: "no locations",
+			s:    New(),
+			want: nil,
+		},
+		{
+			name: "locations",
+			s:    &S{sitemapLocations: []string{"https://www.sitemaps.org/sitemapindex.xml", "https://www.sitemaps.org/sitemap.xml"}},
+			want: []string{"https://www.sitemaps.org/sitemapindex.xml", "https://www.sitemaps.org/sitemap.xml"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.s.GetSitemapLocations(); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
 func TestS_GetURLs(t *testing.T) {
 	tests := []struct {
 		name string
@@ -941,6 +2090,48 @@ func TestS_GetURLs(t *testing.T) {
 	}
 }
 
+func TestS_GetURLs_Ownership(t *testing.T) {
+	newS := func() *S {
+		return &S{urls: []URL{
+			{Loc: "http://www.sitemaps.org/1"},
+			{Loc: "http://www.sitemaps.org/2"},
+			{Loc: "http://www.sitemaps.org/3"},
+		}}
+	}
+	want := newS().urls
+
+	t.Run("GetURLs returns a copy", func(t *testing.T) {
+		s := newS()
+		got := s.GetURLs()
+		sort.Slice(got, func(i, j int) bool { return got[i].Loc > got[j].Loc })
+		got[0].Loc = "http://www.sitemaps.org/modified"
+		if !reflect.DeepEqual(s.urls, want) {
+			t.Errorf("expected the URLs to be unchanged, got %v", s.urls)
+		}
+	})
+
+	t.Run("GetRandomURLs leaves the URLs unchanged", func(t *testing.T) {
+		s := newS()
+		for i := 0; i < 10; i++ {
+			_ = s.GetRandomURLs(2)
+		}
+		if !reflect.DeepEqual(s.urls, want) {
+			t.Errorf("expected the URLs to be unchanged, got %v", s.urls)
+		}
+	})
+
+	t.Run("GetURLsShared shares the URLs", func(t *testing.T) {
+		s := newS()
+		got := s.GetURLsShared()
+		if len(got) != len(s.urls) || &got[0] != &s.urls[0] {
+			t.Errorf("expected the internal slice, got %v", got)
+		}
+		if got := (&S{}).GetURLsShared(); got == nil || len(got) != 0 {
+			t.Errorf("expected empty slice, got %v", got)
+		}
+	})
+}
+
 func TestS_GetURLCount(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -1123,13 +2314,127 @@ func TestS_parseRobotsTXT(t *testing.T) {
 		},
 	}
 
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			s := New()
-			s.parseRobotsTXT(test.input)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New()
+			s.parseRobotsTXT(test.input)
+
+			if len(s.robotsTxtSitemapURLs) != test.output {
+				t.Errorf("Input %s: expected %d, got %d", test.input, test.output, len(s.robotsTxtSitemapURLs))
+			}
+		})
+	}
+}
+
+func TestS_Parse_FollowIndexes(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	tests := []struct {
+		name                 string
+		url                  string
+		multiThread          bool
+		robotsTxtSitemapURLs []string
+		sitemapLocations     []string
+		fetched              []string
+		urlsCount            int64
+	}{
+		{
+			name:        "sitemapindex",
+			url:         fmt.Sprintf("%s/sitemapindex-1.xml", server.URL),
+			multiThread: true,
+			sitemapLocations: []string{
+				fmt.Sprintf("%s/sitemapindex-1.xml", server.URL),
+				fmt.Sprintf("%s/sitemap-01.xml", server.URL),
+				fmt.Sprintf("%s/sitemap-02.xml", server.URL),
+				fmt.Sprintf("%s/sitemap-03.xml", server.URL),
+			},
+			fetched: []string{fmt.Sprintf("%s/sitemapindex-1.xml", server.URL)},
+		},
+		{
+			name:                 "robots.txt",
+			url:                  fmt.Sprintf("%s/robots-with-sitemapindex/robots.txt", server.URL),
+			multiThread:          false,
+			robotsTxtSitemapURLs: []string{fmt.Sprintf("%s/sitemapindex-1.xml", server.URL)},
+			fetched:              []string{fmt.Sprintf("%s/robots-with-sitemapindex/robots.txt", server.URL)},
+		},
+		{
+			name:        "sitemap",
+			url:         fmt.Sprintf("%s/sitemap-02.xml", server.URL),
+			multiThread: false,
+			fetched:     []string{fmt.Sprintf("%s/sitemap-02.xml", server.URL)},
+			urlsCount:   2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := New().SetMultiThread(test.multiThread).SetFollowIndexes(false).Parse(test.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(s.robotsTxtSitemapURLs, test.robotsTxtSitemapURLs) {
+				t.Errorf("expected robots.txt sitemap URLs %v, got %v", test.robotsTxtSitemapURLs, s.robotsTxtSitemapURLs)
+			}
+			if !reflect.DeepEqual(s.GetSitemapLocations(), test.sitemapLocations) {
+				t.Errorf("expected sitemap locations %v, got %v", test.sitemapLocations, s.GetSitemapLocations())
+			}
+			fetched := make([]string, 0)
+			for loc := range s.GetFetchMetadata() {
+				fetched = append(fetched, loc)
+			}
+			if !reflect.DeepEqual(fetched, test.fetched) {
+				t.Errorf("expected fetched %v, got %v", test.fetched, fetched)
+			}
+			if s.GetURLCount() != test.urlsCount {
+				t.Errorf("expected %d URLs, got %d", test.urlsCount, s.GetURLCount())
+			}
+			if s.GetErrorsCount() != 0 {
+				t.Errorf("expected no errors, got %v", s.GetErrors())
+			}
+		})
+	}
+}
+
+func TestS_Parse_CollectURLs(t *testing.T) {
+	server := testServer()
+	defer server.Close()
 
-			if len(s.robotsTxtSitemapURLs) != test.output {
-				t.Errorf("Input %s: expected %d, got %d", test.input, test.output, len(s.robotsTxtSitemapURLs))
+	for _, multiThread := range []bool{true, false} {
+		t.Run(fmt.Sprintf("multiThread=%v", multiThread), func(t *testing.T) {
+			s, err := New().SetMultiThread(multiThread).SetCollectURLs(false).Parse(fmt.Sprintf("%s/robots-with-sitemapindex/robots.txt", server.URL), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(s.GetURLs()) != 0 {
+				t.Errorf("expected no URLs, got %d", len(s.GetURLs()))
+			}
+			if len(s.GetSitemapLocations()) != 4 {
+				t.Errorf("expected 4 sitemap locations, got %v", s.GetSitemapLocations())
+			}
+			if len(s.GetFetchMetadata()) != 5 {
+				t.Errorf("expected 5 fetched locations, got %d", len(s.GetFetchMetadata()))
+			}
+
+			counts := map[string]int64{}
+			var walk func(node *SitemapNode)
+			walk = func(node *SitemapNode) {
+				if node.Kind == SitemapKindURLSet {
+					counts[node.Loc] = node.URLCount
+				}
+				for _, child := range node.Children {
+					walk(child)
+				}
+			}
+			walk(s.GetSitemapTree())
+			want := map[string]int64{
+				fmt.Sprintf("%s/sitemap-01.xml", server.URL): 1,
+				fmt.Sprintf("%s/sitemap-02.xml", server.URL): 2,
+				fmt.Sprintf("%s/sitemap-03.xml", server.URL): 3,
+			}
+			if !reflect.DeepEqual(counts, want) {
+				t.Errorf("expected URL counts %v, got %v", want, counts)
 			}
 		})
 	}
@@ -1185,7 +2490,7 @@ func TestS_fetch(t *testing.T) {
 			s := &S{
 				cfg: test.fields.cfg,
 			}
-			_, err := s.fetch(test.url)
+			_, _, err := s.fetch(test.url)
 			if (err != nil) != test.wantErr {
 				t.Errorf("fetch() error = %v, wantErr %v", err, test.wantErr)
 				return
@@ -1214,6 +2519,7 @@ func TestS_checkAndUnzipContent(t *testing.T) {
 		name    string
 		content []byte
 		want    []byte
+		wantErr string
 	}{
 		{
 			name:    "Uncompressed data",
@@ -1229,6 +2535,23 @@ func TestS_checkAndUnzipContent(t *testing.T) {
 			name:    "Invalid data",
 			content: []byte("\x1f\x8b\x08" + "invalid"), // gzip prefix + invalid content
 			want:    []byte("\x1f\x8b\x08" + "invalid"),
+			wantErr: "https://www.sitemaps.org/sitemap.xml.gz: unexpected EOF",
+		},
+		{
+			name:    "Zlib data",
+			content: zlibByte("test content"),
+			want:    []byte("test content"),
+		},
+		{
+			name:    "Invalid zlib data",
+			content: []byte("\x78\x9c" + "invalid"), // zlib header + invalid content
+			want:    []byte("\x78\x9c" + "invalid"),
+			wantErr: "https://www.sitemaps.org/sitemap.xml.gz: flate: corrupt input before offset 5",
+		},
+		{
+			name:    "Plain content starting with x",
+			content: []byte("xml content"),
+			want:    []byte("xml content"),
 		},
 	}
 
@@ -1238,11 +2561,17 @@ func TestS_checkAndUnzipContent(t *testing.T) {
 				errs: []error{},
 			}
 
-			got := s.checkAndUnzipContent(tt.content)
+			got, _ := s.checkAndUnzipContent("https://www.sitemaps.org/sitemap.xml.gz", tt.content)
 
 			if !bytes.Equal(got, tt.want) {
 				t.Errorf("checkAndUnzipContent() got = %v, want %v", got, tt.want)
 			}
+			if tt.wantErr == "" && len(s.errs) > 0 {
+				t.Errorf("unexpected errors: %v", s.errs)
+			}
+			if tt.wantErr != "" && (len(s.errs) != 1 || s.errs[0].Error() != tt.wantErr) {
+				t.Errorf("expected error %q, got %v", tt.wantErr, s.errs)
+			}
 		})
 	}
 }
@@ -1385,6 +2714,22 @@ func TestS_parse(t *testing.T) {
 			urlsCount:                  2,
 			errsCount:                  0,
 		},
+		{
+			name:                       "URLSet with prefixed root",
+			url:                        fmt.Sprintf("%s/sitemap-prefixed.xml", server.URL),
+			content:                    fmt.Sprintf("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<!-- generated -->\n<sm:urlset xmlns:sm=\"http://www.sitemaps.org/schemas/sitemap/0.9\">\n    <sm:url>\n        <sm:loc>%s/page-01</sm:loc>\n    </sm:url>\n</sm:urlset>\n", server.URL),
+			sitemapLocationsAddedCount: 0,
+			urlsCount:                  1,
+			errsCount:                  0,
+		},
+		{
+			name:                       "feed",
+			url:                        fmt.Sprintf("%s/feed.xml", server.URL),
+			content:                    "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<feed xmlns=\"http://www.w3.org/2005/Atom\"></feed>\n",
+			sitemapLocationsAddedCount: 0,
+			urlsCount:                  0,
+			errsCount:                  1,
+		},
 		{
 			name:                       "invalid content",
 			url:                        fmt.Sprintf("%s/invalid.xml", server.URL),
@@ -1398,127 +2743,547 @@ func TestS_parse(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			s := New()
-			sitemapLocationsAdded := s.parse(test.url, test.content)
+			sitemapLocationsAdded := s.parse(test.url, test.content)
+
+			if len(sitemapLocationsAdded) != int(test.sitemapLocationsAddedCount) {
+				t.Errorf("expected %d, got %d", test.sitemapLocationsAddedCount, len(sitemapLocationsAdded))
+			}
+
+			if len(s.urls) != int(test.urlsCount) {
+				t.Errorf("expected %d, got %d", test.urlsCount, len(s.urls))
+			}
+
+			if len(s.errs) != int(test.errsCount) {
+				t.Errorf("expected %d, got %d", test.errsCount, len(s.errs))
+			}
+		})
+	}
+}
+
+func TestDetectKind(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want SitemapKind
+	}{
+		{
+			name: "sitemapindex",
+			data: "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<sitemapindex xmlns=\"http://www.sitemaps.org/schemas/sitemap/0.9\"></sitemapindex>",
+			want: SitemapKindIndex,
+		},
+		{
+			name: "urlset",
+			data: "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<urlset xmlns=\"http://www.sitemaps.org/schemas/sitemap/0.9\"></urlset>",
+			want: SitemapKindURLSet,
+		},
+		{
+			name: "urlset after comment and doctype",
+			data: "<!-- comment --><!DOCTYPE urlset><urlset>",
+			want: SitemapKindURLSet,
+		},
+		{
+			name: "prefixed urlset",
+			data: "<sm:urlset xmlns:sm=\"http://www.sitemaps.org/schemas/sitemap/0.9\"></sm:urlset>",
+			want: SitemapKindURLSet,
+		},
+		{
+			name: "other root",
+			data: "<feed xmlns=\"http://www.w3.org/2005/Atom\"></feed>",
+			want: SitemapKindUnknown,
+		},
+		{
+			name: "empty",
+			data: "",
+			want: SitemapKindUnknown,
+		},
+		{
+			name: "not XML",
+			data: "invalid content",
+			want: SitemapKindUnknown,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := detectKind(test.data); got != test.want {
+				t.Errorf("expected %s, got %s", test.want, got)
+			}
+		})
+	}
+}
+
+func TestS_parseSitemapIndex(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		data         string
+		sitemapIndex SitemapIndex
+		err          error
+	}{
+		{
+			name: "empty content",
+			data: "",
+			err:  errors.New("sitemapindex is empty"),
+		},
+		{
+			name: "invalid content",
+			data: "invalid content",
+			err:  errors.New("EOF"),
+		},
+		{
+			name: "SitemapIndex",
+			data: fmt.Sprintf("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<sitemapindex xmlns=\"http://www.sitemaps.org/schemas/sitemap/0.9\">\n    <sitemap>\n        <loc>%s/sitemap-01.xml.gz</loc>\n        <lastmod>2024-02-12T12:34:56+01:00</lastmod>\n    </sitemap>\n    <sitemap>\n        <loc>%s/sitemap-02.xml.gz</loc>\n        <lastmod>2024-02-12T12:34:56+01:00</lastmod>\n    </sitemap>\n    <sitemap>\n        <loc>%s/sitemap-03.xml.gz</loc>\n        <lastmod>2024-02-12T12:34:56+01:00</lastmod>\n    </sitemap>\n</sitemapindex>", server.URL, server.URL, server.URL),
+			err:  nil,
+		},
+		{
+			name: "URLSet",
+			data: fmt.Sprintf("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<urlset xmlns=\"http://www.sitemaps.org/schemas/sitemap/0.9\">\n    <url>\n        <loc>%s/page-02</loc>\n        <lastmod>2024-02-12T12:34:56+01:00</lastmod>\n        <changefreq>hourly</changefreq>\n        <priority>0.5</priority>\n    </url>\n    <url>\n        <loc>%s/page-03</loc>\n        <lastmod>2024-02-12T12:34:56+01:00</lastmod>\n        <changefreq>daily</changefreq>\n        <priority>0.5</priority>\n    </url>\n</urlset>\n", server.URL, server.URL),
+			err:  xml.UnmarshalError("expected element type <sitemapindex> but have <urlset>"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New()
+			_, err := s.parseSitemapIndex(test.data)
+
+			if test.err != nil {
+				if err == nil {
+					t.Errorf("expected %v, got %v", test.err, err)
+				} else if err.Error() != test.err.Error() {
+					t.Errorf("expected %v, got %v", test.err, err)
+				}
+			} else {
+				if err != nil {
+					t.Errorf("expected %v, got %v", test.err, err)
+				}
+			}
+		})
+	}
+}
+
+func TestS_parseURLSet(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		data string
+		err  error
+	}{
+		{
+			name: "empty content",
+			data: "",
+			err:  errors.New("sitemap is empty"),
+		},
+		{
+			name: "invalid content",
+			data: "invalid content",
+			err:  errors.New("EOF"),
+		},
+		{
+			name: "Sitemap",
+			data: fmt.Sprintf("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<sitemapindex xmlns=\"http://www.sitemaps.org/schemas/sitemap/0.9\">\n    <sitemap>\n        <loc>%s/sitemap-01.xml.gz</loc>\n        <lastmod>2024-02-12T12:34:56+01:00</lastmod>\n    </sitemap>\n    <sitemap>\n        <loc>%s/sitemap-02.xml.gz</loc>\n        <lastmod>2024-02-12T12:34:56+01:00</lastmod>\n    </sitemap>\n    <sitemap>\n        <loc>%s/sitemap-03.xml.gz</loc>\n        <lastmod>2024-02-12T12:34:56+01:00</lastmod>\n    </sitemap>\n</sitemapindex>", server.URL, server.URL, server.URL),
+			err:  xml.UnmarshalError("expected element type <urlset> but have <sitemapindex>"),
+		},
+		{
+			name: "URLSet",
+			data: fmt.Sprintf("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<urlset xmlns=\"http://www.sitemaps.org/schemas/sitemap/0.9\">\n    <url>\n        <loc>%s/page-02</loc>\n        <lastmod>2024-02-12T12:34:56+01:00</lastmod>\n        <changefreq>hourly</changefreq>\n        <priority>0.5</priority>\n    </url>\n    <url>\n        <loc>%s/page-03</loc>\n        <lastmod>2024-02-12T12:34:56+01:00</lastmod>\n        <changefreq>daily</changefreq>\n        <priority>0.5</priority>\n    </url>\n</urlset>\n", server.URL, server.URL),
+			err:  nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New()
+			_, err := s.parseURLSet(test.data)
+
+			if test.err != nil {
+				if err == nil {
+					t.Errorf("expected %v, got %v", test.err, err)
+				} else if err.Error() != test.err.Error() {
+					t.Errorf("expected %v, got %v", test.err, err)
+				}
+			} else {
+				if err != nil {
+					t.Errorf("expected %v, got %v", test.err, err)
+				}
+			}
+		})
+	}
+}
+
+func TestS_parseURLSet_Extensions(t *testing.T) {
+	content, err := os.ReadFile("./test/sitemap-extensions.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := New()
+	urlSet, err := s.parseURLSet(string(content))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []URL{
+		{
+			Loc:     "http://HOST/page-01",
+			LastMod: pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, time.FixedZone("", 3600))}),
+			Images: []Image{
+				{Loc: "http://HOST/images/01.jpg", Caption: "Caption & more"},
+				{Loc: "http://HOST/images/02.jpg", Title: "Title"},
+			},
+		},
+		{
+			Loc: "http://HOST/page-02",
+			Videos: []Video{
+				{
+					ThumbnailLoc:    "http://HOST/thumbs/01.jpg",
+					Title:           "Video title",
+					Description:     "Video description",
+					ContentLoc:      "http://HOST/videos/01.mp4",
+					Duration:        "600",
+					PublicationDate: "2024-02-12T12:34:56+01:00",
+					FamilyFriendly:  "yes",
+					Tags:            []string{"first", "second"},
+				},
+			},
+		},
+		{
+			Loc: "http://HOST/page-03",
+			News: &News{
+				Publication:     NewsPublication{Name: "The Example Times", Language: "en"},
+				PublicationDate: "2024-02-12T12:34:56+01:00",
+				Title:           "Headline",
+			},
+		},
+		{
+			Loc:        "http://HOST/page-04",
+			ChangeFreq: pointerOfURLChangeFreq(changeFreqDaily),
+			Priority:   pointerOfFloat32(0.8),
+			Alternates: []Alternate{
+				{Rel: "alternate", Hreflang: "de", Href: "http://HOST/de/page-04"},
+				{Rel: "alternate", Hreflang: "x-default", Href: "http://HOST/page-04"},
+			},
+		},
+		{
+			Loc: "http://HOST/page-05",
+		},
+	}
+
+	if !reflect.DeepEqual(urlSet.URL, want) {
+		t.Errorf("expected %+v, got %+v", want, urlSet.URL)
+	}
+}
+
+func TestS_unzip(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		output   []byte
+		hasError bool
+	}{
+		{
+			name:     "Valid content",
+			input:    gzipByte("hello world"),
+			output:   []byte("hello world"),
+			hasError: false,
+		},
+		{
+			name:     "Multi-member content",
+			input:    append(gzipByte("hello "), gzipByte("world")...),
+			output:   []byte("hello world"),
+			hasError: false,
+		},
+		{
+			name:     "Trailing newline",
+			input:    append(gzipByte("hello world"), '\n'),
+			output:   []byte("hello world"),
+			hasError: false,
+		},
+		{
+			name:     "Invalid gzip content",
+			input:    []byte("\x1f\x8b\x08" + "invalid"),
+			output:   []byte("\x1f\x8b\x08" + "invalid"),
+			hasError: true,
+		},
+		{
+			name:     "Invalid content",
+			input:    []byte("invalid"),
+			output:   []byte("invalid"),
+			hasError: true,
+		},
+		{
+			name:     "Empty content",
+			input:    []byte(""),
+			output:   nil,
+			hasError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New()
 
-			if len(sitemapLocationsAdded) != int(test.sitemapLocationsAddedCount) {
-				t.Errorf("expected %d, got %d", test.sitemapLocationsAddedCount, len(sitemapLocationsAdded))
-			}
+			uncompressed, err := s.unzip(test.input)
 
-			if len(s.urls) != int(test.urlsCount) {
-				t.Errorf("expected %d, got %d", test.urlsCount, len(s.urls))
+			if (err != nil) != test.hasError {
+				t.Errorf("expected %v, got %v", test.hasError, err)
 			}
 
-			if len(s.errs) != int(test.errsCount) {
-				t.Errorf("expected %d, got %d", test.errsCount, len(s.errs))
+			if !bytes.Equal(uncompressed, test.output) {
+				t.Errorf("expected %v, got %v", test.output, uncompressed)
 			}
+
 		})
 	}
 }
 
-func TestS_parseSitemapIndex(t *testing.T) {
-	server := testServer()
-	defer server.Close()
+func TestS_Parse_MultistreamGzip(t *testing.T) {
+	content, err := os.ReadFile("./test/sitemap-multistream.xml.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	urlSetContent := string(content)
+
+	s, err := New().Parse("https://www.sitemaps.org/sitemap-multistream.xml.gz", &urlSetContent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.GetErrorsCount() != 0 {
+		t.Fatalf("unexpected errors: %v", s.GetErrors())
+	}
+	want := []string{"http://HOST/page-01", "http://HOST/page-02", "http://HOST/page-03", "http://HOST/page-04"}
+	if got := locsOf(s.GetURLs()); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestS_Parse_StrictDecompression(t *testing.T) {
+	truncated, err := os.ReadFile("./test/sitemap-truncated.xml.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	badChecksum := gzipByte("<urlset></urlset>")
+	badChecksum[len(badChecksum)-8] ^= 0xff
+
+	// rawServer serves the given bodies as they are, the test server would recompress them
+	rawServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sitemap-truncated.xml.gz":
+			_, _ = w.Write(truncated)
+		case "/sitemap-checksum.xml.gz":
+			_, _ = w.Write(badChecksum)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer rawServer.Close()
 
 	tests := []struct {
-		name         string
-		data         string
-		sitemapIndex sitemapIndex
-		err          error
+		name          string
+		strict        bool
+		path          string
+		wantErr       bool
+		errsCount     int64
+		warningsCount int
+		truncated     bool
 	}{
 		{
-			name: "empty content",
-			data: "",
-			err:  errors.New("sitemapindex is empty"),
+			name:          "truncated, lenient",
+			path:          "/sitemap-truncated.xml.gz",
+			errsCount:     1, // the partial content is not a valid document
+			warningsCount: 1,
+			truncated:     true,
 		},
 		{
-			name: "invalid content",
-			data: "invalid content",
-			err:  errors.New("EOF"),
+			name:      "truncated, strict",
+			strict:    true,
+			path:      "/sitemap-truncated.xml.gz",
+			wantErr:   true,
+			errsCount: 1,
+			truncated: true,
 		},
 		{
-			name: "SitemapIndex",
-			data: fmt.Sprintf("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<sitemapindex xmlns=\"http://www.sitemaps.org/schemas/sitemap/0.9\">\n    <sitemap>\n        <loc>%s/sitemap-01.xml.gz</loc>\n        <lastmod>2024-02-12T12:34:56+01:00</lastmod>\n    </sitemap>\n    <sitemap>\n        <loc>%s/sitemap-02.xml.gz</loc>\n        <lastmod>2024-02-12T12:34:56+01:00</lastmod>\n    </sitemap>\n    <sitemap>\n        <loc>%s/sitemap-03.xml.gz</loc>\n        <lastmod>2024-02-12T12:34:56+01:00</lastmod>\n    </sitemap>\n</sitemapindex>", server.URL, server.URL, server.URL),
-			err:  nil,
+			name:      "checksum, lenient",
+			path:      "/sitemap-checksum.xml.gz",
+			errsCount: 2, // the checksum error and the original content not being a valid document
 		},
 		{
-			name: "URLSet",
-			data: fmt.Sprintf("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<urlset xmlns=\"http://www.sitemaps.org/schemas/sitemap/0.9\">\n    <url>\n        <loc>%s/page-02</loc>\n        <lastmod>2024-02-12T12:34:56+01:00</lastmod>\n        <changefreq>hourly</changefreq>\n        <priority>0.5</priority>\n    </url>\n    <url>\n        <loc>%s/page-03</loc>\n        <lastmod>2024-02-12T12:34:56+01:00</lastmod>\n        <changefreq>daily</changefreq>\n        <priority>0.5</priority>\n    </url>\n</urlset>\n", server.URL, server.URL),
-			err:  xml.UnmarshalError("expected element type <sitemapindex> but have <urlset>"),
+			name:      "checksum, strict",
+			strict:    true,
+			path:      "/sitemap-checksum.xml.gz",
+			wantErr:   true,
+			errsCount: 1,
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			s := New()
-			_, err := s.parseSitemapIndex(test.data)
+			url := rawServer.URL + test.path
+			s, err := New().SetStrictDecompression(test.strict).Parse(url, nil)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("expected error %v, got %v", test.wantErr, err)
+			}
+			if s.GetErrorsCount() != test.errsCount {
+				t.Errorf("expected %d errors, got %v", test.errsCount, s.GetErrors())
+			}
+			if len(s.GetWarnings()) != test.warningsCount {
+				t.Errorf("expected %d warnings, got %v", test.warningsCount, s.GetWarnings())
+			}
 
-			if test.err != nil {
-				if err == nil {
-					t.Errorf("expected %v, got %v", test.err, err)
-				} else if err.Error() != test.err.Error() {
-					t.Errorf("expected %v, got %v", test.err, err)
+			if !test.strict && !test.truncated {
+				// the current behavior is kept: the underlying error is recorded as is
+				if !errors.Is(s.GetErrors()[0], gzip.ErrChecksum) {
+					t.Errorf("expected %v, got %v", gzip.ErrChecksum, s.GetErrors()[0])
 				}
-			} else {
-				if err != nil {
-					t.Errorf("expected %v, got %v", test.err, err)
+				return
+			}
+			recorded := append(s.GetWarnings(), s.GetErrors()...)
+			var decompressionErr *DecompressionError
+			if !errors.As(recorded[0], &decompressionErr) {
+				t.Fatalf("expected *DecompressionError, got %v", recorded[0])
+			}
+			if decompressionErr.Location != url || decompressionErr.Truncated != test.truncated {
+				t.Errorf("unexpected decompression error: %+v", decompressionErr)
+			}
+			if test.truncated && !errors.Is(decompressionErr, io.ErrUnexpectedEOF) {
+				t.Errorf("expected %v, got %v", io.ErrUnexpectedEOF, decompressionErr)
+			}
+			if !test.truncated && !errors.Is(decompressionErr, gzip.ErrChecksum) {
+				t.Errorf("expected %v, got %v", gzip.ErrChecksum, decompressionErr)
+			}
+			if test.strict {
+				if s.GetURLCount() != 0 || s.GetCompleteness().FailedSitemaps != 1 {
+					t.Errorf("expected the content to be discarded, got %d URLs and %+v", s.GetURLCount(), s.GetCompleteness())
 				}
 			}
 		})
 	}
+
+	t.Run("index sitemap, strict", func(t *testing.T) {
+		indexContent := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?><sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><sitemap><loc>%s/sitemap-truncated.xml.gz</loc></sitemap></sitemapindex>`, rawServer.URL)
+		s, err := New().SetStrictDecompression(true).Parse(rawServer.URL+"/sitemapindex.xml", &indexContent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decompressionErr *DecompressionError
+		if s.GetErrorsCount() != 1 || !errors.As(s.GetErrors()[0], &decompressionErr) || !decompressionErr.Truncated {
+			t.Errorf("expected a single truncation error, got %v", s.GetErrors())
+		}
+		if got := s.GetCompleteness().FailedSitemaps; got != 1 {
+			t.Errorf("expected 1 failed sitemap, got %d", got)
+		}
+	})
 }
 
-func TestS_parseURLSet(t *testing.T) {
-	server := testServer()
-	defer server.Close()
+func TestS_Parse_Retries(t *testing.T) {
+	content, err := os.ReadFile("./test/sitemap-02.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	good := gzipByte(string(content))
+	corrupted := append([]byte(nil), good...)
+	corrupted[len(corrupted)-8] ^= 0xff
 
 	tests := []struct {
-		name string
-		data string
-		err  error
+		name          string
+		retries       int
+		corruptions   int64
+		viaIndex      bool
+		wantURLs      int64
+		wantRetries   int
+		wantRequests  int64
+		wantCorrupted bool
 	}{
 		{
-			name: "empty content",
-			data: "",
-			err:  errors.New("sitemap is empty"),
+			name:          "no retries",
+			retries:       0,
+			corruptions:   1,
+			wantURLs:      0,
+			wantRetries:   0,
+			wantRequests:  1,
+			wantCorrupted: true,
 		},
 		{
-			name: "invalid content",
-			data: "invalid content",
-			err:  errors.New("EOF"),
+			name:         "corrupted once",
+			retries:      2,
+			corruptions:  1,
+			wantURLs:     2,
+			wantRetries:  1,
+			wantRequests: 2,
 		},
 		{
-			name: "Sitemap",
-			data: fmt.Sprintf("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<sitemapindex xmlns=\"http://www.sitemaps.org/schemas/sitemap/0.9\">\n    <sitemap>\n        <loc>%s/sitemap-01.xml.gz</loc>\n        <lastmod>2024-02-12T12:34:56+01:00</lastmod>\n    </sitemap>\n    <sitemap>\n        <loc>%s/sitemap-02.xml.gz</loc>\n        <lastmod>2024-02-12T12:34:56+01:00</lastmod>\n    </sitemap>\n    <sitemap>\n        <loc>%s/sitemap-03.xml.gz</loc>\n        <lastmod>2024-02-12T12:34:56+01:00</lastmod>\n    </sitemap>\n</sitemapindex>", server.URL, server.URL, server.URL),
-			err:  xml.UnmarshalError("expected element type <urlset> but have <sitemapindex>"),
+			name:         "corrupted once, via index",
+			retries:      2,
+			corruptions:  1,
+			viaIndex:     true,
+			wantURLs:     2,
+			wantRetries:  1,
+			wantRequests: 2,
 		},
 		{
-			name: "URLSet",
-			data: fmt.Sprintf("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<urlset xmlns=\"http://www.sitemaps.org/schemas/sitemap/0.9\">\n    <url>\n        <loc>%s/page-02</loc>\n        <lastmod>2024-02-12T12:34:56+01:00</lastmod>\n        <changefreq>hourly</changefreq>\n        <priority>0.5</priority>\n    </url>\n    <url>\n        <loc>%s/page-03</loc>\n        <lastmod>2024-02-12T12:34:56+01:00</lastmod>\n        <changefreq>daily</changefreq>\n        <priority>0.5</priority>\n    </url>\n</urlset>\n", server.URL, server.URL),
-			err:  nil,
+			name:          "always corrupted",
+			retries:       2,
+			corruptions:   10,
+			wantURLs:      0,
+			wantRetries:   2,
+			wantRequests:  3,
+			wantCorrupted: true,
+		},
+		{
+			name:         "not corrupted",
+			retries:      2,
+			corruptions:  0,
+			wantURLs:     2,
+			wantRetries:  0,
+			wantRequests: 1,
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			s := New()
-			_, err := s.parseURLSet(test.data)
-
-			if test.err != nil {
-				if err == nil {
-					t.Errorf("expected %v, got %v", test.err, err)
-				} else if err.Error() != test.err.Error() {
-					t.Errorf("expected %v, got %v", test.err, err)
+			var requests int64
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt64(&requests, 1) <= test.corruptions {
+					_, _ = w.Write(corrupted)
+					return
 				}
+				_, _ = w.Write(good)
+			}))
+			defer server.Close()
+
+			url := server.URL + "/sitemap.xml.gz"
+			s := New().SetRetries(test.retries)
+			if test.viaIndex {
+				indexContent := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?><sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><sitemap><loc>%s</loc></sitemap></sitemapindex>`, url)
+				s, err = s.Parse(server.URL+"/sitemapindex.xml", &indexContent)
 			} else {
-				if err != nil {
-					t.Errorf("expected %v, got %v", test.err, err)
-				}
+				s, err = s.Parse(url, nil)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if s.GetURLCount() != test.wantURLs {
+				t.Errorf("expected %d URLs, got %d: %v", test.wantURLs, s.GetURLCount(), s.GetErrors())
+			}
+			if got := atomic.LoadInt64(&requests); got != test.wantRequests {
+				t.Errorf("expected %d requests, got %d", test.wantRequests, got)
+			}
+			if got := s.GetFetchMetadata()[url].Retries; got != test.wantRetries {
+				t.Errorf("expected %d retries, got %d", test.wantRetries, got)
+			}
+			if corrupted := len(s.GetErrors()) > 0 && errors.Is(s.GetErrors()[0], gzip.ErrChecksum); corrupted != test.wantCorrupted {
+				t.Errorf("expected corrupted %v, got %v", test.wantCorrupted, s.GetErrors())
 			}
 		})
 	}
 }
 
-func TestS_unzip(t *testing.T) {
+func TestS_inflate(t *testing.T) {
 	tests := []struct {
 		name     string
 		input    []byte
@@ -1527,14 +3292,20 @@ func TestS_unzip(t *testing.T) {
 	}{
 		{
 			name:     "Valid content",
-			input:    gzipByte("hello world"),
+			input:    zlibByte("hello world"),
 			output:   []byte("hello world"),
 			hasError: false,
 		},
 		{
-			name:     "Invalid gzip content",
-			input:    []byte("\x1f\x8b\x08" + "invalid"),
-			output:   []byte("\x1f\x8b\x08" + "invalid"),
+			name:     "Truncated content",
+			input:    zlibByte("hello world")[:8],
+			output:   []byte("h"),
+			hasError: false,
+		},
+		{
+			name:     "Invalid zlib content",
+			input:    []byte("\x78\x9c" + "invalid"),
+			output:   []byte("\x78\x9c" + "invalid"),
 			hasError: true,
 		},
 		{
@@ -1543,19 +3314,13 @@ func TestS_unzip(t *testing.T) {
 			output:   []byte("invalid"),
 			hasError: true,
 		},
-		{
-			name:     "Empty content",
-			input:    []byte(""),
-			output:   nil,
-			hasError: true,
-		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			s := New()
 
-			uncompressed, err := s.unzip(test.input)
+			uncompressed, err := s.inflate(test.input)
 
 			if (err != nil) != test.hasError {
 				t.Errorf("expected %v, got %v", test.hasError, err)
@@ -1564,7 +3329,141 @@ func TestS_unzip(t *testing.T) {
 			if !bytes.Equal(uncompressed, test.output) {
 				t.Errorf("expected %v, got %v", test.output, uncompressed)
 			}
+		})
+	}
+}
+
+func TestIsZlib(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+		want    bool
+	}{
+		{name: "default compression", content: zlibByte("a"), want: true},
+		{name: "best compression", content: []byte{0x78, 0xda}, want: true},
+		{name: "no compression", content: []byte{0x78, 0x01}, want: true},
+		{name: "xml", content: []byte("<?xml"), want: false},
+		{name: "x", content: []byte("xml"), want: false},
+		{name: "too short", content: []byte{0x78}, want: false},
+		{name: "empty", content: nil, want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isZlib(test.content); got != test.want {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestS_Parse_Zlib(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	t.Run("zlib urlset", func(t *testing.T) {
+		s, err := New().Parse(fmt.Sprintf("%s/sitemap-02.xml.zlib", server.URL), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.GetURLCount() != 2 || s.GetErrorsCount() != 0 {
+			t.Errorf("expected 2 URLs and no errors, got %d URLs and %v", s.GetURLCount(), s.GetErrors())
+		}
+		if s.GetURLs()[0].Loc != fmt.Sprintf("%s/page-02", server.URL) {
+			t.Errorf("unexpected URL %s", s.GetURLs()[0].Loc)
+		}
+	})
+
+	t.Run("corrupted zlib", func(t *testing.T) {
+		s, _ := New().Parse(fmt.Sprintf("%s/sitemap-corrupted.xml.zlib", server.URL), nil)
+		if s.GetURLCount() != 0 || s.GetErrorsCount() == 0 {
+			t.Errorf("expected errors and no URLs, got %d URLs and %v", s.GetURLCount(), s.GetErrors())
+		}
+	})
+
+	content, err := os.ReadFile("./test/sitemap-01.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rawDeflate bytes.Buffer
+	flateWriter, _ := flate.NewWriter(&rawDeflate, flate.DefaultCompression)
+	_, _ = flateWriter.Write(content)
+	_ = flateWriter.Close()
+
+	tests := []struct {
+		name string
+		body []byte
+	}{
+		{name: "Content-Encoding deflate, zlib", body: zlibByte(string(content))},
+		{name: "Content-Encoding deflate, raw deflate", body: rawDeflate.Bytes()},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			deflateServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Encoding", "deflate")
+				_, _ = w.Write(test.body)
+			}))
+			defer deflateServer.Close()
+
+			s, err := New().Parse(fmt.Sprintf("%s/sitemap.xml", deflateServer.URL), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.GetURLCount() != 1 {
+				t.Errorf("expected 1 URL, got %d: %v", s.GetURLCount(), s.GetErrors())
+			}
+			meta := s.GetFetchMetadata()[fmt.Sprintf("%s/sitemap.xml", deflateServer.URL)]
+			if meta.CompressedBytes != int64(len(test.body)) || meta.DecompressedBytes != int64(len(content)) {
+				t.Errorf("unexpected sizes: %+v", meta)
+			}
+		})
+	}
+}
+
+func TestS_Parse_ZlibMaxDecompressedSize(t *testing.T) {
+	content := bombContent(20000)
+	var rawDeflate bytes.Buffer
+	flateWriter, _ := flate.NewWriter(&rawDeflate, flate.BestCompression)
+	_, _ = flateWriter.Write(content)
+	_ = flateWriter.Close()
+
+	tests := []struct {
+		name     string
+		encoding string
+		body     []byte
+	}{
+		{name: "zlib", body: zlibByte(string(content))},
+		{name: "Content-Encoding deflate, zlib", encoding: "deflate", body: zlibByte(string(content))},
+		{name: "Content-Encoding deflate, raw deflate", encoding: "deflate", body: rawDeflate.Bytes()},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if len(test.body) > 64<<10 {
+				t.Fatalf("expected a small compressed body, got %d bytes", len(test.body))
+			}
+			bombServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if test.encoding != "" {
+					w.Header().Set("Content-Encoding", test.encoding)
+				}
+				_, _ = w.Write(test.body)
+			}))
+			defer bombServer.Close()
+
+			s, err := New().SetMaxDecompressedSize(64<<10).Parse(fmt.Sprintf("%s/sitemap.xml", bombServer.URL), nil)
+			if !errors.Is(err, ErrDecompressedTooLarge) {
+				t.Errorf("expected %v, got %v", ErrDecompressedTooLarge, err)
+			}
+			if s.GetURLCount() != 0 {
+				t.Errorf("expected no URLs, got %d", s.GetURLCount())
+			}
+			if errs := s.GetErrors(); len(errs) != 1 || !errors.Is(errs[0], ErrDecompressedTooLarge) {
+				t.Errorf("expected a single error matching %v, got %v", ErrDecompressedTooLarge, errs)
+			}
 
+			s, err = New().SetMaxDecompressedSize(int64(len(content))).Parse(fmt.Sprintf("%s/sitemap.xml", bombServer.URL), nil)
+			if err != nil || s.GetURLCount() != 20000 {
+				t.Errorf("expected 20000 URLs at the maximum size, got %d: %v", s.GetURLCount(), s.GetErrors())
+			}
 		})
 	}
 }
@@ -1618,6 +3517,8 @@ func configsEqual(c1, c2 config) bool {
 	return c1.fetchTimeout == c2.fetchTimeout &&
 		c1.userAgent == c2.userAgent &&
 		c1.multiThread == c2.multiThread &&
+		c1.followIndexes == c2.followIndexes &&
+		c1.skipURLs == c2.skipURLs &&
 		reflect.DeepEqual(c1.follow, c2.follow) &&
 		reflect.DeepEqual(c1.rules, c2.rules)
 }
@@ -1634,7 +3535,7 @@ func pointerOfTime(t time.Time) *time.Time {
 	return &t
 }
 
-func pointerOfLastModTime(lmt lastModTime) *lastModTime {
+func pointerOfLastModTime(lmt LastMod) *LastMod {
 	return &lmt
 }
 
@@ -1684,6 +3585,18 @@ func compareURLsArray(sitemapURLs []URL, testCaseURLs []URL) bool {
 	return true
 }
 
+func zlibByte(s string) []byte {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	if _, err := zw.Write([]byte(s)); err != nil {
+		panic(err)
+	}
+	if err := zw.Close(); err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
+
 func gzipByte(s string) []byte {
 	var buf bytes.Buffer
 	gz := gzip.NewWriter(&buf)
@@ -1695,3 +3608,129 @@ func gzipByte(s string) []byte {
 	}
 	return buf.Bytes()
 }
+
+func TestS_Parse_Doctype(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	for _, collectURLs := range []bool{true, false} {
+		location := server.URL + "/sitemap-billion-laughs.xml"
+		s, err := New().SetCollectURLs(collectURLs).Parse(location, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.GetURLCount() != 0 {
+			t.Errorf("expected no URLs, got %d", s.GetURLCount())
+		}
+		errs := s.GetErrors()
+		if len(errs) != 1 || !errors.Is(errs[0], ErrDoctypeNotAllowed) || !strings.HasPrefix(errs[0].Error(), location) {
+			t.Errorf("expected %v of %s, got %v", ErrDoctypeNotAllowed, location, errs)
+		}
+		if node := s.GetSitemapTree(); !errors.Is(node.Err, ErrDoctypeNotAllowed) {
+			t.Errorf("expected the node error %v, got %v", ErrDoctypeNotAllowed, node.Err)
+		}
+	}
+}
+
+func TestS_Parse_DecodeLimits(t *testing.T) {
+	content := `<urlset><url><loc>https://www.example.com/` + strings.Repeat("a", 100) + `</loc></url></urlset>`
+
+	for _, collectURLs := range []bool{true, false} {
+		location := "https://www.example.com/sitemap.xml"
+		s, err := New().SetCollectURLs(collectURLs).SetDecodeLimits(DecodeLimits{MaxCharDataLength: 64}).Parse(location, &content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.GetURLCount() != 0 {
+			t.Errorf("expected no URLs, got %d", s.GetURLCount())
+		}
+		var limitErr *DecodeLimitError
+		errs := s.GetErrors()
+		if len(errs) != 1 || !errors.As(errs[0], &limitErr) {
+			t.Fatalf("expected a decode limit error, got %v", errs)
+		}
+		want := DecodeLimitError{Location: location, Limit: DecodeLimitCharDataLength, Max: 64}
+		if *limitErr != want {
+			t.Errorf("expected %v, got %v", want, *limitErr)
+		}
+		if !strings.HasPrefix(errs[0].Error(), location) {
+			t.Errorf("expected the location in %v", errs[0])
+		}
+	}
+}
+
+func TestS_Parse_MaxURLsPerSitemap(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	tests := []struct {
+		name          string
+		s             *S
+		wantCounts    map[string]int64
+		wantTruncated map[string]bool
+		wantURLs      int
+	}{
+		{
+			name:          "no limit",
+			s:             New(),
+			wantCounts:    map[string]int64{"01": 5, "02": 3, "03": 2, "04": 1},
+			wantTruncated: map[string]bool{},
+			wantURLs:      11,
+		},
+		{
+			name:          "limit",
+			s:             New().SetMaxURLsPerSitemap(2),
+			wantCounts:    map[string]int64{"01": 2, "02": 2, "03": 2, "04": 1},
+			wantTruncated: map[string]bool{"01": true, "02": true},
+			wantURLs:      7,
+		},
+		{
+			name:          "limit, URLs not collected",
+			s:             New().SetMaxURLsPerSitemap(2).SetCollectURLs(false),
+			wantCounts:    map[string]int64{"01": 2, "02": 2, "03": 2, "04": 1},
+			wantTruncated: map[string]bool{"01": true, "02": true},
+			wantURLs:      0,
+		},
+		{
+			name:          "limit with rules",
+			s:             New().SetMaxURLsPerSitemap(2).SetRules([]string{`page-0[2-5]$`}),
+			wantCounts:    map[string]int64{"01": 2, "02": 2, "03": 1, "04": 0},
+			wantTruncated: map[string]bool{"01": true},
+			wantURLs:      5,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := test.s.Parse(server.URL+"/sitemapindex-shards.xml", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.GetErrorsCount() != 0 {
+				t.Fatalf("unexpected errors: %v", s.GetErrors())
+			}
+
+			counts := map[string]int64{}
+			truncated := map[string]bool{}
+			for _, node := range s.GetSitemapTree().Children {
+				shard := strings.TrimSuffix(strings.TrimPrefix(node.Loc, server.URL+"/sitemap-shard-"), ".xml")
+				counts[shard] = node.URLCount
+				if node.Truncated {
+					truncated[shard] = true
+				}
+			}
+			if !reflect.DeepEqual(counts, test.wantCounts) {
+				t.Errorf("expected counts %v, got %v", test.wantCounts, counts)
+			}
+			if !reflect.DeepEqual(truncated, test.wantTruncated) {
+				t.Errorf("expected truncated %v, got %v", test.wantTruncated, truncated)
+			}
+			if got := len(s.GetURLs()); got != test.wantURLs {
+				t.Errorf("expected %d URLs, got %d", test.wantURLs, got)
+			}
+			if !s.GetCompleteness().Complete {
+				t.Errorf("expected a complete parse, got %v", s.GetCompleteness())
+			}
+		})
+	}
+}
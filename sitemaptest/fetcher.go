@@ -0,0 +1,53 @@
+package sitemaptest
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"sync"
+)
+
+// FakeFetcher is a fetcher serving the contents of a map keyed by URL without a network, for the SetFetcher method
+// of the sitemap parser (it implements its Fetcher interface). A URL missing from the map fails with an error wrapping fs.ErrNotExist.
+// It is safe for concurrent use as long as the map is not modified while fetching. Create it with NewFakeFetcher.
+// The contents field is the map of the contents, the requests field lists the URLs fetched, in order.
+type FakeFetcher struct {
+	mu       sync.Mutex
+	contents map[string][]byte
+	requests []string
+}
+
+// NewFakeFetcher creates a FakeFetcher serving the given contents keyed by URL, e.g. built with URLSet and SitemapIndex:
+//
+//	fetcher := sitemaptest.NewFakeFetcher(map[string][]byte{
+//		"https://www.example.com/sitemap.xml": sitemaptest.URLSet("https://www.example.com/page-1"),
+//	})
+//	s, err := sitemap.New().SetFetcher(fetcher).Parse("https://www.example.com/sitemap.xml", nil)
+func NewFakeFetcher(contents map[string][]byte) *FakeFetcher {
+	return &FakeFetcher{contents: contents}
+}
+
+// Fetch returns the content of the given URL, or an error wrapping fs.ErrNotExist if the map has none.
+// It returns the error of ctx if ctx is done. The URL is recorded in the requests in both cases, see Requests.
+func (f *FakeFetcher) Fetch(ctx context.Context, url string) ([]byte, error) {
+	f.mu.Lock()
+	f.requests = append(f.requests, url)
+	f.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	content, ok := f.contents[url]
+	if !ok {
+		return nil, fmt.Errorf("fetching %q: %w", url, fs.ErrNotExist)
+	}
+	return append([]byte(nil), content...), nil
+}
+
+// Requests returns a copy of the URLs fetched so far, in the order they were fetched.
+func (f *FakeFetcher) Requests() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]string{}, f.requests...)
+}
@@ -0,0 +1,66 @@
+package sitemaptest_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"reflect"
+	"testing"
+
+	sitemap "github.com/aafeher/go-sitemap-parser"
+	"github.com/aafeher/go-sitemap-parser/sitemaptest"
+)
+
+var _ sitemap.Fetcher = (*sitemaptest.FakeFetcher)(nil)
+
+func TestFakeFetcher(t *testing.T) {
+	fetcher := sitemaptest.NewFakeFetcher(map[string][]byte{
+		"https://example.com/sitemap-index.xml": sitemaptest.SitemapIndex("https://example.com/sitemap-1.xml", "https://example.com/sitemap-2.xml"),
+		"https://example.com/sitemap-1.xml":     sitemaptest.URLSet("https://example.com/a", "https://example.com/b"),
+	})
+
+	s, err := sitemap.New().SetMultiThread(false).SetFetcher(fetcher).Parse("https://example.com/sitemap-index.xml", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.GetLocs(), []string{"https://example.com/a", "https://example.com/b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	errs := s.GetErrors()
+	if len(errs) != 1 || !errors.Is(errs[0], fs.ErrNotExist) {
+		t.Errorf("expected a not found error for the missing sitemap, got %v", errs)
+	}
+	want := []string{"https://example.com/sitemap-index.xml", "https://example.com/sitemap-1.xml", "https://example.com/sitemap-2.xml"}
+	if got := fetcher.Requests(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected requests %v, got %v", want, got)
+	}
+}
+
+func TestFakeFetcher_Fetch(t *testing.T) {
+	content := []byte("content")
+	fetcher := sitemaptest.NewFakeFetcher(map[string][]byte{"https://example.com/a": content})
+
+	got, err := fetcher.Fetch(context.Background(), "https://example.com/a")
+	if err != nil || string(got) != "content" {
+		t.Fatalf("expected the content, got %q (%v)", got, err)
+	}
+	got[0] = 'C'
+	if string(content) != "content" {
+		t.Error("expected the returned content to be a copy")
+	}
+
+	if _, err = fetcher.Fetch(context.Background(), "https://example.com/b"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected %v, got %v", fs.ErrNotExist, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = fetcher.Fetch(ctx, "https://example.com/a"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+
+	want := []string{"https://example.com/a", "https://example.com/b", "https://example.com/a"}
+	if got := fetcher.Requests(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected requests %v, got %v", want, got)
+	}
+}
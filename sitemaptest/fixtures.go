@@ -0,0 +1,56 @@
+package sitemaptest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"testing/fstest"
+)
+
+// URLSet returns a <urlset> document of the sitemaps.org protocol with a <url> entry for each of the given locations.
+func URLSet(locs ...string) []byte {
+	return document("urlset", "url", locs)
+}
+
+// SitemapIndex returns a <sitemapindex> document of the sitemaps.org protocol with a <sitemap> entry for each of the given locations.
+func SitemapIndex(locs ...string) []byte {
+	return document("sitemapindex", "sitemap", locs)
+}
+
+// ShardedIndex returns the files of a sitemap index of n sitemaps of m URLs each, to be served by NewFixtureServer.
+// The index is "sitemap-index.xml", the sitemaps are "sitemap-0001.xml", "sitemap-0002.xml", etc.,
+// and the URLs are "http://HOST/page-0001-0001", "http://HOST/page-0001-0002", etc., numbered by their sitemap and their position.
+// The files can be added to, e.g. to include a fixture failing to be parsed.
+func ShardedIndex(n int, m int) fstest.MapFS {
+	files := fstest.MapFS{}
+	shards := make([]string, 0, n)
+	for i := 1; i <= n; i++ {
+		name := fmt.Sprintf("sitemap-%04d.xml", i)
+		shards = append(shards, "http://HOST/"+name)
+
+		locs := make([]string, 0, m)
+		for j := 1; j <= m; j++ {
+			locs = append(locs, fmt.Sprintf("http://HOST/page-%04d-%04d", i, j))
+		}
+		files[name] = &fstest.MapFile{Data: URLSet(locs...)}
+	}
+	files["sitemap-index.xml"] = &fstest.MapFile{Data: SitemapIndex(shards...)}
+
+	return files
+}
+
+// document returns a document of the sitemaps.org protocol with the given root element,
+// containing an entry element with a <loc> element for each of the given locations.
+func document(root string, entry string, locs []string) []byte {
+	var doc bytes.Buffer
+	doc.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	fmt.Fprintf(&doc, `<%s xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`+"\n", root)
+	for _, loc := range locs {
+		fmt.Fprintf(&doc, "    <%s><loc>", entry)
+		_ = xml.EscapeText(&doc, []byte(loc))
+		fmt.Fprintf(&doc, "</loc></%s>\n", entry)
+	}
+	fmt.Fprintf(&doc, "</%s>\n", root)
+
+	return doc.Bytes()
+}
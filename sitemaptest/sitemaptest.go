@@ -0,0 +1,160 @@
+// Package sitemaptest provides utilities for testing code using the sitemap package:
+// an HTTP server serving sitemap fixtures, and builders of fixtures.
+package sitemaptest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+// NewFixtureServer starts and returns a new test server serving the files of fsys, see NewHandler.
+// The fixtures refer to the server with the "HOST" placeholder, e.g. "http://HOST/sitemap-01.xml",
+// as the address of the server is only known once it is started. The caller should call Close when finished, to shut it down.
+//
+// A fixture directory can be served with os.DirFS, and fixtures built in memory with fstest.MapFS:
+//
+//	server := sitemaptest.NewFixtureServer(fstest.MapFS{
+//		"sitemap.xml": {Data: sitemaptest.URLSet("http://HOST/page-1")},
+//	})
+//	defer server.Close()
+func NewFixtureServer(fsys fs.FS) *httptest.Server {
+	return httptest.NewServer(NewHandler(fsys))
+}
+
+// NewHandler returns an HTTP handler serving the files of fsys by the (unescaped) path of the request, with every "HOST" string of the files
+// replaced with the Host header of the request, followed by a newline.
+// Gzip- and zlib-compressed files are decompressed, the placeholders are replaced, then they are compressed again;
+// a truncated compressed file is served complete, as far as it could be decompressed. A zlib-compressed file that cannot be
+// decompressed is served as it is, while for a gzip-compressed one the decompression error is served.
+// Other files, including those compressed with other formats, are served with the placeholders replaced in their raw bytes.
+// The index page, directories and missing files are not found.
+func NewHandler(fsys fs.FS) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/")
+		if name == "" || !fs.ValidPath(name) {
+			// index page is always not found
+			http.NotFound(w, r)
+			return
+		}
+		res, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		if bytes.Contains(res, []byte("\x1f\x8b\x08")) {
+			uncompressed, err := gunzip(res)
+			if err != nil {
+				_, _ = fmt.Fprintf(w, "error: %v\n", err)
+				return
+			}
+			res = Gzip(replaceHost(uncompressed, r.Host))
+		} else if isZlib(res) {
+			uncompressed, err := inflate(res)
+			if err != nil {
+				// serve corrupted files as they are
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write(res)
+				return
+			}
+			res = Zlib(replaceHost(uncompressed, r.Host))
+		} else {
+			res = replaceHost(res, r.Host)
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = fmt.Fprintln(w, string(res))
+	})
+}
+
+// Gzip returns the given content compressed with gzip, e.g. to add a compressed fixture to a file system.
+func Gzip(content []byte) []byte {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, _ = writer.Write(content)
+	_ = writer.Close()
+	return compressed.Bytes()
+}
+
+// Zlib returns the given content compressed with zlib, see Gzip.
+func Zlib(content []byte) []byte {
+	var compressed bytes.Buffer
+	writer := zlib.NewWriter(&compressed)
+	_, _ = writer.Write(content)
+	_ = writer.Close()
+	return compressed.Bytes()
+}
+
+// replaceHost replaces every "HOST" placeholder of the content with the given host.
+func replaceHost(content []byte, host string) []byte {
+	return bytes.ReplaceAll(content, []byte("HOST"), []byte(host))
+}
+
+// gunzip decompresses all members of the given gzip-compressed content, ignoring trailing bytes after the last member.
+// If the content is truncated, the content decompressed so far is returned.
+func gunzip(content []byte) ([]byte, error) {
+	r := bytes.NewReader(content)
+	reader, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer func(reader *gzip.Reader) {
+		_ = reader.Close()
+	}(reader)
+
+	var uncompressed bytes.Buffer
+	for {
+		reader.Multistream(false)
+		if _, err = io.Copy(&uncompressed, reader); err != nil {
+			break
+		}
+		if !bytes.HasPrefix(content[len(content)-r.Len():], []byte("\x1f\x8b")) {
+			return uncompressed.Bytes(), nil
+		}
+		if err = reader.Reset(r); err != nil {
+			break
+		}
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return uncompressed.Bytes(), nil
+	}
+	return nil, err
+}
+
+// inflate decompresses the given zlib-compressed content.
+// If the content is truncated, the content decompressed so far is returned.
+func inflate(content []byte) ([]byte, error) {
+	reader, err := zlib.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	defer func(reader io.ReadCloser) {
+		_ = reader.Close()
+	}(reader)
+
+	uncompressed, err := io.ReadAll(reader)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	return uncompressed, nil
+}
+
+// isZlib reports whether the content starts with a zlib header: the deflate method with a 32K window (0x78),
+// followed by a check byte of one of the standard compression levels.
+func isZlib(content []byte) bool {
+	if len(content) < 2 || content[0] != 0x78 {
+		return false
+	}
+	switch content[1] {
+	case 0x01, 0x5e, 0x9c, 0xda:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,199 @@
+package sitemaptest_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	sitemap "github.com/aafeher/go-sitemap-parser"
+	"github.com/aafeher/go-sitemap-parser/sitemaptest"
+)
+
+func TestNewHandler(t *testing.T) {
+	content := []byte("<loc>http://HOST/page</loc>")
+	multistream := append(sitemaptest.Gzip([]byte("<loc>http://HOST/1</loc>")), sitemaptest.Gzip([]byte("<loc>http://HOST/2</loc>"))...)
+	truncated := sitemaptest.Gzip(content)
+	truncated = truncated[:len(truncated)-10]
+
+	server := sitemaptest.NewFixtureServer(fstest.MapFS{
+		"plain.xml":        {Data: content},
+		"gzip.xml.gz":      {Data: sitemaptest.Gzip(content)},
+		"multi.xml.gz":     {Data: multistream},
+		"truncated.xml.gz": {Data: truncated},
+		"zlib.xml.zlib":    {Data: sitemaptest.Zlib(content)},
+		"corrupted.zlib":   {Data: []byte("\x78\x9cnot zlib")},
+		"dir/nested.xml":   {Data: content},
+	})
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+	want := "<loc>http://" + host + "/page</loc>"
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		decode     func(t *testing.T, body []byte) string
+		want       string
+	}{
+		{name: "plain", path: "/plain.xml", wantStatus: http.StatusOK, want: want + "\n"},
+		{name: "nested", path: "/dir/nested.xml", wantStatus: http.StatusOK, want: want + "\n"},
+		{name: "gzip", path: "/gzip.xml.gz", wantStatus: http.StatusOK, decode: gunzip, want: want},
+		{name: "gzip multistream", path: "/multi.xml.gz", wantStatus: http.StatusOK, decode: gunzip, want: "<loc>http://" + host + "/1</loc><loc>http://" + host + "/2</loc>"},
+		{name: "gzip truncated", path: "/truncated.xml.gz", wantStatus: http.StatusOK, decode: gunzip},
+		{name: "zlib", path: "/zlib.xml.zlib", wantStatus: http.StatusOK, decode: inflate, want: want},
+		{name: "zlib corrupted", path: "/corrupted.zlib", wantStatus: http.StatusOK, want: "\x78\x9cnot zlib"},
+		{name: "index page", path: "/", wantStatus: http.StatusNotFound},
+		{name: "directory", path: "/dir", wantStatus: http.StatusNotFound},
+		{name: "missing", path: "/missing.xml", wantStatus: http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response, err := http.Get(server.URL + test.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func(Body io.ReadCloser) {
+				_ = Body.Close()
+			}(response.Body)
+			if response.StatusCode != test.wantStatus {
+				t.Fatalf("expected status %d, got %d", test.wantStatus, response.StatusCode)
+			}
+			if test.wantStatus != http.StatusOK {
+				return
+			}
+
+			body, err := io.ReadAll(response.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := string(body)
+			if test.decode != nil {
+				got = test.decode(t, bytes.TrimSuffix(body, []byte("\n")))
+			}
+			if test.want != "" && got != test.want {
+				t.Errorf("expected %q, got %q", test.want, got)
+			}
+			if strings.Contains(got, "HOST") {
+				t.Errorf("expected the placeholders to be replaced, got %q", got)
+			}
+		})
+	}
+}
+
+func TestShardedIndex(t *testing.T) {
+	tests := []struct {
+		name     string
+		n        int
+		m        int
+		wantURLs int
+	}{
+		{name: "3 shards of 4 URLs", n: 3, m: 4, wantURLs: 12},
+		{name: "1 shard of 1 URL", n: 1, m: 1, wantURLs: 1},
+		{name: "2 empty shards", n: 2, m: 0, wantURLs: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := sitemaptest.NewFixtureServer(sitemaptest.ShardedIndex(test.n, test.m))
+			defer server.Close()
+
+			s, err := sitemap.New().Parse(server.URL+"/sitemap-index.xml", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if errs := s.GetErrors(); len(errs) != 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if got := len(s.GetURLs()); got != test.wantURLs {
+				t.Errorf("expected %d URLs, got %d", test.wantURLs, got)
+			}
+			// the locations include the index
+			if got := len(s.GetSitemapLocations()); got != test.n+1 {
+				t.Errorf("expected %d sitemaps, got %d", test.n+1, got)
+			}
+			if want := server.URL + "/page-0001-0001"; test.m > 0 && !containsLoc(s.GetURLs(), want) {
+				t.Errorf("expected %s among the URLs", want)
+			}
+		})
+	}
+}
+
+func TestURLSet(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  []byte
+		want []string
+	}{
+		{name: "urlset", doc: sitemaptest.URLSet("https://example.com/a", "https://example.com/b?x=1&y=2"), want: []string{"https://example.com/a", "https://example.com/b?x=1&y=2"}},
+		{name: "empty urlset", doc: sitemaptest.URLSet(), want: []string{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			content := string(test.doc)
+			s, err := sitemap.New().Parse("https://example.com/sitemap.xml", &content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.GetLocs(); len(got) != len(test.want) || (len(got) > 0 && strings.Join(got, " ") != strings.Join(test.want, " ")) {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestSitemapIndex(t *testing.T) {
+	content := string(sitemaptest.SitemapIndex("https://example.com/sitemap-1.xml", "https://example.com/sitemap-2.xml"))
+	s, err := sitemap.New().SetFollowIndexes(false).Parse("https://example.com/sitemap-index.xml", &content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"https://example.com/sitemap-index.xml", "https://example.com/sitemap-1.xml", "https://example.com/sitemap-2.xml"}
+	if got := s.GetSitemapLocations(); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+// containsLoc reports whether a URL of the list has the given location.
+func containsLoc(urls []sitemap.URL, loc string) bool {
+	for _, u := range urls {
+		if u.Loc == loc {
+			return true
+		}
+	}
+	return false
+}
+
+// gunzip decompresses the body of a gzip-compressed response.
+func gunzip(t *testing.T, body []byte) string {
+	t.Helper()
+	reader, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	uncompressed, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(uncompressed)
+}
+
+// inflate decompresses the body of a zlib-compressed response.
+func inflate(t *testing.T, body []byte) string {
+	t.Helper()
+	reader, err := zlib.NewReader(bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	uncompressed, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(uncompressed)
+}
@@ -0,0 +1,41 @@
+package sitemap
+
+import "fmt"
+
+// TooLargeError is the error recorded for a location that is skipped without downloading its body,
+// because the Content-Length header of the response advertises a size over the hint set by SetMaxFetchSizeHint.
+// The Location field is the location, the Size field is the advertised size in bytes and the Max field is the hint.
+type TooLargeError struct {
+	Location string
+	Size     int64
+	Max      int64
+}
+
+// Error returns the message of the error.
+func (e *TooLargeError) Error() string {
+	return fmt.Sprintf("skipped: too large, advertised size of %d bytes over %d", e.Size, e.Max)
+}
+
+// SetMaxFetchSizeHint sets the maximum size of a document, in bytes, advertised by the Content-Length header of its response.
+// If a response advertises a larger size, the download is aborted before reading the body, the location is skipped
+// with a *TooLargeError recorded, and the parse goes on with the other sitemaps. The skipped sitemaps are counted by GetCompleteness,
+// but not as failed: they count neither against the maximum failure rate nor against the circuit breaker of their host.
+// If the main URL is skipped, Parse returns the *TooLargeError. The responses without a Content-Length header are not affected. A value of 0 (the default) means no limit.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetMaxFetchSizeHint(bytes int64) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.maxFetchSizeHint = bytes
+
+	return s
+}
+
+// checkSizeHint returns a TooLargeError if the advertised size of the document of the given location is over the hint,
+// see SetMaxFetchSizeHint. A negative size means the size is unknown.
+func (s *S) checkSizeHint(location string, size int64) error {
+	if s.cfg.maxFetchSizeHint <= 0 || size <= s.cfg.maxFetchSizeHint {
+		return nil
+	}
+	return &TooLargeError{Location: location, Size: size, Max: s.cfg.maxFetchSizeHint}
+}
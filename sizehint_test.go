@@ -0,0 +1,107 @@
+package sitemap
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// sizeHintServer returns a test server serving a sitemap index of three sitemaps, the second one advertising 800 MB.
+func sizeHintServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sitemapindex.xml":
+			_, _ = fmt.Fprintf(w, `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`+
+				`<sitemap><loc>http://%[1]s/sitemap-01.xml</loc></sitemap>`+
+				`<sitemap><loc>http://%[1]s/sitemap-huge.xml</loc></sitemap>`+
+				`<sitemap><loc>http://%[1]s/sitemap-02.xml</loc></sitemap>`+
+				`</sitemapindex>`, r.Host)
+		case "/sitemap-huge.xml":
+			w.Header().Set("Content-Length", "838860800")
+			_, _ = w.Write([]byte(`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`))
+		default:
+			_, _ = fmt.Fprintf(w, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><url><loc>http://%s%s/page</loc></url></urlset>`, r.Host, r.URL.Path)
+		}
+	}))
+}
+
+func TestS_SetMaxFetchSizeHint(t *testing.T) {
+	server := sizeHintServer()
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		hint        int64
+		wantURLs    int
+		wantSkipped int
+		wantFailed  bool
+	}{
+		{name: "no hint", hint: 0, wantURLs: 2, wantFailed: true},
+		{name: "hint under the advertised size", hint: 10 << 20, wantURLs: 2, wantSkipped: 1},
+		{name: "hint over the advertised size", hint: 1 << 30, wantURLs: 2, wantFailed: true},
+	}
+
+	for _, test := range tests {
+		for _, multiThread := range []bool{true, false} {
+			t.Run(fmt.Sprintf("%s, multiThread=%v", test.name, multiThread), func(t *testing.T) {
+				s, err := New().SetMaxFetchSizeHint(test.hint).SetMultiThread(multiThread).Parse(server.URL+"/sitemapindex.xml", nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if s.GetURLCount() != int64(test.wantURLs) {
+					t.Errorf("expected %d URLs, got %d", test.wantURLs, s.GetURLCount())
+				}
+				completeness := s.GetCompleteness()
+				if completeness.SkippedSitemaps != test.wantSkipped {
+					t.Errorf("expected %d skipped sitemaps, got %d", test.wantSkipped, completeness.SkippedSitemaps)
+				}
+
+				var tooLargeErr *TooLargeError
+				found := false
+				for _, err := range s.GetErrors() {
+					if errors.As(err, &tooLargeErr) {
+						found = true
+						break
+					}
+				}
+				if test.wantSkipped == 0 {
+					if found {
+						t.Errorf("unexpected error %v", tooLargeErr)
+					}
+					// without the hint, the truncated body fails the sitemap
+					if test.wantFailed && completeness.FailedSitemaps != 1 {
+						t.Errorf("expected 1 failed sitemap, got %d", completeness.FailedSitemaps)
+					}
+					return
+				}
+				if !found {
+					t.Fatalf("expected a *TooLargeError, got %v", s.GetErrors())
+				}
+				want := TooLargeError{Location: server.URL + "/sitemap-huge.xml", Size: 838860800, Max: test.hint}
+				if *tooLargeErr != want {
+					t.Errorf("expected %+v, got %+v", want, *tooLargeErr)
+				}
+				if completeness.FailedSitemaps != 0 {
+					t.Errorf("expected no failed sitemaps, got %d", completeness.FailedSitemaps)
+				}
+				if got := s.GetFetchMetadata()[want.Location].StatusCode; got != http.StatusOK {
+					t.Errorf("expected the status of the response to be recorded, got %d", got)
+				}
+			})
+		}
+	}
+
+	t.Run("main URL", func(t *testing.T) {
+		_, err := New().SetMaxFetchSizeHint(1024).Parse(server.URL+"/sitemap-huge.xml", nil)
+		var tooLargeErr *TooLargeError
+		if !errors.As(err, &tooLargeErr) {
+			t.Fatalf("expected a *TooLargeError, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "skipped: too large, advertised size of 838860800 bytes over 1024") {
+			t.Errorf("unexpected message %q", err.Error())
+		}
+	})
+}
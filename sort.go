@@ -0,0 +1,167 @@
+package sitemap
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+)
+
+// SortField represents the field the parsed URLs are sorted by with SortURLs.
+type SortField string
+
+const (
+	// SortByLoc sorts the URLs by their <loc> value.
+	SortByLoc SortField = "loc"
+
+	// SortByLastMod sorts the URLs by their <lastmod> value.
+	SortByLastMod SortField = "lastmod"
+
+	// SortByChangeFreq sorts the URLs by their <changefreq> value, in the order always, hourly, daily, weekly, monthly, yearly, never.
+	SortByChangeFreq SortField = "changefreq"
+
+	// SortByPriority sorts the URLs by their <priority> value.
+	SortByPriority SortField = "priority"
+)
+
+// changeFreqRanks maps the valid <changefreq> values to their position in the SortByChangeFreq order.
+var changeFreqRanks = map[urlChangeFreq]int{
+	changeFreqAlways:  0,
+	changeFreqHourly:  1,
+	changeFreqDaily:   2,
+	changeFreqWeekly:  3,
+	changeFreqMonthly: 4,
+	changeFreqYearly:  5,
+	changeFreqNever:   6,
+}
+
+// SortURLs sorts the parsed URLs in place by the given field, in descending order if desc is true.
+// Subsequent calls of GetURLs and GetURLsShared return the URLs in the sorted order, while GetRandomURLs is not affected.
+// The sort is stable, URLs with equal values keep their relative order. URLs missing the value of the field
+// (or with an invalid <changefreq> value) are placed last in both orders.
+// The source sitemap of each URL is moved along with it.
+// It returns an error if the field is unknown. On a nil S object or without URLs, it does nothing.
+func (s *S) SortURLs(by SortField, desc bool) error {
+	has, less, err := urlOrder(by)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sort.SliceStable(s.urls, func(i, j int) bool {
+		a, b := s.urls[i], s.urls[j]
+		if hasA, hasB := has(a), has(b); hasA != hasB || !hasA {
+			return hasA && !hasB
+		}
+		if desc {
+			return less(b, a)
+		}
+		return less(a, b)
+	})
+	return nil
+}
+
+// urlOrder returns the functions to sort the URLs by the given field:
+// has reports whether a URL has a value for the field, less compares two URLs having one, in ascending order.
+func urlOrder(by SortField) (has func(u URL) bool, less func(a, b URL) bool, err error) {
+	switch by {
+	case SortByLoc:
+		return func(u URL) bool { return true },
+			func(a, b URL) bool { return a.Loc < b.Loc }, nil
+	case SortByLastMod:
+		return func(u URL) bool { return u.LastMod != nil },
+			func(a, b URL) bool { return a.LastMod.Before(b.LastMod.Time) }, nil
+	case SortByChangeFreq:
+		return func(u URL) bool {
+				_, ok := changeFreqRank(u.ChangeFreq)
+				return ok
+			},
+			func(a, b URL) bool {
+				aRank, _ := changeFreqRank(a.ChangeFreq)
+				bRank, _ := changeFreqRank(b.ChangeFreq)
+				return aRank < bRank
+			}, nil
+	case SortByPriority:
+		return func(u URL) bool { return u.Priority != nil },
+			func(a, b URL) bool { return *a.Priority < *b.Priority }, nil
+	}
+	return nil, nil, fmt.Errorf("unknown sort field %q", by)
+}
+
+// changeFreqRank returns the position of the given <changefreq> value in the SortByChangeFreq order.
+// It returns false if the value is missing or invalid.
+func changeFreqRank(changeFreq *urlChangeFreq) (int, bool) {
+	if changeFreq == nil {
+		return 0, false
+	}
+	rank, ok := changeFreqRanks[*changeFreq]
+	return rank, ok
+}
+
+// defaultPriority is the priority of a URL without a <priority> value, as defined by the sitemaps protocol.
+const defaultPriority = 0.5
+
+// TopURLsByPriority returns the n URLs with the highest <priority> value, in descending order of priority.
+// URLs without a <priority> value count as the protocol default of 0.5, ties are broken by ascending <loc> value.
+// The URLs are selected with a heap of size n, without sorting all of them.
+// If n is not positive or the S object is nil, an empty slice is returned; if n is larger than the number of URLs, all of them are returned.
+func (s *S) TopURLsByPriority(n int) []URL {
+	if s == nil || n <= 0 {
+		return []URL{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if n > len(s.urls) {
+		n = len(s.urls)
+	}
+	top := make(priorityHeap, 0, n)
+	for _, u := range s.urls {
+		if len(top) < n {
+			heap.Push(&top, u)
+		} else if higherPriority(u, top[0]) {
+			top[0] = u
+			heap.Fix(&top, 0)
+		}
+	}
+
+	urls := make([]URL, len(top))
+	for i := len(top) - 1; i >= 0; i-- {
+		urls[i] = heap.Pop(&top).(URL)
+	}
+	return urls
+}
+
+// urlPriority returns the <priority> value of the URL, or the protocol default if it is missing.
+func urlPriority(u URL) float32 {
+	if u.Priority == nil {
+		return defaultPriority
+	}
+	return *u.Priority
+}
+
+// higherPriority reports whether a ranks before b in TopURLsByPriority.
+func higherPriority(a, b URL) bool {
+	aPriority, bPriority := urlPriority(a), urlPriority(b)
+	if aPriority != bPriority {
+		return aPriority > bPriority
+	}
+	return a.Loc < b.Loc
+}
+
+// priorityHeap is a min-heap of URLs by rank, its root is the lowest ranked URL selected by TopURLsByPriority.
+type priorityHeap []URL
+
+func (h priorityHeap) Len() int            { return len(h) }
+func (h priorityHeap) Less(i, j int) bool  { return higherPriority(h[j], h[i]) }
+func (h priorityHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *priorityHeap) Push(x interface{}) { *h = append(*h, x.(URL)) }
+func (h *priorityHeap) Pop() interface{} {
+	old := *h
+	u := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return u
+}
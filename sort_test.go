@@ -0,0 +1,191 @@
+package sitemap
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestS_SortURLs(t *testing.T) {
+	daily, weekly, never, invalid := changeFreqDaily, changeFreqWeekly, changeFreqNever, urlChangeFreq("sometimes")
+	lastMod := func(day int) *LastMod {
+		return &LastMod{time.Date(2024, 2, day, 0, 0, 0, 0, time.UTC)}
+	}
+	urls := func() []URL {
+		return []URL{
+			{Loc: "https://www.sitemaps.org/c", LastMod: lastMod(3), ChangeFreq: &weekly, Priority: pointerOfFloat32(0.5), source: "sitemap-1.xml"},
+			{Loc: "https://www.sitemaps.org/a", ChangeFreq: &invalid, Priority: pointerOfFloat32(0.9), source: "sitemap-2.xml"},
+			{Loc: "https://www.sitemaps.org/d", LastMod: lastMod(1), Priority: pointerOfFloat32(0.5), source: "sitemap-3.xml"},
+			{Loc: "https://www.sitemaps.org/b", LastMod: lastMod(2), ChangeFreq: &never, source: "sitemap-4.xml"},
+			{Loc: "https://www.sitemaps.org/e", ChangeFreq: &daily, Priority: pointerOfFloat32(0.1), source: "sitemap-5.xml"},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		by      SortField
+		desc    bool
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "loc",
+			by:   SortByLoc,
+			want: []string{"a", "b", "c", "d", "e"},
+		},
+		{
+			name: "loc descending",
+			by:   SortByLoc,
+			desc: true,
+			want: []string{"e", "d", "c", "b", "a"},
+		},
+		{
+			name: "lastmod, missing last",
+			by:   SortByLastMod,
+			want: []string{"d", "b", "c", "a", "e"},
+		},
+		{
+			name: "lastmod descending, missing last",
+			by:   SortByLastMod,
+			desc: true,
+			want: []string{"c", "b", "d", "a", "e"},
+		},
+		{
+			name: "changefreq, missing and invalid last",
+			by:   SortByChangeFreq,
+			want: []string{"e", "c", "b", "a", "d"},
+		},
+		{
+			name: "priority descending, stable",
+			by:   SortByPriority,
+			desc: true,
+			want: []string{"a", "c", "d", "e", "b"},
+		},
+		{
+			name:    "unknown field",
+			by:      SortField("size"),
+			want:    []string{"c", "a", "d", "b", "e"},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := &S{urls: urls()}
+			err := s.SortURLs(test.by, test.desc)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("expected error %v, got %v", test.wantErr, err)
+			}
+
+			got := make([]string, 0, len(test.want))
+			for _, u := range s.GetURLs() {
+				got = append(got, u.Loc[len("https://www.sitemaps.org/"):])
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+
+	t.Run("source moves with the URL", func(t *testing.T) {
+		s := &S{urls: urls()}
+		if err := s.SortURLs(SortByLoc, false); err != nil {
+			t.Fatal(err)
+		}
+		if s.urls[0].source != "sitemap-2.xml" {
+			t.Errorf("expected source sitemap-2.xml, got %s", s.urls[0].source)
+		}
+	})
+
+	t.Run("nil and empty", func(t *testing.T) {
+		var s *S
+		if err := s.SortURLs(SortByLoc, false); err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+		if err := (&S{}).SortURLs(SortByPriority, true); err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+	})
+}
+
+func TestS_TopURLsByPriority(t *testing.T) {
+	urls := []URL{
+		{Loc: "https://www.sitemaps.org/c", Priority: pointerOfFloat32(0.5)},
+		{Loc: "https://www.sitemaps.org/a", Priority: pointerOfFloat32(0.9)},
+		{Loc: "https://www.sitemaps.org/d"},
+		{Loc: "https://www.sitemaps.org/b", Priority: pointerOfFloat32(0.1)},
+		{Loc: "https://www.sitemaps.org/e", Priority: pointerOfFloat32(1.0)},
+	}
+
+	tests := []struct {
+		name string
+		s    *S
+		n    int
+		want []string
+	}{
+		{
+			name: "nil receiver",
+			s:    nil,
+			n:    2,
+			want: []string{},
+		},
+		{
+			name: "zero",
+			s:    &S{urls: urls},
+			n:    0,
+			want: []string{},
+		},
+		{
+			name: "negative",
+			s:    &S{urls: urls},
+			n:    -1,
+			want: []string{},
+		},
+		{
+			name: "top 3, missing priority as 0.5 and ties by loc",
+			s:    &S{urls: urls},
+			n:    3,
+			want: []string{"e", "a", "c"},
+		},
+		{
+			name: "more than count",
+			s:    &S{urls: urls},
+			n:    10,
+			want: []string{"e", "a", "c", "d", "b"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := []string{}
+			for _, u := range test.s.TopURLsByPriority(test.n) {
+				got = append(got, u.Loc[len("https://www.sitemaps.org/"):])
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %v, got %v", test.want, got)
+			}
+		})
+	}
+
+	t.Run("large", func(t *testing.T) {
+		const count = 100000
+		s := &S{urls: make([]URL, 0, count)}
+		for i := 0; i < count; i++ {
+			s.urls = append(s.urls, URL{Loc: fmt.Sprintf("https://www.sitemaps.org/%06d", i), Priority: pointerOfFloat32(float32(i%1000) / 1000)})
+		}
+
+		got := s.TopURLsByPriority(100)
+		if len(got) != 100 {
+			t.Fatalf("expected 100 URLs, got %d", len(got))
+		}
+		if got[0].Loc != "https://www.sitemaps.org/000999" || *got[99].Priority != 0.999 {
+			t.Errorf("unexpected top URLs: first %s, last priority %v", got[0].Loc, *got[99].Priority)
+		}
+		for i := 1; i < len(got); i++ {
+			if higherPriority(got[i], got[i-1]) {
+				t.Fatalf("expected descending order at %d: %v, %v", i, got[i-1], got[i])
+			}
+		}
+	})
+}
@@ -0,0 +1,52 @@
+package sitemap
+
+import (
+	"sort"
+	"time"
+)
+
+// StaleSitemap is a sitemap returned by GetStaleSitemaps.
+// The Location field is the location of the sitemap, the NewestLastMod field is its newest lastmod, see GetStaleSitemaps,
+// and the URLCount field is the number of URLs collected from it.
+type StaleSitemap struct {
+	Location      string    `json:"location"`
+	NewestLastMod time.Time `json:"newest_lastmod"`
+	URLCount      int64     `json:"url_count"`
+}
+
+// GetStaleSitemaps returns the sitemaps (<urlset> documents) not updated for longer than olderThan before now,
+// e.g. a shard a site has stopped regenerating. The newest lastmod of a sitemap is the later of the latest <lastmod> value
+// of its URLs and the <lastmod> value of its entry in the referencing sitemap index. The sitemaps without either are not reported,
+// as there is nothing to judge them by. The result is sorted by the newest lastmod, the stalest first, then by location.
+// If the S object is nil, an empty slice is returned.
+func (s *S) GetStaleSitemaps(olderThan time.Duration, now time.Time) []StaleSitemap {
+	stale := []StaleSitemap{}
+	if s == nil {
+		return stale
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	threshold := now.Add(-olderThan)
+	for _, n := range s.nodes {
+		if n.Kind != SitemapKindURLSet {
+			continue
+		}
+		newest := n.NewestURLLastMod
+		if n.LastMod != nil && (newest == nil || n.LastMod.After(*newest)) {
+			newest = n.LastMod
+		}
+		if newest == nil || !newest.Before(threshold) {
+			continue
+		}
+		stale = append(stale, StaleSitemap{Location: n.Loc, NewestLastMod: *newest, URLCount: n.URLCount})
+	}
+
+	sort.Slice(stale, func(i, j int) bool {
+		if !stale[i].NewestLastMod.Equal(stale[j].NewestLastMod) {
+			return stale[i].NewestLastMod.Before(stale[j].NewestLastMod)
+		}
+		return stale[i].Location < stale[j].Location
+	})
+	return stale
+}
@@ -0,0 +1,108 @@
+package sitemap
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestS_GetStaleSitemaps(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	now := time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		s         *S
+		olderThan time.Duration
+		want      []StaleSitemap
+	}{
+		{
+			name:      "90 days",
+			s:         New(),
+			olderThan: 90 * 24 * time.Hour,
+			want: []StaleSitemap{
+				{Location: "/sitemap-stale.xml", NewestLastMod: time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC), URLCount: 3},
+			},
+		},
+		{
+			name:      "30 days",
+			s:         New(),
+			olderThan: 30 * 24 * time.Hour,
+			want: []StaleSitemap{
+				{Location: "/sitemap-stale.xml", NewestLastMod: time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC), URLCount: 3},
+			},
+		},
+		{
+			name:      "8 days",
+			s:         New(),
+			olderThan: 8 * 24 * time.Hour,
+			want: []StaleSitemap{
+				{Location: "/sitemap-stale.xml", NewestLastMod: time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC), URLCount: 3},
+				{Location: "/sitemap-stale-declared-fresh.xml", NewestLastMod: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), URLCount: 1},
+			},
+		},
+		{
+			name:      "rules",
+			s:         New().SetRules([]string{`fresh-01$`, `stale-01$`}),
+			olderThan: 90 * 24 * time.Hour,
+			want: []StaleSitemap{
+				{Location: "/sitemap-stale.xml", NewestLastMod: time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC), URLCount: 1},
+			},
+		},
+		{
+			name:      "none",
+			s:         New(),
+			olderThan: 365 * 24 * time.Hour,
+			want:      []StaleSitemap{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := test.s.Parse(server.URL+"/sitemapindex-stale.xml", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := s.GetStaleSitemaps(test.olderThan, now)
+			for i := range got {
+				got[i].Location = strings.TrimPrefix(got[i].Location, server.URL)
+				got[i].NewestLastMod = got[i].NewestLastMod.UTC()
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %+v, got %+v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestS_Parse_NewestURLLastMod(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	s, err := New().Parse(server.URL+"/sitemapindex-stale.xml", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"/sitemapindex-stale.xml":           "",
+		"/sitemap-stale.xml":                "2023-05-20T10:00:00Z",
+		"/sitemap-fresh.xml":                "2024-03-05T10:00:00Z",
+		"/sitemap-stale-declared-fresh.xml": "2022-11-30T10:00:00Z",
+		"/sitemap-without-lastmod.xml":      "",
+	}
+	got := map[string]string{}
+	walkNodes(s.GetSitemapTree(), func(n *SitemapNode) {
+		got[strings.TrimPrefix(n.Loc, server.URL)] = ""
+		if n.NewestURLLastMod != nil {
+			got[strings.TrimPrefix(n.Loc, server.URL)] = n.NewestURLLastMod.UTC().Format(time.RFC3339)
+		}
+	})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
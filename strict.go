@@ -0,0 +1,96 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// coreURLElements are the child elements of <url> defined by the sitemaps.org protocol.
+var coreURLElements = map[string]bool{"loc": true, "lastmod": true, "changefreq": true, "priority": true}
+
+// extensionNamespaces are the namespaces of the recognized sitemap extensions, whose elements may appear in <url>.
+var extensionNamespaces = map[string]bool{
+	imageNamespace:  true,
+	videoNamespace:  true,
+	newsNamespace:   true,
+	mobileNamespace: true,
+	xhtmlNamespace:  true,
+}
+
+// SetStrictElements sets whether the unexpected elements of a <urlset> are reported, e.g. for conformance testing:
+// a child of <urlset> other than <url>, and a child of <url> which is neither an element of the sitemaps.org protocol
+// (<loc>, <lastmod>, <changefreq> and <priority>) nor an element of a recognized extension namespace (image, video, news, mobile
+// and xhtml). The elements of the protocol are expected in its namespace, or in none. An *UnexpectedElementError warning is recorded
+// for each such element, with its position and the location of its <url>, see GetWarnings; the elements are still skipped.
+// The descendants of the unexpected elements and of the extension elements are not checked.
+// By default, the unexpected elements are silently skipped.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetStrictElements(strictElements bool) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.strictElements = strictElements
+
+	return s
+}
+
+// strictElements checks the elements of a <urlset> document read by a guardedTokenReader, see SetStrictElements.
+// The urlSet field is true if the root element is <urlset>, the inURL field is true inside a <url>.
+// The pending field holds the violations of the current <url>, which are completed with its location,
+// collected from the loc field while the inLoc field is true, at the end of the <url>. The violations field holds the completed violations.
+type strictElements struct {
+	urlSet     bool
+	inURL      bool
+	inLoc      bool
+	loc        strings.Builder
+	pending    []*UnexpectedElementError
+	violations []*UnexpectedElementError
+}
+
+// check checks the given token read at the given depth (after the start or before the end of an element) and position.
+func (c *strictElements) check(token xml.Token, depth int, position Position) {
+	switch t := token.(type) {
+	case xml.StartElement:
+		switch {
+		case depth == 1:
+			c.urlSet = t.Name.Local == "urlset"
+		case !c.urlSet:
+		case depth == 2:
+			c.inURL = t.Name.Local == "url" && coreNamespace(t.Name.Space)
+			if !c.inURL {
+				c.violations = append(c.violations, &UnexpectedElementError{Element: t.Name, Parent: "urlset", Position: position})
+			}
+		case depth != 3 || !c.inURL:
+		case t.Name.Local == "loc" && coreNamespace(t.Name.Space):
+			c.inLoc = true
+		case !(coreURLElements[t.Name.Local] && coreNamespace(t.Name.Space)) && !extensionNamespaces[t.Name.Space]:
+			c.pending = append(c.pending, &UnexpectedElementError{Element: t.Name, Parent: "url", Position: position})
+		}
+	case xml.CharData:
+		if c.inLoc {
+			c.loc.Write(t)
+		}
+	case xml.EndElement:
+		if !c.urlSet {
+			return
+		}
+		switch depth {
+		case 3:
+			c.inLoc = false
+		case 2:
+			c.inURL = false
+			loc := strings.TrimSpace(c.loc.String())
+			for _, violation := range c.pending {
+				violation.Loc = loc
+			}
+			c.violations = append(c.violations, c.pending...)
+			c.pending = nil
+			c.loc.Reset()
+		}
+	}
+}
+
+// coreNamespace reports whether an element of the given namespace may be an element of the sitemaps.org protocol.
+func coreNamespace(space string) bool {
+	return space == "" || space == sitemapNamespace
+}
@@ -0,0 +1,163 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestS_SetStrictElements(t *testing.T) {
+	tests := []struct {
+		name           string
+		strictElements bool
+	}{
+		{name: "enabled", strictElements: true},
+		{name: "disabled", strictElements: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New().SetStrictElements(test.strictElements)
+			if s.cfg.strictElements != test.strictElements {
+				t.Errorf("expected %v, got %v", test.strictElements, s.cfg.strictElements)
+			}
+		})
+	}
+}
+
+func TestS_Parse_StrictElements(t *testing.T) {
+	data, err := os.ReadFile("./test/sitemap-unexpected-elements.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	location := "https://www.example.com/sitemap.xml"
+
+	unexpected := []UnexpectedElementError{
+		{
+			Location: location,
+			Element:  xml.Name{Space: sitemapNamespace, Local: "foo"},
+			Parent:   "url",
+			Loc:      "https://www.example.com/page-01",
+			Position: Position{Offset: 245, Line: 6, Column: 9},
+		},
+		{
+			Location: location,
+			Element:  xml.Name{Space: sitemapNamespace, Local: "bar"},
+			Parent:   "urlset",
+			Position: Position{Offset: 483, Line: 13, Column: 5},
+		},
+		{
+			Location: location,
+			Element:  xml.Name{Space: "https://www.example.com/schemas/custom", Local: "custom"},
+			Parent:   "url",
+			Loc:      "https://www.example.com/page-02",
+			Position: Position{Offset: 584, Line: 18, Column: 9},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		s        *S
+		content  string
+		want     []UnexpectedElementError
+		wantURLs int
+	}{
+		{
+			name:     "not strict",
+			s:        New(),
+			content:  content,
+			wantURLs: 2,
+		},
+		{
+			name:     "strict",
+			s:        New().SetStrictElements(true),
+			content:  content,
+			want:     unexpected,
+			wantURLs: 2,
+		},
+		{
+			name:     "strict with fields",
+			s:        New().SetStrictElements(true).SetFields(FieldLoc),
+			content:  content,
+			want:     unexpected,
+			wantURLs: 2,
+		},
+		{
+			name:     "strict in loc-only mode",
+			s:        New().SetStrictElements(true).SetLocOnly(true),
+			content:  content,
+			want:     unexpected,
+			wantURLs: 0,
+		},
+		{
+			name:     "strict with a limit of URLs",
+			s:        New().SetStrictElements(true).SetMaxURLsPerSitemap(1),
+			content:  content,
+			want:     unexpected,
+			wantURLs: 1,
+		},
+		{
+			name:     "strict without namespace",
+			s:        New().SetStrictElements(true),
+			content:  "<urlset><url><loc>https://www.example.com/</loc><changefreq>daily</changefreq></url></urlset>",
+			wantURLs: 1,
+		},
+		{
+			name:    "strict sitemap index",
+			s:       New().SetStrictElements(true).SetFollowIndexes(false),
+			content: "<sitemapindex><sitemap><loc>https://www.example.com/sitemap-01.xml</loc><foo/></sitemap></sitemapindex>",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			content := test.content
+			s, err := test.s.Parse(location, &content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(s.GetURLs()); got != test.wantURLs {
+				t.Errorf("expected %d URLs, got %d", test.wantURLs, got)
+			}
+
+			var got []UnexpectedElementError
+			for _, warning := range s.GetWarnings() {
+				var unexpectedErr *UnexpectedElementError
+				if errors.As(warning, &unexpectedErr) {
+					got = append(got, *unexpectedErr)
+				}
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %+v, got %+v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestUnexpectedElementError_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *UnexpectedElementError
+		want string
+	}{
+		{
+			name: "in url",
+			err:  &UnexpectedElementError{Element: xml.Name{Local: "foo"}, Parent: "url", Loc: "https://www.example.com/", Position: Position{Line: 6, Column: 9}},
+			want: `unexpected element <foo> in <url> of "https://www.example.com/" at 6:9`,
+		},
+		{
+			name: "in urlset",
+			err:  &UnexpectedElementError{Element: xml.Name{Space: "https://www.example.com/ns", Local: "bar"}, Parent: "urlset", Position: Position{Line: 13, Column: 5}},
+			want: `unexpected element <https://www.example.com/ns bar> in <urlset> at 13:5`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.err.Error(); got != test.want {
+				t.Errorf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
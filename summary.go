@@ -0,0 +1,94 @@
+package sitemap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// String returns a one-line summary of the parse, e.g.
+// "parsed https://www.example.com/robots.txt: 3 sitemaps (1 index), 12,345 URLs, 2 errors, 14.2s".
+// The sitemaps are the sitemap indexes and URL sets fetched, the URLs are the URLs collected
+// (or counted, if they are not collected, see SetCollectURLs), and the warnings and the cause the parse was cut short by
+// are only mentioned if there are any. The duration is the duration of the last parse.
+// It returns "<nil>" if the S object is nil, and "not parsed" before a parse.
+func (s *S) String() string {
+	if s == nil {
+		return "<nil>"
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.mainURL == "" {
+		return "not parsed"
+	}
+
+	var sitemaps, indexes int
+	var counted int64
+	for _, node := range s.nodes {
+		if !node.fetched {
+			continue
+		}
+		switch node.Kind {
+		case SitemapKindIndex:
+			indexes++
+			sitemaps++
+		case SitemapKindURLSet:
+			sitemaps++
+		}
+		counted += node.URLCount
+	}
+	urls := int64(len(s.urls) + len(s.locs))
+	if s.cfg.skipURLs {
+		urls = counted
+	}
+
+	var summary strings.Builder
+	fmt.Fprintf(&summary, "parsed %s: %s", s.mainURL, plural(int64(sitemaps), "sitemap", "sitemaps"))
+	if indexes > 0 {
+		fmt.Fprintf(&summary, " (%s)", plural(int64(indexes), "index", "indexes"))
+	}
+	fmt.Fprintf(&summary, ", %s, %s", plural(urls, "URL", "URLs"), plural(int64(len(s.errs)), "error", "errors"))
+	if len(s.warnings) > 0 {
+		fmt.Fprintf(&summary, ", %s", plural(int64(len(s.warnings)), "warning", "warnings"))
+	}
+	if s.truncatedBy != "" {
+		fmt.Fprintf(&summary, ", truncated by %s", s.truncatedBy)
+	}
+	fmt.Fprintf(&summary, ", %s", roundDuration(s.timing.Total))
+	return summary.String()
+}
+
+// plural returns the count with thousands separators, followed by the noun one or many depending on the count.
+func plural(n int64, one string, many string) string {
+	if n == 1 {
+		return "1 " + one
+	}
+	return groupThousands(n) + " " + many
+}
+
+// groupThousands formats the number with commas separating the thousands, e.g. 12,345.
+func groupThousands(n int64) string {
+	digits := strconv.FormatInt(n, 10)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	var grouped strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+	return sign + grouped.String()
+}
+
+// roundDuration rounds the duration for display, to tenths of seconds from a second, to milliseconds below.
+func roundDuration(d time.Duration) time.Duration {
+	if d >= time.Second {
+		return d.Round(100 * time.Millisecond)
+	}
+	return d.Round(time.Millisecond)
+}
@@ -0,0 +1,126 @@
+package sitemap
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestS_String(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		s    *S
+		url  string
+		want string
+	}{
+		{
+			name: "robots.txt",
+			s:    New(),
+			url:  "/robots-with-sitemapindex/robots.txt",
+			want: "parsed HOST/robots-with-sitemapindex/robots.txt: 4 sitemaps (1 index), 6 URLs, 0 errors, 14.2s",
+		},
+		{
+			name: "failed sitemap",
+			s:    New(),
+			url:  "/sitemapindex-with-invalid-sitemap.xml",
+			want: "parsed HOST/sitemapindex-with-invalid-sitemap.xml: 1 sitemap (1 index), 0 URLs, 1 error, 14.2s",
+		},
+		{
+			name: "single URL",
+			s:    New(),
+			url:  "/sitemap-01.xml",
+			want: "parsed HOST/sitemap-01.xml: 1 sitemap, 1 URL, 0 errors, 14.2s",
+		},
+		{
+			name: "URLs not collected",
+			s:    New().SetCollectURLs(false),
+			url:  "/sitemapindex-shards.xml",
+			want: "parsed HOST/sitemapindex-shards.xml: 5 sitemaps (1 index), 11 URLs, 0 errors, 14.2s",
+		},
+		{
+			name: "truncated",
+			s:    New().SetMaxURLs(2).SetMultiThread(false),
+			url:  "/sitemapindex-shards.xml",
+			want: "parsed HOST/sitemapindex-shards.xml: 2 sitemaps (1 index), 2 URLs, 0 errors, truncated by max_urls, 14.2s",
+		},
+		{
+			name: "warnings",
+			s:    New(),
+			url:  "/sitemapindex-unencoded.xml",
+			want: "parsed HOST/sitemapindex-unencoded.xml: 3 sitemaps (1 index), 5 URLs, 0 errors, 2 warnings, 14.2s",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := test.s.Parse(server.URL+test.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s.timing.Total = 14*time.Second + 234*time.Millisecond
+			if got := strings.ReplaceAll(s.String(), server.URL, "HOST"); got != test.want {
+				t.Errorf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+
+	t.Run("implements fmt.Stringer", func(t *testing.T) {
+		var _ fmt.Stringer = New()
+	})
+
+	t.Run("not parsed", func(t *testing.T) {
+		if got := New().String(); got != "not parsed" {
+			t.Errorf("expected %q, got %q", "not parsed", got)
+		}
+	})
+
+	t.Run("nil receiver", func(t *testing.T) {
+		var s *S
+		if got := fmt.Sprint(s); got != "<nil>" {
+			t.Errorf("expected %q, got %q", "<nil>", got)
+		}
+	})
+}
+
+func TestGroupThousands(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{n: 0, want: "0"},
+		{n: 999, want: "999"},
+		{n: 1000, want: "1,000"},
+		{n: 12345, want: "12,345"},
+		{n: 1234567, want: "1,234,567"},
+		{n: -1234, want: "-1,234"},
+	}
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			if got := groupThousands(test.n); got != test.want {
+				t.Errorf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestRoundDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: 14*time.Second + 234*time.Millisecond, want: "14.2s"},
+		{d: 2*time.Minute + 3*time.Second + 456*time.Millisecond, want: "2m3.5s"},
+		{d: 35*time.Millisecond + 678*time.Microsecond, want: "36ms"},
+	}
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			if got := roundDuration(test.d).String(); got != test.want {
+				t.Errorf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package sitemap
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	"github.com/aafeher/go-sitemap-parser/sitemaptest"
+)
+
+// testServer creates a test server with a custom request handler that serves static files and dynamically replaces
+// the "HOST" string in the response with the value of the request's Host header. The server handles the following routes:
+//   - "/" returns a 404 Not Found response.
+//   - "/example" returns a 200 OK response with the content "example content".
+//   - other routes serve static files located in the "./test" directory, see sitemaptest.NewHandler. If a file is gzip-encoded,
+//     it will be decompressed, and if it contains the "HOST" string, it will be replaced with the request's Host value.
+//     The modified response will be sent back to the client. zlib-compressed files are handled the same way.
+//
+// It returns an httptest.Server instance, which can be used to make HTTP requests to the test server.
+func testServer() *httptest.Server {
+	fixtures := sitemaptest.NewHandler(os.DirFS("./test"))
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.RequestURI == "/example" {
+			w.WriteHeader(http.StatusOK)
+			_, _ = fmt.Fprintln(w, "example content")
+			return
+		}
+		fixtures.ServeHTTP(w, r)
+	}))
+}
@@ -0,0 +1,93 @@
+package sitemap
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+)
+
+// WriteText writes the locations of the given URLs to w as a plain-text sitemap: one location per line,
+// UTF-8 encoded, with LF line endings. The URLs whose location fails the validation of the sitemaps.org protocol
+// (relative or non-http(s) locations, or locations over 2048 characters, see Builder.AddURL) are skipped.
+// It returns an error without writing anything if more than 50000 URLs remain, see WriteTextSplit,
+// or if writing to w fails.
+func WriteText(w io.Writer, urls []URL) error {
+	locs := textLocs(urls)
+	if len(locs) > maxSitemapURLs {
+		return fmt.Errorf("%d URLs exceed the maximum of %d URLs of a text sitemap", len(locs), maxSitemapURLs)
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, loc := range locs {
+		_, _ = bw.WriteString(loc)
+		_ = bw.WriteByte('\n')
+	}
+
+	return bw.Flush()
+}
+
+// WriteTextSplit writes the locations of the given URLs into the dir directory as multiple plain-text sitemaps, see WriteText.
+// The files are named sitemap-0001.txt, sitemap-0002.txt, etc. (with the ".txt.gz" extension if compressed),
+// and each of them contains at most 50000 URLs and is at most 50 MB uncompressed, unless lower limits are set by opts.
+// No index is written, as plain-text sitemaps are referenced one by one, e.g. from robots.txt.
+// It returns an error if a single URL exceeds the size limit or writing the files fails.
+func WriteTextSplit(dir string, urls []URL, opts ...SplitOption) error {
+	cfg := splitConfig{
+		maxURLs:  maxSitemapURLs,
+		maxBytes: maxSitemapBytes,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	var files int
+	var shard bytes.Buffer
+	var shardURLs int
+
+	flush := func() error {
+		if shardURLs == 0 {
+			return nil
+		}
+		files++
+		name := fmt.Sprintf("sitemap-%04d.txt", files)
+		if cfg.gzip {
+			name += ".gz"
+		}
+		if err := writeFile(filepath.Join(dir, name), shard.Bytes(), cfg.gzip); err != nil {
+			return err
+		}
+		shard.Reset()
+		shardURLs = 0
+		return nil
+	}
+
+	for _, loc := range textLocs(urls) {
+		if len(loc)+1 > cfg.maxBytes {
+			return fmt.Errorf("URL %q exceeds the maximum sitemap size of %d bytes", loc, cfg.maxBytes)
+		}
+		if shardURLs == cfg.maxURLs || shard.Len()+len(loc)+1 > cfg.maxBytes {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		shard.WriteString(loc)
+		shard.WriteByte('\n')
+		shardURLs++
+	}
+
+	return flush()
+}
+
+// textLocs returns the locations of the given URLs passing validateURL, in their order.
+// Only the locations are validated, as the other fields are not written.
+func textLocs(urls []URL) []string {
+	locs := make([]string, 0, len(urls))
+	for _, u := range urls {
+		if validateURL(URL{Loc: u.Loc}) == nil {
+			locs = append(locs, u.Loc)
+		}
+	}
+	return locs
+}
@@ -0,0 +1,217 @@
+package sitemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	urls := func(n int) []URL {
+		urls := make([]URL, 0, n)
+		for i := 0; i < n; i++ {
+			urls = append(urls, URL{Loc: fmt.Sprintf("https://www.sitemaps.org/page-%06d", i+1)})
+		}
+		return urls
+	}
+	priority := float32(2)
+
+	tests := []struct {
+		name    string
+		urls    []URL
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "URLs",
+			urls: []URL{
+				{Loc: "https://www.sitemaps.org/"},
+				{Loc: "https://www.sitemaps.org/protocol.html?lang=en&q=a%20b"},
+				{Loc: "https://www.sitemaps.org/München"},
+			},
+			want: "https://www.sitemaps.org/\nhttps://www.sitemaps.org/protocol.html?lang=en&q=a%20b\nhttps://www.sitemaps.org/München\n",
+		},
+		{
+			name: "invalid locations skipped",
+			urls: []URL{
+				{Loc: "/relative"},
+				{Loc: "ftp://www.sitemaps.org/file"},
+				{Loc: "https://www.sitemaps.org/a\nb"},
+				{Loc: "https://www.sitemaps.org/" + strings.Repeat("a", maxLocLength)},
+				{Loc: "https://www.sitemaps.org/valid", Priority: &priority},
+			},
+			want: "https://www.sitemaps.org/valid\n",
+		},
+		{
+			name: "empty",
+			urls: nil,
+			want: "",
+		},
+		{
+			name: "max URLs",
+			urls: urls(maxSitemapURLs),
+			want: strings.Join(locsOf(urls(maxSitemapURLs)), "\n") + "\n",
+		},
+		{
+			name:    "over max URLs",
+			urls:    urls(maxSitemapURLs + 1),
+			wantErr: true,
+		},
+		{
+			name: "over max URLs with invalid ones skipped",
+			urls: append(urls(maxSitemapURLs), URL{Loc: "/relative"}),
+			want: strings.Join(locsOf(urls(maxSitemapURLs)), "\n") + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := WriteText(&buf, test.urls)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if got := buf.String(); got != test.want {
+				t.Errorf("expected %d bytes, got %d: %.200q", len(test.want), len(got), got)
+			}
+		})
+	}
+}
+
+func TestWriteText_WriterError(t *testing.T) {
+	if err := WriteText(errWriter{}, []URL{{Loc: "https://www.sitemaps.org/"}}); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+// errWriter is an io.Writer failing every write.
+type errWriter struct{}
+
+// Write returns io.ErrClosedPipe.
+func (errWriter) Write([]byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
+func TestWriteTextSplit(t *testing.T) {
+	urls := func(n int) []URL {
+		urls := make([]URL, 0, n)
+		for i := 0; i < n; i++ {
+			urls = append(urls, URL{Loc: fmt.Sprintf("https://www.sitemaps.org/page-%06d", i+1)})
+		}
+		return urls
+	}
+	lineLength := len("https://www.sitemaps.org/page-000001\n")
+
+	tests := []struct {
+		name       string
+		urls       []URL
+		opts       []SplitOption
+		wantFiles  []string
+		wantCounts []int
+		wantErr    bool
+	}{
+		{
+			name:       "default limits",
+			urls:       urls(maxSitemapURLs + 1),
+			wantFiles:  []string{"sitemap-0001.txt", "sitemap-0002.txt"},
+			wantCounts: []int{maxSitemapURLs, 1},
+		},
+		{
+			name:       "max URLs",
+			urls:       urls(7),
+			opts:       []SplitOption{WithSplitMaxURLs(3)},
+			wantFiles:  []string{"sitemap-0001.txt", "sitemap-0002.txt", "sitemap-0003.txt"},
+			wantCounts: []int{3, 3, 1},
+		},
+		{
+			name:       "max bytes",
+			urls:       urls(5),
+			opts:       []SplitOption{WithSplitMaxBytes(2*lineLength + 1)},
+			wantFiles:  []string{"sitemap-0001.txt", "sitemap-0002.txt", "sitemap-0003.txt"},
+			wantCounts: []int{2, 2, 1},
+		},
+		{
+			name:       "gzip",
+			urls:       urls(3),
+			opts:       []SplitOption{WithSplitMaxURLs(2), WithSplitGzip(true)},
+			wantFiles:  []string{"sitemap-0001.txt.gz", "sitemap-0002.txt.gz"},
+			wantCounts: []int{2, 1},
+		},
+		{
+			name:       "invalid locations skipped",
+			urls:       append([]URL{{Loc: "/relative"}}, urls(2)...),
+			wantFiles:  []string{"sitemap-0001.txt"},
+			wantCounts: []int{2},
+		},
+		{
+			name:      "empty",
+			urls:      nil,
+			wantFiles: []string{},
+		},
+		{
+			name:    "URL exceeds max bytes",
+			urls:    urls(1),
+			opts:    []SplitOption{WithSplitMaxBytes(lineLength - 1)},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			err := WriteTextSplit(dir, test.urls, test.opts...)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if err != nil {
+				return
+			}
+
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			files := []string{}
+			for _, entry := range entries {
+				files = append(files, entry.Name())
+			}
+			sort.Strings(files)
+			if !reflect.DeepEqual(files, test.wantFiles) {
+				t.Fatalf("expected %v, got %v", test.wantFiles, files)
+			}
+
+			// reading the files back in order yields the valid locations
+			var locs []string
+			for i, name := range files {
+				content, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if strings.HasSuffix(name, ".gz") {
+					zr, err := gzip.NewReader(bytes.NewReader(content))
+					if err != nil {
+						t.Fatal(err)
+					}
+					if content, err = io.ReadAll(zr); err != nil {
+						t.Fatal(err)
+					}
+				}
+				lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+				if len(lines) != test.wantCounts[i] {
+					t.Errorf("%s: expected %d URLs, got %d", name, test.wantCounts[i], len(lines))
+				}
+				locs = append(locs, lines...)
+			}
+			if want := textLocs(test.urls); len(want) > 0 && !reflect.DeepEqual(locs, want) {
+				t.Errorf("expected %d locations, got %d", len(want), len(locs))
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+package sitemap
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+type (
+	// rateLimiter is a token bucket with a capacity of one token, refilled at a fixed interval.
+	// The interval field is the time between two requests allowed by the limiter.
+	// The next field is the earliest time the next request is allowed at.
+	rateLimiter struct {
+		mu       sync.Mutex
+		interval time.Duration
+		next     time.Time
+	}
+
+	// hostRateLimiters is a set of rateLimiter instances keyed by host, created on demand.
+	hostRateLimiters struct {
+		mu       sync.Mutex
+		interval time.Duration
+		limiters map[string]*rateLimiter
+	}
+
+	// hostDelays serializes the requests per host and enforces a delay between them.
+	// The delay field is the time to wait after a request to a host has finished, before the next one to the same host.
+	// The jitter field is the maximum random deviation added to or subtracted from the delay,
+	// the random field returns the random numbers in [0,n) the deviation is drawn from, nil means the shared source of math/rand.
+	// The crawlDelays field holds the Crawl-delay of the robots.txt file per host, the stricter of it and the delay applies.
+	// The hosts field holds the state per host, created on demand.
+	hostDelays struct {
+		mu          sync.Mutex
+		delay       time.Duration
+		jitter      time.Duration
+		random      func(n int64) int64
+		crawlDelays map[string]time.Duration
+		hosts       map[string]*hostDelay
+	}
+
+	// hostDelay is the state of a single host in hostDelays.
+	// The slot field is a semaphore allowing a single request to the host at a time.
+	// The finished field is the time the last request to the host finished at.
+	hostDelay struct {
+		slot     chan struct{}
+		finished time.Time
+	}
+
+	// concurrencyLimiter limits the number of requests in progress, overall and per host.
+	// The overall field is a semaphore of the requests in progress, nil means no overall limit.
+	// The perHost field is the maximum number of requests in progress per host, 0 means no limit per host.
+	// The hosts field holds the semaphore per host, created on demand.
+	concurrencyLimiter struct {
+		mu      sync.Mutex
+		overall chan struct{}
+		perHost int
+		hosts   map[string]chan struct{}
+	}
+)
+
+// newRateLimiter creates a rateLimiter allowing rps requests per second.
+// It returns nil if rps is not positive, which means no limit.
+func newRateLimiter(rps float64) *rateLimiter {
+	if rps <= 0 {
+		return nil
+	}
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / rps)}
+}
+
+// newHostRateLimiters creates a hostRateLimiters allowing rps requests per second per host.
+// It returns nil if rps is not positive, which means no limit.
+func newHostRateLimiters(rps float64) *hostRateLimiters {
+	if rps <= 0 {
+		return nil
+	}
+	return &hostRateLimiters{
+		interval: time.Duration(float64(time.Second) / rps),
+		limiters: map[string]*rateLimiter{},
+	}
+}
+
+// wait blocks until the limiter allows the next request or ctx is done.
+// It returns the error of ctx if ctx is done before the request is allowed.
+// A nil limiter allows every request immediately.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	return sleep(ctx, delay)
+}
+
+// wait blocks until the limiter of the given host allows the next request or ctx is done.
+// A nil hostRateLimiters allows every request immediately.
+func (h *hostRateLimiters) wait(ctx context.Context, host string) error {
+	if h == nil {
+		return ctx.Err()
+	}
+
+	h.mu.Lock()
+	limiter, ok := h.limiters[host]
+	if !ok {
+		limiter = &rateLimiter{interval: h.interval}
+		h.limiters[host] = limiter
+	}
+	h.mu.Unlock()
+
+	return limiter.wait(ctx)
+}
+
+// newHostDelays creates a hostDelays enforcing the given delay and jitter between requests to the same host,
+// drawing the jitter with the given function, nil means the shared source of math/rand.
+// It returns nil if delay is not positive, which means no delay.
+func newHostDelays(delay time.Duration, jitter time.Duration, random func(n int64) int64) *hostDelays {
+	if delay <= 0 {
+		return nil
+	}
+	if jitter < 0 {
+		jitter = 0
+	}
+	return &hostDelays{
+		delay:  delay,
+		jitter: jitter,
+		random: random,
+		hosts:  map[string]*hostDelay{},
+	}
+}
+
+// withCrawlDelay sets the Crawl-delay of the given host, creating a hostDelays without a delay of its own if h is nil.
+// It returns the hostDelays the Crawl-delay is set on.
+func (h *hostDelays) withCrawlDelay(host string, crawlDelay time.Duration) *hostDelays {
+	if h == nil {
+		h = &hostDelays{hosts: map[string]*hostDelay{}}
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.crawlDelays == nil {
+		h.crawlDelays = map[string]time.Duration{}
+	}
+	h.crawlDelays[host] = crawlDelay
+	return h
+}
+
+// acquire blocks until no other request to the given host is in progress and the delay since the last one has elapsed,
+// the stricter of the delay deviated by the jitter and the Crawl-delay of the host, or until ctx is done. On success, it returns a function that must be called when the request has finished.
+// It returns the error of ctx if ctx is done first. A nil hostDelays allows every request immediately.
+func (h *hostDelays) acquire(ctx context.Context, host string) (func(), error) {
+	if h == nil {
+		return func() {}, ctx.Err()
+	}
+
+	h.mu.Lock()
+	hd, ok := h.hosts[host]
+	if !ok {
+		hd = &hostDelay{slot: make(chan struct{}, 1)}
+		h.hosts[host] = hd
+	}
+	crawlDelay := h.crawlDelays[host]
+	h.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case hd.slot <- struct{}{}:
+	}
+
+	if !hd.finished.IsZero() {
+		delay := h.delay
+		if h.jitter > 0 {
+			random := h.random
+			if random == nil {
+				random = rand.Int63n
+			}
+			delay += time.Duration(random(int64(2*h.jitter)+1)) - h.jitter
+		}
+		delay = max(delay, crawlDelay)
+		if err := sleep(ctx, time.Until(hd.finished.Add(delay))); err != nil {
+			<-hd.slot
+			return nil, err
+		}
+	}
+
+	return func() {
+		hd.finished = time.Now()
+		<-hd.slot
+	}, nil
+}
+
+// newConcurrencyLimiter creates a concurrencyLimiter allowing overall requests in progress and perHost requests in progress per host.
+// A limit that is not positive means no limit, and it returns nil if neither limit is positive.
+func newConcurrencyLimiter(overall int, perHost int) *concurrencyLimiter {
+	if overall <= 0 && perHost <= 0 {
+		return nil
+	}
+	c := &concurrencyLimiter{hosts: map[string]chan struct{}{}}
+	if overall > 0 {
+		c.overall = make(chan struct{}, overall)
+	}
+	if perHost > 0 {
+		c.perHost = perHost
+	}
+	return c
+}
+
+// acquire blocks until a request to the given host is allowed by both limits, or until ctx is done.
+// The slot of the host is acquired first, so a request waiting for a busy host does not hold an overall slot.
+// On success, it returns a function that must be called when the request has finished.
+// It returns the error of ctx if ctx is done first. A nil concurrencyLimiter allows every request immediately.
+func (c *concurrencyLimiter) acquire(ctx context.Context, host string) (func(), error) {
+	if c == nil {
+		return func() {}, ctx.Err()
+	}
+
+	var hostSlot chan struct{}
+	if c.perHost > 0 {
+		c.mu.Lock()
+		hostSlot = c.hosts[host]
+		if hostSlot == nil {
+			hostSlot = make(chan struct{}, c.perHost)
+			c.hosts[host] = hostSlot
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case hostSlot <- struct{}{}:
+		}
+	}
+	if c.overall != nil {
+		select {
+		case <-ctx.Done():
+			if hostSlot != nil {
+				<-hostSlot
+			}
+			return nil, ctx.Err()
+		case c.overall <- struct{}{}:
+		}
+	}
+
+	return func() {
+		if c.overall != nil {
+			<-c.overall
+		}
+		if hostSlot != nil {
+			<-hostSlot
+		}
+	}, nil
+}
+
+// sleep blocks for the given duration or until ctx is done, whichever happens first.
+// It returns the error of ctx if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// throttle waits for the concurrency slots, the request delay and the rate limits of the given host before a request,
+// and adds the time waited to the timing of the parse. The returned function releases the slots once the request is done.
+func (s *S) throttle(ctx context.Context, host string) (func(), error) {
+	defer s.addTiming(&s.timing.Waiting, time.Now())
+
+	releaseSlot, err := s.concurrency.acquire(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	releaseDelay, err := s.hostDelays.acquire(ctx, host)
+	if err != nil {
+		releaseSlot()
+		return nil, err
+	}
+	release := func() {
+		releaseDelay()
+		releaseSlot()
+	}
+	if err = s.rateLimiter.wait(ctx); err != nil {
+		release()
+		return nil, err
+	}
+	if err = s.hostRateLimiters.wait(ctx, host); err != nil {
+		release()
+		return nil, err
+	}
+	return release, nil
+}
@@ -0,0 +1,488 @@
+package sitemap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_wait(t *testing.T) {
+	tests := []struct {
+		name       string
+		rps        float64
+		requests   int
+		minElapsed time.Duration
+		maxElapsed time.Duration
+	}{
+		{
+			name:       "no limit",
+			rps:        0,
+			requests:   10,
+			minElapsed: 0,
+			maxElapsed: 50 * time.Millisecond,
+		},
+		{
+			name:       "20 requests per second",
+			rps:        20,
+			requests:   5,
+			minElapsed: 200 * time.Millisecond,
+			maxElapsed: time.Second,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			limiter := newRateLimiter(test.rps)
+			start := time.Now()
+			for i := 0; i < test.requests; i++ {
+				if err := limiter.wait(context.Background()); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			elapsed := time.Since(start)
+			if elapsed < test.minElapsed || elapsed > test.maxElapsed {
+				t.Errorf("expected elapsed time between %v and %v, got %v", test.minElapsed, test.maxElapsed, elapsed)
+			}
+		})
+	}
+
+	t.Run("cancelled context", func(t *testing.T) {
+		limiter := newRateLimiter(1)
+		_ = limiter.wait(context.Background())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		err := limiter.wait(ctx)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+		}
+		if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+			t.Errorf("expected wait to be cancelled, took %v", elapsed)
+		}
+	})
+}
+
+func TestHostRateLimiters_wait(t *testing.T) {
+	limiters := newHostRateLimiters(5)
+
+	start := time.Now()
+	_ = limiters.wait(context.Background(), "a.sitemaps.org")
+	_ = limiters.wait(context.Background(), "b.sitemaps.org")
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("expected first requests of different hosts to be allowed immediately, took %v", elapsed)
+	}
+
+	_ = limiters.wait(context.Background(), "a.sitemaps.org")
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("expected second request of the same host to wait, took %v", elapsed)
+	}
+
+	var nilLimiters *hostRateLimiters
+	if err := nilLimiters.wait(context.Background(), "a.sitemaps.org"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestS_Parse_RateLimit(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	url := fmt.Sprintf("%s/robots-with-sitemapindex/robots.txt", server.URL)
+
+	tests := []struct {
+		name             string
+		rateLimit        float64
+		perHostRateLimit float64
+		minElapsed       time.Duration
+	}{
+		{
+			name:       "rate limit",
+			rateLimit:  20,
+			minElapsed: 200 * time.Millisecond,
+		},
+		{
+			name:             "per-host rate limit",
+			perHostRateLimit: 20,
+			minElapsed:       200 * time.Millisecond,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			start := time.Now()
+			// 5 requests: robots.txt, sitemapindex and 3 sitemaps
+			s, err := New().SetRateLimit(test.rateLimit).SetPerHostRateLimit(test.perHostRateLimit).Parse(url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if elapsed := time.Since(start); elapsed < test.minElapsed {
+				t.Errorf("expected at least %v, got %v", test.minElapsed, elapsed)
+			}
+			if s.GetURLCount() != 6 {
+				t.Errorf("expected 6 URLs, got %d", s.GetURLCount())
+			}
+		})
+	}
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		s, _ := New().SetRateLimit(2).ParseContext(ctx, url, nil)
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("expected the parse to be cancelled, took %v", elapsed)
+		}
+		if s.GetErrorsCount() == 0 {
+			t.Fatal("expected errors")
+		}
+		for _, err := range s.GetErrors() {
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+			}
+		}
+	})
+}
+
+func TestHostDelays_acquire(t *testing.T) {
+	t.Run("delay between requests to the same host", func(t *testing.T) {
+		delays := newHostDelays(100*time.Millisecond, 0, nil)
+
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			release, err := delays.acquire(context.Background(), "a.sitemaps.org")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			release()
+		}
+		if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+			t.Errorf("expected at least 200ms, got %v", elapsed)
+		}
+
+		start = time.Now()
+		release, _ := delays.acquire(context.Background(), "b.sitemaps.org")
+		release()
+		if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+			t.Errorf("expected other host to be allowed immediately, took %v", elapsed)
+		}
+	})
+
+	t.Run("jitter", func(t *testing.T) {
+		delays := newHostDelays(100*time.Millisecond, 50*time.Millisecond, nil)
+
+		release, _ := delays.acquire(context.Background(), "a.sitemaps.org")
+		release()
+		for i := 0; i < 3; i++ {
+			start := time.Now()
+			release, _ = delays.acquire(context.Background(), "a.sitemaps.org")
+			release()
+			if elapsed := time.Since(start); elapsed < 50*time.Millisecond || elapsed > 200*time.Millisecond {
+				t.Errorf("expected delay between 50ms and 150ms, got %v", elapsed)
+			}
+		}
+	})
+
+	t.Run("jitter from the given source", func(t *testing.T) {
+		var drawn []int64
+		delays := newHostDelays(100*time.Millisecond, 50*time.Millisecond, func(n int64) int64 {
+			drawn = append(drawn, n)
+			return 0
+		})
+
+		release, _ := delays.acquire(context.Background(), "a.sitemaps.org")
+		release()
+		start := time.Now()
+		release, _ = delays.acquire(context.Background(), "a.sitemaps.org")
+		release()
+		if elapsed := time.Since(start); elapsed < 50*time.Millisecond || elapsed > 90*time.Millisecond {
+			t.Errorf("expected the shortest delay of 50ms, got %v", elapsed)
+		}
+		if want := []int64{int64(100*time.Millisecond) + 1}; !reflect.DeepEqual(drawn, want) {
+			t.Errorf("expected the jitter to be drawn from %v, got %v", want, drawn)
+		}
+	})
+
+	t.Run("stricter crawl delay", func(t *testing.T) {
+		delays := newHostDelays(10*time.Millisecond, 0, nil).withCrawlDelay("a.sitemaps.org", 100*time.Millisecond)
+
+		start := time.Now()
+		for i := 0; i < 2; i++ {
+			release, _ := delays.acquire(context.Background(), "a.sitemaps.org")
+			release()
+		}
+		if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+			t.Errorf("expected the crawl delay of at least 100ms, got %v", elapsed)
+		}
+
+		start = time.Now()
+		for i := 0; i < 2; i++ {
+			release, _ := delays.acquire(context.Background(), "b.sitemaps.org")
+			release()
+		}
+		if elapsed := time.Since(start); elapsed > 90*time.Millisecond {
+			t.Errorf("expected the crawl delay not to apply to other hosts, took %v", elapsed)
+		}
+	})
+
+	t.Run("crawl delay without delay", func(t *testing.T) {
+		var none *hostDelays
+		delays := none.withCrawlDelay("a.sitemaps.org", 50*time.Millisecond)
+
+		start := time.Now()
+		for i := 0; i < 2; i++ {
+			release, _ := delays.acquire(context.Background(), "a.sitemaps.org")
+			release()
+		}
+		if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+			t.Errorf("expected the crawl delay of at least 50ms, got %v", elapsed)
+		}
+	})
+
+	t.Run("requests to the same host are serialized", func(t *testing.T) {
+		delays := newHostDelays(10*time.Millisecond, 0, nil)
+
+		release, _ := delays.acquire(context.Background(), "a.sitemaps.org")
+		acquired := make(chan struct{})
+		go func() {
+			release, _ := delays.acquire(context.Background(), "a.sitemaps.org")
+			release()
+			close(acquired)
+		}()
+
+		select {
+		case <-acquired:
+			t.Fatal("expected the second request to wait for the first one")
+		case <-time.After(50 * time.Millisecond):
+		}
+		release()
+		<-acquired
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		delays := newHostDelays(time.Second, 0, nil)
+		release, _ := delays.acquire(context.Background(), "a.sitemaps.org")
+		release()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		if _, err := delays.acquire(ctx, "a.sitemaps.org"); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+		}
+	})
+
+	t.Run("nil receiver", func(t *testing.T) {
+		var delays *hostDelays
+		release, err := delays.acquire(context.Background(), "a.sitemaps.org")
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		release()
+	})
+}
+
+func TestS_Parse_RequestDelay(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	start := time.Now()
+	// 5 requests to the same host: robots.txt, sitemapindex and 3 sitemaps
+	s, err := New().SetRequestDelay(50*time.Millisecond, 0).Parse(fmt.Sprintf("%s/robots-with-sitemapindex/robots.txt", server.URL), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("expected at least 200ms, got %v", elapsed)
+	}
+	if s.GetURLCount() != 6 {
+		t.Errorf("expected 6 URLs, got %d", s.GetURLCount())
+	}
+}
+
+func TestConcurrencyLimiter_acquire(t *testing.T) {
+	t.Run("limit per host", func(t *testing.T) {
+		limiter := newConcurrencyLimiter(0, 2)
+
+		releaseA1, _ := limiter.acquire(context.Background(), "a.sitemaps.org")
+		releaseA2, _ := limiter.acquire(context.Background(), "a.sitemaps.org")
+		releaseB, err := limiter.acquire(context.Background(), "b.sitemaps.org")
+		if err != nil {
+			t.Fatalf("expected other host to be allowed, got %v", err)
+		}
+		releaseB()
+
+		acquired := make(chan struct{})
+		go func() {
+			release, _ := limiter.acquire(context.Background(), "a.sitemaps.org")
+			release()
+			close(acquired)
+		}()
+		select {
+		case <-acquired:
+			t.Fatal("expected the third request to the host to wait")
+		case <-time.After(50 * time.Millisecond):
+		}
+		releaseA1()
+		<-acquired
+		releaseA2()
+	})
+
+	t.Run("overall limit", func(t *testing.T) {
+		limiter := newConcurrencyLimiter(2, 2)
+
+		releaseA, _ := limiter.acquire(context.Background(), "a.sitemaps.org")
+		releaseB, _ := limiter.acquire(context.Background(), "b.sitemaps.org")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		if _, err := limiter.acquire(ctx, "c.sitemaps.org"); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+		}
+		releaseA()
+		releaseC, err := limiter.acquire(context.Background(), "c.sitemaps.org")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		releaseC()
+		releaseB()
+
+		// the slot of the host is given back when the overall limit is not reached in time
+		for i := 0; i < 2; i++ {
+			release, err := limiter.acquire(context.Background(), "c.sitemaps.org")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer release()
+		}
+	})
+
+	t.Run("no limit", func(t *testing.T) {
+		if limiter := newConcurrencyLimiter(0, 0); limiter != nil {
+			t.Errorf("expected nil, got %+v", limiter)
+		}
+	})
+
+	t.Run("nil receiver", func(t *testing.T) {
+		var limiter *concurrencyLimiter
+		release, err := limiter.acquire(context.Background(), "a.sitemaps.org")
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		release()
+	})
+}
+
+// concurrencyCounter records the peak number of requests in progress, overall and per server.
+type concurrencyCounter struct {
+	mu          sync.Mutex
+	inProgress  map[string]int
+	total       int
+	peak        map[string]int
+	peakOverall int
+}
+
+func (c *concurrencyCounter) start(server string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.inProgress[server]++
+	c.total++
+	if c.inProgress[server] > c.peak[server] {
+		c.peak[server] = c.inProgress[server]
+	}
+	if c.total > c.peakOverall {
+		c.peakOverall = c.total
+	}
+}
+
+func (c *concurrencyCounter) end(server string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.inProgress[server]--
+	c.total--
+}
+
+// newConcurrencyServer returns a test server serving slow sitemaps, counting its requests in progress as the given name.
+func newConcurrencyServer(name string, counter *concurrencyCounter) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		counter.start(name)
+		defer counter.end(name)
+		time.Sleep(30 * time.Millisecond)
+		_, _ = fmt.Fprintf(w, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><url><loc>http://%s%s/page</loc></url></urlset>`, r.Host, r.URL.Path)
+	}))
+}
+
+func TestS_Parse_MaxConcurrencyPerHost(t *testing.T) {
+	tests := []struct {
+		name           string
+		s              func() *S
+		wantPerHost    int
+		wantOverall    int
+		wantMinOverall int
+	}{
+		{
+			name:           "per host",
+			s:              func() *S { return New().SetMaxConcurrencyPerHost(2) },
+			wantPerHost:    2,
+			wantOverall:    4,
+			wantMinOverall: 2,
+		},
+		{
+			name:           "per host and overall",
+			s:              func() *S { return New().SetMaxConcurrencyPerHost(2).SetMaxConcurrency(3) },
+			wantPerHost:    2,
+			wantOverall:    3,
+			wantMinOverall: 2,
+		},
+		{
+			name:           "overall",
+			s:              func() *S { return New().SetMaxConcurrency(1) },
+			wantPerHost:    1,
+			wantOverall:    1,
+			wantMinOverall: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			counter := &concurrencyCounter{inProgress: map[string]int{}, peak: map[string]int{}}
+			a := newConcurrencyServer("a", counter)
+			defer a.Close()
+			b := newConcurrencyServer("b", counter)
+			defer b.Close()
+
+			var index strings.Builder
+			index.WriteString(`<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`)
+			for i := 0; i < 6; i++ {
+				fmt.Fprintf(&index, "<sitemap><loc>%s/sitemap-%02d.xml</loc></sitemap>", a.URL, i)
+				fmt.Fprintf(&index, "<sitemap><loc>%s/sitemap-%02d.xml</loc></sitemap>", b.URL, i)
+			}
+			index.WriteString("</sitemapindex>")
+			content := index.String()
+
+			s, err := test.s().Parse("https://www.example.com/sitemapindex.xml", &content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.GetURLCount() != 12 {
+				t.Fatalf("expected 12 URLs, got %d: %v", s.GetURLCount(), s.GetErrors())
+			}
+			for host, peak := range counter.peak {
+				if peak > test.wantPerHost {
+					t.Errorf("expected at most %d concurrent requests to %s, got %d", test.wantPerHost, host, peak)
+				}
+			}
+			if counter.peakOverall > test.wantOverall || counter.peakOverall < test.wantMinOverall {
+				t.Errorf("expected between %d and %d concurrent requests overall, got %d", test.wantMinOverall, test.wantOverall, counter.peakOverall)
+			}
+		})
+	}
+}
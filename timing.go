@@ -0,0 +1,54 @@
+package sitemap
+
+import (
+	"time"
+)
+
+// Timing holds the timing of the last parse, to tell a slow site apart from a slow decoding.
+// The Total field is the wall-clock duration of the parse.
+// The Fetching field is the time spent on the requests, from sending them to reading their bodies, summed over all the fetches.
+// The Waiting field is the time the fetches spent waiting for the concurrency limits, the request delays and the rate limits, summed over all the fetches.
+// The Decoding field is the time spent decompressing and decoding the documents, summed over all the documents.
+// The Fetches field is the number of requests sent.
+// As the fetches run concurrently in multi-thread mode, the sums may exceed the total duration.
+// All durations are measured with the monotonic clock.
+type Timing struct {
+	Total    time.Duration `json:"total"`
+	Fetching time.Duration `json:"fetching"`
+	Waiting  time.Duration `json:"waiting"`
+	Decoding time.Duration `json:"decoding"`
+	Fetches  int           `json:"fetches"`
+}
+
+// GetDuration returns the wall-clock duration of the last parse finished, see GetTiming.
+// If the S object is nil or no parse has finished, 0 is returned.
+func (s *S) GetDuration() time.Duration {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.timing.Total
+}
+
+// GetTiming returns the timing of the last parse, see Timing. The sums are updated while a parse is in progress,
+// the total duration is set once it finishes. If the S object is nil, a zero Timing is returned.
+func (s *S) GetTiming() Timing {
+	if s == nil {
+		return Timing{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.timing
+}
+
+// addTiming adds the time elapsed since start to the given duration of the timing of the parse.
+func (s *S) addTiming(d *time.Duration, start time.Time) {
+	elapsed := time.Since(start)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	*d += elapsed
+}
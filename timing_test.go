@@ -0,0 +1,75 @@
+package sitemap
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestS_GetTiming(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		s           *S
+		wantWaiting bool
+	}{
+		{
+			name: "sequential",
+			s:    New().SetMultiThread(false),
+		},
+		{
+			name: "multi-thread",
+			s:    New(),
+		},
+		{
+			name:        "rate limit",
+			s:           New().SetRateLimit(20),
+			wantWaiting: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := test.s.Parse(fmt.Sprintf("%s/robots-with-sitemapindex-gz/robots.txt", server.URL), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			timing := s.GetTiming()
+			if timing.Total <= 0 || timing.Total != s.GetDuration() {
+				t.Errorf("expected a positive total duration returned by GetDuration, got %v and %v", timing.Total, s.GetDuration())
+			}
+			// the robots.txt file, the index and its 3 sitemaps
+			if timing.Fetches != 5 {
+				t.Errorf("expected 5 fetches, got %d", timing.Fetches)
+			}
+			if timing.Fetching <= 0 || timing.Decoding <= 0 {
+				t.Errorf("expected positive fetching and decoding durations, got %v and %v", timing.Fetching, timing.Decoding)
+			}
+			// at most the 3 sitemaps of the index are fetched at the same time
+			if timing.Fetching > 3*timing.Total || timing.Decoding > 3*timing.Total || timing.Waiting > 3*timing.Total {
+				t.Errorf("expected the sums within the total duration of 3 workers, got %+v", timing)
+			}
+			if test.wantWaiting && timing.Waiting < 100*time.Millisecond {
+				t.Errorf("expected at least 100ms waiting for the rate limit, got %v", timing.Waiting)
+			}
+		})
+	}
+}
+
+func TestS_GetTiming_Reset(t *testing.T) {
+	content := "<urlset><url><loc>https://www.example.com/</loc></url></urlset>"
+	s := New()
+	s.timing = Timing{Total: time.Hour, Fetching: time.Hour, Fetches: 10}
+
+	if _, err := s.Parse("https://www.example.com/sitemap.xml", &content); err != nil {
+		t.Fatal(err)
+	}
+	timing := s.GetTiming()
+	if timing.Total >= time.Hour || timing.Fetching != 0 || timing.Fetches != 0 {
+		t.Errorf("expected the timing of the previous parse to be reset, got %+v", timing)
+	}
+	if timing.Decoding <= 0 {
+		t.Errorf("expected a positive decoding duration, got %v", timing.Decoding)
+	}
+}
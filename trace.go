@@ -0,0 +1,130 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// TraceEntry is a decision log entry of the parser about a single location, recorded when the debug trace is turned on, see SetDebugTrace.
+// The Location field is the location of the document.
+// The Kind field is the kind the document has been classified as.
+// The RootElement field is the local name of the root element of the document, empty if it has none or has not been fetched.
+// The Encoding field is the encoding declared by the XML declaration of the document, empty if there is none.
+// The CompressedBytes and DecompressedBytes fields are the sizes of the document as received and after decompression, see FetchMeta;
+// they differ if the document has been decompressed. They are 0 if the content has not been fetched.
+// The EntriesDecoded field is the number of entries decoded from the document: <sitemap> entries, <url> entries or Sitemap lines of a robots.txt file.
+// The EntriesKept field is the number of those entries kept: the sitemaps followed or the URLs collected.
+// The Err field is the error encountered while processing the document, if any.
+type TraceEntry struct {
+	Location          string      `json:"location"`
+	Kind              SitemapKind `json:"kind"`
+	RootElement       string      `json:"root_element,omitempty"`
+	Encoding          string      `json:"encoding,omitempty"`
+	CompressedBytes   int64       `json:"compressed_bytes"`
+	DecompressedBytes int64       `json:"decompressed_bytes"`
+	EntriesDecoded    int         `json:"entries_decoded"`
+	EntriesKept       int         `json:"entries_kept"`
+	Err               error       `json:"-"`
+}
+
+// SetDebugTrace sets whether the Sitemap Parser records a decision log entry per location processed, retrievable with GetTrace.
+// The trace is meant for debugging, e.g. a document yielding no URLs; it costs an extra pass over the prolog of each document.
+// The default is false.
+// The function returns a pointer to the S structure to allow method chaining.
+func (s *S) SetDebugTrace(debugTrace bool) *S {
+	if s == nil {
+		return nil
+	}
+	s.cfg.debugTrace = debugTrace
+
+	return s
+}
+
+// GetTrace returns a copy of the decision log recorded during parsing, in the order the locations were processed, see SetDebugTrace.
+// A location failing after it has been parsed, e.g. because of a panic, has a second entry.
+// If the S object is nil or the debug trace is turned off, an empty slice is returned.
+func (s *S) GetTrace() []TraceEntry {
+	if s == nil {
+		return []TraceEntry{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]TraceEntry{}, s.trace...)
+}
+
+// traceEntry returns a trace entry of the given location with its kind and sizes, see TraceEntry.
+// It must be called with s.mu held.
+func (s *S) traceEntry(loc string) TraceEntry {
+	entry := TraceEntry{Location: loc, Kind: s.node(loc).Kind}
+	if meta, ok := s.fetchMeta[loc]; ok {
+		entry.CompressedBytes = meta.CompressedBytes
+		entry.DecompressedBytes = meta.DecompressedBytes
+	}
+	return entry
+}
+
+// traceDocument records the trace entry of the given parsed document, if the debug trace is turned on.
+// The content is the decompressed content of the document, the decoded and kept values are the numbers of its entries.
+// It must be called with s.mu held.
+func (s *S) traceDocument(loc string, content string, decoded, kept int) {
+	if !s.cfg.debugTrace {
+		return
+	}
+	entry := s.traceEntry(loc)
+	entry.RootElement, entry.Encoding = documentProlog(content)
+	if entry.CompressedBytes == 0 && entry.DecompressedBytes == 0 {
+		// the content has been passed to Parse
+		entry.CompressedBytes = int64(len(content))
+		entry.DecompressedBytes = int64(len(content))
+	}
+	entry.EntriesDecoded = decoded
+	entry.EntriesKept = kept
+	entry.Err = s.node(loc).Err
+	s.trace = append(s.trace, entry)
+}
+
+// traceError records the trace entry of the given location which failed with the given error, if the debug trace is turned on.
+// It must be called with s.mu held.
+func (s *S) traceError(loc string, err error) {
+	if !s.cfg.debugTrace {
+		return
+	}
+	entry := s.traceEntry(loc)
+	entry.Err = err
+	s.trace = append(s.trace, entry)
+}
+
+// documentProlog returns the local name of the root element of the XML document and the encoding of its XML declaration.
+// Both are empty if they cannot be found.
+func documentProlog(data string) (root string, encoding string) {
+	decoder := xml.NewDecoder(strings.NewReader(data))
+	for {
+		token, err := decoder.RawToken()
+		if err != nil {
+			return "", encoding
+		}
+		switch t := token.(type) {
+		case xml.ProcInst:
+			if t.Target == "xml" {
+				encoding = procInstParam(string(t.Inst), "encoding")
+			}
+		case xml.StartElement:
+			return t.Name.Local, encoding
+		}
+	}
+}
+
+// procInstParam returns the value of the given parameter of the instruction of a processing instruction, empty if it is not found.
+func procInstParam(inst string, param string) string {
+	for _, field := range strings.Fields(inst) {
+		name, value, ok := strings.Cut(field, "=")
+		if !ok || name != param || len(value) < 2 {
+			continue
+		}
+		if quote := value[0]; (quote == '"' || quote == '\'') && value[len(value)-1] == quote {
+			return value[1 : len(value)-1]
+		}
+	}
+	return ""
+}
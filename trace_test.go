@@ -0,0 +1,180 @@
+package sitemap
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestS_SetDebugTrace(t *testing.T) {
+	tests := []struct {
+		name       string
+		debugTrace bool
+	}{
+		{
+			name:       "Enabled",
+			debugTrace: true,
+		},
+		{
+			name:       "Disabled",
+			debugTrace: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := New().SetDebugTrace(test.debugTrace)
+			if s.cfg.debugTrace != test.debugTrace {
+				t.Errorf("expected %v, got %v", test.debugTrace, s.cfg.debugTrace)
+			}
+		})
+	}
+}
+
+func TestS_GetTrace(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	// traceRow is the comparable part of a trace entry, the sizes are checked separately
+	type traceRow struct {
+		Location       string
+		Kind           SitemapKind
+		RootElement    string
+		Encoding       string
+		EntriesDecoded int
+		EntriesKept    int
+		Err            bool
+	}
+
+	htmlContent := `<html><body>not a sitemap</body></html>`
+
+	tests := []struct {
+		name           string
+		s              *S
+		url            string
+		content        *string
+		want           []traceRow
+		wantCompressed []string
+	}{
+		{
+			name: "robots.txt with compressed index",
+			s:    New().SetDebugTrace(true).SetMultiThread(false),
+			url:  "/robots-with-sitemapindex-gz/robots.txt",
+			want: []traceRow{
+				{Location: "/robots-with-sitemapindex-gz/robots.txt", Kind: SitemapKindRobotsTXT, EntriesDecoded: 1, EntriesKept: 1},
+				{Location: "/sitemapindex-1.xml.gz", Kind: SitemapKindIndex, RootElement: "sitemapindex", Encoding: "UTF-8", EntriesDecoded: 3, EntriesKept: 3},
+				{Location: "/sitemap-01.xml.gz", Kind: SitemapKindURLSet, RootElement: "urlset", Encoding: "UTF-8", EntriesDecoded: 1, EntriesKept: 1},
+				{Location: "/sitemap-02.xml.gz", Kind: SitemapKindURLSet, RootElement: "urlset", Encoding: "UTF-8", EntriesDecoded: 2, EntriesKept: 2},
+				{Location: "/sitemap-03.xml.gz", Kind: SitemapKindURLSet, RootElement: "urlset", Encoding: "UTF-8", EntriesDecoded: 3, EntriesKept: 3},
+			},
+			wantCompressed: []string{"/sitemapindex-1.xml.gz", "/sitemap-01.xml.gz", "/sitemap-02.xml.gz", "/sitemap-03.xml.gz"},
+		},
+		{
+			name: "filtered entries",
+			s:    New().SetDebugTrace(true).SetMultiThread(false).SetFollow([]string{`sitemap-0[12]`}).SetRules([]string{`page-0[14]$`}),
+			url:  "/sitemapindex-1.xml",
+			want: []traceRow{
+				{Location: "/sitemapindex-1.xml", Kind: SitemapKindIndex, RootElement: "sitemapindex", Encoding: "UTF-8", EntriesDecoded: 3, EntriesKept: 2},
+				{Location: "/sitemap-01.xml", Kind: SitemapKindURLSet, RootElement: "urlset", Encoding: "UTF-8", EntriesDecoded: 1, EntriesKept: 1},
+				{Location: "/sitemap-02.xml", Kind: SitemapKindURLSet, RootElement: "urlset", Encoding: "UTF-8", EntriesDecoded: 2, EntriesKept: 0},
+			},
+		},
+		{
+			name: "failed sitemap",
+			s:    New().SetDebugTrace(true),
+			url:  "/sitemapindex-with-invalid-sitemap.xml",
+			want: []traceRow{
+				{Location: "/sitemapindex-with-invalid-sitemap.xml", Kind: SitemapKindIndex, RootElement: "sitemapindex", Encoding: "UTF-8", EntriesDecoded: 1, EntriesKept: 1},
+				{Location: "/invalid.xml", Kind: SitemapKindUnknown, Err: true},
+			},
+		},
+		{
+			name:    "not a sitemap",
+			s:       New().SetDebugTrace(true),
+			url:     "/page.html",
+			content: &htmlContent,
+			want: []traceRow{
+				{Location: "/page.html", Kind: SitemapKindUnknown, RootElement: "html", Err: true},
+			},
+		},
+		{
+			name: "debug trace turned off",
+			s:    New().SetDebugTrace(false),
+			url:  "/sitemapindex-1.xml",
+			want: []traceRow{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, _ := test.s.Parse(server.URL+test.url, test.content)
+
+			trace := s.GetTrace()
+			got := []traceRow{}
+			for _, entry := range trace {
+				got = append(got, traceRow{
+					Location:       strings.TrimPrefix(entry.Location, server.URL),
+					Kind:           entry.Kind,
+					RootElement:    entry.RootElement,
+					Encoding:       entry.Encoding,
+					EntriesDecoded: entry.EntriesDecoded,
+					EntriesKept:    entry.EntriesKept,
+					Err:            entry.Err != nil,
+				})
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %+v, got %+v", test.want, got)
+			}
+
+			compressed := map[string]bool{}
+			for _, loc := range test.wantCompressed {
+				compressed[loc] = true
+			}
+			for _, entry := range trace {
+				if entry.Err != nil {
+					continue
+				}
+				if entry.DecompressedBytes == 0 {
+					t.Errorf("%s: expected the decompressed size", entry.Location)
+				}
+				if got := entry.CompressedBytes != entry.DecompressedBytes; got != compressed[strings.TrimPrefix(entry.Location, server.URL)] {
+					t.Errorf("%s: expected decompressed %v, got sizes %d and %d", entry.Location, !got, entry.CompressedBytes, entry.DecompressedBytes)
+				}
+			}
+		})
+	}
+}
+
+func TestS_GetTrace_Error(t *testing.T) {
+	content := `<!DOCTYPE urlset><urlset></urlset>`
+	s, err := New().SetDebugTrace(true).Parse("https://www.example.com/sitemap.xml", &content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trace := s.GetTrace()
+	if len(trace) != 1 || !errors.Is(trace[0].Err, ErrDoctypeNotAllowed) {
+		t.Errorf("expected a trace entry with %v, got %+v", ErrDoctypeNotAllowed, trace)
+	}
+}
+
+func TestProcInstParam(t *testing.T) {
+	tests := []struct {
+		name string
+		inst string
+		want string
+	}{
+		{name: "double quotes", inst: `version="1.0" encoding="UTF-8"`, want: "UTF-8"},
+		{name: "single quotes", inst: `version='1.0' encoding='ISO-8859-1'`, want: "ISO-8859-1"},
+		{name: "missing", inst: `version="1.0"`, want: ""},
+		{name: "unquoted", inst: `encoding=UTF-8`, want: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := procInstParam(test.inst, "encoding"); got != test.want {
+				t.Errorf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package sitemap
+
+import (
+	"time"
+)
+
+// SitemapKind represents the kind of document a sitemap location points to.
+type SitemapKind string
+
+const (
+	// SitemapKindRobotsTXT is the kind of a robots.txt file.
+	SitemapKindRobotsTXT SitemapKind = "robots.txt"
+
+	// SitemapKindIndex is the kind of a <sitemapindex> document.
+	SitemapKindIndex SitemapKind = "sitemapindex"
+
+	// SitemapKindURLSet is the kind of a <urlset> document.
+	SitemapKindURLSet SitemapKind = "urlset"
+
+	// SitemapKindHTML is the kind of an HTML page linking to sitemaps, see SetDiscoverFromHTML.
+	SitemapKindHTML SitemapKind = "html"
+
+	// SitemapKindText is the kind of a plain text sitemap, listing one URL per line, see DetectKind.
+	SitemapKindText SitemapKind = "text"
+
+	// SitemapKindUnknown is the kind of a document that has not been fetched or is neither a sitemapindex nor a sitemap.
+	SitemapKindUnknown SitemapKind = "unknown"
+)
+
+// SitemapNode is a node of the sitemap tree, representing a single document processed during parsing.
+// The Loc field is the location of the document.
+// The Kind field is the kind of the document.
+// The Parent field is the node of the document referencing this document, or nil for the root node.
+// The Children field holds the nodes of the documents referenced by this document.
+// The URLCount field is the number of URLs collected from the document.
+// The Truncated field reports whether URLs of the document have been dropped because of SetMaxURLsPerSitemap.
+// The LastMod field is the <lastmod> value of the entry referencing the document in the parent sitemap index, if any.
+// The NewestURLLastMod field is the latest <lastmod> value of the URLs of the document, including the URLs rejected by the rules, if any.
+// The Err field is the error encountered while processing the document, if any.
+// The fetched field records whether the document has been processed, successfully or not, see GetCheckpoint.
+type SitemapNode struct {
+	Loc              string         `json:"loc"`
+	Kind             SitemapKind    `json:"kind"`
+	Parent           *SitemapNode   `json:"-"`
+	Children         []*SitemapNode `json:"children,omitempty"`
+	URLCount         int64          `json:"url_count"`
+	Truncated        bool           `json:"truncated,omitempty"`
+	LastMod          *time.Time     `json:"lastmod,omitempty"`
+	NewestURLLastMod *time.Time     `json:"newest_url_lastmod,omitempty"`
+	Err              error          `json:"-"`
+
+	fetched bool
+}
+
+// GetSitemapTree returns a copy of the root node of the sitemap tree built during parsing.
+// The root node represents the main URL passed to Parse, its descendants represent the documents referenced by it.
+// The copy is not modified by the parse, nor does modifying it affect the S object.
+// If the S object is nil or Parse has not been called, nil is returned.
+func (s *S) GetSitemapTree() *SitemapNode {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tree == nil {
+		return nil
+	}
+	tree := copyNode(s.tree, nil, map[*SitemapNode]bool{})
+	s.copyNodeErrors(tree)
+	return tree
+}
+
+// copyNodeErrors sets the errors of the given copied node and its descendants to the ones of the nodes of their locations.
+// It must be called with s.mu held.
+func (s *S) copyNodeErrors(n *SitemapNode) {
+	if node, ok := s.nodes[n.Loc]; ok {
+		n.Err = node.Err
+	}
+	for _, child := range n.Children {
+		s.copyNodeErrors(child)
+	}
+}
+
+// node returns the node of the sitemap tree for the given location, creating it if it does not exist yet.
+// It must be called with s.mu held.
+func (s *S) node(loc string) *SitemapNode {
+	if s.nodes == nil {
+		s.nodes = map[string]*SitemapNode{}
+	}
+	n, ok := s.nodes[loc]
+	if !ok {
+		n = &SitemapNode{Loc: loc, Kind: SitemapKindUnknown}
+		s.nodes[loc] = n
+	}
+	return n
+}
+
+// addChildNode adds the node of the given location as a child of the parent node.
+// The lastMod value is the <lastmod> value of the referencing entry, nil if there is none.
+// The node is not added if it is the parent node or one of its ancestors, as the tree would become a cycle.
+// It reports whether the node was added. It must be called with s.mu held.
+func (s *S) addChildNode(parent *SitemapNode, loc string, lastMod *time.Time) bool {
+	child := s.node(loc)
+	for ancestor := parent; ancestor != nil; ancestor = ancestor.Parent {
+		if ancestor == child {
+			return false
+		}
+	}
+	child.Parent = parent
+	if lastMod != nil {
+		child.LastMod = lastMod
+	}
+	parent.Children = append(parent.Children, child)
+	return true
+}
+
+// setNodeError records the error encountered while processing the document of the given location.
+func (s *S) setNodeError(loc string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.node(loc).Err = err
+	s.traceError(loc, err)
+}
@@ -0,0 +1,184 @@
+package sitemap
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestS_GetSitemapTree(t *testing.T) {
+	server := testServer()
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		url         string
+		multiThread bool
+		want        string
+	}{
+		{
+			name:        "robots.txt with two sitemapindex, multi-thread",
+			url:         fmt.Sprintf("%s/robots-with-sitemapindex-2/robots.txt", server.URL),
+			multiThread: true,
+			want: "robots.txt HOST/robots-with-sitemapindex-2/robots.txt urls=0\n" +
+				"  sitemapindex HOST/sitemapindex-1.xml urls=0\n" +
+				"    urlset HOST/sitemap-01.xml urls=1 lastmod=2024-02-12T11:34:56Z\n" +
+				"    urlset HOST/sitemap-02.xml urls=2 lastmod=2024-02-12T11:34:56Z\n" +
+				"    urlset HOST/sitemap-03.xml urls=3 lastmod=2024-02-12T11:34:56Z\n" +
+				"  sitemapindex HOST/sitemapindex-2.xml urls=0\n" +
+				"    urlset HOST/sitemap-04.xml urls=1 lastmod=2024-02-12T11:34:56Z\n" +
+				"    urlset HOST/sitemap-05.xml urls=2 lastmod=2024-02-12T11:34:56Z\n" +
+				"    urlset HOST/sitemap-06.xml urls=3 lastmod=2024-02-12T11:34:56Z\n",
+		},
+		{
+			name:        "robots.txt with two sitemapindex, sequential",
+			url:         fmt.Sprintf("%s/robots-with-sitemapindex-2/robots.txt", server.URL),
+			multiThread: false,
+			want: "robots.txt HOST/robots-with-sitemapindex-2/robots.txt urls=0\n" +
+				"  sitemapindex HOST/sitemapindex-1.xml urls=0\n" +
+				"    urlset HOST/sitemap-01.xml urls=1 lastmod=2024-02-12T11:34:56Z\n" +
+				"    urlset HOST/sitemap-02.xml urls=2 lastmod=2024-02-12T11:34:56Z\n" +
+				"    urlset HOST/sitemap-03.xml urls=3 lastmod=2024-02-12T11:34:56Z\n" +
+				"  sitemapindex HOST/sitemapindex-2.xml urls=0\n" +
+				"    urlset HOST/sitemap-04.xml urls=1 lastmod=2024-02-12T11:34:56Z\n" +
+				"    urlset HOST/sitemap-05.xml urls=2 lastmod=2024-02-12T11:34:56Z\n" +
+				"    urlset HOST/sitemap-06.xml urls=3 lastmod=2024-02-12T11:34:56Z\n",
+		},
+		{
+			name:        "sitemapindex with invalid sitemap",
+			url:         fmt.Sprintf("%s/sitemapindex-with-invalid-sitemap.xml", server.URL),
+			multiThread: true,
+			want: "sitemapindex HOST/sitemapindex-with-invalid-sitemap.xml urls=0\n" +
+				"  unknown HOST/invalid.xml urls=0 lastmod=2024-02-12T11:34:56Z err=received HTTP status 404\n",
+		},
+		{
+			name:        "robots.txt with invalid sitemap",
+			url:         fmt.Sprintf("%s/robots-with-invalid-sitemap/robots.txt", server.URL),
+			multiThread: false,
+			want: "robots.txt HOST/robots-with-invalid-sitemap/robots.txt urls=0\n" +
+				"  unknown HOST/invalid.xml urls=0 err=received HTTP status 404\n",
+		},
+		{
+			name:        "page not found",
+			url:         fmt.Sprintf("%s/404", server.URL),
+			multiThread: true,
+			want:        "unknown HOST/404 urls=0 err=received HTTP status 404\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, _ := New().SetMultiThread(test.multiThread).Parse(test.url, nil)
+
+			var sb strings.Builder
+			writeTreeString(&sb, s.GetSitemapTree(), "")
+			got := strings.ReplaceAll(sb.String(), server.URL, "HOST")
+			if got != test.want {
+				t.Errorf("expected\n%s\ngot\n%s", test.want, got)
+			}
+		})
+	}
+
+	t.Run("parent links", func(t *testing.T) {
+		s, _ := New().Parse(fmt.Sprintf("%s/robots-with-sitemapindex/robots.txt", server.URL), nil)
+		root := s.GetSitemapTree()
+		for _, index := range root.Children {
+			if index.Parent != root {
+				t.Errorf("unexpected parent of %s", index.Loc)
+			}
+			for _, child := range index.Children {
+				if child.Parent != index {
+					t.Errorf("unexpected parent of %s", child.Loc)
+				}
+			}
+		}
+	})
+
+	t.Run("copy", func(t *testing.T) {
+		s, _ := New().Parse(fmt.Sprintf("%s/sitemapindex-with-invalid-sitemap.xml", server.URL), nil)
+		if children := s.GetSitemapTree().Children; len(children) != 1 || children[0].Err == nil {
+			t.Fatalf("expected a child with an error, got %+v", children)
+		}
+
+		s, err := New().Parse(fmt.Sprintf("%s/sitemapindex-1.xml", server.URL), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tree := s.GetSitemapTree()
+		tree.URLCount = 100
+		tree.Children = tree.Children[:1]
+		if got := s.GetSitemapTree(); got.URLCount != 0 || len(got.Children) != 3 {
+			t.Errorf("expected the tree of the S object to be unchanged, got %+v", got)
+		}
+
+		if _, err = s.Parse(fmt.Sprintf("%s/sitemap-01.xml", server.URL), nil); err != nil {
+			t.Fatal(err)
+		}
+		if tree.Loc != fmt.Sprintf("%s/sitemapindex-1.xml", server.URL) || tree.Kind != SitemapKindIndex || len(tree.Children) != 1 {
+			t.Errorf("expected the returned tree to be unchanged by the next parse, got %+v", tree)
+		}
+	})
+
+	t.Run("cycles", func(t *testing.T) {
+		var cyclicServer *httptest.Server
+		cyclicServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var locs []string
+			switch r.URL.Path {
+			case "/sitemapindex.xml":
+				locs = []string{"/sitemapindex.xml", "/sitemapindex-child.xml"}
+			case "/sitemapindex-child.xml":
+				locs = []string{"/sitemapindex.xml", "/sitemap-01.xml"}
+			case "/sitemap-01.xml":
+				_, _ = fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><url><loc>%s/page-01</loc></url></urlset>`, cyclicServer.URL)
+				return
+			default:
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?><sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`)
+			for _, loc := range locs {
+				_, _ = fmt.Fprintf(w, "<sitemap><loc>%s%s</loc></sitemap>", cyclicServer.URL, loc)
+			}
+			_, _ = fmt.Fprint(w, `</sitemapindex>`)
+		}))
+		defer cyclicServer.Close()
+
+		s, _ := New().SetMultiThread(false).Parse(fmt.Sprintf("%s/sitemapindex.xml", cyclicServer.URL), nil)
+		var sb strings.Builder
+		writeTreeString(&sb, s.GetSitemapTree(), "")
+		want := "sitemapindex HOST/sitemapindex.xml urls=0\n" +
+			"  sitemapindex HOST/sitemapindex-child.xml urls=0\n" +
+			"    urlset HOST/sitemap-01.xml urls=1\n"
+		if got := strings.ReplaceAll(sb.String(), cyclicServer.URL, "HOST"); got != want {
+			t.Errorf("expected\n%s\ngot\n%s", want, got)
+		}
+	})
+
+	t.Run("nil receiver", func(t *testing.T) {
+		var s *S
+		if s.GetSitemapTree() != nil {
+			t.Error("expected nil tree")
+		}
+	})
+}
+
+// writeTreeString writes a line per node of the tree to sb, indenting the children.
+func writeTreeString(sb *strings.Builder, node *SitemapNode, indent string) {
+	if node == nil {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("%s%s %s urls=%d", indent, node.Kind, node.Loc, node.URLCount))
+	if node.LastMod != nil {
+		sb.WriteString(" lastmod=" + node.LastMod.UTC().Format(time.RFC3339))
+	}
+	if node.Err != nil {
+		sb.WriteString(" err=" + node.Err.Error())
+	}
+	sb.WriteString("\n")
+	for _, child := range node.Children {
+		writeTreeString(sb, child, indent+"  ")
+	}
+}
@@ -0,0 +1,390 @@
+package sitemap
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// xmlHeader is the XML declaration written at the beginning of the generated documents.
+	xmlHeader = `<?xml version="1.0" encoding="UTF-8"?>` + "\n"
+
+	// sitemapNamespace is the namespace of the sitemaps.org protocol.
+	sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+	// imageNamespace is the namespace of the image sitemap extension.
+	imageNamespace = "http://www.google.com/schemas/sitemap-image/1.1"
+
+	// videoNamespace is the namespace of the video sitemap extension.
+	videoNamespace = "http://www.google.com/schemas/sitemap-video/1.1"
+
+	// newsNamespace is the namespace of the news sitemap extension.
+	newsNamespace = "http://www.google.com/schemas/sitemap-news/0.9"
+
+	// mobileNamespace is the namespace of the mobile sitemap extension.
+	mobileNamespace = "http://www.google.com/schemas/sitemap-mobile/1.0"
+
+	// xhtmlNamespace is the namespace of the <xhtml:link> elements declaring the language alternates.
+	xhtmlNamespace = "http://www.w3.org/1999/xhtml"
+
+	// urlSetHeader is the opening of a generated <urlset> document without extensions.
+	urlSetHeader = xmlHeader + `<urlset xmlns="` + sitemapNamespace + `">` + "\n"
+
+	// urlSetFooter is the closing of a generated <urlset> document.
+	urlSetFooter = "</urlset>\n"
+
+	// sitemapIndexHeader is the opening of a generated <sitemapindex> document.
+	sitemapIndexHeader = xmlHeader + `<sitemapindex xmlns="` + sitemapNamespace + `">` + "\n"
+
+	// sitemapIndexFooter is the closing of a generated <sitemapindex> document.
+	sitemapIndexFooter = "</sitemapindex>\n"
+
+	// maxSitemapURLs is the maximum number of URLs a sitemap may contain according to the sitemaps.org protocol.
+	maxSitemapURLs = 50000
+
+	// maxSitemapBytes is the maximum uncompressed size of a sitemap according to the sitemaps.org protocol.
+	maxSitemapBytes = 50 * 1024 * 1024
+
+	// sitemapIndexFileName is the name of the sitemap index file written by WriteSplit.
+	sitemapIndexFileName = "sitemap_index.xml"
+)
+
+type (
+	// SplitOption is a function that modifies the settings of WriteSplit.
+	SplitOption func(*splitConfig)
+
+	// splitConfig is a structure that holds the settings of WriteSplit.
+	// The maxURLs field is the maximum number of URLs written to a single sitemap file.
+	// The maxBytes field is the maximum uncompressed size of a single sitemap file.
+	// The gzip field determines whether the sitemap files are gzip compressed.
+	splitConfig struct {
+		maxURLs  int
+		maxBytes int
+		gzip     bool
+	}
+
+	// sitemapIndexEntry is a <sitemap> entry of a generated <sitemapindex> document.
+	sitemapIndexEntry struct {
+		loc     string
+		lastMod *LastMod
+	}
+)
+
+// WithSplitMaxURLs sets the maximum number of URLs written to a single sitemap file by WriteSplit.
+// Values outside the range 1-50000 are ignored.
+func WithSplitMaxURLs(maxURLs int) SplitOption {
+	return func(cfg *splitConfig) {
+		if maxURLs > 0 && maxURLs <= maxSitemapURLs {
+			cfg.maxURLs = maxURLs
+		}
+	}
+}
+
+// WithSplitMaxBytes sets the maximum uncompressed size (in bytes) of a single sitemap file written by WriteSplit.
+// Values outside the range 1-52428800 are ignored.
+func WithSplitMaxBytes(maxBytes int) SplitOption {
+	return func(cfg *splitConfig) {
+		if maxBytes > 0 && maxBytes <= maxSitemapBytes {
+			cfg.maxBytes = maxBytes
+		}
+	}
+}
+
+// WithSplitGzip sets whether the sitemap files written by WriteSplit are gzip compressed.
+// Compressed files are written with the ".xml.gz" extension.
+func WithSplitGzip(compress bool) SplitOption {
+	return func(cfg *splitConfig) {
+		cfg.gzip = compress
+	}
+}
+
+// WriteXML writes the given URLs to w as a <urlset> document compliant with the sitemaps.org protocol.
+// The lastmod value is formatted as RFC3339 preserving its original offset,
+// the priority is formatted with one decimal and nil fields are omitted.
+// The image, video, news and xhtml:link extensions of the URLs are written as well,
+// and only the namespaces of the extensions actually used are declared.
+// Special characters in the values are escaped.
+// It returns an error if writing to w fails.
+func WriteXML(w io.Writer, urls []URL) error {
+	bw := bufio.NewWriter(w)
+
+	_, _ = bw.WriteString(urlSetHeaderFor(urls))
+	for _, u := range urls {
+		writeXMLURL(bw, u)
+	}
+	_, _ = bw.WriteString(urlSetFooter)
+
+	return bw.Flush()
+}
+
+// WriteSplit writes the given URLs into the dir directory as multiple sitemap files and a sitemap index referencing them.
+// The sitemap files are named sitemap-0001.xml, sitemap-0002.xml, etc. (with the ".xml.gz" extension if compressed),
+// and each of them contains at most 50000 URLs and is at most 50 MB uncompressed, unless lower limits are set by opts.
+// The sitemap index is written to sitemap_index.xml, its entries point to the sitemap files joined to baseURL,
+// and the lastmod of each entry is the latest lastmod of the URLs in the sitemap file.
+// Each sitemap file only declares the namespaces of the extensions its URLs use.
+// It returns an error if baseURL is not an absolute http or https URL, a single URL exceeds the size limit or writing the files fails.
+func WriteSplit(dir string, baseURL string, urls []URL, opts ...SplitOption) error {
+	cfg := splitConfig{
+		maxURLs:  maxSitemapURLs,
+		maxBytes: maxSitemapBytes,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if parsedURL, err := url.Parse(baseURL); err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+		return fmt.Errorf("invalid base URL %q: must be an absolute http or https URL", baseURL)
+	}
+
+	var entries []sitemapIndexEntry
+	var shard bytes.Buffer
+	var shardURLs int
+	var shardLastMod *LastMod
+	var shardNamespaces urlSetNamespaces
+
+	flush := func() error {
+		if shardURLs == 0 {
+			return nil
+		}
+		name := fmt.Sprintf("sitemap-%04d.xml", len(entries)+1)
+		if cfg.gzip {
+			name += ".gz"
+		}
+		loc, err := url.JoinPath(baseURL, name)
+		if err != nil {
+			return err
+		}
+		content := make([]byte, 0, len(shardNamespaces.header())+shard.Len()+len(urlSetFooter))
+		content = append(content, shardNamespaces.header()...)
+		content = append(content, shard.Bytes()...)
+		content = append(content, urlSetFooter...)
+		if err = writeFile(filepath.Join(dir, name), content, cfg.gzip); err != nil {
+			return err
+		}
+		entries = append(entries, sitemapIndexEntry{loc: loc, lastMod: shardLastMod})
+		shard.Reset()
+		shardURLs = 0
+		shardLastMod = nil
+		shardNamespaces = urlSetNamespaces{}
+		return nil
+	}
+
+	var entry bytes.Buffer
+	for _, u := range urls {
+		entry.Reset()
+		bw := bufio.NewWriter(&entry)
+		writeXMLURL(bw, u)
+		_ = bw.Flush()
+
+		var own urlSetNamespaces
+		own.add(u)
+		if len(own.header())+entry.Len()+len(urlSetFooter) > cfg.maxBytes {
+			return fmt.Errorf("URL %q exceeds the maximum sitemap size of %d bytes", u.Loc, cfg.maxBytes)
+		}
+		namespaces := shardNamespaces
+		namespaces.add(u)
+		if shardURLs == cfg.maxURLs || len(namespaces.header())+shard.Len()+entry.Len()+len(urlSetFooter) > cfg.maxBytes {
+			if err := flush(); err != nil {
+				return err
+			}
+			namespaces = own
+		}
+		shard.Write(entry.Bytes())
+		shardURLs++
+		shardNamespaces = namespaces
+		if u.LastMod != nil && (shardLastMod == nil || u.LastMod.After(shardLastMod.Time)) {
+			shardLastMod = u.LastMod
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	var index bytes.Buffer
+	bw := bufio.NewWriter(&index)
+	_, _ = bw.WriteString(sitemapIndexHeader)
+	for _, e := range entries {
+		_, _ = bw.WriteString("  <sitemap>\n")
+		writeXMLElement(bw, "    ", "loc", e.loc)
+		if e.lastMod != nil {
+			writeXMLElement(bw, "    ", "lastmod", e.lastMod.Format(time.RFC3339))
+		}
+		_, _ = bw.WriteString("  </sitemap>\n")
+	}
+	_, _ = bw.WriteString(sitemapIndexFooter)
+	_ = bw.Flush()
+
+	return writeFile(filepath.Join(dir, sitemapIndexFileName), index.Bytes(), false)
+}
+
+// writeFile writes content to the file at path, gzip compressing it if compress is true.
+func writeFile(path string, content []byte, compress bool) error {
+	if compress {
+		compressed, err := gzipBytes(content)
+		if err != nil {
+			return err
+		}
+		content = compressed
+	}
+	return os.WriteFile(path, content, 0o644)
+}
+
+// formatPriority formats the given priority with as many decimals as needed to read it back unchanged, and at least one, e.g. "1.0" or "0.85".
+func formatPriority(priority float32) string {
+	formatted := strconv.FormatFloat(float64(priority), 'f', -1, 32)
+	if !strings.Contains(formatted, ".") {
+		formatted += ".0"
+	}
+	return formatted
+}
+
+// writeXMLURL writes a single <url> element to bw, omitting the nil fields.
+func writeXMLURL(bw *bufio.Writer, u URL) {
+	_, _ = bw.WriteString("  <url>\n")
+	writeXMLElement(bw, "    ", "loc", u.Loc)
+	if u.LastMod != nil {
+		writeXMLElement(bw, "    ", "lastmod", u.LastMod.Format(time.RFC3339))
+	}
+	if u.ChangeFreq != nil {
+		writeXMLElement(bw, "    ", "changefreq", string(*u.ChangeFreq))
+	}
+	if u.Priority != nil {
+		writeXMLElement(bw, "    ", "priority", formatPriority(*u.Priority))
+	}
+	for _, image := range u.Images {
+		_, _ = bw.WriteString("    <image:image>\n")
+		writeXMLElementNotEmpty(bw, "      ", "image:loc", image.Loc)
+		writeXMLElementNotEmpty(bw, "      ", "image:caption", image.Caption)
+		writeXMLElementNotEmpty(bw, "      ", "image:geo_location", image.GeoLocation)
+		writeXMLElementNotEmpty(bw, "      ", "image:title", image.Title)
+		writeXMLElementNotEmpty(bw, "      ", "image:license", image.License)
+		_, _ = bw.WriteString("    </image:image>\n")
+	}
+	for _, video := range u.Videos {
+		_, _ = bw.WriteString("    <video:video>\n")
+		writeXMLElementNotEmpty(bw, "      ", "video:thumbnail_loc", video.ThumbnailLoc)
+		writeXMLElementNotEmpty(bw, "      ", "video:title", video.Title)
+		writeXMLElementNotEmpty(bw, "      ", "video:description", video.Description)
+		writeXMLElementNotEmpty(bw, "      ", "video:content_loc", video.ContentLoc)
+		writeXMLElementNotEmpty(bw, "      ", "video:player_loc", video.PlayerLoc)
+		writeXMLElementNotEmpty(bw, "      ", "video:duration", video.Duration)
+		writeXMLElementNotEmpty(bw, "      ", "video:expiration_date", video.ExpirationDate)
+		writeXMLElementNotEmpty(bw, "      ", "video:rating", video.Rating)
+		writeXMLElementNotEmpty(bw, "      ", "video:view_count", video.ViewCount)
+		writeXMLElementNotEmpty(bw, "      ", "video:publication_date", video.PublicationDate)
+		writeXMLElementNotEmpty(bw, "      ", "video:family_friendly", video.FamilyFriendly)
+		writeXMLElementNotEmpty(bw, "      ", "video:requires_subscription", video.RequiresSubscription)
+		writeXMLElementNotEmpty(bw, "      ", "video:live", video.Live)
+		for _, tag := range video.Tags {
+			writeXMLElement(bw, "      ", "video:tag", tag)
+		}
+		_, _ = bw.WriteString("    </video:video>\n")
+	}
+	if u.News != nil {
+		_, _ = bw.WriteString("    <news:news>\n")
+		_, _ = bw.WriteString("      <news:publication>\n")
+		writeXMLElementNotEmpty(bw, "        ", "news:name", u.News.Publication.Name)
+		writeXMLElementNotEmpty(bw, "        ", "news:language", u.News.Publication.Language)
+		_, _ = bw.WriteString("      </news:publication>\n")
+		writeXMLElementNotEmpty(bw, "      ", "news:publication_date", u.News.PublicationDate)
+		writeXMLElementNotEmpty(bw, "      ", "news:title", u.News.Title)
+		writeXMLElementNotEmpty(bw, "      ", "news:keywords", u.News.Keywords)
+		_, _ = bw.WriteString("    </news:news>\n")
+	}
+	for _, alternate := range u.Alternates {
+		_, _ = bw.WriteString("    <xhtml:link")
+		writeXMLAttrNotEmpty(bw, "rel", alternate.Rel)
+		writeXMLAttrNotEmpty(bw, "hreflang", alternate.Hreflang)
+		writeXMLAttrNotEmpty(bw, "href", alternate.Href)
+		_, _ = bw.WriteString("/>\n")
+	}
+	if u.Mobile != nil {
+		_, _ = bw.WriteString("    <mobile:mobile/>\n")
+	}
+	_, _ = bw.WriteString("  </url>\n")
+}
+
+// urlSetHeaderFor returns the opening of a <urlset> document containing the given URLs.
+// Besides the sitemaps.org namespace, only the namespaces of the extensions used by the URLs are declared.
+func urlSetHeaderFor(urls []URL) string {
+	var namespaces urlSetNamespaces
+	for _, u := range urls {
+		namespaces.add(u)
+	}
+	return namespaces.header()
+}
+
+// urlSetNamespaces records which extension namespaces the URLs of a <urlset> document use.
+type urlSetNamespaces struct {
+	images, videos, news, alternates, mobile bool
+}
+
+// add records the extensions used by the given URL.
+func (n *urlSetNamespaces) add(u URL) {
+	n.images = n.images || len(u.Images) > 0
+	n.videos = n.videos || len(u.Videos) > 0
+	n.news = n.news || u.News != nil
+	n.alternates = n.alternates || len(u.Alternates) > 0
+	n.mobile = n.mobile || u.Mobile != nil
+}
+
+// header returns the opening of a <urlset> document declaring the sitemaps.org namespace and the recorded extension namespaces.
+func (n urlSetNamespaces) header() string {
+	if n == (urlSetNamespaces{}) {
+		return urlSetHeader
+	}
+
+	header := xmlHeader + `<urlset xmlns="` + sitemapNamespace + `"`
+	if n.images {
+		header += ` xmlns:image="` + imageNamespace + `"`
+	}
+	if n.videos {
+		header += ` xmlns:video="` + videoNamespace + `"`
+	}
+	if n.news {
+		header += ` xmlns:news="` + newsNamespace + `"`
+	}
+	if n.alternates {
+		header += ` xmlns:xhtml="` + xhtmlNamespace + `"`
+	}
+	if n.mobile {
+		header += ` xmlns:mobile="` + mobileNamespace + `"`
+	}
+	return header + ">\n"
+}
+
+// writeXMLElement writes a single element with the given name and escaped text content to bw, prefixed by indent.
+// Write errors are not returned, as bufio.Writer keeps the first error and reports it on Flush.
+func writeXMLElement(bw *bufio.Writer, indent string, name string, value string) {
+	_, _ = bw.WriteString(indent + "<" + name + ">")
+	_ = xml.EscapeText(bw, []byte(value))
+	_, _ = bw.WriteString("</" + name + ">\n")
+}
+
+// writeXMLElementNotEmpty writes a single element like writeXMLElement, but only if value is not empty.
+func writeXMLElementNotEmpty(bw *bufio.Writer, indent string, name string, value string) {
+	if value == "" {
+		return
+	}
+	writeXMLElement(bw, indent, name, value)
+}
+
+// writeXMLAttrNotEmpty writes a single attribute with the given name and escaped value to bw, if value is not empty.
+func writeXMLAttrNotEmpty(bw *bufio.Writer, name string, value string) {
+	if value == "" {
+		return
+	}
+	_, _ = bw.WriteString(" " + name + `="`)
+	_ = xml.EscapeText(bw, []byte(value))
+	_, _ = bw.WriteString(`"`)
+}
@@ -0,0 +1,470 @@
+package sitemap
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteXML(t *testing.T) {
+	tests := []struct {
+		name string
+		urls []URL
+		want string
+	}{
+		{
+			name: "no URLs",
+			urls: nil,
+			want: "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n" +
+				"<urlset xmlns=\"http://www.sitemaps.org/schemas/sitemap/0.9\">\n" +
+				"</urlset>\n",
+		},
+		{
+			name: "all fields",
+			urls: []URL{
+				{
+					Loc:        "https://www.sitemaps.org/page?a=1&b=<2>",
+					LastMod:    pointerOfLastModTime(LastMod{time.Date(2024, time.February, 12, 12, 34, 56, 0, time.FixedZone("", 3600))}),
+					ChangeFreq: pointerOfURLChangeFreq(changeFreqDaily),
+					Priority:   pointerOfFloat32(0.8),
+				},
+			},
+			want: "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n" +
+				"<urlset xmlns=\"http://www.sitemaps.org/schemas/sitemap/0.9\">\n" +
+				"  <url>\n" +
+				"    <loc>https://www.sitemaps.org/page?a=1&amp;b=&lt;2&gt;</loc>\n" +
+				"    <lastmod>2024-02-12T12:34:56+01:00</lastmod>\n" +
+				"    <changefreq>daily</changefreq>\n" +
+				"    <priority>0.8</priority>\n" +
+				"  </url>\n" +
+				"</urlset>\n",
+		},
+		{
+			name: "nil fields omitted",
+			urls: []URL{
+				{Loc: "https://www.sitemaps.org/1"},
+				{Loc: "https://www.sitemaps.org/2", Priority: pointerOfFloat32(1)},
+			},
+			want: "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n" +
+				"<urlset xmlns=\"http://www.sitemaps.org/schemas/sitemap/0.9\">\n" +
+				"  <url>\n" +
+				"    <loc>https://www.sitemaps.org/1</loc>\n" +
+				"  </url>\n" +
+				"  <url>\n" +
+				"    <loc>https://www.sitemaps.org/2</loc>\n" +
+				"    <priority>1.0</priority>\n" +
+				"  </url>\n" +
+				"</urlset>\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteXML(&buf, test.urls); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != test.want {
+				t.Errorf("expected %q, got %q", test.want, buf.String())
+			}
+		})
+	}
+}
+
+func TestWriteXML_RoundTrip(t *testing.T) {
+	fixtures := []string{
+		"./test/sitemap-01.xml",
+		"./test/sitemap-02.xml",
+		"./test/sitemap-03.xml",
+		"./test/sitemap-04.xml",
+		"./test/sitemap-05.xml",
+		"./test/sitemap-06.xml",
+		"./test/sitemap-extensions.xml",
+		"./test/sitemap-extension-stats.xml",
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(fixture, func(t *testing.T) {
+			content, err := os.ReadFile(fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			parsed := New()
+			parsed.parse(fixture, string(content))
+			if parsed.GetErrorsCount() > 0 {
+				t.Fatalf("unexpected errors: %v", parsed.GetErrors())
+			}
+
+			var buf bytes.Buffer
+			if err = WriteXML(&buf, parsed.GetURLs()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			reparsed := New()
+			reparsed.parse(fixture, buf.String())
+			if reparsed.GetErrorsCount() > 0 {
+				t.Fatalf("unexpected errors: %v", reparsed.GetErrors())
+			}
+
+			if !reflect.DeepEqual(parsed.GetURLs(), reparsed.GetURLs()) {
+				t.Errorf("expected %v, got %v", parsed.GetURLs(), reparsed.GetURLs())
+			}
+		})
+	}
+}
+
+func TestWriteXML_PriorityRoundTrip(t *testing.T) {
+	priorities := []float32{0.85, 0.05, 1, 0, 0.333}
+	b := NewBuilder()
+	for i, priority := range priorities {
+		b.AddURL(fmt.Sprintf("https://www.sitemaps.org/%d", i), WithPriority(priority))
+	}
+	if errs := b.Errors(); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteXML(&buf, b.Build().URL); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "<priority>0.85</priority>") || !strings.Contains(buf.String(), "<priority>1.0</priority>") {
+		t.Errorf("expected the priorities to be written unchanged, got %s", buf.String())
+	}
+
+	reparsed := New()
+	reparsed.parse("sitemap.xml", buf.String())
+	urls := reparsed.GetURLs()
+	if len(urls) != len(priorities) {
+		t.Fatalf("expected %d URLs, got %d: %v", len(priorities), len(urls), reparsed.GetErrors())
+	}
+	for i, u := range urls {
+		if u.Priority == nil || *u.Priority != priorities[i] {
+			t.Errorf("expected priority %v, got %v", priorities[i], u.Priority)
+		}
+	}
+}
+
+func TestWriteXML_Extensions(t *testing.T) {
+	tests := []struct {
+		name string
+		urls []URL
+		want string
+	}{
+		{
+			name: "image only",
+			urls: []URL{
+				{
+					Loc:    "https://www.sitemaps.org/1",
+					Images: []Image{{Loc: "https://www.sitemaps.org/1.jpg", Caption: "a \"b\" & c"}},
+				},
+				{Loc: "https://www.sitemaps.org/2"},
+			},
+			want: "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n" +
+				"<urlset xmlns=\"http://www.sitemaps.org/schemas/sitemap/0.9\" xmlns:image=\"http://www.google.com/schemas/sitemap-image/1.1\">\n" +
+				"  <url>\n" +
+				"    <loc>https://www.sitemaps.org/1</loc>\n" +
+				"    <image:image>\n" +
+				"      <image:loc>https://www.sitemaps.org/1.jpg</image:loc>\n" +
+				"      <image:caption>a &#34;b&#34; &amp; c</image:caption>\n" +
+				"    </image:image>\n" +
+				"  </url>\n" +
+				"  <url>\n" +
+				"    <loc>https://www.sitemaps.org/2</loc>\n" +
+				"  </url>\n" +
+				"</urlset>\n",
+		},
+		{
+			name: "news and alternates",
+			urls: []URL{
+				{
+					Loc: "https://www.sitemaps.org/1",
+					News: &News{
+						Publication:     NewsPublication{Name: "Sitemaps", Language: "en"},
+						PublicationDate: "2024-02-12",
+						Title:           "Title",
+					},
+					Alternates: []Alternate{{Rel: "alternate", Hreflang: "de", Href: "https://www.sitemaps.org/de/1?a=1&b=2"}},
+				},
+			},
+			want: "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n" +
+				"<urlset xmlns=\"http://www.sitemaps.org/schemas/sitemap/0.9\" xmlns:news=\"http://www.google.com/schemas/sitemap-news/0.9\" xmlns:xhtml=\"http://www.w3.org/1999/xhtml\">\n" +
+				"  <url>\n" +
+				"    <loc>https://www.sitemaps.org/1</loc>\n" +
+				"    <news:news>\n" +
+				"      <news:publication>\n" +
+				"        <news:name>Sitemaps</news:name>\n" +
+				"        <news:language>en</news:language>\n" +
+				"      </news:publication>\n" +
+				"      <news:publication_date>2024-02-12</news:publication_date>\n" +
+				"      <news:title>Title</news:title>\n" +
+				"    </news:news>\n" +
+				"    <xhtml:link rel=\"alternate\" hreflang=\"de\" href=\"https://www.sitemaps.org/de/1?a=1&amp;b=2\"/>\n" +
+				"  </url>\n" +
+				"</urlset>\n",
+		},
+		{
+			name: "mobile",
+			urls: []URL{{Loc: "https://www.sitemaps.org/1", Mobile: &Mobile{}}},
+			want: "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n" +
+				"<urlset xmlns=\"http://www.sitemaps.org/schemas/sitemap/0.9\" xmlns:mobile=\"http://www.google.com/schemas/sitemap-mobile/1.0\">\n" +
+				"  <url>\n" +
+				"    <loc>https://www.sitemaps.org/1</loc>\n" +
+				"    <mobile:mobile/>\n" +
+				"  </url>\n" +
+				"</urlset>\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteXML(&buf, test.urls); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != test.want {
+				t.Errorf("expected %q, got %q", test.want, buf.String())
+			}
+		})
+	}
+}
+
+func TestWriteSplit(t *testing.T) {
+	urls := func(n int) []URL {
+		urls := make([]URL, 0, n)
+		for i := 0; i < n; i++ {
+			urls = append(urls, URL{
+				Loc:     fmt.Sprintf("https://www.sitemaps.org/page-%06d", i+1),
+				LastMod: pointerOfLastModTime(LastMod{time.Date(2024, time.February, 1+i%28, 0, 0, 0, 0, time.UTC)}),
+			})
+		}
+		return urls
+	}
+
+	tests := []struct {
+		name        string
+		urls        []URL
+		opts        []SplitOption
+		wantFiles   []string
+		wantCounts  []int
+		wantLastMod []string
+		wantErr     bool
+	}{
+		{
+			name:        "default limits",
+			urls:        urls(50001),
+			wantFiles:   []string{"sitemap-0001.xml", "sitemap-0002.xml"},
+			wantCounts:  []int{50000, 1},
+			wantLastMod: []string{"2024-02-28T00:00:00Z", "2024-02-21T00:00:00Z"},
+		},
+		{
+			name:        "max URLs",
+			urls:        urls(7),
+			opts:        []SplitOption{WithSplitMaxURLs(3)},
+			wantFiles:   []string{"sitemap-0001.xml", "sitemap-0002.xml", "sitemap-0003.xml"},
+			wantCounts:  []int{3, 3, 1},
+			wantLastMod: []string{"2024-02-03T00:00:00Z", "2024-02-06T00:00:00Z", "2024-02-07T00:00:00Z"},
+		},
+		{
+			name:        "max bytes",
+			urls:        urls(5),
+			opts:        []SplitOption{WithSplitMaxBytes(len(urlSetHeader) + 2*len("  <url>\n    <loc>https://www.sitemaps.org/page-000001</loc>\n    <lastmod>2024-02-01T00:00:00Z</lastmod>\n  </url>\n") + len(urlSetFooter))},
+			wantFiles:   []string{"sitemap-0001.xml", "sitemap-0002.xml", "sitemap-0003.xml"},
+			wantCounts:  []int{2, 2, 1},
+			wantLastMod: []string{"2024-02-02T00:00:00Z", "2024-02-04T00:00:00Z", "2024-02-05T00:00:00Z"},
+		},
+		{
+			name:        "gzip",
+			urls:        urls(3),
+			opts:        []SplitOption{WithSplitMaxURLs(2), WithSplitGzip(true)},
+			wantFiles:   []string{"sitemap-0001.xml.gz", "sitemap-0002.xml.gz"},
+			wantCounts:  []int{2, 1},
+			wantLastMod: []string{"2024-02-02T00:00:00Z", "2024-02-03T00:00:00Z"},
+		},
+		{
+			name:        "without lastmod",
+			urls:        []URL{{Loc: "https://www.sitemaps.org/page"}},
+			wantFiles:   []string{"sitemap-0001.xml"},
+			wantCounts:  []int{1},
+			wantLastMod: []string{""},
+		},
+		{
+			name:    "URL exceeds max bytes",
+			urls:    urls(1),
+			opts:    []SplitOption{WithSplitMaxBytes(100)},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// the generated files are served under the base URL to parse the whole tree back
+			dir := t.TempDir()
+			mux := http.NewServeMux()
+			mux.Handle("/sitemaps/", http.StripPrefix("/sitemaps/", http.FileServer(http.Dir(dir))))
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			err := WriteSplit(dir, server.URL+"/sitemaps", test.urls, test.opts...)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if err != nil {
+				return
+			}
+
+			index, err := os.ReadFile(filepath.Join(dir, "sitemap_index.xml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			smIndex, err := New().parseSitemapIndex(string(index))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(smIndex.Sitemap) != len(test.wantFiles) {
+				t.Fatalf("expected %d sitemaps, got %d", len(test.wantFiles), len(smIndex.Sitemap))
+			}
+
+			for i, file := range test.wantFiles {
+				if smIndex.Sitemap[i].Loc != server.URL+"/sitemaps/"+file {
+					t.Errorf("expected loc %s, got %s", server.URL+"/sitemaps/"+file, smIndex.Sitemap[i].Loc)
+				}
+				lastMod := ""
+				if smIndex.Sitemap[i].LastMod != nil {
+					lastMod = smIndex.Sitemap[i].LastMod.Format(time.RFC3339)
+				}
+				if lastMod != test.wantLastMod[i] {
+					t.Errorf("expected lastmod %s, got %s", test.wantLastMod[i], lastMod)
+				}
+
+				content, err := os.ReadFile(filepath.Join(dir, file))
+				if err != nil {
+					t.Fatal(err)
+				}
+				s := New()
+				if content, err = s.checkAndUnzipContent(file, content); err != nil {
+					t.Fatal(err)
+				}
+				s.parse(file, string(content))
+				if s.GetURLCount() != int64(test.wantCounts[i]) {
+					t.Errorf("expected %d URLs in %s, got %d", test.wantCounts[i], file, s.GetURLCount())
+				}
+			}
+
+			s, err := New().Parse(server.URL+"/sitemaps/sitemap_index.xml", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.GetErrorsCount() > 0 {
+				t.Fatalf("unexpected errors: %v", s.GetErrors())
+			}
+			if !compareURLsLocs(s.GetURLs(), test.urls) {
+				t.Error("parsed URLs are not equal to the written URLs")
+			}
+		})
+	}
+}
+
+func compareURLsLocs(sitemapURLs []URL, testCaseURLs []URL) bool {
+	if len(sitemapURLs) != len(testCaseURLs) {
+		return false
+	}
+
+	locs := make(map[string]int, len(sitemapURLs))
+	for _, u := range sitemapURLs {
+		locs[u.Loc]++
+	}
+	for _, u := range testCaseURLs {
+		if locs[u.Loc] == 0 {
+			return false
+		}
+		locs[u.Loc]--
+	}
+	return true
+}
+
+func TestWriteSplit_BaseURL(t *testing.T) {
+	urls := []URL{{Loc: "https://www.sitemaps.org/page"}}
+
+	tests := []struct {
+		name    string
+		baseURL string
+		wantErr bool
+	}{
+		{
+			name:    "absolute",
+			baseURL: "https://www.sitemaps.org/sitemaps",
+		},
+		{
+			name:    "empty",
+			baseURL: "",
+			wantErr: true,
+		},
+		{
+			name:    "relative",
+			baseURL: "/sitemaps",
+			wantErr: true,
+		},
+		{
+			name:    "scheme-relative",
+			baseURL: "//www.sitemaps.org/sitemaps",
+			wantErr: true,
+		},
+		{
+			name:    "unsupported scheme",
+			baseURL: "ftp://www.sitemaps.org/sitemaps",
+			wantErr: true,
+		},
+		{
+			name:    "unparseable",
+			baseURL: "https://www.sitemaps.org/%zz",
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			err := WriteSplit(dir, test.baseURL, urls)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if _, statErr := os.Stat(filepath.Join(dir, "sitemap-0001.xml")); test.wantErr && statErr == nil {
+				t.Error("expected no file to be written")
+			}
+		})
+	}
+}
+
+func TestWriteSplit_Namespaces(t *testing.T) {
+	urls := []URL{
+		{Loc: "https://www.sitemaps.org/1", Images: []Image{{Loc: "https://www.sitemaps.org/1.png"}}},
+		{Loc: "https://www.sitemaps.org/2"},
+		{Loc: "https://www.sitemaps.org/3", Mobile: &Mobile{}},
+	}
+	want := []string{
+		`<urlset xmlns="` + sitemapNamespace + `" xmlns:image="` + imageNamespace + `">`,
+		`<urlset xmlns="` + sitemapNamespace + `">`,
+		`<urlset xmlns="` + sitemapNamespace + `" xmlns:mobile="` + mobileNamespace + `">`,
+	}
+
+	dir := t.TempDir()
+	if err := WriteSplit(dir, "https://www.sitemaps.org", urls, WithSplitMaxURLs(1)); err != nil {
+		t.Fatal(err)
+	}
+	for i, wantHeader := range want {
+		content, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("sitemap-%04d.xml", i+1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(content), wantHeader+"\n") {
+			t.Errorf("sitemap-%04d.xml: expected %s, got %s", i+1, wantHeader, content)
+		}
+	}
+}